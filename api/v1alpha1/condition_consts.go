@@ -39,6 +39,14 @@ const (
 	// VirtualMachineImageNotReadyReason (Severity=Error) documents that the VirtualMachineImage specified in the VirtualMachineSpec
 	// is not ready.
 	VirtualMachineImageNotReadyReason = "VirtualMachineImageNotReady"
+
+	// VirtualMachineSourceNotFoundReason (Severity=Error) documents that the source VirtualMachine specified in the
+	// VirtualMachineSpec is not available.
+	VirtualMachineSourceNotFoundReason = "VirtualMachineSourceNotFound"
+
+	// VirtualMachineSourceNotReadyReason (Severity=Info) documents that the source VirtualMachine specified in the
+	// VirtualMachineSpec has not been created yet.
+	VirtualMachineSourceNotReadyReason = "VirtualMachineSourceNotReady"
 )
 
 const (
@@ -61,6 +69,20 @@ const (
 	GuestCustomizationFailedReason = "GuestCustomizationFailed"
 )
 
+const (
+	// VirtualMachineStartedCondition documents whether the startup probe of the VM has succeeded. The readiness and
+	// liveness probes of the VM are not run until this condition is true.
+	VirtualMachineStartedCondition ConditionType = "VirtualMachineStarted"
+
+	// StartupProbePendingReason (Severity=Info) documents that the startup probe has not succeeded yet, or has not
+	// been initiated because the VM is not powered on or guest customization has not succeeded.
+	StartupProbePendingReason = "StartupProbePending"
+
+	// StartupProbeFailedReason (Severity=Warning) documents that the startup probe failed FailureThreshold
+	// consecutive times and the VM was restarted.
+	StartupProbeFailedReason = "StartupProbeFailed"
+)
+
 const (
 	// VirtualMachineToolsCondition exposes the status of VMware Tools running in the guest OS, when available.
 	VirtualMachineToolsCondition ConditionType = "VirtualMachineTools"
@@ -72,6 +94,73 @@ const (
 	VirtualMachineToolsRunningReason = "VirtualMachineToolsRunning"
 )
 
+const (
+	// VirtualMachineClassConfigurationSyncedCondition documents whether the CPU and memory of the VM
+	// match its VirtualMachineClass.
+	VirtualMachineClassConfigurationSyncedCondition ConditionType = "VirtualMachineClassConfigurationSynced"
+
+	// VirtualMachineResizePendingReason (Severity=Info) documents that the VM has not yet been resized to
+	// match its VirtualMachineClass because the resize cannot be applied while the VM is powered on or
+	// suspended. The VM is resized when it is powered off, but not when a suspended VM is resumed. The
+	// Severity is Warning if the resize was attempted but failed.
+	VirtualMachineResizePendingReason = "ResizePending"
+
+	// VirtualMachineResizeDisabledReason (Severity=Info) documents that the VM is not resized to match its
+	// VirtualMachineClass because the updatePolicy of the class is None.
+	VirtualMachineResizeDisabledReason = "ResizeDisabled"
+)
+
+// Conditions related to the VirtualMachineClasses.
+const (
+	// VirtualMachineClassConfigSpecValidCondition documents whether the ConfigSpec XML of the VirtualMachineClass
+	// can be parsed.
+	VirtualMachineClassConfigSpecValidCondition ConditionType = "VirtualMachineClassConfigSpecValid"
+
+	// VirtualMachineClassDevicesAvailableCondition documents whether the vGPU profiles and the DynamicDirectPathIO
+	// devices of the VirtualMachineClass are available on the hosts.
+	VirtualMachineClassDevicesAvailableCondition ConditionType = "VirtualMachineClassDevicesAvailable"
+
+	// VirtualMachineClassReservationsValidCondition documents whether the CPU and memory reservations of the
+	// VirtualMachineClass are within its limits and hardware, and fit on the hosts.
+	VirtualMachineClassReservationsValidCondition ConditionType = "VirtualMachineClassReservationsValid"
+)
+
+// Condition.Reason for Conditions related to VirtualMachineClasses.
+const (
+	// VirtualMachineClassConfigSpecInvalidReason (Severity=Error) documents that the ConfigSpec XML of the
+	// VirtualMachineClass cannot be parsed.
+	VirtualMachineClassConfigSpecInvalidReason = "ConfigSpecInvalid"
+
+	// VirtualMachineClassDevicesNotFoundReason (Severity=Error) documents that a vGPU profile or DynamicDirectPathIO
+	// device of the VirtualMachineClass is not available on any host.
+	VirtualMachineClassDevicesNotFoundReason = "DevicesNotFound"
+
+	// VirtualMachineClassReservationsInvalidReason (Severity=Error) documents that a CPU or memory reservation of
+	// the VirtualMachineClass exceeds its limit or hardware, or does not fit on any host.
+	VirtualMachineClassReservationsInvalidReason = "ReservationsInvalid"
+
+	// VirtualMachineClassHostHardwareUnknownReason documents that the hardware of the hosts could not be
+	// retrieved, so the VirtualMachineClass could not be validated against it.
+	VirtualMachineClassHostHardwareUnknownReason = "HostHardwareUnknown"
+)
+
+// Conditions related to the WebConsoleRequests.
+const (
+	// WebConsoleRequestTicketIssuedCondition documents whether the ticket of the WebConsoleRequest was issued.
+	WebConsoleRequestTicketIssuedCondition ConditionType = "TicketIssued"
+)
+
+// Condition.Reason for Conditions related to WebConsoleRequests.
+const (
+	// WebConsoleRequestSerialPortNotFoundReason (Severity=Error) documents that the VM does not have a serial port
+	// that is backed by the vSPC, and one cannot be added because the VM is powered on.
+	WebConsoleRequestSerialPortNotFoundReason = "SerialPortNotFound"
+
+	// WebConsoleRequestTicketFailedReason (Severity=Warning) documents that the ticket could not be acquired.
+	// This is a warning because the reconciler will retry.
+	WebConsoleRequestTicketFailedReason = "TicketFailed"
+)
+
 // Common Condition.Reason used by VM Operator API objects.
 const (
 	// DeletingReason (Severity=Info) documents a condition not in Status=True because the underlying object it is currently being deleted.
@@ -175,3 +264,17 @@ const (
 	// hasn't been available yet.
 	ImageUnavailableReason = "ImageUnavailable"
 )
+
+// Condition.Reason for Conditions related to VirtualMachineSnapshot.
+const (
+	// VirtualMachineSnapshotVirtualMachineNotFoundReason (Severity=Error) documents that the VirtualMachine
+	// specified in the VirtualMachineSnapshotSpec does not exist.
+	VirtualMachineSnapshotVirtualMachineNotFoundReason = "VirtualMachineNotFound"
+
+	// VirtualMachineSnapshotVirtualMachineNotCreatedReason (Severity=Info) documents that the VirtualMachine
+	// specified in the VirtualMachineSnapshotSpec has not been created on vSphere yet.
+	VirtualMachineSnapshotVirtualMachineNotCreatedReason = "VirtualMachineNotCreated"
+
+	// VirtualMachineSnapshotCreateFailedReason (Severity=Error) documents that the snapshot could not be taken.
+	VirtualMachineSnapshotCreateFailedReason = "CreateFailed"
+)
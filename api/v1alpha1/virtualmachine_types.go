@@ -14,13 +14,51 @@ import (
 const (
 	VirtualMachinePoweredOff VirtualMachinePowerState = "poweredOff"
 	VirtualMachinePoweredOn  VirtualMachinePowerState = "poweredOn"
+	VirtualMachineSuspended  VirtualMachinePowerState = "suspended"
 )
 
 // VirtualMachinePowerState represents the power state of a VirtualMachine.
-// The value values are "poweredOn", and "poweredOff".
-// +kubebuilder:validation:Enum=poweredOff;poweredOn
+// The value values are "poweredOn", "poweredOff", and "suspended".
+// +kubebuilder:validation:Enum=poweredOff;poweredOn;suspended
 type VirtualMachinePowerState string
 
+// VirtualMachinePowerOpMode represents the various power operation modes
+// when powering off or suspending a VirtualMachine.
+// The valid values are "hard", "soft", and "trySoft".
+// +kubebuilder:validation:Enum=hard;soft;trySoft
+type VirtualMachinePowerOpMode string
+
+const (
+	// VirtualMachinePowerOpModeHard indicates to halt a VM when powering it
+	// off or when suspending a VM to not involve the guest.
+	VirtualMachinePowerOpModeHard VirtualMachinePowerOpMode = "hard"
+
+	// VirtualMachinePowerOpModeSoft indicates to ask VMware Tools running
+	// inside of a VM's guest to shutdown the guest gracefully when powering
+	// off a VM or when suspending a VM to allow the guest to participate.
+	//
+	// If this mode is set on a VM whose guest does not have VMware Tools or if
+	// VMware Tools is present but the operation fails, the VM may never realize
+	// the desired power state. It is recommended to use trySoft instead.
+	VirtualMachinePowerOpModeSoft VirtualMachinePowerOpMode = "soft"
+
+	// VirtualMachinePowerOpModeTrySoft indicates to first attempt a soft
+	// operation and fall back to hard if VMware Tools is not present in the
+	// guest, if the soft operation fails, or if the VM is not in the desired
+	// power state within five minutes.
+	VirtualMachinePowerOpModeTrySoft VirtualMachinePowerOpMode = "trySoft"
+)
+
+// VirtualMachineSoftPowerOp describes a soft power operation that the guest of a VirtualMachine was asked to
+// perform.
+type VirtualMachineSoftPowerOp struct {
+	// PowerState describes the power state that the guest was asked to change the VirtualMachine to.
+	PowerState VirtualMachinePowerState `json:"powerState"`
+
+	// RequestTime describes when the guest was asked to change the power state.
+	RequestTime metav1.Time `json:"requestTime"`
+}
+
 // VMStatusPhase is used to indicate the phase of a VirtualMachine's lifecycle.
 type VMStatusPhase string
 
@@ -61,6 +99,12 @@ const (
 	// this annotation to skip adding a default nic. VM Operator won't add default NIC to any existing VMs or new VMs
 	// with VirtualMachineNetworkInterfaces specified. This annotation is not required for such VMs.
 	NoDefaultNicAnnotation = GroupName + "/no-default-nic"
+
+	// ClassRolloutGenerationAnnotation is an annotation that is applied to a VirtualMachine by the
+	// VirtualMachineClass controller to power cycle the VirtualMachine as part of a Rolling update of its class.
+	// The value is the generation of the VirtualMachineClass that is being rolled out. The VirtualMachine is power
+	// cycled if its resize is pending and the value is greater than status.appliedClassGeneration.
+	ClassRolloutGenerationAnnotation = GroupName + "/class-rollout-generation"
 )
 
 // VirtualMachinePort is unused and can be considered deprecated.
@@ -113,8 +157,8 @@ type VirtualMachineNetworkInterface struct {
 }
 
 // VirtualMachineMetadataTransport is used to indicate the transport used by VirtualMachineMetadata
-// Valid values are "ExtraConfig", "OvfEnv", "vAppConfig" and "CloudInit".
-// +kubebuilder:validation:Enum=ExtraConfig;OvfEnv;vAppConfig;CloudInit
+// Valid values are "ExtraConfig", "OvfEnv", "vAppConfig", "CloudInit", "Sysprep" and "Ignition".
+// +kubebuilder:validation:Enum=ExtraConfig;OvfEnv;vAppConfig;CloudInit;Sysprep;Ignition
 type VirtualMachineMetadataTransport string
 
 const (
@@ -151,11 +195,42 @@ const (
 	// the VirtualMachineMetadata Transport Resource, i.e., a ConfigMap or Secret,
 	// in the "user-data" key is cloud-init userdata.
 	//
+	// Additional userdata parts may be set in keys prefixed with "user-data.",
+	// e.g. "user-data.10-packages". The "user-data" key and the parts, in
+	// lexical order of their keys, are merged into a MIME multipart archive.
+	// Cloud-config parts are merged by appending lists and recursively merging
+	// dictionaries. The "vendor-data" key is cloud-init vendordata, and the
+	// "network-config" key is a cloud-init network configuration used instead
+	// of the one generated from the VirtualMachine's network interfaces.
+	//
 	// Please note that, despite the name, VirtualMachineMetadata has no
 	// relationship to cloud-init instance metadata.
 	//
 	// For more information, please refer to cloud-init's official documentation.
 	VirtualMachineMetadataCloudInitTransport VirtualMachineMetadataTransport = "CloudInit"
+
+	// VirtualMachineMetadataSysprepTransport indicates that the data set in
+	// the VirtualMachineMetadata Transport Resource, which must be a Secret,
+	// is used to customize a Windows guest with Sysprep.
+	//
+	// If the "unattend" key is set, its value is used as the raw Sysprep
+	// unattend XML answer file. Otherwise, the Sysprep answers are built from
+	// the "admin-password", "join-domain", "domain-admin",
+	// "domain-admin-password", "join-workgroup", "time-zone", "product-key",
+	// "full-name" and "org-name" keys. The computer name is the
+	// VirtualMachine's name and the network is configured from the
+	// VirtualMachine's network interfaces.
+	VirtualMachineMetadataSysprepTransport VirtualMachineMetadataTransport = "Sysprep"
+
+	// VirtualMachineMetadataIgnitionTransport indicates the data set in
+	// the VirtualMachineMetadata Transport Resource, which must be a Secret,
+	// in the "ignition" key is an Ignition config, such as used by Fedora
+	// CoreOS and Flatcar Container Linux guests.
+	//
+	// The guest's network is configured by Afterburn from kernel arguments
+	// generated from the VirtualMachine's network interfaces. Only IPv4
+	// gateways are configured.
+	VirtualMachineMetadataIgnitionTransport VirtualMachineMetadataTransport = "Ignition"
 )
 
 // VirtualMachineMetadata defines any metadata that should be passed to the VirtualMachine instance.  A typical use
@@ -178,10 +253,32 @@ type VirtualMachineMetadata struct {
 	SecretName string `json:"secretName,omitempty"`
 
 	// Transport describes the name of a supported VirtualMachineMetadata transport protocol.  Currently, the only supported
-	// transport protocols are "ExtraConfig", "OvfEnv" and "CloudInit".
+	// transport protocols are "ExtraConfig", "OvfEnv", "vAppConfig", "CloudInit", "Sysprep" and "Ignition".
 	Transport VirtualMachineMetadataTransport `json:"transport,omitempty"`
+
+	// UpdatePolicy describes how a change to the ConfigMap or Secret is applied to a powered on VirtualMachine.
+	// When omitted, the default is "Reapply". Only a change to the data of the ConfigMap or Secret is applied. Keys
+	// that are removed from the ConfigMap or Secret are not removed from the VirtualMachine's ExtraConfig.
+	// +optional
+	UpdatePolicy VirtualMachineMetadataUpdatePolicy `json:"updatePolicy,omitempty"`
 }
 
+// VirtualMachineMetadataUpdatePolicy describes how a change to the VirtualMachineMetadata ConfigMap or Secret is
+// applied to a powered on VirtualMachine.
+// +kubebuilder:validation:Enum=Reapply;Reboot
+type VirtualMachineMetadataUpdatePolicy string
+
+const (
+	// VirtualMachineMetadataUpdatePolicyReapply indicates the metadata is pushed to the powered on VirtualMachine,
+	// i.e., its ExtraConfig, vAppConfig and guestinfo are updated. The guest is not customized again, so it is up
+	// to the guest to pick up the change.
+	VirtualMachineMetadataUpdatePolicyReapply VirtualMachineMetadataUpdatePolicy = "Reapply"
+
+	// VirtualMachineMetadataUpdatePolicyReboot indicates the VirtualMachine is powered off, customized again with
+	// the metadata, and powered back on.
+	VirtualMachineMetadataUpdatePolicyReboot VirtualMachineMetadataUpdatePolicy = "Reboot"
+)
+
 // VirtualMachineVolume describes a Volume that should be attached to a specific VirtualMachine.
 // Only one of PersistentVolumeClaim, VsphereVolume should be specified.
 type VirtualMachineVolume struct {
@@ -249,6 +346,14 @@ type Probe struct {
 	// +optional
 	GuestHeartbeat *GuestHeartbeatAction `json:"guestHeartbeat,omitempty"`
 
+	// HTTPGet specifies an action involving an HTTP GET request.
+	// +optional
+	HTTPGet *HTTPGetAction `json:"httpGet,omitempty"`
+
+	// Exec specifies an action involving a command executed inside the guest.
+	// +optional
+	Exec *ExecAction `json:"exec,omitempty"`
+
 	// TimeoutSeconds specifies a number of seconds after which the probe times out.
 	// Defaults to 10 seconds. Minimum value is 1.
 	// +optional
@@ -261,6 +366,25 @@ type Probe struct {
 	// +optional
 	// +kubebuilder:validation:Minimum:=1
 	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+
+	// InitialDelaySeconds specifies the number of seconds after the VirtualMachine is powered on, or restarted by
+	// its liveness probe, before the probe is initiated. For the readiness and liveness probes, the delay starts once
+	// the startup probe, if any, has succeeded. Defaults to 0 seconds.
+	// +optional
+	// +kubebuilder:validation:Minimum:=0
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+
+	// SuccessThreshold specifies the minimum consecutive successes for the probe to be considered successful after
+	// having failed. Defaults to 1. Must be 1 for a liveness or startup probe. Minimum value is 1.
+	// +optional
+	// +kubebuilder:validation:Minimum:=1
+	SuccessThreshold int32 `json:"successThreshold,omitempty"`
+
+	// FailureThreshold specifies the minimum consecutive failures for the probe to be considered failed after
+	// having succeeded. Defaults to 3, or 30 for a startup probe. Minimum value is 1.
+	// +optional
+	// +kubebuilder:validation:Minimum:=1
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
 }
 
 // TCPSocketAction describes an action based on opening a socket.
@@ -275,6 +399,67 @@ type TCPSocketAction struct {
 	Host string `json:"host,omitempty"`
 }
 
+// URIScheme identifies the scheme used for connection to a host for an HTTPGetAction.
+// +kubebuilder:validation:Enum=HTTP;HTTPS
+type URIScheme string
+
+const (
+	// URISchemeHTTP means that the scheme used will be http://.
+	URISchemeHTTP URIScheme = "HTTP"
+	// URISchemeHTTPS means that the scheme used will be https://.
+	URISchemeHTTPS URIScheme = "HTTPS"
+)
+
+// HTTPGetAction describes an action based on HTTP GET requests. The requests are sent to the VirtualMachine IP.
+// To set the HTTP Host header, use HTTPHeaders.
+type HTTPGetAction struct {
+	// Path specifies the path to access on the HTTP server. Defaults to "/".
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Port specifies a number or name of the port to access on the VirtualMachine.
+	// If the format of port is a number, it must be in the range 1 to 65535.
+	// If the format of name is a string, it must be an IANA_SVC_NAME.
+	Port intstr.IntOrString `json:"port"`
+
+	// Scheme specifies the scheme to use for connecting to the host. Defaults to HTTP. The certificate of
+	// an HTTPS server is not verified.
+	// +optional
+	Scheme URIScheme `json:"scheme,omitempty"`
+
+	// HTTPHeaders specifies custom headers to set in the request. HTTP allows repeated headers.
+	// +optional
+	HTTPHeaders []HTTPHeader `json:"httpHeaders,omitempty"`
+
+	// ExpectedStatuses specifies the ranges of HTTP response status codes that are considered successful.
+	// Defaults to 200 through 399.
+	// +optional
+	ExpectedStatuses []HTTPStatusRange `json:"expectedStatuses,omitempty"`
+}
+
+// HTTPHeader describes a custom header to be used in HTTP probes.
+type HTTPHeader struct {
+	// Name specifies the header field name.
+	Name string `json:"name"`
+
+	// Value specifies the header field value.
+	Value string `json:"value"`
+}
+
+// HTTPStatusRange describes an inclusive range of HTTP response status codes.
+type HTTPStatusRange struct {
+	// Min is the lowest status code of the range.
+	// +kubebuilder:validation:Minimum:=100
+	// +kubebuilder:validation:Maximum:=599
+	Min int32 `json:"min"`
+
+	// Max is the highest status code of the range. Defaults to Min.
+	// +optional
+	// +kubebuilder:validation:Minimum:=100
+	// +kubebuilder:validation:Maximum:=599
+	Max int32 `json:"max,omitempty"`
+}
+
 // GuestHeartbeatStatus is the status type for a GuestHeartbeat.
 type GuestHeartbeatStatus string
 
@@ -290,6 +475,27 @@ const (
 	GreenHeartbeatStatus GuestHeartbeatStatus = "green"
 )
 
+// VirtualMachineSource describes an existing VirtualMachine that is cloned to create a new VirtualMachine.
+type VirtualMachineSource struct {
+	// VirtualMachineName is the name of the VirtualMachine to clone. The VirtualMachine must be in the same
+	// namespace as the new VirtualMachine, and the user creating the new VirtualMachine must be allowed to get it.
+	// The disks of the source VirtualMachine's Volumes are not cloned.
+	VirtualMachineName string `json:"virtualMachineName"`
+
+	// LinkedClone describes whether the new VirtualMachine is a linked clone of the source VirtualMachine. A linked
+	// clone shares the disks of the source VirtualMachine's current snapshot instead of copying them, so the source
+	// VirtualMachine must have a snapshot, ex. one taken with a VirtualMachineSnapshot. If omitted, a full clone is
+	// created.
+	// +optional
+	LinkedClone bool `json:"linkedClone,omitempty"`
+
+	// ResetCustomization describes whether the guest customization of the source VirtualMachine is removed from the
+	// clone. When true, the "guestinfo" keys of the source VirtualMachine's ExtraConfig are not copied to the clone,
+	// so the guest OS is only customized with the metadata of the new VirtualMachine.
+	// +optional
+	ResetCustomization bool `json:"resetCustomization,omitempty"`
+}
+
 // GuestHeartbeatAction describes an action based on the guest heartbeat.
 type GuestHeartbeatAction struct {
 	// ThresholdStatus is the value that the guest heartbeat status must be at or above to be
@@ -300,6 +506,22 @@ type GuestHeartbeatAction struct {
 	ThresholdStatus GuestHeartbeatStatus `json:"thresholdStatus,omitempty"`
 }
 
+// ExecAction describes an action based on running a command inside the guest with the VMware Tools
+// guest operations. The probe is successful when the command exits with a status code of zero.
+type ExecAction struct {
+	// Command is the command line to execute inside the guest. The first element is the absolute path
+	// of the program and the remaining elements are its arguments. Each argument is quoted for the guest OS so
+	// that it is passed to the program as is, even if it contains spaces or quotes.
+	// The command is not run in a shell so to use shell features, such as pipes, call the shell explicitly.
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+
+	// SecretName is the name of a Secret in the same namespace as the VirtualMachine that contains
+	// the credentials of the guest account used to execute the command. The Secret must have the
+	// "username" and "password" keys, such as a Secret of type kubernetes.io/basic-auth.
+	SecretName string `json:"secretName"`
+}
+
 // VirtualMachineSpec defines the desired state of a VirtualMachine.
 type VirtualMachineSpec struct {
 	// ImageName describes the name of a VirtualMachineImage that is to be used as the base Operating System image of
@@ -307,14 +529,63 @@ type VirtualMachineSpec struct {
 	// attributes that may help users to identify the desired image to use.
 	ImageName string `json:"imageName"`
 
+	// Source describes an existing VirtualMachine in the same namespace that is cloned to create this VirtualMachine,
+	// instead of deploying the VirtualMachineImage specified by ImageName. The source VirtualMachine may be powered on
+	// or powered off.
+	//
+	// When Source is specified, ImageName defaults to the ImageName of the source VirtualMachine, and the
+	// VirtualMachineImage is still used to describe the guest OS of the clone. This field may not be changed once the
+	// VirtualMachine is created.
+	// +optional
+	Source *VirtualMachineSource `json:"source,omitempty"`
+
 	// ClassName describes the name of a VirtualMachineClass that is to be used as the overlaid resource configuration
 	// of VirtualMachine.  A VirtualMachineClass is used to further customize the attributes of the VirtualMachine
 	// instance.  See VirtualMachineClass for more description.
 	ClassName string `json:"className"`
 
-	// PowerState describes the desired power state of a VirtualMachine.  Valid power states are "poweredOff" and "poweredOn".
+	// PowerState describes the desired power state of a VirtualMachine.  Valid power states are "poweredOff", "poweredOn",
+	// and "suspended".
+	//
+	// Please note a VirtualMachine may not be created in the "suspended" power state, and only a VirtualMachine that
+	// is powered on may be suspended.
 	PowerState VirtualMachinePowerState `json:"powerState"`
 
+	// PowerOffMode describes the desired behavior when powering off a VirtualMachine.  Valid modes are "hard",
+	// "soft", and "trySoft".  If omitted, the mode defaults to "hard".
+	//
+	// Please see VirtualMachinePowerOpMode for more information on the supported modes.
+	// +optional
+	PowerOffMode VirtualMachinePowerOpMode `json:"powerOffMode,omitempty"`
+
+	// SuspendMode describes the desired behavior when suspending a VirtualMachine.  Valid modes are "hard",
+	// "soft", and "trySoft".  If omitted, the mode defaults to "hard".
+	//
+	// Please see VirtualMachinePowerOpMode for more information on the supported modes.
+	// +optional
+	SuspendMode VirtualMachinePowerOpMode `json:"suspendMode,omitempty"`
+
+	// NextRestartTime may be used to restart a VirtualMachine by setting the value of this field to "now"
+	// (case-insensitive) or to an RFC3339 timestamp, ex. "2006-01-02T15:04:05Z".
+	//
+	// A mutating webhook changes the value "now" to the current time as an RFC3339Nano timestamp in UTC. The
+	// VirtualMachine is restarted when this timestamp is later than status.lastRestartTime. Since the same timestamp
+	// never triggers more than one restart, a GitOps tool may repeatedly apply a VirtualMachine with a fixed
+	// timestamp and the VirtualMachine is only restarted once.
+	//
+	// A VirtualMachine that is not powered on is not restarted. Instead, the restart is considered satisfied the
+	// next time the VirtualMachine is powered on. The timestamp may not be in the future.
+	// +optional
+	NextRestartTime string `json:"nextRestartTime,omitempty"`
+
+	// RestartMode describes the desired behavior when restarting a VirtualMachine.  Valid modes are "hard", "soft",
+	// and "trySoft".  If omitted, the mode defaults to "trySoft".
+	//
+	// A soft restart asks VMware Tools to reboot the guest, while a hard restart resets the VirtualMachine. When the
+	// mode is "trySoft", a hard restart is performed if the guest cannot be rebooted.
+	// +optional
+	RestartMode VirtualMachinePowerOpMode `json:"restartMode,omitempty"`
+
 	// Ports is currently unused and can be considered deprecated.
 	// +optional
 	Ports []VirtualMachinePort `json:"ports,omitempty"`
@@ -352,6 +623,21 @@ type VirtualMachineSpec struct {
 	// +optional
 	ReadinessProbe *Probe `json:"readinessProbe,omitempty"`
 
+	// LivenessProbe describes a probe that is used to determine if the VirtualMachine is alive. The VirtualMachine
+	// is restarted, using RestartMode, when the probe fails FailureThreshold consecutive times.
+	// +optional
+	LivenessProbe *Probe `json:"livenessProbe,omitempty"`
+
+	// StartupProbe describes a probe that is used to determine if the guest of the VirtualMachine has started. The
+	// probe is not initiated until guest customization has succeeded, and the readiness and liveness probes are not
+	// initiated until the startup probe has succeeded. The VirtualMachine is restarted, using RestartMode, when the
+	// probe fails FailureThreshold consecutive times, which defaults to 30 for a startup probe so that VMs which take
+	// minutes to boot and customize are not restarted prematurely. The startup probe is run again after the
+	// VirtualMachine is powered off or restarted by VM Operator, or its BootTime changes. A reboot initiated from
+	// within the guest does not change the BootTime, so the startup probe is not run again after it.
+	// +optional
+	StartupProbe *Probe `json:"startupProbe,omitempty"`
+
 	// AdvancedOptions describes a set of optional, advanced options for configuring a VirtualMachine
 	AdvancedOptions *VirtualMachineAdvancedOptions `json:"advancedOptions,omitempty"`
 }
@@ -420,6 +706,10 @@ type VirtualMachineStatus struct {
 	// +optional
 	PowerState VirtualMachinePowerState `json:"powerState,omitempty"`
 
+	// BootTime describes the time the VirtualMachine was last powered on.
+	// +optional
+	BootTime *metav1.Time `json:"bootTime,omitempty"`
+
 	// Phase describes the current phase information of the VirtualMachine.
 	// +optional
 	Phase VMStatusPhase `json:"phase,omitempty"`
@@ -434,6 +724,16 @@ type VirtualMachineStatus struct {
 	// +optional
 	VmIp string `json:"vmIp,omitempty"` //nolint:revive,stylecheck
 
+	// PrimaryIPv4 describes the primary IPv4 address assigned to the guest operating system, if known. This is
+	// VmIp if it is an IPv4 address, and otherwise the first global IPv4 address of the networkInterfaces.
+	// +optional
+	PrimaryIPv4 string `json:"primaryIPv4,omitempty"`
+
+	// PrimaryIPv6 describes the primary IPv6 address assigned to the guest operating system, if known. This is
+	// VmIp if it is an IPv6 address, and otherwise the first global IPv6 address of the networkInterfaces.
+	// +optional
+	PrimaryIPv6 string `json:"primaryIPv6,omitempty"`
+
 	// UniqueID describes a unique identifier that is provided by the underlying infrastructure provider, such as
 	// vSphere.
 	// +optional
@@ -462,10 +762,41 @@ type VirtualMachineStatus struct {
 	// +optional
 	NetworkInterfaces []NetworkInterfaceStatus `json:"networkInterfaces,omitempty"`
 
+	// PendingSoftPowerOp describes the soft power operation that the guest was asked to perform, and that the
+	// VirtualMachine has not realized yet. When the power op mode is trySoft, a hard power operation is issued
+	// once the soft power operation is pending for five minutes.
+	// +optional
+	PendingSoftPowerOp *VirtualMachineSoftPowerOp `json:"pendingSoftPowerOp,omitempty"`
+
+	// LastRestartTime describes the value of spec.nextRestartTime that was last satisfied, either by restarting
+	// the VirtualMachine or by powering it on.
+	// +optional
+	LastRestartTime *metav1.Time `json:"lastRestartTime,omitempty"`
+
 	// Zone describes the availability zone where the VirtualMachine has been scheduled.
 	// Please note this field may be empty when the cluster is not zone-aware.
 	// +optional
 	Zone string `json:"zone,omitempty"`
+
+	// VmMetadataResourceVersion describes the resourceVersion of the VirtualMachineMetadata ConfigMap or Secret that
+	// was last applied to the VirtualMachine.
+	// +optional
+	VmMetadataResourceVersion string `json:"vmMetadataResourceVersion,omitempty"` //nolint:revive,stylecheck
+
+	// VmMetadataHash describes a hash of the data of the VirtualMachineMetadata ConfigMap or Secret that was last
+	// applied to the VirtualMachine.
+	// +optional
+	VmMetadataHash string `json:"vmMetadataHash,omitempty"` //nolint:revive,stylecheck
+
+	// RestartCount describes the number of times the VirtualMachine has been restarted because its liveness probe
+	// failed.
+	// +optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+
+	// AppliedClassGeneration describes the generation of the VirtualMachineClass whose CPU, memory and
+	// reservations were last applied to the VirtualMachine.
+	// +optional
+	AppliedClassGeneration int64 `json:"appliedClassGeneration,omitempty"`
 }
 
 func (vm *VirtualMachine) GetConditions() Conditions {
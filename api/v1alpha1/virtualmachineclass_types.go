@@ -6,6 +6,7 @@ package v1alpha1
 import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // VirtualMachineConfigSpec contains additional virtual machine
@@ -89,6 +90,41 @@ type VirtualMachineClassPolicies struct {
 	Resources VirtualMachineClassResources `json:"resources,omitempty"`
 }
 
+// VirtualMachineClassUpdatePolicyType represents how the VirtualMachines that use a VirtualMachineClass are
+// resized when the CPU, memory or reservations of the class are changed.
+// The valid values are "None", "OnNextPowerOn", and "Rolling".
+// +kubebuilder:validation:Enum=None;OnNextPowerOn;Rolling
+type VirtualMachineClassUpdatePolicyType string
+
+const (
+	// VirtualMachineClassUpdatePolicyNone indicates that existing VirtualMachines are not resized when the class
+	// is changed. Only new VirtualMachines are created with the changed class.
+	VirtualMachineClassUpdatePolicyNone VirtualMachineClassUpdatePolicyType = "None"
+
+	// VirtualMachineClassUpdatePolicyOnNextPowerOn indicates that VirtualMachines are resized when they are
+	// powered off, or hot resized if the class enables CPU and memory hot add. This is the default.
+	VirtualMachineClassUpdatePolicyOnNextPowerOn VirtualMachineClassUpdatePolicyType = "OnNextPowerOn"
+
+	// VirtualMachineClassUpdatePolicyRolling indicates that VirtualMachines are resized like OnNextPowerOn, and
+	// that the powered on VirtualMachines that cannot be hot resized are power cycled in batches that respect
+	// MaxUnavailable.
+	VirtualMachineClassUpdatePolicyRolling VirtualMachineClassUpdatePolicyType = "Rolling"
+)
+
+// VirtualMachineClassUpdatePolicy describes how the VirtualMachines that use a VirtualMachineClass are resized
+// when the class is changed.
+type VirtualMachineClassUpdatePolicy struct {
+	// Type describes how the VirtualMachines are resized. Defaults to OnNextPowerOn.
+	// +optional
+	Type VirtualMachineClassUpdatePolicyType `json:"type,omitempty"`
+
+	// MaxUnavailable is the maximum number of VirtualMachines that are power cycled at the same time by a Rolling
+	// update. The value may be a number or a percentage of the VirtualMachines that use the class, which is
+	// rounded down but is at least one. Defaults to 1.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 // VirtualMachineClassSpec defines the desired state of VirtualMachineClass.
 type VirtualMachineClassSpec struct {
 	// Hardware describes the configuration of the VirtualMachineClass attributes related to virtual hardware.  The
@@ -110,11 +146,31 @@ type VirtualMachineClassSpec struct {
 	// for a VirtualMachine
 	// +optional
 	ConfigSpec *VirtualMachineConfigSpec `json:"configSpec,omitempty"`
+
+	// UpdatePolicy describes how the existing VirtualMachines that use this VirtualMachineClass are resized when
+	// its CPU, memory or reservations are changed.
+	// +optional
+	UpdatePolicy VirtualMachineClassUpdatePolicy `json:"updatePolicy,omitempty"`
 }
 
-// VirtualMachineClassStatus defines the observed state of VirtualMachineClass.  VirtualMachineClasses are immutable,
-// non-dynamic resources, so this status is currently unused.
+// VirtualMachineClassStatus defines the observed state of VirtualMachineClass.
 type VirtualMachineClassStatus struct {
+	// Namespaces is the sorted list of the namespaces that have VirtualMachines that use this VirtualMachineClass.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// VirtualMachineCount is the number of VirtualMachines that use this VirtualMachineClass.
+	// +optional
+	VirtualMachineCount int32 `json:"virtualMachineCount,omitempty"`
+
+	// ObservedGeneration is the generation of the VirtualMachineClass that the conditions were last computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describes whether the VirtualMachineClass can be realized in the cluster. The Ready condition
+	// summarizes the other conditions.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -123,6 +179,8 @@ type VirtualMachineClassStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="CPU",type="string",JSONPath=".spec.hardware.cpus"
 // +kubebuilder:printcolumn:name="Memory",type="string",JSONPath=".spec.hardware.memory"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="VMs",type="integer",priority=1,JSONPath=".status.virtualMachineCount"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="VGPU-Devices-Profile-Names",type="string",priority=1,JSONPath=".spec.hardware.devices.vgpuDevices[*].profileName"
 // +kubebuilder:printcolumn:name="Passthrough-DeviceIDs",type="string",priority=1,JSONPath=".spec.hardware.devices.dynamicDirectPathIODevices[*].deviceID"
@@ -140,6 +198,14 @@ type VirtualMachineClass struct {
 	Status VirtualMachineClassStatus `json:"status,omitempty"`
 }
 
+func (vmClass *VirtualMachineClass) GetConditions() Conditions {
+	return vmClass.Status.Conditions
+}
+
+func (vmClass *VirtualMachineClass) SetConditions(conditions Conditions) {
+	vmClass.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // VirtualMachineClassList contains a list of VirtualMachineClass.
@@ -27,6 +27,94 @@ const (
 	VirtualMachineServiceTypeExternalName VirtualMachineServiceType = "ExternalName"
 )
 
+// VirtualMachineServiceSessionAffinity describes the session affinity of a VirtualMachineService.
+type VirtualMachineServiceSessionAffinity string
+
+// These types correspond to the core Service Affinity types.
+const (
+	// VirtualMachineServiceSessionAffinityClientIP means that connections from the same client IP are passed to
+	// the same VirtualMachine.
+	VirtualMachineServiceSessionAffinityClientIP VirtualMachineServiceSessionAffinity = "ClientIP"
+
+	// VirtualMachineServiceSessionAffinityNone means that there is no session affinity.
+	VirtualMachineServiceSessionAffinityNone VirtualMachineServiceSessionAffinity = "None"
+)
+
+// VirtualMachineServiceTrafficPolicy describes how traffic is routed to the VirtualMachines backing a
+// VirtualMachineService.
+type VirtualMachineServiceTrafficPolicy string
+
+// These types correspond to the core Service traffic policy types.
+const (
+	// VirtualMachineServiceTrafficPolicyCluster means that traffic is routed to all of the VirtualMachines backing
+	// the VirtualMachineService.
+	VirtualMachineServiceTrafficPolicyCluster VirtualMachineServiceTrafficPolicy = "Cluster"
+
+	// VirtualMachineServiceTrafficPolicyLocal means that traffic is only routed to the VirtualMachines backing
+	// the VirtualMachineService that are local to the node receiving the traffic. For external traffic, this
+	// preserves the client source IP.
+	VirtualMachineServiceTrafficPolicyLocal VirtualMachineServiceTrafficPolicy = "Local"
+)
+
+// VirtualMachineServiceTopologyMode describes the topology-aware routing mode of a VirtualMachineService.
+type VirtualMachineServiceTopologyMode string
+
+const (
+	// VirtualMachineServiceTopologyModeAuto means that traffic is preferentially routed to VirtualMachines in
+	// the same zone as the client.
+	VirtualMachineServiceTopologyModeAuto VirtualMachineServiceTopologyMode = "Auto"
+
+	// VirtualMachineServiceTopologyModeDisabled means that traffic is routed to VirtualMachines in any zone.
+	VirtualMachineServiceTopologyModeDisabled VirtualMachineServiceTopologyMode = "Disabled"
+)
+
+// IPFamily describes the IP address family of a VirtualMachineService.
+// +kubebuilder:validation:Enum=IPv4;IPv6
+type IPFamily string
+
+// These types correspond to the core Service IPFamily types.
+const (
+	// IPv4Protocol denotes the IPv4 address family.
+	IPv4Protocol IPFamily = "IPv4"
+
+	// IPv6Protocol denotes the IPv6 address family.
+	IPv6Protocol IPFamily = "IPv6"
+)
+
+// IPFamilyPolicy describes whether a VirtualMachineService is single-stack or dual-stack.
+type IPFamilyPolicy string
+
+// These types correspond to the core Service IPFamilyPolicy types.
+const (
+	// IPFamilyPolicySingleStack means that the VirtualMachineService has a single IP family.
+	IPFamilyPolicySingleStack IPFamilyPolicy = "SingleStack"
+
+	// IPFamilyPolicyPreferDualStack means that the VirtualMachineService has both IPv4 and IPv6 families when
+	// the cluster is dual-stack, and a single IP family otherwise.
+	IPFamilyPolicyPreferDualStack IPFamilyPolicy = "PreferDualStack"
+
+	// IPFamilyPolicyRequireDualStack means that the VirtualMachineService has both IPv4 and IPv6 families. The
+	// Service cannot be created if the cluster is not dual-stack.
+	IPFamilyPolicyRequireDualStack IPFamilyPolicy = "RequireDualStack"
+)
+
+// SessionAffinityConfig describes the session affinity configuration of a VirtualMachineService.
+type SessionAffinityConfig struct {
+	// ClientIP contains the configuration of the ClientIP session affinity.
+	// +optional
+	ClientIP *ClientIPConfig `json:"clientIP,omitempty"`
+}
+
+// ClientIPConfig describes the configuration of the ClientIP session affinity.
+type ClientIPConfig struct {
+	// TimeoutSeconds specifies the number of seconds the affinity of a client IP is kept.
+	// Defaults to 10800 seconds (3 hours). Minimum value is 1 and maximum value is 86400 (1 day).
+	// +optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=86400
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
 // VirtualMachineServicePort describes the specification of a service port to be exposed by a VirtualMachineService.
 // This VirtualMachineServicePort specification includes attributes that define the external and internal
 // representation of the service port.
@@ -120,6 +208,65 @@ type VirtualMachineServiceSpec struct {
 	// and requires Type to be ExternalName.
 	// +optional
 	ExternalName string `json:"externalName,omitempty"`
+
+	// SessionAffinity specifies whether connections from the same client IP are passed to the same
+	// VirtualMachine. Supported values are ClientIP and None. Defaults to None.
+	// This field will be ignored if the load balancer provider does not support the feature.
+	// +optional
+	// +kubebuilder:validation:Enum=ClientIP;None
+	SessionAffinity VirtualMachineServiceSessionAffinity `json:"sessionAffinity,omitempty"`
+
+	// SessionAffinityConfig contains the configuration of the session affinity. It may only be set when
+	// SessionAffinity is ClientIP.
+	// +optional
+	SessionAffinityConfig *SessionAffinityConfig `json:"sessionAffinityConfig,omitempty"`
+
+	// ExternalTrafficPolicy specifies how traffic received on the external addresses of a LoadBalancer
+	// VirtualMachineService is routed. Supported values are Cluster and Local. Defaults to Cluster.
+	// Only applies to VirtualMachineService Type: LoadBalancer.
+	// +optional
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy VirtualMachineServiceTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// InternalTrafficPolicy specifies how traffic received on the ClusterIP is routed. Supported values are
+	// Cluster and Local. Defaults to Cluster.
+	// Ignored if type is ExternalName.
+	// +optional
+	// +kubebuilder:validation:Enum=Cluster;Local
+	InternalTrafficPolicy VirtualMachineServiceTrafficPolicy `json:"internalTrafficPolicy,omitempty"`
+
+	// HealthCheckNodePort specifies the node port on which the health of the VirtualMachineService is checked
+	// by the load balancer. If not specified, a port is allocated.
+	// Only applies to VirtualMachineService Type: LoadBalancer with ExternalTrafficPolicy: Local.
+	// +optional
+	HealthCheckNodePort int32 `json:"healthCheckNodePort,omitempty"`
+
+	// IPFamilies specifies the IP families, IPv4 and/or IPv6, of the VirtualMachineService. The first family is
+	// the primary family of the VirtualMachineService. If not specified, the families are assigned according to
+	// IPFamilyPolicy and the configuration of the cluster. This field can not be changed through updates, except
+	// to add or remove the secondary family.
+	// Ignored if type is ExternalName.
+	// +optional
+	// +kubebuilder:validation:MaxItems=2
+	IPFamilies []IPFamily `json:"ipFamilies,omitempty"`
+
+	// IPFamilyPolicy specifies whether the VirtualMachineService is single-stack or dual-stack. Supported values
+	// are SingleStack, PreferDualStack and RequireDualStack. Defaults to SingleStack, or RequireDualStack if two
+	// IPFamilies are specified.
+	// Ignored if type is ExternalName.
+	// +optional
+	// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+	IPFamilyPolicy IPFamilyPolicy `json:"ipFamilyPolicy,omitempty"`
+
+	// TopologyMode specifies the topology-aware routing mode of the VirtualMachineService. Supported values are
+	// Auto and Disabled. Defaults to Disabled. When Auto, the EndpointSlices of the VirtualMachineService have
+	// zone hints from the zone of each VirtualMachine so that clients prefer VirtualMachines in their own zone.
+	// The hints are only set when every ready VirtualMachine has a zone and there are at least as many ready
+	// VirtualMachines as zones.
+	// Ignored if type is ExternalName.
+	// +optional
+	// +kubebuilder:validation:Enum=Auto;Disabled
+	TopologyMode VirtualMachineServiceTopologyMode `json:"topologyMode,omitempty"`
 }
 
 // VirtualMachineServiceStatus defines the observed state of VirtualMachineService.
@@ -0,0 +1,121 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VirtualMachineSnapshotSpec defines the desired state of a VirtualMachineSnapshot.
+type VirtualMachineSnapshotSpec struct {
+	// VirtualMachineName is the name of the VirtualMachine in the same namespace to snapshot.
+	VirtualMachineName string `json:"virtualMachineName"`
+
+	// Description is an optional description of the snapshot. A line that tags the snapshot with the UID of the
+	// VirtualMachineSnapshot is appended to the description of the vSphere snapshot.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Memory describes whether the memory of the VirtualMachine is included in the snapshot. This is only
+	// applicable when the VirtualMachine is powered on. Reverting to a snapshot that includes memory returns the
+	// VirtualMachine to the power state it was in when the snapshot was taken.
+	// +optional
+	Memory bool `json:"memory,omitempty"`
+
+	// Quiesce describes whether VMware Tools is used to quiesce the file system of the guest before the snapshot
+	// is taken. This is only applicable when the VirtualMachine is powered on and Memory is false.
+	// +optional
+	Quiesce bool `json:"quiesce,omitempty"`
+
+	// NextRevertTime may be used to revert the VirtualMachine to this snapshot by setting the value of this field
+	// to "now" (case-insensitive).
+	//
+	// A mutating webhook changes the value "now" to the current time as an RFC3339Nano timestamp in UTC. The
+	// VirtualMachine is reverted to this snapshot when this timestamp is later than status.lastRevertTime. Since
+	// the same timestamp never triggers more than one revert, a GitOps tool may repeatedly apply a
+	// VirtualMachineSnapshot with a fixed timestamp and the VirtualMachine is only reverted once.
+	//
+	// This field may not be set when the VirtualMachineSnapshot is created, and the timestamp may not be in the
+	// future.
+	// +optional
+	NextRevertTime string `json:"nextRevertTime,omitempty"`
+}
+
+// VirtualMachineSnapshotStatus defines the observed state of a VirtualMachineSnapshot.
+type VirtualMachineSnapshotStatus struct {
+	// UniqueID is the identifier of the snapshot on the vSphere VM.
+	// +optional
+	UniqueID string `json:"uniqueID,omitempty"`
+
+	// CreationTime is when the snapshot was taken.
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// Parent is the name of the parent snapshot, if any.
+	// +optional
+	Parent string `json:"parent,omitempty"`
+
+	// Size is the amount of storage consumed by the snapshot.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Quiesced describes whether the file system of the guest was quiesced when the snapshot was taken.
+	// +optional
+	Quiesced bool `json:"quiesced,omitempty"`
+
+	// LastRevertTime describes the value of spec.nextRevertTime that was last satisfied by reverting the
+	// VirtualMachine to this snapshot.
+	// +optional
+	LastRevertTime *metav1.Time `json:"lastRevertTime,omitempty"`
+
+	// Conditions describes the current condition information of the VirtualMachineSnapshot.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+func (s *VirtualMachineSnapshot) GetConditions() Conditions {
+	return s.Status.Conditions
+}
+
+func (s *VirtualMachineSnapshot) SetConditions(conditions Conditions) {
+	s.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmsnapshot
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="VirtualMachine",type="string",JSONPath=".spec.virtualMachineName"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Size",type="string",priority=1,JSONPath=".status.size"
+// +kubebuilder:printcolumn:name="Parent",type="string",priority=1,JSONPath=".status.parent"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VirtualMachineSnapshot is the Schema for the virtualmachinesnapshots API.
+// A VirtualMachineSnapshot represents a point-in-time snapshot of a VirtualMachine in the same namespace.
+type VirtualMachineSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineSnapshotSpec   `json:"spec,omitempty"`
+	Status VirtualMachineSnapshotStatus `json:"status,omitempty"`
+}
+
+func (s *VirtualMachineSnapshot) NamespacedName() string {
+	return s.Namespace + "/" + s.Name
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineSnapshotList contains a list of VirtualMachineSnapshot.
+type VirtualMachineSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineSnapshot `json:"items"`
+}
+
+func init() {
+	RegisterTypeWithScheme(&VirtualMachineSnapshot{}, &VirtualMachineSnapshotList{})
+}
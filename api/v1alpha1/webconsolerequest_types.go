@@ -7,20 +7,56 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// WebConsoleType is the type of console that is requested by a WebConsoleRequest.
+type WebConsoleType string
+
+const (
+	// WebConsoleTypeWebMKS requests a WebMKS ticket for the graphical console of the VM.
+	WebConsoleTypeWebMKS WebConsoleType = "WebMKS"
+
+	// WebConsoleTypeSerial requests a connection to a virtual serial port of the VM. The serial port is backed by
+	// the virtual serial port concentrator (vSPC) that is configured for VM Operator, and is added to the VM when
+	// it is powered on. A serial port cannot be added to a powered on VM, so a VM that was powered on before the
+	// vSPC was configured must be power cycled before its serial console can be requested.
+	WebConsoleTypeSerial WebConsoleType = "Serial"
+)
+
 // WebConsoleRequestSpec describes the specification for used to request a web console request.
 type WebConsoleRequestSpec struct {
 	// VirtualMachineName is the VM in the same namespace, for which the web console is requested.
 	VirtualMachineName string `json:"virtualMachineName"`
 	// PublicKey is used to encrypt the status.response. This is expected to be a RSA OAEP public key in X.509 PEM format.
 	PublicKey string `json:"publicKey"`
+	// ConsoleType is the type of console that is requested. When this is Serial, the status.response is the
+	// encrypted URI to connect to the serial console of the VM through the vSPC, which includes the UUID of this
+	// request and the expiry time of the ticket as the "uuid" and "expires" query parameters. Defaults to WebMKS.
+	// +optional
+	// +kubebuilder:validation:Enum=WebMKS;Serial
+	ConsoleType WebConsoleType `json:"consoleType,omitempty"`
+	// TTLSeconds is the lifetime of the ticket in seconds, after which the ticket expires and this request is
+	// deleted. This is bounded by the maximum lifetime that is configured for VM Operator. Defaults to 120.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TTLSeconds *int32 `json:"ttlSeconds,omitempty"`
+	// OneTimeUse specifies that the ticket can be used to connect to the web console only once. This request is
+	// marked as used and deleted after the first connection is validated.
+	// +optional
+	OneTimeUse bool `json:"oneTimeUse,omitempty"`
 }
 
 // WebConsoleRequestStatus defines the observed state, which includes the web console request itself.
 type WebConsoleRequestStatus struct {
-	// Response will be the authenticated ticket corresponding to this web console request.
+	// Response will be the authenticated ticket corresponding to this web console request. This is encrypted with
+	// the spec.publicKey.
 	Response string `json:"response,omitempty"`
 	// ExpiryTime is when the ticket referenced in Response will expire.
 	ExpiryTime metav1.Time `json:"expiryTime,omitempty"`
+	// Used is true when the ticket of a one-time use request has been used to connect to the web console.
+	// +optional
+	Used bool `json:"used,omitempty"`
+	// Conditions describes the current condition information of the WebConsoleRequest.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -41,6 +77,14 @@ func (s *WebConsoleRequest) NamespacedName() string {
 	return s.Namespace + "/" + s.Name
 }
 
+func (s *WebConsoleRequest) GetConditions() Conditions {
+	return s.Status.Conditions
+}
+
+func (s *WebConsoleRequest) SetConditions(conditions Conditions) {
+	s.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // WebConsoleRequestList contains a list of WebConsoleRequests.
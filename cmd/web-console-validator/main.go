@@ -12,14 +12,20 @@ import (
 	klog "k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlsig "sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
 	"github.com/vmware-tanzu/vm-operator/pkg"
 	"github.com/vmware-tanzu/vm-operator/pkg/webconsolevalidation"
 )
 
 var (
-	defaultServerPort = 9868
-	defaultServerPath = "/validate"
+	defaultServerPort      = 9868
+	defaultTLSServerPort   = 9869
+	defaultServerPath      = "/validate"
+	defaultTLSCertFile     = ""
+	defaultTLSKeyFile      = ""
+	defaultTLSCAFile       = ""
+	defaultCAConfigMapName = ""
 )
 
 func init() {
@@ -29,6 +35,21 @@ func init() {
 	if v, err := strconv.Atoi(os.Getenv("SERVER_PORT")); err == nil {
 		defaultServerPort = v
 	}
+	if v, err := strconv.Atoi(os.Getenv("TLS_SERVER_PORT")); err == nil {
+		defaultTLSServerPort = v
+	}
+	if v := os.Getenv("TLS_CERT_FILE"); v != "" {
+		defaultTLSCertFile = v
+	}
+	if v := os.Getenv("TLS_KEY_FILE"); v != "" {
+		defaultTLSKeyFile = v
+	}
+	if v := os.Getenv("TLS_CA_FILE"); v != "" {
+		defaultTLSCAFile = v
+	}
+	if v := os.Getenv("CA_CONFIGMAP_NAME"); v != "" {
+		defaultCAConfigMapName = v
+	}
 }
 
 func main() {
@@ -43,7 +64,12 @@ func main() {
 	serverPort := flag.Int(
 		"server-port",
 		defaultServerPort,
-		"The port on which web-console validation server to listen for incoming requests.",
+		"The port on which web-console validation server to listen for incoming plain HTTP requests. Set to 0 to not serve plain HTTP.",
+	)
+	tlsServerPort := flag.Int(
+		"tls-server-port",
+		defaultTLSServerPort,
+		"The port on which web-console validation server to listen for incoming HTTPS requests.",
 	)
 	serverPath := flag.String(
 		"server-path",
@@ -51,18 +77,79 @@ func main() {
 		"The pattern path to handle the web-console validation requests.",
 	)
 
+	tlsCertFile := flag.String(
+		"tls-cert-file",
+		defaultTLSCertFile,
+		"The serving certificate of the web-console validation server. The server only serves plain HTTP if this is not set.",
+	)
+	tlsKeyFile := flag.String(
+		"tls-key-file",
+		defaultTLSKeyFile,
+		"The serving key of the web-console validation server.",
+	)
+	tlsCAFile := flag.String(
+		"tls-ca-file",
+		defaultTLSCAFile,
+		"The CA certificate of the serving certificate of the web-console validation server.",
+	)
+	caConfigMapName := flag.String(
+		"ca-configmap-name",
+		defaultCAConfigMapName,
+		"The name of the ConfigMap in the pod's namespace that the CA certificate from --tls-ca-file is published in.",
+	)
+	rateLimitQPS := flag.Float64(
+		"rate-limit-qps",
+		webconsolevalidation.DefaultRateLimitQPS,
+		"The number of validation requests per second that are allowed per namespace. Set to 0 to disable rate limiting.",
+	)
+	rateLimitBurst := flag.Int(
+		"rate-limit-burst",
+		webconsolevalidation.DefaultRateLimitBurst,
+		"The burst of validation requests that are allowed per namespace.",
+	)
+
 	flag.Parse()
 
-	if initErr := webconsolevalidation.InitServer(); initErr != nil {
+	if (*tlsCertFile == "") != (*tlsKeyFile == "") {
+		logger.Error(nil, "Both --tls-cert-file and --tls-key-file must be set to serve over TLS")
+		os.Exit(1)
+	}
+	if *serverPort == 0 && *tlsCertFile == "" {
+		logger.Error(nil, "Either --server-port or --tls-cert-file and --tls-key-file must be set")
+		os.Exit(1)
+	}
+
+	addr := ""
+	if *serverPort != 0 {
+		addr = ":" + strconv.Itoa(*serverPort)
+	}
+
+	opts := webconsolevalidation.ServerOptions{
+		// Pass serverPath to the RunServer so one can check what path the server is listening on
+		// by looking at the commands specified in the server deployment spec.
+		Addr:                 addr,
+		TLSAddr:              ":" + strconv.Itoa(*tlsServerPort),
+		Path:                 *serverPath,
+		TLSCertFile:          *tlsCertFile,
+		TLSKeyFile:           *tlsKeyFile,
+		TLSCAFile:            *tlsCAFile,
+		CAConfigMapNamespace: os.Getenv("POD_NAMESPACE"),
+		CAConfigMapName:      *caConfigMapName,
+		RateLimitQPS:         *rateLimitQPS,
+		RateLimitBurst:       *rateLimitBurst,
+	}
+
+	ctx := ctrlsig.SetupSignalHandler()
+
+	if initErr := webconsolevalidation.InitServer(ctx, opts); initErr != nil {
 		logger.Error(initErr, "Failed to initialize web-console validation server")
 		os.Exit(1)
 	}
 
-	logger.Info("Starting the web-console validation server", "port", *serverPort, "path", *serverPath)
+	logger.Info("Starting the web-console validation server", "port", *serverPort, "path", *serverPath,
+		"tls", *tlsCertFile != "", "tlsPort", *tlsServerPort, "rateLimitQPS", *rateLimitQPS, "rateLimitBurst", *rateLimitBurst)
 
-	// Pass serverPath to the RunServer so one can check what path the server is listening on
-	// by looking at the commands specified in the server deployment spec.
-	runErr := webconsolevalidation.RunServer(":"+strconv.Itoa(*serverPort), *serverPath)
+	runErr := webconsolevalidation.RunServer(ctx, opts)
 	if runErr != nil && runErr != http.ErrServerClosed {
 		logger.Error(runErr, "Error occurred while running the web-console validation server!")
 	}
@@ -10,13 +10,16 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -27,6 +30,7 @@ import (
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	pkgmgr "github.com/vmware-tanzu/vm-operator/pkg/manager"
 	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
 	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/prober"
@@ -46,7 +50,7 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
 	)
 
-	proberManager, err := prober.AddToManager(mgr, ctx.VMProvider)
+	proberManager, err := prober.AddToManager(mgr, ctx.VMProvider, ctx.MaxProbeWorkers)
 	if err != nil {
 		return err
 	}
@@ -63,7 +67,20 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 		For(controlledType).
 		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
 		Watches(&source.Kind{Type: &vmopv1alpha1.VirtualMachineClassBinding{}},
-			handler.EnqueueRequestsFromMapFunc(classBindingToVMMapperFn(ctx, r.Client)))
+			handler.EnqueueRequestsFromMapFunc(classBindingToVMMapperFn(ctx, r.Client))).
+		// Only spec changes of a class affect its VMs, and not the status updates of the class controller.
+		Watches(&source.Kind{Type: &vmopv1alpha1.VirtualMachineClass{}},
+			handler.EnqueueRequestsFromMapFunc(classToVMMapperFn(ctx, r.Client)),
+			ctrlbuilder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Only the metadata of ConfigMaps and Secrets is cached since there may be many, large ones.
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(vmMetadataToVMMapperFn(ctx, r.Client, "ConfigMap",
+				pkgmgr.VirtualMachineMetadataConfigMapIndexField)),
+			ctrlbuilder.OnlyMetadata).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(vmMetadataToVMMapperFn(ctx, r.Client, "Secret",
+				pkgmgr.VirtualMachineMetadataSecretIndexField)),
+			ctrlbuilder.OnlyMetadata)
 
 	if !lib.IsWCPVMImageRegistryEnabled() {
 		builder = builder.Watches(&source.Kind{Type: &vmopv1alpha1.ContentSourceBinding{}},
@@ -164,6 +181,66 @@ func classBindingToVMMapperFn(ctx *context.ControllerManagerContext, c client.Cl
 	}
 }
 
+// classToVMMapperFn returns a mapper function that can be used to queue reconcile request
+// for the VirtualMachines in response to an event on the VirtualMachineClass resource, so
+// that the VMs are resized when their class is edited.
+func classToVMMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(o client.Object) []reconcile.Request {
+	return func(o client.Object) []reconcile.Request {
+		class := o.(*vmopv1alpha1.VirtualMachineClass)
+		logger := ctx.Logger.WithValues("name", class.Name)
+
+		logger.V(4).Info("Reconciling all VMs referencing a VM class because of a VirtualMachineClass watch")
+
+		vmList := &vmopv1alpha1.VirtualMachineList{}
+		if err := c.List(ctx, vmList, client.MatchingFields{pkgmgr.VirtualMachineClassNameIndexField: class.Name}); err != nil {
+			logger.Error(err, "Failed to list VirtualMachines for reconciliation due to VirtualMachineClass watch")
+			return nil
+		}
+
+		var reconcileRequests []reconcile.Request
+		for _, vm := range vmList.Items {
+			if vm.Spec.ClassName == class.Name {
+				key := client.ObjectKey{Namespace: vm.Namespace, Name: vm.Name}
+				reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: key})
+			}
+		}
+
+		logger.V(4).Info("Returning VM reconcile requests due to VirtualMachineClass watch", "requests", reconcileRequests)
+		return reconcileRequests
+	}
+}
+
+// vmMetadataToVMMapperFn returns a mapper function that can be used to queue reconcile request
+// for the VirtualMachines in response to an event on their metadata ConfigMap or Secret, so that
+// the VM metadata is reapplied when it is edited. The VMs are looked up by the given index field.
+func vmMetadataToVMMapperFn(
+	ctx *context.ControllerManagerContext,
+	c client.Client,
+	kind, indexField string) func(o client.Object) []reconcile.Request {
+
+	return func(o client.Object) []reconcile.Request {
+		logger := ctx.Logger.WithValues("kind", kind, "name", o.GetName(), "namespace", o.GetNamespace())
+
+		vmList := &vmopv1alpha1.VirtualMachineList{}
+		if err := c.List(ctx, vmList, client.InNamespace(o.GetNamespace()),
+			client.MatchingFields{indexField: o.GetName()}); err != nil {
+			logger.Error(err, "Failed to list VirtualMachines for reconciliation due to VM metadata watch")
+			return nil
+		}
+
+		var reconcileRequests []reconcile.Request
+		for _, vm := range vmList.Items {
+			key := client.ObjectKey{Namespace: vm.Namespace, Name: vm.Name}
+			reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: key})
+		}
+
+		if len(reconcileRequests) > 0 {
+			logger.V(4).Info("Returning VM reconcile requests due to VM metadata watch", "requests", reconcileRequests)
+		}
+		return reconcileRequests
+	}
+}
+
 func NewReconciler(
 	client client.Client,
 	logger logr.Logger,
@@ -193,10 +270,11 @@ type Reconciler struct {
 
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vmware.com,resources=virtualnetworkinterfaces;virtualnetworkinterfaces/status,verbs=create;get;list;patch;delete;watch;update
 // +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events;configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=resourcequotas;namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclassbindings,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=contentsources,verbs=get;list;watch
@@ -269,6 +347,11 @@ func requeueDelay(ctx *context.VirtualMachineContext) time.Duration {
 		return 10 * time.Second
 	}
 
+	// Check again whether the guest realized the requested power state, or if it is time to give up on it.
+	if ctx.VM.Status.PendingSoftPowerOp != nil {
+		return 10 * time.Second
+	}
+
 	return 0
 }
 
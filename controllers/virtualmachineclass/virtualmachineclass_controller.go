@@ -1,4 +1,4 @@
-// Copyright (c) 2020-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2022 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachineclass
@@ -6,20 +6,51 @@ package virtualmachineclass
 import (
 	goctx "context"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	vimTypes "github.com/vmware/govmomi/vim25/types"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	pkgmgr "github.com/vmware-tanzu/vm-operator/pkg/manager"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	// RequeueAfter is how often the VirtualMachineClasses are validated again, since the hardware of the hosts
+	// may change.
+	RequeueAfter = 10 * time.Minute
+
+	// HostHardwareTTL is how long the hardware of the hosts is cached, so that it is not retrieved from vCenter
+	// for every VirtualMachineClass that is reconciled.
+	HostHardwareTTL = time.Minute
 )
 
 // AddToManager adds this package's controller to the provided manager.
@@ -36,40 +67,93 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 		mgr.GetClient(),
 		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
 		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProvider,
 	)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(controlledType).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		Watches(&source.Kind{Type: &vmopv1alpha1.VirtualMachine{}},
+			handler.EnqueueRequestsFromMapFunc(vmToClassMapperFn),
+			builder.WithPredicates(vmClassUsagePredicate())).
 		Complete(r)
 }
 
+// vmClassUsagePredicate filters the VM events to the ones that change the usage of a class, which are when
+// VMs are created, deleted or change their class, and when a VM is resized by the rolling update of its class.
+func vmClassUsagePredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldVM, oldOK := e.ObjectOld.(*vmopv1alpha1.VirtualMachine)
+			newVM, newOK := e.ObjectNew.(*vmopv1alpha1.VirtualMachine)
+			if !oldOK || !newOK {
+				return false
+			}
+			return oldVM.Spec.ClassName != newVM.Spec.ClassName ||
+				oldVM.Status.AppliedClassGeneration != newVM.Status.AppliedClassGeneration
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// vmToClassMapperFn returns the VirtualMachineClass of the VM, so that the usage of the class is updated when
+// VMs are created, deleted or change their class. The handler maps both the old and the new VM of an update.
+func vmToClassMapperFn(o client.Object) []reconcile.Request {
+	vm := o.(*vmopv1alpha1.VirtualMachine)
+	if vm.Spec.ClassName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: vm.Spec.ClassName}}}
+}
+
 func NewReconciler(
 	client client.Client,
 	logger logr.Logger,
-	recorder record.Recorder) *Reconciler {
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterface) *Reconciler {
 	return &Reconciler{
-		Client:   client,
-		Logger:   logger,
-		Recorder: recorder,
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
 	}
 }
 
 // Reconciler reconciles a VirtualMachineClass object.
 type Reconciler struct {
 	client.Client
-	Logger   logr.Logger
-	Recorder record.Recorder
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterface
+
+	hostHardwareMu     sync.Mutex
+	hostHardware       *vmprovider.HostHardware
+	hostHardwareExpiry time.Time
+
+	// rolloutExpectations are, for each class, the VMs that have been selected for the rolling update, and the
+	// class generation they were selected for, until the selection is observed in the cache.
+	rolloutExpectationsMu sync.Mutex
+	rolloutExpectations   map[string]map[string]int64
 }
 
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch;patch
 
-func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	vmClass := &vmopv1alpha1.VirtualMachineClass{}
 	err := r.Get(ctx, req.NamespacedName, vmClass)
 	if err != nil {
 		if apiErrors.IsNotFound(err) {
+			r.forgetRolloutExpectations(req.Name)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
@@ -81,15 +165,421 @@ func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (ctrl.Result
 		VMClass: vmClass,
 	}
 
+	patchHelper, err := patch.NewHelper(vmClass, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", vmClassCtx)
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, vmClass); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			vmClassCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	if !vmClass.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
 	if err := r.ReconcileNormal(vmClassCtx); err != nil {
 		vmClassCtx.Logger.Error(err, "Failed to reconcile VirtualMachineClass")
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: RequeueAfter}, nil
 }
 
-func (r *Reconciler) ReconcileNormal(_ *context.VirtualMachineClassContext) error {
-	// NoOp.
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineClassContext) error {
+	vmClass := ctx.VMClass
+
+	vms, err := r.getVirtualMachines(ctx)
+	if err != nil {
+		return err
+	}
+	reconcileUsage(vmClass, vms)
+
+	configSpec := r.reconcileConfigSpec(ctx)
+
+	var retErr error
+	hostHardware, err := r.getHostHardware(ctx)
+	if err != nil {
+		for _, t := range []vmopv1alpha1.ConditionType{
+			vmopv1alpha1.VirtualMachineClassDevicesAvailableCondition,
+			vmopv1alpha1.VirtualMachineClassReservationsValidCondition,
+		} {
+			// Unknown conditions do not make the summary not Ready, so the checks that could not be done are
+			// marked False with a warning instead.
+			conditions.MarkFalse(vmClass, t, vmopv1alpha1.VirtualMachineClassHostHardwareUnknownReason,
+				vmopv1alpha1.ConditionSeverityWarning, "Failed to get the hardware of the hosts: %v", err)
+		}
+		retErr = errors.Wrap(err, "failed to get the hardware of the hosts")
+	} else {
+		reconcileDevices(vmClass, configSpec, hostHardware)
+		reconcileReservations(vmClass, hostHardware)
+	}
+
+	conditions.SetSummary(vmClass, conditions.WithConditions(
+		vmopv1alpha1.VirtualMachineClassConfigSpecValidCondition,
+		vmopv1alpha1.VirtualMachineClassDevicesAvailableCondition,
+		vmopv1alpha1.VirtualMachineClassReservationsValidCondition,
+	))
+	vmClass.Status.ObservedGeneration = vmClass.Generation
+
+	if retErr != nil {
+		return retErr
+	}
+
+	return r.reconcileRollingUpdate(ctx, vms)
+}
+
+// getHostHardware returns the hardware of the hosts, which is cached for the HostHardwareTTL.
+func (r *Reconciler) getHostHardware(ctx goctx.Context) (*vmprovider.HostHardware, error) {
+	r.hostHardwareMu.Lock()
+	defer r.hostHardwareMu.Unlock()
+
+	if r.hostHardware != nil && time.Now().Before(r.hostHardwareExpiry) {
+		return r.hostHardware, nil
+	}
+
+	hostHardware, err := r.VMProvider.GetHostHardware(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.hostHardware = hostHardware
+	r.hostHardwareExpiry = time.Now().Add(HostHardwareTTL)
+	return hostHardware, nil
+}
+
+// getVirtualMachines returns the VMs that use the class, sorted by namespace and name.
+func (r *Reconciler) getVirtualMachines(ctx *context.VirtualMachineClassContext) ([]*vmopv1alpha1.VirtualMachine, error) {
+	vmList := &vmopv1alpha1.VirtualMachineList{}
+	if err := r.List(ctx, vmList, client.MatchingFields{pkgmgr.VirtualMachineClassNameIndexField: ctx.VMClass.Name}); err != nil {
+		return nil, errors.Wrap(err, "failed to list VirtualMachines")
+	}
+
+	var vms []*vmopv1alpha1.VirtualMachine
+	for i := range vmList.Items {
+		if vm := &vmList.Items[i]; vm.Spec.ClassName == ctx.VMClass.Name {
+			vms = append(vms, vm)
+		}
+	}
+
+	sort.Slice(vms, func(i, j int) bool {
+		if vms[i].Namespace != vms[j].Namespace {
+			return vms[i].Namespace < vms[j].Namespace
+		}
+		return vms[i].Name < vms[j].Name
+	})
+
+	return vms, nil
+}
+
+// reconcileUsage sets the namespaces and the count of the VMs that use the class.
+func reconcileUsage(vmClass *vmopv1alpha1.VirtualMachineClass, vms []*vmopv1alpha1.VirtualMachine) {
+	namespaces := map[string]struct{}{}
+	for _, vm := range vms {
+		namespaces[vm.Namespace] = struct{}{}
+	}
+
+	vmClass.Status.Namespaces = make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		vmClass.Status.Namespaces = append(vmClass.Status.Namespaces, ns)
+	}
+	sort.Strings(vmClass.Status.Namespaces)
+	vmClass.Status.VirtualMachineCount = int32(len(vms))
+}
+
+// reconcileRollingUpdate selects the next batch of VMs to power cycle when the updatePolicy of the class is
+// Rolling. A VM is selected by setting the ClassRolloutGenerationAnnotation, and the VM controller then power
+// cycles the VM so that it is resized. The VMs that have been selected but not yet resized are unavailable, and
+// no more than maxUnavailable VMs are selected at the same time. The class is not rolled out unless it is Ready.
+// The VMs are listed from the cache, which may not have the annotations that were just patched yet, so the
+// selections are also remembered as expectations until the cache has observed them.
+func (r *Reconciler) reconcileRollingUpdate(
+	ctx *context.VirtualMachineClassContext,
+	vms []*vmopv1alpha1.VirtualMachine) error {
+
+	vmClass := ctx.VMClass
+	if vmClass.Spec.UpdatePolicy.Type != vmopv1alpha1.VirtualMachineClassUpdatePolicyRolling {
+		r.forgetRolloutExpectations(vmClass.Name)
+		return nil
+	}
+	if !conditions.IsTrue(vmClass, vmopv1alpha1.ReadyCondition) {
+		ctx.Logger.Info("Skipping the rolling update because the VirtualMachineClass is not Ready")
+		return nil
+	}
+
+	maxUnavailable, err := getMaxUnavailable(vmClass, len(vms))
+	if err != nil {
+		return err
+	}
+
+	r.rolloutExpectationsMu.Lock()
+	defer r.rolloutExpectationsMu.Unlock()
+
+	expectations := r.observeRolloutExpectations(vmClass.Name, vms)
+
+	unavailable := 0
+	var pending []*vmopv1alpha1.VirtualMachine
+	for _, vm := range vms {
+		_, expected := expectations[vm.NamespacedName()]
+		switch {
+		case expected || isRolloutInProgress(vm):
+			unavailable++
+		case isRolloutPending(vm, vmClass):
+			pending = append(pending, vm)
+		}
+	}
+
+	for _, vm := range pending {
+		if unavailable >= maxUnavailable {
+			break
+		}
+
+		ctx.Logger.Info("Power cycling VM for the rolling update", "vm", vm.NamespacedName(),
+			"appliedClassGeneration", vm.Status.AppliedClassGeneration)
+
+		vmPatch := client.MergeFrom(vm.DeepCopy())
+		if vm.Annotations == nil {
+			vm.Annotations = map[string]string{}
+		}
+		vm.Annotations[vmopv1alpha1.ClassRolloutGenerationAnnotation] = strconv.FormatInt(vmClass.Generation, 10)
+		if err := r.Patch(ctx, vm, vmPatch); err != nil {
+			return errors.Wrapf(err, "failed to select VM %s for the rolling update", vm.NamespacedName())
+		}
+
+		expectations[vm.NamespacedName()] = vmClass.Generation
+		unavailable++
+	}
+
 	return nil
 }
+
+// forgetRolloutExpectations removes the rollout expectations of the class.
+func (r *Reconciler) forgetRolloutExpectations(className string) {
+	r.rolloutExpectationsMu.Lock()
+	defer r.rolloutExpectationsMu.Unlock()
+	delete(r.rolloutExpectations, className)
+}
+
+// observeRolloutExpectations returns the rollout expectations of the class, after removing the ones that the
+// cache has observed, which are the VMs that have the selection annotation, or have been resized, or no longer
+// use the class. Must be called with the rolloutExpectationsMu held.
+func (r *Reconciler) observeRolloutExpectations(
+	className string,
+	vms []*vmopv1alpha1.VirtualMachine) map[string]int64 {
+
+	if r.rolloutExpectations == nil {
+		r.rolloutExpectations = map[string]map[string]int64{}
+	}
+	expectations := r.rolloutExpectations[className]
+	if expectations == nil {
+		expectations = map[string]int64{}
+		r.rolloutExpectations[className] = expectations
+	}
+
+	vmsByName := make(map[string]*vmopv1alpha1.VirtualMachine, len(vms))
+	for _, vm := range vms {
+		vmsByName[vm.NamespacedName()] = vm
+	}
+
+	for name, generation := range expectations {
+		vm, ok := vmsByName[name]
+		if !ok || vm.Status.AppliedClassGeneration >= generation {
+			delete(expectations, name)
+			continue
+		}
+		value, err := strconv.ParseInt(vm.Annotations[vmopv1alpha1.ClassRolloutGenerationAnnotation], 10, 64)
+		if err == nil && value >= generation {
+			delete(expectations, name)
+		}
+	}
+
+	return expectations
+}
+
+// getMaxUnavailable returns the maximum number of the VMs that may be power cycled at the same time.
+func getMaxUnavailable(vmClass *vmopv1alpha1.VirtualMachineClass, total int) (int, error) {
+	maxUnavailable := vmClass.Spec.UpdatePolicy.MaxUnavailable
+	if maxUnavailable == nil {
+		return 1, nil
+	}
+
+	value, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, total, false)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid maxUnavailable")
+	}
+	if value < 1 {
+		// At least one VM is power cycled so the rolling update progresses.
+		value = 1
+	}
+	return value, nil
+}
+
+// isRolloutInProgress returns true if the VM has been selected to be power cycled and has not been resized yet.
+func isRolloutInProgress(vm *vmopv1alpha1.VirtualMachine) bool {
+	value, ok := vm.Annotations[vmopv1alpha1.ClassRolloutGenerationAnnotation]
+	if !ok {
+		return false
+	}
+	generation, err := strconv.ParseInt(value, 10, 64)
+	return err == nil && generation > vm.Status.AppliedClassGeneration
+}
+
+// isRolloutPending returns true if the VM is powered on and must be power cycled to be resized to the class.
+func isRolloutPending(vm *vmopv1alpha1.VirtualMachine, vmClass *vmopv1alpha1.VirtualMachineClass) bool {
+	if _, paused := vm.Annotations[vmopv1alpha1.PauseAnnotation]; paused {
+		return false
+	}
+	if vm.Spec.PowerState != vmopv1alpha1.VirtualMachinePoweredOn ||
+		vm.Status.PowerState != vmopv1alpha1.VirtualMachinePoweredOn {
+		return false
+	}
+	if vm.Status.AppliedClassGeneration >= vmClass.Generation {
+		return false
+	}
+	return conditions.GetReason(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition) ==
+		vmopv1alpha1.VirtualMachineResizePendingReason
+}
+
+// reconcileConfigSpec sets whether the ConfigSpec XML parses, and returns the parsed ConfigSpec.
+func (r *Reconciler) reconcileConfigSpec(ctx *context.VirtualMachineClassContext) *vimTypes.VirtualMachineConfigSpec {
+	vmClass := ctx.VMClass
+
+	if vmClass.Spec.ConfigSpec == nil || vmClass.Spec.ConfigSpec.XML == "" {
+		conditions.MarkTrue(vmClass, vmopv1alpha1.VirtualMachineClassConfigSpecValidCondition)
+		return nil
+	}
+
+	configSpec, err := util.UnmarshalConfigSpecFromBase64XML([]byte(vmClass.Spec.ConfigSpec.XML))
+	if err != nil {
+		conditions.MarkFalse(vmClass, vmopv1alpha1.VirtualMachineClassConfigSpecValidCondition,
+			vmopv1alpha1.VirtualMachineClassConfigSpecInvalidReason, vmopv1alpha1.ConditionSeverityError,
+			"Failed to parse the ConfigSpec XML: %v", err)
+		return nil
+	}
+
+	conditions.MarkTrue(vmClass, vmopv1alpha1.VirtualMachineClassConfigSpecValidCondition)
+	return configSpec
+}
+
+// reconcileDevices sets whether the vGPU profiles and the DynamicDirectPathIO devices of the class, including
+// those in the ConfigSpec, are available on the hosts.
+func reconcileDevices(
+	vmClass *vmopv1alpha1.VirtualMachineClass,
+	configSpec *vimTypes.VirtualMachineConfigSpec,
+	hostHardware *vmprovider.HostHardware) {
+
+	vgpuProfiles := map[string]struct{}{}
+	for _, profile := range hostHardware.VGPUProfiles {
+		vgpuProfiles[profile] = struct{}{}
+	}
+	pciDevices := map[vmprovider.PCIDevice]struct{}{}
+	for _, dev := range hostHardware.PCIDevices {
+		pciDevices[dev] = struct{}{}
+	}
+
+	var missing []string
+	checkVGPU := func(profile string) {
+		if _, ok := vgpuProfiles[profile]; !ok {
+			missing = append(missing, fmt.Sprintf("vGPU profile %q", profile))
+		}
+	}
+	checkDevice := func(dev vmprovider.PCIDevice) {
+		if _, ok := pciDevices[dev]; !ok {
+			missing = append(missing, fmt.Sprintf("DynamicDirectPathIO device %#04x:%#04x", dev.VendorID, dev.DeviceID))
+		}
+	}
+
+	for _, vgpu := range vmClass.Spec.Hardware.Devices.VGPUDevices {
+		checkVGPU(vgpu.ProfileName)
+	}
+	for _, dev := range vmClass.Spec.Hardware.Devices.DynamicDirectPathIODevices {
+		checkDevice(vmprovider.PCIDevice{VendorID: dev.VendorID, DeviceID: dev.DeviceID})
+	}
+
+	for _, dev := range util.DevicesFromConfigSpec(configSpec) {
+		pciDev, ok := dev.(*vimTypes.VirtualPCIPassthrough)
+		if !ok {
+			continue
+		}
+		switch backing := pciDev.Backing.(type) {
+		case *vimTypes.VirtualPCIPassthroughVmiopBackingInfo:
+			checkVGPU(backing.Vgpu)
+		case *vimTypes.VirtualPCIPassthroughDynamicBackingInfo:
+			for _, allowed := range backing.AllowedDevice {
+				checkDevice(vmprovider.PCIDevice{
+					VendorID: int(uint16(allowed.VendorId)),
+					DeviceID: int(uint16(allowed.DeviceId)),
+				})
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		conditions.MarkFalse(vmClass, vmopv1alpha1.VirtualMachineClassDevicesAvailableCondition,
+			vmopv1alpha1.VirtualMachineClassDevicesNotFoundReason, vmopv1alpha1.ConditionSeverityError,
+			"Not available on any host: %s", strings.Join(missing, ", "))
+		return
+	}
+
+	conditions.MarkTrue(vmClass, vmopv1alpha1.VirtualMachineClassDevicesAvailableCondition)
+}
+
+// reconcileReservations sets whether the CPU and memory reservations of the class are within its limits and
+// hardware, and fit on the largest host. The host checks are skipped when the host capacity is not known.
+func reconcileReservations(vmClass *vmopv1alpha1.VirtualMachineClass, hostHardware *vmprovider.HostHardware) {
+	hardware := vmClass.Spec.Hardware
+	requests := vmClass.Spec.Policies.Resources.Requests
+	limits := vmClass.Spec.Policies.Resources.Limits
+
+	var problems []string
+
+	if !requests.Cpu.IsZero() {
+		if !limits.Cpu.IsZero() && requests.Cpu.Cmp(limits.Cpu) > 0 {
+			problems = append(problems, fmt.Sprintf("CPU request %s exceeds the limit %s",
+				requests.Cpu.String(), limits.Cpu.String()))
+		}
+		if hardware.Cpus > 0 && requests.Cpu.Cmp(*resource.NewQuantity(hardware.Cpus, resource.DecimalSI)) > 0 {
+			problems = append(problems, fmt.Sprintf("CPU request %s exceeds the %d CPUs",
+				requests.Cpu.String(), hardware.Cpus))
+		}
+		if freq := hostHardware.MinCPUFreqMHz; freq > 0 && hostHardware.MaxCPUMHz > 0 {
+			// The provider sets the reservation in MHz from the minimum CPU frequency of the hosts.
+			mhz := int64(math.Ceil(float64(requests.Cpu.MilliValue()) * float64(freq) / 1000))
+			if mhz > hostHardware.MaxCPUMHz {
+				problems = append(problems, fmt.Sprintf("CPU request of %d MHz exceeds the largest host capacity of %d MHz",
+					mhz, hostHardware.MaxCPUMHz))
+			}
+		}
+	}
+
+	if !requests.Memory.IsZero() {
+		if !limits.Memory.IsZero() && requests.Memory.Cmp(limits.Memory) > 0 {
+			problems = append(problems, fmt.Sprintf("memory request %s exceeds the limit %s",
+				requests.Memory.String(), limits.Memory.String()))
+		}
+		if !hardware.Memory.IsZero() && requests.Memory.Cmp(hardware.Memory) > 0 {
+			problems = append(problems, fmt.Sprintf("memory request %s exceeds the memory %s",
+				requests.Memory.String(), hardware.Memory.String()))
+		}
+		if hostHardware.MaxMemoryMB > 0 {
+			mb := int64(math.Ceil(float64(requests.Memory.Value()) / (1024 * 1024)))
+			if mb > hostHardware.MaxMemoryMB {
+				problems = append(problems, fmt.Sprintf("memory request of %d MB exceeds the largest host capacity of %d MB",
+					mb, hostHardware.MaxMemoryMB))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		conditions.MarkFalse(vmClass, vmopv1alpha1.VirtualMachineClassReservationsValidCondition,
+			vmopv1alpha1.VirtualMachineClassReservationsInvalidReason, vmopv1alpha1.ConditionSeverityError,
+			"%s", strings.Join(problems, "; "))
+		return
+	}
+
+	conditions.MarkTrue(vmClass, vmopv1alpha1.VirtualMachineClassReservationsValidCondition)
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2020 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2022 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachineclass_test
@@ -11,8 +11,11 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
@@ -32,16 +35,16 @@ func intgTests() {
 			Spec: vmopv1alpha1.VirtualMachineClassSpec{
 				Hardware: vmopv1alpha1.VirtualMachineClassHardware{
 					Cpus:   4,
-					Memory: resource.MustParse("1Mi"),
+					Memory: resource.MustParse("1Gi"),
 				},
 				Policies: vmopv1alpha1.VirtualMachineClassPolicies{
 					Resources: vmopv1alpha1.VirtualMachineClassResources{
 						Requests: vmopv1alpha1.VirtualMachineResourceSpec{
-							Cpu:    resource.MustParse("1000Mi"),
+							Cpu:    resource.MustParse("1"),
 							Memory: resource.MustParse("100Mi"),
 						},
 						Limits: vmopv1alpha1.VirtualMachineResourceSpec{
-							Cpu:    resource.MustParse("2000Mi"),
+							Cpu:    resource.MustParse("2"),
 							Memory: resource.MustParse("200Mi"),
 						},
 					},
@@ -64,7 +67,14 @@ func intgTests() {
 			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
 		})
 
-		It("NoOp", func() {
+		It("Marks the class Ready", func() {
+			Eventually(func() bool {
+				obj := &vmopv1alpha1.VirtualMachineClass{}
+				if err := ctx.Client.Get(ctx, client.ObjectKeyFromObject(vmClass), obj); err != nil {
+					return false
+				}
+				return conditions.IsTrue(obj, vmopv1alpha1.ReadyCondition)
+			}).Should(BeTrue())
 		})
 	})
 }
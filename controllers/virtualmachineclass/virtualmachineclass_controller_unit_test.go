@@ -1,19 +1,31 @@
-// Copyright (c) 2020-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2020-2022 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package virtualmachineclass_test
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineclass"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
@@ -26,26 +38,72 @@ func unitTestsReconcile() {
 		initObjects []client.Object
 		ctx         *builder.UnitTestContextForController
 
-		reconciler *virtualmachineclass.Reconciler
-		vmClassCtx *vmopContext.VirtualMachineClassContext
-		vmClass    *vmopv1alpha1.VirtualMachineClass
+		reconciler     *virtualmachineclass.Reconciler
+		fakeVMProvider *providerfake.VMProvider
+		vmClassCtx     *vmopContext.VirtualMachineClassContext
+		vmClass        *vmopv1alpha1.VirtualMachineClass
+		hostHardware   *vmprovider.HostHardware
+		hostHardwareFn func(context.Context) (*vmprovider.HostHardware, error)
 	)
 
+	newVM := func(namespace, name, className string) *vmopv1alpha1.VirtualMachine {
+		return &vmopv1alpha1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       vmopv1alpha1.VirtualMachineSpec{ClassName: className},
+		}
+	}
+
 	BeforeEach(func() {
 		vmClass = &vmopv1alpha1.VirtualMachineClass{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: "dummy-vmclass",
+				Name:       "dummy-vmclass",
+				Generation: 2,
 			},
+			Spec: vmopv1alpha1.VirtualMachineClassSpec{
+				Hardware: vmopv1alpha1.VirtualMachineClassHardware{
+					Cpus:   4,
+					Memory: resource.MustParse("4Gi"),
+				},
+				Policies: vmopv1alpha1.VirtualMachineClassPolicies{
+					Resources: vmopv1alpha1.VirtualMachineClassResources{
+						Requests: vmopv1alpha1.VirtualMachineResourceSpec{
+							Cpu:    resource.MustParse("1"),
+							Memory: resource.MustParse("1Gi"),
+						},
+						Limits: vmopv1alpha1.VirtualMachineResourceSpec{
+							Cpu:    resource.MustParse("2"),
+							Memory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+		}
+
+		hostHardware = &vmprovider.HostHardware{
+			VGPUProfiles:  []string{"grid_v100-4q"},
+			PCIDevices:    []vmprovider.PCIDevice{{VendorID: 0x10de, DeviceID: 0x1eb8}},
+			MaxCPUMHz:     16000,
+			MaxMemoryMB:   8192,
+			MinCPUFreqMHz: 2000,
 		}
 	})
 
 	JustBeforeEach(func() {
+		initObjects = append(initObjects, vmClass)
 		ctx = suite.NewUnitTestContextForController(initObjects...)
 		reconciler = virtualmachineclass.NewReconciler(
 			ctx.Client,
 			ctx.Logger,
 			ctx.Recorder,
+			ctx.VMProvider,
 		)
+		fakeVMProvider = ctx.VMProvider.(*providerfake.VMProvider)
+		hostHardwareFn = func(_ context.Context) (*vmprovider.HostHardware, error) {
+			return hostHardware, nil
+		}
+		fakeVMProvider.GetHostHardwareFn = func(ctx context.Context) (*vmprovider.HostHardware, error) {
+			return hostHardwareFn(ctx)
+		}
 
 		vmClassCtx = &vmopContext.VirtualMachineClassContext{
 			Context: ctx,
@@ -54,15 +112,298 @@ func unitTestsReconcile() {
 		}
 	})
 
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+		fakeVMProvider.Reset()
+		fakeVMProvider = nil
+	})
+
 	Context("ReconcileNormal", func() {
-		BeforeEach(func() {
-			initObjects = append(initObjects, vmClass)
+		var err error
+
+		JustBeforeEach(func() {
+			err = reconciler.ReconcileNormal(vmClassCtx)
+		})
+
+		When("the class is valid", func() {
+			It("marks the class Ready", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conditions.IsTrue(vmClass, vmopv1alpha1.ReadyCondition)).To(BeTrue())
+				Expect(conditions.IsTrue(vmClass, vmopv1alpha1.VirtualMachineClassConfigSpecValidCondition)).To(BeTrue())
+				Expect(conditions.IsTrue(vmClass, vmopv1alpha1.VirtualMachineClassDevicesAvailableCondition)).To(BeTrue())
+				Expect(conditions.IsTrue(vmClass, vmopv1alpha1.VirtualMachineClassReservationsValidCondition)).To(BeTrue())
+				Expect(vmClass.Status.ObservedGeneration).To(BeEquivalentTo(2))
+			})
+		})
+
+		When("VMs use the class", func() {
+			BeforeEach(func() {
+				initObjects = append(initObjects,
+					newVM("ns-b", "vm-1", vmClass.Name),
+					newVM("ns-a", "vm-2", vmClass.Name),
+					newVM("ns-b", "vm-3", vmClass.Name),
+					newVM("ns-c", "vm-4", "other-vmclass"),
+				)
+			})
+
+			It("returns the namespaces and the count of the VMs", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vmClass.Status.Namespaces).To(Equal([]string{"ns-a", "ns-b"}))
+				Expect(vmClass.Status.VirtualMachineCount).To(BeEquivalentTo(3))
+			})
+		})
+
+		When("the ConfigSpec XML is invalid", func() {
+			BeforeEach(func() {
+				vmClass.Spec.ConfigSpec = &vmopv1alpha1.VirtualMachineConfigSpec{XML: "not-base64-xml"}
+			})
+
+			It("marks the ConfigSpec invalid", func() {
+				Expect(err).ToNot(HaveOccurred())
+				c := conditions.Get(vmClass, vmopv1alpha1.VirtualMachineClassConfigSpecValidCondition)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(corev1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1alpha1.VirtualMachineClassConfigSpecInvalidReason))
+				Expect(conditions.IsFalse(vmClass, vmopv1alpha1.ReadyCondition)).To(BeTrue())
+			})
+		})
+
+		When("the devices are not available on the hosts", func() {
+			BeforeEach(func() {
+				vmClass.Spec.Hardware.Devices = vmopv1alpha1.VirtualDevices{
+					VGPUDevices: []vmopv1alpha1.VGPUDevice{{ProfileName: "grid_v100-8q"}},
+					DynamicDirectPathIODevices: []vmopv1alpha1.DynamicDirectPathIODevice{
+						{VendorID: 0x10de, DeviceID: 0x1eb8},
+						{VendorID: 0x8086, DeviceID: 0x1234},
+					},
+				}
+			})
+
+			It("marks the devices not found", func() {
+				Expect(err).ToNot(HaveOccurred())
+				c := conditions.Get(vmClass, vmopv1alpha1.VirtualMachineClassDevicesAvailableCondition)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(corev1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1alpha1.VirtualMachineClassDevicesNotFoundReason))
+				Expect(c.Message).To(ContainSubstring("grid_v100-8q"))
+				Expect(c.Message).To(ContainSubstring("0x8086:0x1234"))
+				Expect(c.Message).ToNot(ContainSubstring("0x10de"))
+				Expect(conditions.IsFalse(vmClass, vmopv1alpha1.ReadyCondition)).To(BeTrue())
+			})
+		})
+
+		When("the ConfigSpec has a vGPU device that is not available on the hosts", func() {
+			BeforeEach(func() {
+				configSpec := &vimTypes.VirtualMachineConfigSpec{
+					DeviceChange: []vimTypes.BaseVirtualDeviceConfigSpec{
+						&vimTypes.VirtualDeviceConfigSpec{
+							Operation: vimTypes.VirtualDeviceConfigSpecOperationAdd,
+							Device: &vimTypes.VirtualPCIPassthrough{
+								VirtualDevice: vimTypes.VirtualDevice{
+									Backing: &vimTypes.VirtualPCIPassthroughVmiopBackingInfo{Vgpu: "grid_t4-16c"},
+								},
+							},
+						},
+					},
+				}
+				data, err := util.MarshalConfigSpecToXML(configSpec)
+				Expect(err).ToNot(HaveOccurred())
+				vmClass.Spec.ConfigSpec = &vmopv1alpha1.VirtualMachineConfigSpec{XML: base64.StdEncoding.EncodeToString(data)}
+			})
+
+			It("marks the devices not found", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conditions.IsTrue(vmClass, vmopv1alpha1.VirtualMachineClassConfigSpecValidCondition)).To(BeTrue())
+				c := conditions.Get(vmClass, vmopv1alpha1.VirtualMachineClassDevicesAvailableCondition)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(corev1.ConditionFalse))
+				Expect(c.Message).To(ContainSubstring("grid_t4-16c"))
+			})
+		})
+
+		When("the reservations exceed the limits", func() {
+			BeforeEach(func() {
+				vmClass.Spec.Policies.Resources.Requests.Memory = resource.MustParse("3Gi")
+			})
+
+			It("marks the reservations invalid", func() {
+				Expect(err).ToNot(HaveOccurred())
+				c := conditions.Get(vmClass, vmopv1alpha1.VirtualMachineClassReservationsValidCondition)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(corev1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1alpha1.VirtualMachineClassReservationsInvalidReason))
+				Expect(c.Message).To(ContainSubstring("memory request 3Gi exceeds the limit 2Gi"))
+			})
 		})
 
-		When("NoOp", func() {
-			It("returns success", func() {
-				err := reconciler.ReconcileNormal(vmClassCtx)
+		When("the reservations do not fit on any host", func() {
+			BeforeEach(func() {
+				vmClass.Spec.Hardware.Cpus = 16
+				vmClass.Spec.Policies.Resources.Requests.Cpu = resource.MustParse("10")
+				vmClass.Spec.Policies.Resources.Limits.Cpu = resource.MustParse("10")
+			})
+
+			It("marks the reservations invalid", func() {
+				Expect(err).ToNot(HaveOccurred())
+				c := conditions.Get(vmClass, vmopv1alpha1.VirtualMachineClassReservationsValidCondition)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(corev1.ConditionFalse))
+				Expect(c.Message).To(ContainSubstring("CPU request of 20000 MHz exceeds the largest host capacity of 16000 MHz"))
+			})
+		})
+
+		When("the hardware of the hosts is retrieved again within the TTL", func() {
+			var calls int
+
+			JustBeforeEach(func() {
+				hostHardwareFn = func(_ context.Context) (*vmprovider.HostHardware, error) {
+					calls++
+					return nil, errors.New("fake error")
+				}
+				err = reconciler.ReconcileNormal(vmClassCtx)
+			})
+
+			It("uses the cached hardware of the hosts", func() {
 				Expect(err).ToNot(HaveOccurred())
+				Expect(calls).To(BeZero())
+				Expect(conditions.IsTrue(vmClass, vmopv1alpha1.ReadyCondition)).To(BeTrue())
+			})
+		})
+
+		When("the hardware of the hosts cannot be retrieved", func() {
+			JustBeforeEach(func() {
+				// Use a new reconciler since the outer JustBeforeEach has already cached the default hardware.
+				reconciler = virtualmachineclass.NewReconciler(
+					ctx.Client,
+					ctx.Logger,
+					ctx.Recorder,
+					ctx.VMProvider,
+				)
+				hostHardwareFn = func(_ context.Context) (*vmprovider.HostHardware, error) {
+					return nil, errors.New("fake error")
+				}
+				err = reconciler.ReconcileNormal(vmClassCtx)
+			})
+
+			It("returns an error and marks the host checks failed with a warning", func() {
+				Expect(err).To(HaveOccurred())
+				c := conditions.Get(vmClass, vmopv1alpha1.VirtualMachineClassDevicesAvailableCondition)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(corev1.ConditionFalse))
+				Expect(c.Severity).To(Equal(vmopv1alpha1.ConditionSeverityWarning))
+				Expect(c.Reason).To(Equal(vmopv1alpha1.VirtualMachineClassHostHardwareUnknownReason))
+				Expect(conditions.IsFalse(vmClass, vmopv1alpha1.ReadyCondition)).To(BeTrue())
+				Expect(vmClass.Status.VirtualMachineCount).To(BeZero())
+			})
+		})
+
+		When("the updatePolicy is Rolling", func() {
+			var vms []*vmopv1alpha1.VirtualMachine
+
+			// newPendingVM returns a powered on VM whose resize to the class is pending.
+			newPendingVM := func(name string) *vmopv1alpha1.VirtualMachine {
+				vm := newVM("ns", name, vmClass.Name)
+				vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+				vm.Status.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+				vm.Status.AppliedClassGeneration = 1
+				conditions.MarkFalse(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition,
+					vmopv1alpha1.VirtualMachineResizePendingReason, vmopv1alpha1.ConditionSeverityInfo, "")
+				return vm
+			}
+
+			rolloutGeneration := func(vm *vmopv1alpha1.VirtualMachine) string {
+				obj := &vmopv1alpha1.VirtualMachine{}
+				Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vm), obj)).To(Succeed())
+				return obj.Annotations[vmopv1alpha1.ClassRolloutGenerationAnnotation]
+			}
+
+			BeforeEach(func() {
+				vmClass.Spec.UpdatePolicy.Type = vmopv1alpha1.VirtualMachineClassUpdatePolicyRolling
+
+				synced := newVM("ns", "vm-synced", vmClass.Name)
+				synced.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+				synced.Status.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+				synced.Status.AppliedClassGeneration = 2
+				conditions.MarkTrue(synced, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)
+
+				poweredOff := newPendingVM("vm-powered-off")
+				poweredOff.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOff
+
+				vms = []*vmopv1alpha1.VirtualMachine{
+					newPendingVM("vm-1"), newPendingVM("vm-2"), newPendingVM("vm-3"), synced, poweredOff,
+				}
+				for _, vm := range vms {
+					initObjects = append(initObjects, vm)
+				}
+			})
+
+			It("power cycles one VM at a time by default", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rolloutGeneration(vms[0])).To(Equal("2"))
+				Expect(rolloutGeneration(vms[1])).To(BeEmpty())
+				Expect(rolloutGeneration(vms[2])).To(BeEmpty())
+				Expect(rolloutGeneration(vms[3])).To(BeEmpty())
+				Expect(rolloutGeneration(vms[4])).To(BeEmpty())
+
+				By("does not power cycle another VM until the VM is resized", func() {
+					Expect(reconciler.ReconcileNormal(vmClassCtx)).To(Succeed())
+					Expect(rolloutGeneration(vms[1])).To(BeEmpty())
+				})
+			})
+
+			It("does not select more VMs when the cache has not observed the selection yet", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rolloutGeneration(vms[0])).To(Equal("2"))
+
+				// Simulate a stale cache by removing the annotation that was just patched.
+				vm := &vmopv1alpha1.VirtualMachine{}
+				Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vms[0]), vm)).To(Succeed())
+				delete(vm.Annotations, vmopv1alpha1.ClassRolloutGenerationAnnotation)
+				Expect(ctx.Client.Update(ctx, vm)).To(Succeed())
+
+				// The VM is counted as unavailable from the expectation, instead of being selected again.
+				Expect(reconciler.ReconcileNormal(vmClassCtx)).To(Succeed())
+				Expect(rolloutGeneration(vms[0])).To(BeEmpty())
+				Expect(rolloutGeneration(vms[1])).To(BeEmpty())
+
+				By("power cycles the next VM once the VM is resized", func() {
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vms[0]), vm)).To(Succeed())
+					vm.Status.AppliedClassGeneration = 2
+					Expect(ctx.Client.Status().Update(ctx, vm)).To(Succeed())
+
+					Expect(reconciler.ReconcileNormal(vmClassCtx)).To(Succeed())
+					Expect(rolloutGeneration(vms[1])).To(Equal("2"))
+				})
+			})
+
+			When("a VM power cycle is in progress", func() {
+				BeforeEach(func() {
+					vms[0].Annotations = map[string]string{vmopv1alpha1.ClassRolloutGenerationAnnotation: "2"}
+					maxUnavailable := intstr.FromString("50%")
+					vmClass.Spec.UpdatePolicy.MaxUnavailable = &maxUnavailable
+				})
+
+				It("power cycles no more than maxUnavailable VMs", func() {
+					Expect(err).ToNot(HaveOccurred())
+					Expect(rolloutGeneration(vms[1])).To(Equal("2"))
+					Expect(rolloutGeneration(vms[2])).To(BeEmpty())
+				})
+			})
+
+			When("the class is not Ready", func() {
+				BeforeEach(func() {
+					vmClass.Spec.ConfigSpec = &vmopv1alpha1.VirtualMachineConfigSpec{XML: "not-base64-xml"}
+				})
+
+				It("does not power cycle any VM", func() {
+					Expect(err).ToNot(HaveOccurred())
+					for _, vm := range vms {
+						Expect(rolloutGeneration(vm)).To(BeEmpty())
+					}
+				})
 			})
 		})
 	})
@@ -117,7 +117,7 @@ func (nl *NsxtLoadbalancerProvider) GetServiceLabels(ctx context.Context, vmServ
 
 	// When externalTrafficPolicy is set to Local, skip kube-proxy for the
 	// target Service
-	if etp := vmService.Annotations[utils.AnnotationServiceExternalTrafficPolicyKey]; corev1.ServiceExternalTrafficPolicyType(etp) == corev1.ServiceExternalTrafficPolicyTypeLocal {
+	if utils.GetExternalTrafficPolicy(vmService) == corev1.ServiceExternalTrafficPolicyTypeLocal {
 		res[LabelServiceProxyName] = NSXTServiceProxy
 	}
 
@@ -131,7 +131,7 @@ func (nl *NsxtLoadbalancerProvider) GetToBeRemovedServiceLabels(ctx context.Cont
 
 	// When there is no externalTrafficPolicy configured or it's not Local,
 	// remove the service-proxy label
-	if etp := vmService.Annotations[utils.AnnotationServiceExternalTrafficPolicyKey]; corev1.ServiceExternalTrafficPolicyType(etp) != corev1.ServiceExternalTrafficPolicyTypeLocal {
+	if utils.GetExternalTrafficPolicy(vmService) != corev1.ServiceExternalTrafficPolicyTypeLocal {
 		res[LabelServiceProxyName] = NSXTServiceProxy
 	}
 
@@ -143,7 +143,7 @@ func (nl *NsxtLoadbalancerProvider) GetToBeRemovedServiceLabels(ctx context.Cont
 func (nl *NsxtLoadbalancerProvider) GetServiceAnnotations(ctx context.Context, vmService *vmopv1alpha1.VirtualMachineService) (map[string]string, error) {
 	res := make(map[string]string)
 
-	if healthCheckNodePortString, ok := vmService.Annotations[utils.AnnotationServiceHealthCheckNodePortKey]; ok {
+	if healthCheckNodePortString, ok := utils.GetHealthCheckNodePort(vmService); ok {
 		res[ServiceLoadBalancerHealthCheckNodePortTagKey] = healthCheckNodePortString
 	}
 
@@ -157,7 +157,7 @@ func (nl *NsxtLoadbalancerProvider) GetToBeRemovedServiceAnnotations(ctx context
 
 	// When healthCheckNodePort is NOT present, the corresponding NSX-T
 	// annotation should be cleared as well
-	if _, ok := vmService.Annotations[utils.AnnotationServiceHealthCheckNodePortKey]; !ok {
+	if _, ok := utils.GetHealthCheckNodePort(vmService); !ok {
 		res[ServiceLoadBalancerHealthCheckNodePortTagKey] = ""
 	}
 
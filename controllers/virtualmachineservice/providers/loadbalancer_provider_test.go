@@ -172,6 +172,46 @@ var _ = Describe("Loadbalancer Provider", func() {
 				Expect(labels[LabelServiceProxyName]).To(Equal(NSXTServiceProxy))
 			})
 		})
+
+		Context("etp is Local in the spec", func() {
+			BeforeEach(func() {
+				vmService.Spec.ExternalTrafficPolicy = vmoperatorv1alpha1.VirtualMachineServiceTrafficPolicyLocal
+			})
+
+			It("should take precedence over the annotation", func() {
+				labels, err := lbProvider.GetServiceLabels(ctx, vmService)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(labels).To(HaveLen(1))
+				Expect(labels[LabelServiceProxyName]).To(Equal(NSXTServiceProxy))
+			})
+		})
+	})
+
+	Context("GetServiceAnnotations when VMService has healthCheckNodePort in the spec", func() {
+		BeforeEach(func() {
+			vmService = &vmoperatorv1alpha1.VirtualMachineService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dummy-vmservice",
+					Namespace: dummyNamespace,
+				},
+				Spec: vmoperatorv1alpha1.VirtualMachineServiceSpec{
+					Type:                  vmoperatorv1alpha1.VirtualMachineServiceTypeLoadBalancer,
+					ExternalTrafficPolicy: vmoperatorv1alpha1.VirtualMachineServiceTrafficPolicyLocal,
+					HealthCheckNodePort:   30013,
+				},
+			}
+			lbProvider = NsxtLoadBalancerProvider()
+		})
+
+		It("should get health check node port from the spec", func() {
+			vmServiceAnnotations, err := lbProvider.GetServiceAnnotations(ctx, vmService)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmServiceAnnotations).To(HaveKeyWithValue(ServiceLoadBalancerHealthCheckNodePortTagKey, "30013"))
+
+			toBeRemoved, err := lbProvider.GetToBeRemovedServiceAnnotations(ctx, vmService)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(toBeRemoved).ToNot(HaveKey(ServiceLoadBalancerHealthCheckNodePortTagKey))
+		})
 	})
 
 	Context("GetToBeRemovedServiceLabels", func() {
@@ -22,6 +22,9 @@ type lbConfigParams struct {
 	Ports       []vmopv1alpha1.VirtualMachineServicePort
 	CPNodes     []string
 	XdsNodePort int
+	// ClientIPAffinity is true when connections from the same client IP should be passed to the same VM. Envoy
+	// hashes the client IP to select the VM, so the affinity has no timeout.
+	ClientIPAffinity bool
 }
 
 const envoyBootstrapConfig = `node:
@@ -41,6 +44,10 @@ static_resources:
         config:
           stat_prefix: ingress_tcp
           cluster: {{.Name}}
+          # {{- if $.ClientIPAffinity}}
+          hash_policy:
+          - source_ip: {}
+          # {{- end}}
   # {{- end}}
 
   clusters:
@@ -48,7 +55,11 @@ static_resources:
   - name: {{.Name}}
     connect_timeout: 0.25s
     type: EDS
+    # {{- if $.ClientIPAffinity}}
+    lb_policy: RING_HASH
+    # {{- else}}
     lb_policy: ROUND_ROBIN
+    # {{- end}}
     eds_cluster_config:
       eds_config:
         api_config_source:
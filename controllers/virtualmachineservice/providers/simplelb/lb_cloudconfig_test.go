@@ -71,5 +71,32 @@ var _ = Describe("lbCloudConfig", func() {
 				})
 			})
 		})
+
+		When("ClientIP session affinity is requested", func() {
+			params := lbConfigParams{
+				NodeID: "testnamespace/testname",
+				Ports: []vmoperatorv1alpha1.VirtualMachineServicePort{{
+					Name:       "apiserver",
+					Protocol:   "TCP",
+					Port:       6443,
+					TargetPort: 6443,
+				}},
+				CPNodes:          []string{"10.10.00.3"},
+				XdsNodePort:      XdsNodePort,
+				ClientIPAffinity: true,
+			}
+			sb := &strings.Builder{}
+			err := envoyBootstrapConfigTemplate.Execute(sb, params)
+			s := sb.String()
+
+			It("should hash the client source IP", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(s).To(ContainSubstring("source_ip: {}"))
+				Expect(s).To(ContainSubstring("lb_policy: RING_HASH"))
+
+				config := map[string]interface{}{}
+				Expect(yaml.Unmarshal([]byte(s), &config)).To(Succeed())
+			})
+		})
 	})
 })
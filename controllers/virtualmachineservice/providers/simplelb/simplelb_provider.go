@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -21,6 +22,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineservice/utils"
 )
 
 type Provider struct {
@@ -30,7 +33,7 @@ type Provider struct {
 }
 
 type loadbalancerControlPlane interface {
-	UpdateEndpoints(*corev1.Service, *corev1.Endpoints) error
+	UpdateEndpoints(*corev1.Service, []discoveryv1.EndpointSlice) error
 }
 
 func New(mgr manager.Manager) *Provider {
@@ -215,7 +218,6 @@ func (s *Provider) ensureLBIP(ctx context.Context, vmService *vmopv1alpha1.Virtu
 
 func (s *Provider) updateLBConfig(ctx context.Context, vmService *vmopv1alpha1.VirtualMachineService) error {
 	service := &corev1.Service{}
-	endpoints := &corev1.Endpoints{}
 	if err := s.client.Get(ctx, types.NamespacedName{
 		Namespace: vmService.Namespace,
 		Name:      vmService.Name,
@@ -225,16 +227,15 @@ func (s *Provider) updateLBConfig(ctx context.Context, vmService *vmopv1alpha1.V
 		}
 		return err
 	}
-	if err := s.client.Get(ctx, types.NamespacedName{
-		Namespace: vmService.Namespace,
-		Name:      vmService.Name,
-	}, endpoints); err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil // endpoints are not ready yet
-		}
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := s.client.List(ctx, sliceList, client.InNamespace(vmService.Namespace),
+		client.MatchingLabels(utils.EndpointSliceLabels(vmService.Name))); err != nil {
 		return err
 	}
-	return s.controlPlane.UpdateEndpoints(service, endpoints)
+	if len(sliceList.Items) == 0 {
+		return nil // endpoint slices are not ready yet
+	}
+	return s.controlPlane.UpdateEndpoints(service, sliceList.Items)
 }
 
 func (s *Provider) getXDSNodes(ctx context.Context) ([]corev1.Node, error) {
@@ -267,9 +268,10 @@ func getLBConfigParams(vmService *vmopv1alpha1.VirtualMachineService, nodes []co
 		ports[i] = port
 	}
 	return lbConfigParams{
-		NodeID:      vmService.NamespacedName(),
-		Ports:       ports,
-		CPNodes:     cpNodes,
-		XdsNodePort: XdsNodePort,
+		NodeID:           vmService.NamespacedName(),
+		Ports:            ports,
+		CPNodes:          cpNodes,
+		XdsNodePort:      XdsNodePort,
+		ClientIPAffinity: vmService.Spec.SessionAffinity == vmopv1alpha1.VirtualMachineServiceSessionAffinityClientIP,
 	}
 }
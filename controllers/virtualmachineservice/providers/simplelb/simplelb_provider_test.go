@@ -11,28 +11,31 @@ import (
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineservice/utils"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
 type cpArgs struct {
-	service   *corev1.Service
-	endpoints *corev1.Endpoints
+	service *corev1.Service
+	slices  []discoveryv1.EndpointSlice
 }
 
 type fakeControlPlane struct {
 	calls []cpArgs
 }
 
-func (cp *fakeControlPlane) UpdateEndpoints(service *corev1.Service, endpoints *corev1.Endpoints) error {
+func (cp *fakeControlPlane) UpdateEndpoints(service *corev1.Service, slices []discoveryv1.EndpointSlice) error {
 	cp.calls = append(cp.calls, cpArgs{
-		service:   service,
-		endpoints: endpoints,
+		service: service,
+		slices:  slices,
 	})
 	return nil
 }
@@ -145,7 +148,7 @@ var _ = Describe("", func() {
 			})
 		})
 
-		When("Service and Endpoints have been created for VMService", func() {
+		When("Service and EndpointSlices have been created for VMService", func() {
 			const (
 				port     = 6443
 				portName = "apiserver"
@@ -169,29 +172,41 @@ var _ = Describe("", func() {
 					}},
 				},
 			}
-			eps := &corev1.Endpoints{
+			slice := &discoveryv1.EndpointSlice{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: testNs,
-					Name:      testSvc,
+					Name:      testSvc + "-ipv4",
+					Labels:    utils.EndpointSliceLabels(testSvc),
 				},
-				Subsets: []corev1.EndpointSubset{{
-					Addresses: []corev1.EndpointAddress{{IP: ip1}, {IP: ip2}},
-					Ports: []corev1.EndpointPort{{
-						Name: portName,
-						Port: port,
-					}},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{
+					{Addresses: []string{ip1}},
+					{Addresses: []string{ip2}},
+				},
+				Ports: []discoveryv1.EndpointPort{{
+					Name: pointer.String(portName),
+					Port: pointer.Int32(port),
 				}},
 			}
+			otherSlice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: testNs,
+					Name:      "other-svc-ipv4",
+					Labels:    utils.EndpointSliceLabels("other-svc"),
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+			}
 			It("should update the LB control plane", func() {
-				err := client.Create(context.TODO(), eps)
-				Expect(err).ToNot(HaveOccurred())
+				Expect(client.Create(context.TODO(), slice)).To(Succeed())
+				Expect(client.Create(context.TODO(), otherSlice)).To(Succeed())
 
-				err = simpleLbProvider.EnsureLoadBalancer(context.TODO(), vmService)
+				err := simpleLbProvider.EnsureLoadBalancer(context.TODO(), vmService)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(controlPlane.calls).To(HaveLen(1))
 				Expect(controlPlane.calls[0].service.Name).To(Equal(svc.Name))
-				Expect(controlPlane.calls[0].endpoints.Name).To(Equal(eps.Name))
+				Expect(controlPlane.calls[0].slices).To(HaveLen(1))
+				Expect(controlPlane.calls[0].slices[0].Name).To(Equal(slice.Name))
 			})
 		})
 	})
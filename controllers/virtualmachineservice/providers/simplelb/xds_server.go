@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
+	"strings"
 
 	envoy_api_v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
@@ -16,6 +18,7 @@ import (
 	"github.com/go-logr/logr"
 	"google.golang.org/grpc"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -53,21 +56,41 @@ func (x *XdsServer) Start(ctx context.Context) error {
 	return grpcServer.Serve(lis)
 }
 
-func (x *XdsServer) UpdateEndpoints(svc *corev1.Service, eps *corev1.Endpoints) error {
+func (x *XdsServer) UpdateEndpoints(svc *corev1.Service, slices []discoveryv1.EndpointSlice) error {
 	clusters := make([]cache.Resource, len(svc.Spec.Ports))
 	endpoints := make([]cache.Resource, len(svc.Spec.Ports))
 	for i, svcPort := range svc.Spec.Ports {
 		clusters[i] = cluster(svcPort)
-		endpoints[i] = clusterEndpoints(svcPort, eps.Subsets)
+		endpoints[i] = clusterEndpoints(svcPort, slices)
 	}
 
 	nodeID := nodeID(svc)
-	snapshot := cache.NewSnapshot(eps.ResourceVersion, endpoints, clusters, nil, nil, nil)
+	snapshot := cache.NewSnapshot(snapshotVersion(slices), endpoints, clusters, nil, nil, nil)
 
 	x.log.V(5).Info("setting xds snapshot", "nodeID", nodeID, "snapshot", snapshot)
 	return x.snapshotCache.SetSnapshot(nodeID, snapshot)
 }
 
+// snapshotVersion returns the version of the snapshot from the resource versions of the EndpointSlices, which
+// changes whenever the endpoints change.
+func snapshotVersion(slices []discoveryv1.EndpointSlice) string {
+	if len(slices) == 0 {
+		return "0"
+	}
+
+	sorted := make([]discoveryv1.EndpointSlice, len(slices))
+	copy(sorted, slices)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	versions := make([]string, 0, len(sorted))
+	for _, slice := range sorted {
+		versions = append(versions, slice.ResourceVersion)
+	}
+	return strings.Join(versions, "-")
+}
+
 func nodeID(svc *corev1.Service) string {
 	return types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}.String()
 }
@@ -83,15 +106,21 @@ func clusterName(svcPort corev1.ServicePort) string {
 	return svcPort.Name
 }
 
-func clusterEndpoints(svcPort corev1.ServicePort, subsets []corev1.EndpointSubset) *envoy_api_v2.ClusterLoadAssignment {
+func clusterEndpoints(svcPort corev1.ServicePort, slices []discoveryv1.EndpointSlice) *envoy_api_v2.ClusterLoadAssignment {
 	var lbEndpoints []*envoy_api_v2_endpoint.LbEndpoint
 
-	for _, subset := range subsets {
-		for _, endpointPort := range subset.Ports {
-			if endpointPort.Port != svcPort.TargetPort.IntVal {
+	for _, slice := range slices {
+		for _, endpointPort := range slice.Ports {
+			if endpointPort.Port == nil || *endpointPort.Port != svcPort.TargetPort.IntVal {
 				continue
 			}
-			for _, endpointAddress := range subset.Addresses {
+			for _, endpoint := range slice.Endpoints {
+				// A nil ready condition is interpreted as ready. Consumers only use the first address.
+				if len(endpoint.Addresses) == 0 ||
+					(endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready) {
+					continue
+				}
+
 				lbEndpoints = append(lbEndpoints, &envoy_api_v2_endpoint.LbEndpoint{
 					HostIdentifier: &envoy_api_v2_endpoint.LbEndpoint_Endpoint{
 						Endpoint: &envoy_api_v2_endpoint.Endpoint{
@@ -99,9 +128,9 @@ func clusterEndpoints(svcPort corev1.ServicePort, subsets []corev1.EndpointSubse
 								Address: &envoy_api_v2_core.Address_SocketAddress{
 									SocketAddress: &envoy_api_v2_core.SocketAddress{
 										Protocol: envoy_api_v2_core.SocketAddress_TCP,
-										Address:  endpointAddress.IP,
+										Address:  endpoint.Addresses[0],
 										PortSpecifier: &envoy_api_v2_core.SocketAddress_PortValue{
-											PortValue: uint32(endpointPort.Port),
+											PortValue: uint32(*endpointPort.Port),
 										},
 									},
 								},
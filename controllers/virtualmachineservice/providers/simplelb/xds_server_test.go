@@ -10,8 +10,10 @@ import (
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 )
 
 var _ = Describe("xdsServer", func() {
@@ -23,6 +25,8 @@ var _ = Describe("xdsServer", func() {
 		portName     = "apiserver"
 		ip1          = "10.11.12.13"
 		ip2          = "21.22.23.24"
+		ip3          = "2001:db8::3"
+		ip4          = "31.32.33.34"
 	)
 
 	x := &XdsServer{
@@ -47,23 +51,41 @@ var _ = Describe("xdsServer", func() {
 			}},
 		},
 	}
-	eps := &corev1.Endpoints{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace:       testNs,
-			Name:            testSvc,
-			ResourceVersion: epResVersion,
+	ports := []discoveryv1.EndpointPort{{
+		Name: pointer.String(portName),
+		Port: pointer.Int32(port),
+	}}
+	slices := []discoveryv1.EndpointSlice{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       testNs,
+				Name:            testSvc + "-ipv6",
+				ResourceVersion: "456",
+			},
+			AddressType: discoveryv1.AddressTypeIPv6,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{ip3}},
+			},
+			Ports: ports,
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       testNs,
+				Name:            testSvc + "-ipv4",
+				ResourceVersion: epResVersion,
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{ip1}},
+				{Addresses: []string{ip2}, Conditions: discoveryv1.EndpointConditions{Ready: pointer.Bool(true)}},
+				{Addresses: []string{ip4}, Conditions: discoveryv1.EndpointConditions{Ready: pointer.Bool(false)}},
+			},
+			Ports: ports,
 		},
-		Subsets: []corev1.EndpointSubset{{
-			Addresses: []corev1.EndpointAddress{{IP: ip1}, {IP: ip2}},
-			Ports: []corev1.EndpointPort{{
-				Name: portName,
-				Port: port,
-			}},
-		}},
 	}
 
 	It("UpdateEndpoints()", func() {
-		err := x.UpdateEndpoints(svc, eps)
+		err := x.UpdateEndpoints(svc, slices)
 		Expect(err).ToNot(HaveOccurred())
 
 		snapshot, err := x.snapshotCache.GetSnapshot(nodeID(svc))
@@ -72,8 +94,8 @@ var _ = Describe("xdsServer", func() {
 		err = snapshot.Consistent()
 		Expect(err).ToNot(HaveOccurred())
 
-		Expect(snapshot.GetVersion(cache.EndpointType)).To(Equal(epResVersion))
-		Expect(snapshot.GetVersion(cache.ClusterType)).To(Equal(epResVersion))
+		Expect(snapshot.GetVersion(cache.EndpointType)).To(Equal(epResVersion + "-456"))
+		Expect(snapshot.GetVersion(cache.ClusterType)).To(Equal(epResVersion + "-456"))
 
 		clusters := snapshot.GetResources(cache.ClusterType)
 		endpoints := snapshot.GetResources(cache.EndpointType)
@@ -82,5 +104,7 @@ var _ = Describe("xdsServer", func() {
 		Expect(endpoints[portName]).ToNot(BeNil())
 		Expect(endpoints[portName].String()).To(ContainSubstring(ip1))
 		Expect(endpoints[portName].String()).To(ContainSubstring(ip2))
+		Expect(endpoints[portName].String()).To(ContainSubstring(ip3))
+		Expect(endpoints[portName].String()).ToNot(ContainSubstring(ip4))
 	})
 })
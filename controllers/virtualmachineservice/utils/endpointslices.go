@@ -0,0 +1,179 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+const (
+	// EndpointSliceManagedBy is the value of the discoveryv1.LabelManagedBy label of the EndpointSlices that are
+	// managed by the VirtualMachineService controller.
+	EndpointSliceManagedBy = "virtualmachineservice-controller.vmoperator.vmware.com"
+
+	// MaxEndpointsPerSlice is the maximum number of endpoints in an EndpointSlice. This is the same default as
+	// the k8s EndpointSlice controller.
+	MaxEndpointsPerSlice = 100
+)
+
+// EndpointSliceLabels returns the labels that select the EndpointSlices of a Service that are managed by the
+// VirtualMachineService controller.
+func EndpointSliceLabels(serviceName string) map[string]string {
+	return map[string]string{
+		discoveryv1.LabelServiceName: serviceName,
+		discoveryv1.LabelManagedBy:   EndpointSliceManagedBy,
+	}
+}
+
+// EndpointSliceName returns the name of the index'th EndpointSlice of the Service for the address type and ports.
+// The name is deterministic so that the slices are updated in place as the endpoints change.
+func EndpointSliceName(
+	serviceName string,
+	addressType discoveryv1.AddressType,
+	ports []discoveryv1.EndpointPort,
+	index int) string {
+
+	return fmt.Sprintf("%s-%s-%s-%d", serviceName, strings.ToLower(string(addressType)), HashEndpointPorts(ports), index)
+}
+
+// SortEndpointPorts sorts the ports by name, and then by port number.
+func SortEndpointPorts(ports []discoveryv1.EndpointPort) {
+	sort.Slice(ports, func(i, j int) bool {
+		nameI, nameJ := stringValue(ports[i].Name), stringValue(ports[j].Name)
+		if nameI != nameJ {
+			return nameI < nameJ
+		}
+		return int32Value(ports[i].Port) < int32Value(ports[j].Port)
+	})
+}
+
+// SortEndpoints sorts the endpoints by the name of the object that they target, and then by address.
+func SortEndpoints(endpoints []discoveryv1.Endpoint) {
+	sort.Slice(endpoints, func(i, j int) bool {
+		return lessEndpoint(&endpoints[i], &endpoints[j])
+	})
+}
+
+func lessEndpoint(a, b *discoveryv1.Endpoint) bool {
+	nameA, nameB := "", ""
+	if a.TargetRef != nil {
+		nameA = a.TargetRef.Name
+	}
+	if b.TargetRef != nil {
+		nameB = b.TargetRef.Name
+	}
+	if nameA != nameB {
+		return nameA < nameB
+	}
+	return strings.Join(a.Addresses, ",") < strings.Join(b.Addresses, ",")
+}
+
+// SetZoneHints sets the zone hints of the endpoints, which are all the endpoints of a Service for an address type,
+// so that each zone is hinted to consume at least one ready endpoint. An endpoint is hinted for its own zone,
+// except when an endpoint is taken from the zone with the most endpoints for a zone that has none. Like the k8s
+// EndpointSlice controller, no hints are set if a ready endpoint does not have a zone or if there are fewer ready
+// endpoints than zones. Returns whether the hints were set.
+func SetZoneHints(endpoints []*discoveryv1.Endpoint, zones []string) bool {
+	zoneSet := map[string]struct{}{}
+	for _, zone := range zones {
+		zoneSet[zone] = struct{}{}
+	}
+
+	readyByZone := map[string][]*discoveryv1.Endpoint{}
+	numReady := 0
+	canHint := true
+
+	for _, endpoint := range endpoints {
+		endpoint.Hints = nil
+
+		if endpoint.Conditions.Ready == nil || !*endpoint.Conditions.Ready {
+			continue
+		}
+		if endpoint.Zone == nil || *endpoint.Zone == "" {
+			canHint = false
+			continue
+		}
+
+		zoneSet[*endpoint.Zone] = struct{}{}
+		readyByZone[*endpoint.Zone] = append(readyByZone[*endpoint.Zone], endpoint)
+		numReady++
+	}
+
+	if !canHint || numReady == 0 || numReady < len(zoneSet) {
+		return false
+	}
+
+	sortedZones := make([]string, 0, len(zoneSet))
+	for zone := range zoneSet {
+		sortedZones = append(sortedZones, zone)
+		// Sort so the same endpoints are taken for the zones without any, which avoids churning the hints.
+		zoneEndpoints := readyByZone[zone]
+		sort.Slice(zoneEndpoints, func(i, j int) bool {
+			return lessEndpoint(zoneEndpoints[i], zoneEndpoints[j])
+		})
+	}
+	sort.Strings(sortedZones)
+
+	for _, zone := range sortedZones {
+		if len(readyByZone[zone]) > 0 {
+			continue
+		}
+
+		// Take the last endpoint from the zone with the most ready endpoints, which always has more than one
+		// since there are at least as many ready endpoints as zones.
+		donor := ""
+		for _, z := range sortedZones {
+			if donor == "" || len(readyByZone[z]) > len(readyByZone[donor]) {
+				donor = z
+			}
+		}
+		donorEndpoints := readyByZone[donor]
+		readyByZone[zone] = donorEndpoints[len(donorEndpoints)-1:]
+		readyByZone[donor] = donorEndpoints[:len(donorEndpoints)-1]
+	}
+
+	for zone, zoneEndpoints := range readyByZone {
+		for _, endpoint := range zoneEndpoints {
+			endpoint.Hints = &discoveryv1.EndpointHints{ForZones: []discoveryv1.ForZone{{Name: zone}}}
+		}
+	}
+
+	// The endpoints that are not ready are hinted for their own zone so that they are not ignored when they
+	// become ready before the next update.
+	for _, endpoint := range endpoints {
+		if endpoint.Hints == nil && endpoint.Zone != nil && *endpoint.Zone != "" {
+			endpoint.Hints = &discoveryv1.EndpointHints{ForZones: []discoveryv1.ForZone{{Name: *endpoint.Zone}}}
+		}
+	}
+
+	return true
+}
+
+// HashEndpointPorts returns a short hash of the sorted ports, so that endpoints with different ports are put in
+// different EndpointSlices.
+func HashEndpointPorts(ports []discoveryv1.EndpointPort) string {
+	hasher := md5.New() //nolint:gosec
+	DeepHashObject(hasher, ports)
+	return hex.EncodeToString(hasher.Sum(nil))[:8]
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func int32Value(i *int32) int32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+)
+
+// GetExternalTrafficPolicy returns the externalTrafficPolicy of the VirtualMachineService. The spec field takes
+// precedence over the AnnotationServiceExternalTrafficPolicyKey annotation, which is set by the GC cloud provider.
+// An empty string is returned if neither is set.
+func GetExternalTrafficPolicy(vmService *vmopv1alpha1.VirtualMachineService) corev1.ServiceExternalTrafficPolicyType {
+	if etp := vmService.Spec.ExternalTrafficPolicy; etp != "" {
+		return corev1.ServiceExternalTrafficPolicyType(etp)
+	}
+	return corev1.ServiceExternalTrafficPolicyType(vmService.Annotations[AnnotationServiceExternalTrafficPolicyKey])
+}
+
+// GetHealthCheckNodePort returns the healthCheckNodePort of the VirtualMachineService. The spec field takes
+// precedence over the AnnotationServiceHealthCheckNodePortKey annotation, which is set by the GC cloud provider.
+func GetHealthCheckNodePort(vmService *vmopv1alpha1.VirtualMachineService) (string, bool) {
+	if port := vmService.Spec.HealthCheckNodePort; port != 0 {
+		return strconv.Itoa(int(port)), true
+	}
+	port, ok := vmService.Annotations[AnnotationServiceHealthCheckNodePortKey]
+	return port, ok
+}
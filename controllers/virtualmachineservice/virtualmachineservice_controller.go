@@ -12,10 +12,14 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	utilnet "k8s.io/utils/net"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -31,8 +35,10 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineservice/utils"
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 )
 
 const (
@@ -80,6 +86,8 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 			&handler.EnqueueRequestForOwner{OwnerType: &vmopv1alpha1.VirtualMachineService{}}).
 		Watches(&source.Kind{Type: &corev1.Endpoints{}},
 			&handler.EnqueueRequestForOwner{OwnerType: &vmopv1alpha1.VirtualMachineService{}}).
+		Watches(&source.Kind{Type: &discoveryv1.EndpointSlice{}},
+			&handler.EnqueueRequestForOwner{OwnerType: &vmopv1alpha1.VirtualMachineService{}}).
 		Watches(&source.Kind{Type: &vmopv1alpha1.VirtualMachine{}},
 			handler.EnqueueRequestsFromMapFunc(r.virtualMachineToVirtualMachineServiceMapper())).
 		Complete(r)
@@ -119,6 +127,7 @@ type ReconcileVirtualMachineService struct {
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete;deletecollection
 
 func (r *ReconcileVirtualMachineService) Reconcile(ctx goctx.Context, request reconcile.Request) (_ reconcile.Result, reterr error) {
 	vmService := &vmopv1alpha1.VirtualMachineService{}
@@ -165,6 +174,13 @@ func (r *ReconcileVirtualMachineService) ReconcileDelete(ctx *context.VirtualMac
 			return err
 		}
 
+		err := r.Client.DeleteAllOf(ctx, &discoveryv1.EndpointSlice{}, client.InNamespace(objectMeta.Namespace),
+			client.MatchingLabels(utils.EndpointSliceLabels(objectMeta.Name)))
+		if err != nil {
+			ctx.Logger.Error(err, "Failed to delete EndpointSlices")
+			return err
+		}
+
 		service := &corev1.Service{ObjectMeta: objectMeta}
 		if err := r.Client.Delete(ctx, service); client.IgnoreNotFound(err) != nil {
 			ctx.Logger.Error(err, "Failed to delete Service")
@@ -182,7 +198,7 @@ func (r *ReconcileVirtualMachineService) ReconcileDelete(ctx *context.VirtualMac
 func (r *ReconcileVirtualMachineService) ReconcileNormal(ctx *context.VirtualMachineServiceContext) error {
 	if !controllerutil.ContainsFinalizer(ctx.VMService, finalizerName) {
 		controllerutil.AddFinalizer(ctx.VMService, finalizerName)
-		// NOTE: The VirtualMachineService is set as the OwnerReference of the Service and Endpoints.
+		// NOTE: The VirtualMachineService is set as the OwnerReference of the Service, Endpoints and EndpointSlices.
 		// So while ReconcileDelete() does delete them when our finalizer is set, the k8s GC will
 		// delete them if they still exist if the VirtualMachineService is deleted so we do not have
 		// to return here. The explicit delete in ReconcileDelete() just speeds up the ultimate removal
@@ -264,6 +280,12 @@ func (r *ReconcileVirtualMachineService) reconcileVMService(ctx *context.Virtual
 		return err
 	}
 
+	err = r.createOrUpdateEndpointSlices(ctx, service)
+	if err != nil {
+		ctx.Logger.Error(err, "Failed to update VirtualMachineService EndpointSlices")
+		return err
+	}
+
 	err = r.updateVMService(ctx, service)
 	if err != nil {
 		ctx.Logger.Error(err, "Failed to update VirtualMachineService Status")
@@ -434,18 +456,24 @@ func (r *ReconcileVirtualMachineService) createOrUpdateService(ctx *context.Virt
 			service.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
 		}
 
-		if externalTrafficPolicy, ok := service.Annotations[utils.AnnotationServiceExternalTrafficPolicyKey]; ok {
-			// Note that this annotation is only set (and makes sense) from the GC cloud provider.
-			trafficPolicy := corev1.ServiceExternalTrafficPolicyType(externalTrafficPolicy)
+		// Note that the annotation is only set (and makes sense) from the GC cloud provider. The spec field
+		// takes precedence over it.
+		if trafficPolicy := utils.GetExternalTrafficPolicy(vmService); trafficPolicy != "" {
 			switch trafficPolicy {
 			case corev1.ServiceExternalTrafficPolicyTypeLocal, corev1.ServiceExternalTrafficPolicyTypeCluster:
 				service.Spec.ExternalTrafficPolicy = trafficPolicy
 			default:
 				ctx.Logger.V(5).Info("Unknown externalTrafficPolicy VirtualMachineService annotation",
-					"externalTrafficPolicy", externalTrafficPolicy)
+					"externalTrafficPolicy", trafficPolicy)
 			}
 		}
 
+		setServiceHealthCheckNodePort(vmService, service)
+		setServiceSessionAffinity(vmService, service)
+		setServiceInternalTrafficPolicy(vmService, service)
+		setServiceIPFamilies(vmService, service)
+		setServiceTopologyMode(vmService, service)
+
 		return nil
 	})
 
@@ -463,6 +491,156 @@ func (r *ReconcileVirtualMachineService) createOrUpdateService(ctx *context.Virt
 	return service, nil
 }
 
+// setServiceHealthCheckNodePort sets the healthCheckNodePort of the Service, which is only valid for a LoadBalancer
+// Service with the Local externalTrafficPolicy.
+func setServiceHealthCheckNodePort(vmService *vmopv1alpha1.VirtualMachineService, service *corev1.Service) {
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer ||
+		service.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyTypeLocal {
+		service.Spec.HealthCheckNodePort = 0
+		return
+	}
+
+	// Otherwise, preserve the port that k8s allocated for the Service.
+	if vmService.Spec.HealthCheckNodePort != 0 {
+		service.Spec.HealthCheckNodePort = vmService.Spec.HealthCheckNodePort
+	}
+}
+
+// setServiceSessionAffinity sets the sessionAffinity and sessionAffinityConfig of the Service.
+func setServiceSessionAffinity(vmService *vmopv1alpha1.VirtualMachineService, service *corev1.Service) {
+	if vmService.Spec.SessionAffinity != vmopv1alpha1.VirtualMachineServiceSessionAffinityClientIP {
+		service.Spec.SessionAffinity = corev1.ServiceAffinityNone
+		service.Spec.SessionAffinityConfig = nil
+		return
+	}
+
+	service.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+	if config := vmService.Spec.SessionAffinityConfig; config != nil && config.ClientIP != nil {
+		service.Spec.SessionAffinityConfig = &corev1.SessionAffinityConfig{
+			ClientIP: &corev1.ClientIPConfig{
+				TimeoutSeconds: config.ClientIP.TimeoutSeconds,
+			},
+		}
+	}
+	// Otherwise, preserve the timeout that k8s defaulted for the Service.
+}
+
+// setServiceInternalTrafficPolicy sets the internalTrafficPolicy of the Service, which does not apply to an
+// ExternalName Service.
+func setServiceInternalTrafficPolicy(vmService *vmopv1alpha1.VirtualMachineService, service *corev1.Service) {
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		service.Spec.InternalTrafficPolicy = nil
+		return
+	}
+
+	// This is the default that k8s would otherwise set.
+	trafficPolicy := corev1.ServiceInternalTrafficPolicyCluster
+	if vmService.Spec.InternalTrafficPolicy != "" {
+		trafficPolicy = corev1.ServiceInternalTrafficPolicyType(vmService.Spec.InternalTrafficPolicy)
+	}
+	service.Spec.InternalTrafficPolicy = &trafficPolicy
+}
+
+// setServiceIPFamilies sets the ipFamilies and ipFamilyPolicy of the Service, which do not apply to an
+// ExternalName Service.
+func setServiceIPFamilies(vmService *vmopv1alpha1.VirtualMachineService, service *corev1.Service) {
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		service.Spec.IPFamilies = nil
+		service.Spec.IPFamilyPolicy = nil
+		return
+	}
+
+	// Otherwise, preserve the families that k8s assigned for the Service.
+	if len(vmService.Spec.IPFamilies) > 0 {
+		ipFamilies := make([]corev1.IPFamily, 0, len(vmService.Spec.IPFamilies))
+		for _, ipFamily := range vmService.Spec.IPFamilies {
+			ipFamilies = append(ipFamilies, corev1.IPFamily(ipFamily))
+		}
+		service.Spec.IPFamilies = ipFamilies
+	}
+
+	// This is the default that k8s would otherwise set.
+	ipFamilyPolicy := corev1.IPFamilyPolicySingleStack
+	if vmService.Spec.IPFamilyPolicy != "" {
+		ipFamilyPolicy = corev1.IPFamilyPolicyType(vmService.Spec.IPFamilyPolicy)
+	} else if len(vmService.Spec.IPFamilies) > 1 {
+		ipFamilyPolicy = corev1.IPFamilyPolicyRequireDualStack
+	}
+	service.Spec.IPFamilyPolicy = &ipFamilyPolicy
+}
+
+// setServiceTopologyMode sets the topology-aware hints annotation of the Service so that kube-proxy routes traffic
+// according to the zone hints of the EndpointSlices. An annotation set on the VirtualMachineService is preserved.
+func setServiceTopologyMode(vmService *vmopv1alpha1.VirtualMachineService, service *corev1.Service) {
+	if isTopologyAwareRoutingEnabled(vmService) {
+		if service.Annotations == nil {
+			service.Annotations = map[string]string{}
+		}
+		service.Annotations[corev1.AnnotationTopologyAwareHints] = "Auto"
+	} else if _, ok := vmService.Annotations[corev1.AnnotationTopologyAwareHints]; !ok {
+		delete(service.Annotations, corev1.AnnotationTopologyAwareHints)
+	}
+}
+
+func isTopologyAwareRoutingEnabled(vmService *vmopv1alpha1.VirtualMachineService) bool {
+	return vmService.Spec.TopologyMode == vmopv1alpha1.VirtualMachineServiceTopologyModeAuto &&
+		vmService.Spec.Type != vmopv1alpha1.VirtualMachineServiceTypeExternalName
+}
+
+// getAvailabilityZoneNames returns the names of the zones that clients of the Service may be in.
+func (r *ReconcileVirtualMachineService) getAvailabilityZoneNames(ctx *context.VirtualMachineServiceContext) []string {
+	if !lib.IsWcpFaultDomainsFSSEnabled() {
+		return nil
+	}
+
+	zones, err := topology.GetAvailabilityZones(ctx, r.Client)
+	if err != nil {
+		// The zones of the VMs are still used for the hints.
+		ctx.Logger.Error(err, "Failed to get AvailabilityZones")
+		return nil
+	}
+
+	names := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		names = append(names, zone.Name)
+	}
+	return names
+}
+
+// getVirtualMachineIP returns the IP of the VM for the primary IP family of the Service. Endpoints only contain
+// addresses of a single family, so an empty string is returned if the VM does not have an IP of that family.
+func getVirtualMachineIP(vm *vmopv1alpha1.VirtualMachine, service *corev1.Service) string {
+	if len(service.Spec.IPFamilies) == 0 {
+		return vm.Status.VmIp
+	}
+	return getVirtualMachineIPForFamily(vm, service.Spec.IPFamilies[0])
+}
+
+// getVirtualMachineIPForFamily returns the primary IP of the VM for the IP family, or an empty string if the VM
+// does not have an IP of that family.
+func getVirtualMachineIPForFamily(vm *vmopv1alpha1.VirtualMachine, ipFamily corev1.IPFamily) string {
+	ip := vm.Status.PrimaryIPv4
+	if ipFamily == corev1.IPv6Protocol {
+		ip = vm.Status.PrimaryIPv6
+	}
+
+	// The primary IPs may not have been populated yet for a VM created before they were added to the status.
+	if ip == "" && vm.Status.VmIp != "" && utilnet.IsIPv6String(vm.Status.VmIp) == (ipFamily == corev1.IPv6Protocol) {
+		ip = vm.Status.VmIp
+	}
+
+	return ip
+}
+
+// getServiceIPFamilies returns the IP families of the Service. k8s assigns the families when the Service is
+// created, so IPv4 is only assumed for a Service that predates dual-stack support.
+func getServiceIPFamilies(service *corev1.Service) []corev1.IPFamily {
+	if len(service.Spec.IPFamilies) == 0 {
+		return []corev1.IPFamily{corev1.IPv4Protocol}
+	}
+	return service.Spec.IPFamilies
+}
+
 func (r *ReconcileVirtualMachineService) getVirtualMachinesSelectedByVMService(
 	ctx goctx.Context,
 	vmService *vmopv1alpha1.VirtualMachineService) (*vmopv1alpha1.VirtualMachineList, error) {
@@ -558,7 +736,13 @@ func (r *ReconcileVirtualMachineService) createOrUpdateEndpoints(ctx *context.Vi
 
 		// NCP apparently needs the same Labels as what is present on the Service, and I'm not aware
 		// of anything else setting Labels, so just sync the Labels (and Annotations) with the Service.
-		endpoints.Labels = service.Labels
+		endpoints.Labels = make(map[string]string, len(service.Labels)+1)
+		for k, v := range service.Labels {
+			endpoints.Labels[k] = v
+		}
+		// The EndpointSlices are managed below, so the k8s EndpointSliceMirroring controller must not
+		// also mirror these Endpoints.
+		endpoints.Labels[discoveryv1.LabelSkipMirror] = "true"
 		endpoints.Annotations = service.Annotations
 		endpoints.Subsets = subsets
 		return nil
@@ -616,49 +800,20 @@ func (r *ReconcileVirtualMachineService) generateSubsetsForService(
 			continue
 		}
 
-		if vm.Status.VmIp == "" {
+		vmIP := getVirtualMachineIP(&vm, service)
+		if vmIP == "" {
 			// The EndpointAddress must have a valid IP so we cannot include this VM in the
 			// NotReadyAddresses.
 			// TODO: When we more fully support multiple NICs, we'll need someway to select which IP.
-			logger.Info("Skipping VM that does not have an IP")
+			logger.Info("Skipping VM that does not have an IP of the Service's primary IP family")
 			continue
 		}
 
-		// If the VM has a ReadinessProbe and Ready condition, ready is a reflection of the condition
-		// status. If the VM has a ReadinessProbe but no condition, we assume that the prober just
-		// hasn't run against the VM yet, so infer the VM's readiness if it was previously in the EP;
-		// this is to handle upgrade scenarios.
-		// Otherwise, a VM that does not have a ReadinessProbe is implicitly ready.
-		ready := true
-
-		if vm.Spec.ReadinessProbe != nil {
-			if condition := conditions.Get(&vm, vmopv1alpha1.ReadyCondition); condition == nil {
-				if vmInSubsetsMap == nil {
-					vmInSubsetsMap = r.getVMsReferencedByServiceEndpoints(ctx, service)
-				}
-
-				// If this VM was previously in the EP subset, preserve its readiness until prober
-				// updates the condition (the probe used to be done inline here before we had a
-				// Ready condition).
-				_, ready = vmInSubsetsMap[vm.UID]
-			} else {
-				ready = condition.Status == corev1.ConditionTrue
-			}
-		}
+		ready := r.isVirtualMachineReady(ctx, service, &vm, &vmInSubsetsMap)
 
 		epa := corev1.EndpointAddress{
-			IP: vm.Status.VmIp,
-			TargetRef: &corev1.ObjectReference{
-				APIVersion: vm.APIVersion,
-				Kind:       vm.Kind,
-				Namespace:  vm.Namespace,
-				Name:       vm.Name,
-				UID:        vm.UID,
-				// NOTE: This currently isn't set to limit downstream reconcile churn in things
-				// watching these Endpoints but isn't ideal. We should be smarter and only update
-				// this when something relevant to the service, e.g. the VM's IP, changes.
-				// ResourceVersion: vm.ResourceVersion,
-			},
+			IP:        vmIP,
+			TargetRef: virtualMachineObjectReference(&vm),
 		}
 
 		// Populate the EP subset for this VM. We create one subset for each VM, and then our
@@ -670,29 +825,295 @@ func (r *ReconcileVirtualMachineService) generateSubsetsForService(
 			subset.NotReadyAddresses = []corev1.EndpointAddress{epa}
 		}
 
-		// TODO: Headless support
-		for _, servicePort := range service.Spec.Ports {
-			portName := servicePort.Name
-			portProto := servicePort.Protocol
+		subset.Ports = getVirtualMachineEndpointPorts(logger, &vm, service)
+
+		subsets = append(subsets, subset)
+	}
+
+	return subsets, nil
+}
+
+// isVirtualMachineReady returns if the VM is ready to receive traffic for the Service. The VMs in the existing
+// Endpoints of the Service are lazily fetched into vmInSubsetsMap.
+func (r *ReconcileVirtualMachineService) isVirtualMachineReady(
+	ctx *context.VirtualMachineServiceContext,
+	service *corev1.Service,
+	vm *vmopv1alpha1.VirtualMachine,
+	vmInSubsetsMap *map[types.UID]struct{}) bool {
+
+	// If the VM has a ReadinessProbe and Ready condition, ready is a reflection of the condition
+	// status. If the VM has a ReadinessProbe but no condition, we assume that the prober just
+	// hasn't run against the VM yet, so infer the VM's readiness if it was previously in the EP;
+	// this is to handle upgrade scenarios.
+	// Otherwise, a VM that does not have a ReadinessProbe is implicitly ready.
+	if vm.Spec.ReadinessProbe == nil {
+		return true
+	}
+
+	if condition := conditions.Get(vm, vmopv1alpha1.ReadyCondition); condition != nil {
+		return condition.Status == corev1.ConditionTrue
+	}
+
+	if *vmInSubsetsMap == nil {
+		*vmInSubsetsMap = r.getVMsReferencedByServiceEndpoints(ctx, service)
+	}
+
+	// If this VM was previously in the EP subset, preserve its readiness until prober
+	// updates the condition (the probe used to be done inline here before we had a
+	// Ready condition).
+	_, ready := (*vmInSubsetsMap)[vm.UID]
+	return ready
+}
+
+// virtualMachineObjectReference returns the reference to the VM for its endpoint.
+func virtualMachineObjectReference(vm *vmopv1alpha1.VirtualMachine) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: vm.APIVersion,
+		Kind:       vm.Kind,
+		Namespace:  vm.Namespace,
+		Name:       vm.Name,
+		UID:        vm.UID,
+		// NOTE: This currently isn't set to limit downstream reconcile churn in things
+		// watching these Endpoints but isn't ideal. We should be smarter and only update
+		// this when something relevant to the service, e.g. the VM's IP, changes.
+		// ResourceVersion: vm.ResourceVersion,
+	}
+}
+
+// getVirtualMachineEndpointPorts returns the ports of the VM for the ports of the Service.
+func getVirtualMachineEndpointPorts(
+	logger logr.Logger,
+	vm *vmopv1alpha1.VirtualMachine,
+	service *corev1.Service) []corev1.EndpointPort {
+
+	var ports []corev1.EndpointPort
+
+	// TODO: Headless support
+	for _, servicePort := range service.Spec.Ports {
+		portName := servicePort.Name
+		portProto := servicePort.Protocol
+
+		logger.V(5).Info("ServicePort for VirtualMachine",
+			"port name", portName, "port proto", portProto)
+
+		portNum, err := findVMPortNum(vm, servicePort.TargetPort, portProto)
+		if err != nil {
+			logger.Info("Failed to find port for service",
+				"name", portName, "protocol", portProto, "error", err)
+			continue
+		}
+
+		ports = append(ports, corev1.EndpointPort{Name: portName, Port: int32(portNum), Protocol: portProto})
+	}
+
+	return ports
+}
+
+// endpointSliceKey groups the endpoints that are put in the same EndpointSlices.
+type endpointSliceKey struct {
+	addressType discoveryv1.AddressType
+	portsHash   string
+}
+
+// createOrUpdateEndpointSlices updates the EndpointSlices for VirtualMachineService. There are EndpointSlices for
+// each IP family of the Service, each with at most utils.MaxEndpointsPerSlice endpoints.
+func (r *ReconcileVirtualMachineService) createOrUpdateEndpointSlices(
+	ctx *context.VirtualMachineServiceContext,
+	service *corev1.Service) error {
+
+	ctx.Logger.V(5).Info("Updating VirtualMachineService EndpointSlices")
+	defer ctx.Logger.V(5).Info("Finished updating VirtualMachineService EndpointSlices")
+
+	endpointsByKey, portsByKey, err := r.generateEndpointsForService(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	if len(endpointsByKey) == 0 {
+		// Like the k8s EndpointSlice controller, keep a placeholder EndpointSlice for a Service without any
+		// endpoints so that consumers can tell that the Service has no endpoints from it not being reconciled yet.
+		key := endpointSliceKey{addressType: discoveryv1.AddressType(getServiceIPFamilies(service)[0])}
+		endpointsByKey[key] = nil
+	}
+
+	desiredSlices := map[string]struct{}{}
+	for key, endpoints := range endpointsByKey {
+		ports := portsByKey[key]
+		utils.SortEndpoints(endpoints)
 
-			logger.V(5).Info("ServicePort for VirtualMachine",
-				"port name", portName, "port proto", portProto)
+		for i := 0; i == 0 || i*utils.MaxEndpointsPerSlice < len(endpoints); i++ {
+			end := (i + 1) * utils.MaxEndpointsPerSlice
+			if end > len(endpoints) {
+				end = len(endpoints)
+			}
+
+			slice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      utils.EndpointSliceName(service.Name, key.addressType, ports, i),
+					Namespace: service.Namespace,
+				},
+			}
+			desiredSlices[slice.Name] = struct{}{}
 
-			portNum, err := findVMPortNum(&vm, servicePort.TargetPort, portProto)
+			err := r.createOrPatchEndpointSlice(ctx, service, slice, key.addressType, ports,
+				endpoints[i*utils.MaxEndpointsPerSlice:end])
 			if err != nil {
-				logger.Info("Failed to find port for service",
-					"name", portName, "protocol", portProto, "error", err)
+				return err
+			}
+		}
+	}
+
+	sliceList := &discoveryv1.EndpointSliceList{}
+	err = r.List(ctx, sliceList, client.InNamespace(service.Namespace),
+		client.MatchingLabels(utils.EndpointSliceLabels(service.Name)))
+	if err != nil {
+		return err
+	}
+
+	for i := range sliceList.Items {
+		slice := &sliceList.Items[i]
+		if _, ok := desiredSlices[slice.Name]; ok {
+			continue
+		}
+
+		if err := r.Delete(ctx, slice); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		ctx.Logger.Info("Deleted stale Service EndpointSlice", "endpointSlice", slice.Name)
+	}
+
+	return nil
+}
+
+func (r *ReconcileVirtualMachineService) createOrPatchEndpointSlice(
+	ctx *context.VirtualMachineServiceContext,
+	service *corev1.Service,
+	slice *discoveryv1.EndpointSlice,
+	addressType discoveryv1.AddressType,
+	ports []discoveryv1.EndpointPort,
+	endpoints []discoveryv1.Endpoint) error {
+
+	result, err := controllerutil.CreateOrPatch(ctx, r.Client, slice, func() error {
+		if err := controllerutil.SetControllerReference(ctx.VMService, slice, r.scheme); err != nil {
+			return err
+		}
+
+		slice.Labels = make(map[string]string, len(service.Labels)+2)
+		for k, v := range service.Labels {
+			slice.Labels[k] = v
+		}
+		for k, v := range utils.EndpointSliceLabels(service.Name) {
+			slice.Labels[k] = v
+		}
+
+		slice.AddressType = addressType
+		slice.Ports = ports
+		slice.Endpoints = endpoints
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	switch result {
+	case controllerutil.OperationResultCreated:
+		ctx.Logger.Info("Creating Service EndpointSlice", "endpointSlice", slice.Name)
+	case controllerutil.OperationResultUpdated:
+		ctx.Logger.Info("Updating Service EndpointSlice", "endpointSlice", slice.Name)
+	}
+
+	return nil
+}
+
+// setEndpointZoneHints sets the zone hints of the endpoints of each address type.
+func (r *ReconcileVirtualMachineService) setEndpointZoneHints(
+	ctx *context.VirtualMachineServiceContext,
+	endpointsByKey map[endpointSliceKey][]discoveryv1.Endpoint) {
+
+	endpointsByAddressType := map[discoveryv1.AddressType][]*discoveryv1.Endpoint{}
+	for key, endpoints := range endpointsByKey {
+		for i := range endpoints {
+			endpointsByAddressType[key.addressType] = append(endpointsByAddressType[key.addressType], &endpoints[i])
+		}
+	}
+
+	zones := r.getAvailabilityZoneNames(ctx)
+	for addressType, endpoints := range endpointsByAddressType {
+		if !utils.SetZoneHints(endpoints, zones) {
+			ctx.Logger.V(4).Info("Not setting EndpointSlice zone hints because a ready VM has no zone or a zone has no ready VM",
+				"addressType", addressType, "zones", zones)
+		}
+	}
+}
+
+// generateEndpointsForService generates the EndpointSlice endpoints for a given Service, grouped by their address
+// type and ports. Unlike the Endpoints, the EndpointSlices include the VMs that are being deleted as terminating.
+func (r *ReconcileVirtualMachineService) generateEndpointsForService(
+	ctx *context.VirtualMachineServiceContext,
+	service *corev1.Service) (map[endpointSliceKey][]discoveryv1.Endpoint, map[endpointSliceKey][]discoveryv1.EndpointPort, error) {
+
+	vmList, err := r.getVirtualMachinesSelectedByVMService(ctx, ctx.VMService)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	endpointsByKey := map[endpointSliceKey][]discoveryv1.Endpoint{}
+	portsByKey := map[endpointSliceKey][]discoveryv1.EndpointPort{}
+	var vmInSubsetsMap map[types.UID]struct{}
+
+	for i := range vmList.Items {
+		vm := &vmList.Items[i]
+		logger := ctx.Logger.WithValues("virtualMachine", vm.NamespacedName())
+
+		ready := r.isVirtualMachineReady(ctx, service, vm, &vmInSubsetsMap)
+		terminating := !vm.DeletionTimestamp.IsZero()
+
+		var ports []discoveryv1.EndpointPort
+		for _, port := range getVirtualMachineEndpointPorts(logger, vm, service) {
+			port := port
+			ports = append(ports, discoveryv1.EndpointPort{Name: &port.Name, Protocol: &port.Protocol, Port: &port.Port})
+		}
+		utils.SortEndpointPorts(ports)
+
+		for _, ipFamily := range getServiceIPFamilies(service) {
+			ip := getVirtualMachineIPForFamily(vm, ipFamily)
+			if ip == "" {
 				continue
 			}
 
-			subset.Ports = append(subset.Ports,
-				corev1.EndpointPort{Name: portName, Port: int32(portNum), Protocol: portProto})
+			endpoint := discoveryv1.Endpoint{
+				Addresses: []string{ip},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready:       pointer.Bool(ready && !terminating),
+					Serving:     pointer.Bool(ready),
+					Terminating: pointer.Bool(terminating),
+				},
+				TargetRef: virtualMachineObjectReference(vm),
+			}
+
+			// The hostname must be a DNS label, while the VM name may be a DNS subdomain.
+			if len(validation.IsDNS1123Label(vm.Name)) == 0 {
+				endpoint.Hostname = pointer.String(vm.Name)
+			}
+			if vm.Status.Zone != "" {
+				endpoint.Zone = pointer.String(vm.Status.Zone)
+			}
+
+			key := endpointSliceKey{
+				addressType: discoveryv1.AddressType(ipFamily),
+				portsHash:   utils.HashEndpointPorts(ports),
+			}
+			endpointsByKey[key] = append(endpointsByKey[key], endpoint)
+			portsByKey[key] = ports
 		}
+	}
 
-		subsets = append(subsets, subset)
+	if isTopologyAwareRoutingEnabled(ctx.VMService) {
+		r.setEndpointZoneHints(ctx, endpointsByKey)
 	}
 
-	return subsets, nil
+	return endpointsByKey, portsByKey, nil
 }
 
 // updateVMService syncs the VirtualMachineService Status from the Service status.
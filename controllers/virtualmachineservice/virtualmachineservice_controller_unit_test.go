@@ -10,6 +10,7 @@ import (
 	"github.com/onsi/gomega/types"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apiEquality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -17,12 +18,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	topologyv1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineservice"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineservice/providers"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineservice/utils"
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
@@ -237,6 +240,68 @@ func unitTestsReconcile() {
 					Expect(service.Annotations).To(HaveKeyWithValue(utils.AnnotationServiceHealthCheckNodePortKey, "99"))
 				})
 			})
+
+			Context("Session affinity and traffic policies", func() {
+				var timeout int32
+
+				BeforeEach(func() {
+					timeout = 600
+					vmService.Spec.SessionAffinity = vmopv1alpha1.VirtualMachineServiceSessionAffinityClientIP
+					vmService.Spec.SessionAffinityConfig = &vmopv1alpha1.SessionAffinityConfig{
+						ClientIP: &vmopv1alpha1.ClientIPConfig{TimeoutSeconds: &timeout},
+					}
+					vmService.Spec.ExternalTrafficPolicy = vmopv1alpha1.VirtualMachineServiceTrafficPolicyLocal
+					vmService.Spec.InternalTrafficPolicy = vmopv1alpha1.VirtualMachineServiceTrafficPolicyLocal
+					vmService.Spec.HealthCheckNodePort = 30100
+				})
+
+				It("Expected values", func() {
+					Expect(service.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityClientIP))
+					Expect(service.Spec.SessionAffinityConfig).ToNot(BeNil())
+					Expect(service.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds).To(Equal(&timeout))
+					Expect(service.Spec.ExternalTrafficPolicy).To(Equal(corev1.ServiceExternalTrafficPolicyTypeLocal))
+					Expect(service.Spec.InternalTrafficPolicy).ToNot(BeNil())
+					Expect(*service.Spec.InternalTrafficPolicy).To(Equal(corev1.ServiceInternalTrafficPolicyLocal))
+					Expect(service.Spec.HealthCheckNodePort).To(BeEquivalentTo(30100))
+				})
+			})
+
+			Context("Default session affinity and traffic policies", func() {
+				It("Expected values", func() {
+					Expect(service.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityNone))
+					Expect(service.Spec.SessionAffinityConfig).To(BeNil())
+					Expect(service.Spec.ExternalTrafficPolicy).To(Equal(corev1.ServiceExternalTrafficPolicyTypeCluster))
+					Expect(service.Spec.InternalTrafficPolicy).ToNot(BeNil())
+					Expect(*service.Spec.InternalTrafficPolicy).To(Equal(corev1.ServiceInternalTrafficPolicyCluster))
+					Expect(service.Spec.HealthCheckNodePort).To(BeZero())
+					Expect(service.Spec.IPFamilies).To(BeEmpty())
+					Expect(service.Spec.IPFamilyPolicy).ToNot(BeNil())
+					Expect(*service.Spec.IPFamilyPolicy).To(Equal(corev1.IPFamilyPolicySingleStack))
+				})
+			})
+
+			Context("Dual-stack IP families", func() {
+				BeforeEach(func() {
+					vmService.Spec.IPFamilies = []vmopv1alpha1.IPFamily{vmopv1alpha1.IPv6Protocol, vmopv1alpha1.IPv4Protocol}
+				})
+
+				It("Expected values", func() {
+					Expect(service.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}))
+					Expect(service.Spec.IPFamilyPolicy).ToNot(BeNil())
+					Expect(*service.Spec.IPFamilyPolicy).To(Equal(corev1.IPFamilyPolicyRequireDualStack))
+				})
+
+				When("IPFamilyPolicy is specified", func() {
+					BeforeEach(func() {
+						vmService.Spec.IPFamilyPolicy = vmopv1alpha1.IPFamilyPolicyPreferDualStack
+					})
+
+					It("Expected values", func() {
+						Expect(service.Spec.IPFamilyPolicy).ToNot(BeNil())
+						Expect(*service.Spec.IPFamilyPolicy).To(Equal(corev1.IPFamilyPolicyPreferDualStack))
+					})
+				})
+			})
 		})
 
 		Context("Service Exists", func() {
@@ -508,6 +573,30 @@ func unitTestsReconcile() {
 						Expect(endpoints.Subsets).To(BeEmpty())
 					})
 				})
+
+				Context("When the Service's primary IP family is IPv6", func() {
+					BeforeEach(func() {
+						vmService.Spec.IPFamilies = []vmopv1alpha1.IPFamily{vmopv1alpha1.IPv6Protocol}
+						vm1.Status.PrimaryIPv4 = vm1.Status.VmIp
+						vm1.Status.PrimaryIPv6 = "2001:db8::1"
+					})
+
+					It("With the VM's IPv6 address in Subsets", func() {
+						Expect(endpoints.Subsets).To(HaveLen(1))
+						Expect(endpoints.Subsets[0].Addresses).To(HaveLen(1))
+						Expect(endpoints.Subsets[0].Addresses[0].IP).To(Equal("2001:db8::1"))
+					})
+
+					Context("When VM does not have an IPv6 address", func() {
+						BeforeEach(func() {
+							vm1.Status.PrimaryIPv6 = ""
+						})
+
+						It("Not included in Subsets", func() {
+							Expect(endpoints.Subsets).To(BeEmpty())
+						})
+					})
+				})
 			})
 
 			Context("When multiple VMs match label selector", func() {
@@ -652,6 +741,272 @@ func unitTestsReconcile() {
 				})
 			})
 		})
+
+		Context("Creates expected EndpointSlices", func() {
+			var labelSelector map[string]string
+			var vm1, vm2 *vmopv1alpha1.VirtualMachine
+
+			getEndpointSlices := func() []discoveryv1.EndpointSlice {
+				sliceList := &discoveryv1.EndpointSliceList{}
+				Expect(ctx.Client.List(ctx, sliceList, client.InNamespace(vmService.Namespace),
+					client.MatchingLabels(utils.EndpointSliceLabels(vmService.Name)))).To(Succeed())
+				return sliceList.Items
+			}
+
+			BeforeEach(func() {
+				labelSelector = map[string]string{"my-app": "dummy-label"}
+
+				vmService.Labels[labelName1] = labelValue1
+				vmService.Spec.Selector = labelSelector
+				vmService.Spec.Ports = []vmopv1alpha1.VirtualMachineServicePort{
+					vmServicePort1,
+				}
+
+				vm1 = &vmopv1alpha1.VirtualMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dummy-vm1",
+						Namespace: vmService.Namespace,
+						Labels:    labelSelector,
+					},
+					Status: vmopv1alpha1.VirtualMachineStatus{
+						VmIp: "1.1.1.1",
+						Zone: "zone-a",
+					},
+				}
+
+				vm2 = &vmopv1alpha1.VirtualMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dummy.vm2",
+						Namespace: vmService.Namespace,
+						Labels:    labelSelector,
+					},
+					Spec: vmopv1alpha1.VirtualMachineSpec{
+						ReadinessProbe: &vmopv1alpha1.Probe{},
+					},
+					Status: vmopv1alpha1.VirtualMachineStatus{
+						VmIp:        "2.2.2.2",
+						PrimaryIPv4: "2.2.2.2",
+						PrimaryIPv6: "2001:db8::2",
+					},
+				}
+				conditions.MarkFalse(vm2, vmopv1alpha1.ReadyCondition, "NotReady", vmopv1alpha1.ConditionSeverityInfo, "")
+			})
+
+			JustBeforeEach(func() {
+				err := reconciler.ReconcileNormal(vmServiceCtx)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("Placeholder EndpointSlice when no VM matches", func() {
+				slices := getEndpointSlices()
+				Expect(slices).To(HaveLen(1))
+				Expect(slices[0].AddressType).To(Equal(discoveryv1.AddressTypeIPv4))
+				Expect(slices[0].Endpoints).To(BeEmpty())
+			})
+
+			It("Legacy Endpoints are not mirrored", func() {
+				endpoints := &corev1.Endpoints{}
+				Expect(ctx.Client.Get(ctx, objKey, endpoints)).To(Succeed())
+				Expect(endpoints.Labels).To(HaveKeyWithValue(discoveryv1.LabelSkipMirror, "true"))
+			})
+
+			Context("When VMs match label selector", func() {
+				BeforeEach(func() {
+					initObjects = append(initObjects, vm1, vm2)
+				})
+
+				It("With Expected EndpointSlice", func() {
+					slices := getEndpointSlices()
+					Expect(slices).To(HaveLen(1))
+					slice := slices[0]
+
+					Expect(slice.OwnerReferences).To(HaveLen(1))
+					Expect(slice.OwnerReferences[0].Name).To(Equal(vmService.Name))
+					Expect(slice.Labels).To(HaveKeyWithValue(labelName1, labelValue1))
+					Expect(slice.Labels).To(HaveKeyWithValue(discoveryv1.LabelServiceName, vmService.Name))
+					Expect(slice.Labels).To(HaveKeyWithValue(discoveryv1.LabelManagedBy, utils.EndpointSliceManagedBy))
+					Expect(slice.AddressType).To(Equal(discoveryv1.AddressTypeIPv4))
+
+					Expect(slice.Ports).To(HaveLen(1))
+					Expect(slice.Ports[0].Name).To(Equal(pointer.String(vmServicePort1.Name)))
+					Expect(slice.Ports[0].Port).To(Equal(pointer.Int32(vmServicePort1.TargetPort)))
+
+					Expect(slice.Endpoints).To(HaveLen(2))
+					ep1, ep2 := slice.Endpoints[0], slice.Endpoints[1]
+
+					Expect(ep1.Addresses).To(Equal([]string{"1.1.1.1"}))
+					Expect(ep1.TargetRef.Name).To(Equal(vm1.Name))
+					Expect(ep1.Hostname).To(Equal(pointer.String(vm1.Name)))
+					Expect(ep1.Zone).To(Equal(pointer.String("zone-a")))
+					Expect(ep1.Conditions.Ready).To(Equal(pointer.Bool(true)))
+					Expect(ep1.Conditions.Serving).To(Equal(pointer.Bool(true)))
+					Expect(ep1.Conditions.Terminating).To(Equal(pointer.Bool(false)))
+
+					Expect(ep2.Addresses).To(Equal([]string{"2.2.2.2"}))
+					Expect(ep2.TargetRef.Name).To(Equal(vm2.Name))
+					Expect(ep2.Hostname).To(BeNil())
+					Expect(ep2.Zone).To(BeNil())
+					Expect(ep2.Conditions.Ready).To(Equal(pointer.Bool(false)))
+					Expect(ep2.Conditions.Serving).To(Equal(pointer.Bool(false)))
+				})
+
+				Context("When VM is being deleted", func() {
+					BeforeEach(func() {
+						now := metav1.Now()
+						vm1.DeletionTimestamp = &now
+						vm1.Finalizers = []string{"dummy-finalizer"}
+					})
+
+					It("Endpoint is terminating", func() {
+						slices := getEndpointSlices()
+						Expect(slices).To(HaveLen(1))
+						Expect(slices[0].Endpoints).To(HaveLen(2))
+						ep1 := slices[0].Endpoints[0]
+						Expect(ep1.TargetRef.Name).To(Equal(vm1.Name))
+						Expect(ep1.Conditions.Ready).To(Equal(pointer.Bool(false)))
+						Expect(ep1.Conditions.Serving).To(Equal(pointer.Bool(true)))
+						Expect(ep1.Conditions.Terminating).To(Equal(pointer.Bool(true)))
+					})
+				})
+
+				Context("When Service is dual-stack", func() {
+					BeforeEach(func() {
+						vmService.Spec.IPFamilies = []vmopv1alpha1.IPFamily{vmopv1alpha1.IPv4Protocol, vmopv1alpha1.IPv6Protocol}
+					})
+
+					It("EndpointSlice for each IP family", func() {
+						slices := getEndpointSlices()
+						Expect(slices).To(HaveLen(2))
+
+						for _, slice := range slices {
+							switch slice.AddressType {
+							case discoveryv1.AddressTypeIPv4:
+								Expect(slice.Endpoints).To(HaveLen(2))
+							case discoveryv1.AddressTypeIPv6:
+								Expect(slice.Endpoints).To(HaveLen(1))
+								Expect(slice.Endpoints[0].Addresses).To(Equal([]string{"2001:db8::2"}))
+							default:
+								Fail("unexpected address type " + string(slice.AddressType))
+							}
+						}
+					})
+				})
+
+				Context("When topology mode is Auto", func() {
+					var (
+						vm3                 *vmopv1alpha1.VirtualMachine
+						oldFaultDomainsFunc func() bool
+					)
+
+					zoneHints := func() map[string]string {
+						hints := map[string]string{}
+						slices := getEndpointSlices()
+						Expect(slices).To(HaveLen(1))
+						for _, ep := range slices[0].Endpoints {
+							if ep.Hints != nil {
+								Expect(ep.Hints.ForZones).To(HaveLen(1))
+								hints[ep.TargetRef.Name] = ep.Hints.ForZones[0].Name
+							}
+						}
+						return hints
+					}
+
+					BeforeEach(func() {
+						vmService.Spec.TopologyMode = vmopv1alpha1.VirtualMachineServiceTopologyModeAuto
+
+						vm2.Status.Zone = "zone-b"
+						conditions.MarkTrue(vm2, vmopv1alpha1.ReadyCondition)
+
+						vm3 = &vmopv1alpha1.VirtualMachine{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "dummy-vm3",
+								Namespace: vmService.Namespace,
+								Labels:    labelSelector,
+							},
+							Status: vmopv1alpha1.VirtualMachineStatus{
+								VmIp: "3.3.3.3",
+								Zone: "zone-a",
+							},
+						}
+						initObjects = append(initObjects, vm3)
+
+						oldFaultDomainsFunc = lib.IsWcpFaultDomainsFSSEnabled
+						lib.IsWcpFaultDomainsFSSEnabled = func() bool { return true }
+						for _, zone := range []string{"zone-a", "zone-b", "zone-c"} {
+							initObjects = append(initObjects, &topologyv1.AvailabilityZone{
+								ObjectMeta: metav1.ObjectMeta{Name: zone},
+							})
+						}
+					})
+
+					AfterEach(func() {
+						lib.IsWcpFaultDomainsFSSEnabled = oldFaultDomainsFunc
+					})
+
+					It("Service has topology-aware hints annotation", func() {
+						service := &corev1.Service{}
+						Expect(ctx.Client.Get(ctx, objKey, service)).To(Succeed())
+						Expect(service.Annotations).To(HaveKeyWithValue(corev1.AnnotationTopologyAwareHints, "Auto"))
+					})
+
+					It("Every zone is hinted a ready VM", func() {
+						Expect(zoneHints()).To(Equal(map[string]string{
+							vm1.Name: "zone-a",
+							vm2.Name: "zone-b",
+							vm3.Name: "zone-c",
+						}))
+					})
+
+					When("A ready VM does not have a zone", func() {
+						BeforeEach(func() {
+							vm3.Status.Zone = ""
+						})
+
+						It("No zone hints", func() {
+							Expect(zoneHints()).To(BeEmpty())
+						})
+					})
+
+					When("There are fewer ready VMs than zones", func() {
+						BeforeEach(func() {
+							conditions.MarkFalse(vm2, vmopv1alpha1.ReadyCondition, "NotReady", vmopv1alpha1.ConditionSeverityInfo, "")
+						})
+
+						It("No zone hints", func() {
+							Expect(zoneHints()).To(BeEmpty())
+						})
+					})
+
+					When("Topology mode is disabled", func() {
+						BeforeEach(func() {
+							vmService.Spec.TopologyMode = vmopv1alpha1.VirtualMachineServiceTopologyModeDisabled
+						})
+
+						It("No zone hints or annotation", func() {
+							Expect(zoneHints()).To(BeEmpty())
+
+							service := &corev1.Service{}
+							Expect(ctx.Client.Get(ctx, objKey, service)).To(Succeed())
+							Expect(service.Annotations).ToNot(HaveKey(corev1.AnnotationTopologyAwareHints))
+						})
+					})
+				})
+
+				It("Deletes stale EndpointSlices", func() {
+					Expect(getEndpointSlices()).To(HaveLen(1))
+
+					vm1.Labels = nil
+					Expect(ctx.Client.Update(ctx, vm1)).To(Succeed())
+					Expect(ctx.Client.Delete(ctx, vm2)).To(Succeed())
+
+					Expect(reconciler.ReconcileNormal(vmServiceCtx)).To(Succeed())
+
+					slices := getEndpointSlices()
+					Expect(slices).To(HaveLen(1))
+					Expect(slices[0].Endpoints).To(BeEmpty())
+				})
+			})
+		})
 	})
 
 	Context("ReconcileDelete", func() {
@@ -674,13 +1029,25 @@ func unitTestsReconcile() {
 				}
 				endpoint := &corev1.Endpoints{ObjectMeta: objectMeta}
 				service := &corev1.Service{ObjectMeta: objectMeta}
-				initObjects = append(initObjects, endpoint, service)
+				slice := &discoveryv1.EndpointSlice{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      vmService.Name + "-ipv4",
+						Namespace: vmService.Namespace,
+						Labels:    utils.EndpointSliceLabels(vmService.Name),
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+				}
+				initObjects = append(initObjects, endpoint, service, slice)
 			})
 
-			It("Deletes Endpoint and Service", func() {
+			It("Deletes Endpoint, EndpointSlices and Service", func() {
 				err := reconciler.ReconcileDelete(vmServiceCtx)
 				Expect(err).ToNot(HaveOccurred())
 
+				sliceList := &discoveryv1.EndpointSliceList{}
+				Expect(ctx.Client.List(ctx, sliceList, client.InNamespace(vmService.Namespace))).To(Succeed())
+				Expect(sliceList.Items).To(BeEmpty())
+
 				endpoint := &corev1.Endpoints{}
 				err = ctx.Client.Get(ctx, objKey, endpoint)
 				Expect(errors.IsNotFound(err)).To(BeTrue())
@@ -0,0 +1,258 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinesnapshot
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const finalizerName = "virtualmachinesnapshot.vmoperator.vmware.com"
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1alpha1.VirtualMachineSnapshot{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProvider,
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Watches(&source.Kind{Type: &vmopv1alpha1.VirtualMachine{}},
+			handler.EnqueueRequestsFromMapFunc(vmToSnapshotMapperFn(ctx, r.Client))).
+		Complete(r)
+}
+
+// vmToSnapshotMapperFn returns a mapper function that can be used to queue reconcile request
+// for the VirtualMachineSnapshots in response to an event on the VirtualMachine resource.
+func vmToSnapshotMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(o client.Object) []reconcile.Request {
+	return func(o client.Object) []reconcile.Request {
+		vm := o.(*vmopv1alpha1.VirtualMachine)
+		logger := ctx.Logger.WithValues("name", vm.Name, "namespace", vm.Namespace)
+
+		vmSnapshotList := &vmopv1alpha1.VirtualMachineSnapshotList{}
+		if err := c.List(ctx, vmSnapshotList, client.InNamespace(vm.Namespace)); err != nil {
+			logger.Error(err, "Failed to list VirtualMachineSnapshots for reconciliation due to VirtualMachine watch")
+			return nil
+		}
+
+		var reconcileRequests []reconcile.Request
+		for _, vmSnapshot := range vmSnapshotList.Items {
+			if vmSnapshot.Spec.VirtualMachineName == vm.Name {
+				key := client.ObjectKey{Namespace: vmSnapshot.Namespace, Name: vmSnapshot.Name}
+				reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: key})
+			}
+		}
+
+		return reconcileRequests
+	}
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterface) *Reconciler {
+	return &Reconciler{
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
+	}
+}
+
+// Reconciler reconciles a VirtualMachineSnapshot object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterface
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesnapshots,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesnapshots/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines/status,verbs=get
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	vmSnapshot := &vmopv1alpha1.VirtualMachineSnapshot{}
+	if err := r.Get(ctx, req.NamespacedName, vmSnapshot); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	vmSnapshotCtx := &context.VirtualMachineSnapshotContext{
+		Context:    ctx,
+		Logger:     ctrl.Log.WithName("VirtualMachineSnapshot").WithValues("name", vmSnapshot.NamespacedName()),
+		VMSnapshot: vmSnapshot,
+	}
+
+	patchHelper, err := patch.NewHelper(vmSnapshot, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to init patch helper for %s: %w", vmSnapshotCtx, err)
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, vmSnapshot); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			vmSnapshotCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	vm, err := r.getVirtualMachine(vmSnapshotCtx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	vmSnapshotCtx.VM = vm
+
+	if !vmSnapshot.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.ReconcileDelete(vmSnapshotCtx)
+	}
+
+	return ctrl.Result{}, r.ReconcileNormal(vmSnapshotCtx)
+}
+
+// getVirtualMachine returns the VirtualMachine being snapshot, or nil if it does not exist.
+func (r *Reconciler) getVirtualMachine(ctx *context.VirtualMachineSnapshotContext) (*vmopv1alpha1.VirtualMachine, error) {
+	vm := &vmopv1alpha1.VirtualMachine{}
+	key := client.ObjectKey{Name: ctx.VMSnapshot.Spec.VirtualMachineName, Namespace: ctx.VMSnapshot.Namespace}
+	if err := r.Get(ctx, key, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get VirtualMachine %s", key)
+	}
+	return vm, nil
+}
+
+// ReconcileDelete removes the snapshot from the VM. The snapshot no longer exists if the VM has been deleted.
+func (r *Reconciler) ReconcileDelete(ctx *context.VirtualMachineSnapshotContext) error {
+	if !controllerutil.ContainsFinalizer(ctx.VMSnapshot, finalizerName) {
+		return nil
+	}
+
+	if ctx.VM != nil && ctx.VMSnapshot.Status.UniqueID != "" {
+		ctx.Logger.Info("Deleting VirtualMachineSnapshot")
+		if err := r.VMProvider.DeleteVirtualMachineSnapshot(ctx, ctx.VM, ctx.VMSnapshot); err != nil {
+			ctx.Logger.Error(err, "Failed to delete VirtualMachineSnapshot")
+			r.Recorder.EmitEvent(ctx.VMSnapshot, "Delete", err, false)
+			return err
+		}
+		r.Recorder.EmitEvent(ctx.VMSnapshot, "Delete", nil, false)
+	}
+
+	controllerutil.RemoveFinalizer(ctx.VMSnapshot, finalizerName)
+	return nil
+}
+
+// ReconcileNormal takes the snapshot if it does not exist yet, refreshes its status, and reverts the VM to the
+// snapshot when requested.
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineSnapshotContext) error {
+	if !controllerutil.ContainsFinalizer(ctx.VMSnapshot, finalizerName) {
+		// The finalizer must be present before the snapshot is taken so that it is removed from the VM.
+		controllerutil.AddFinalizer(ctx.VMSnapshot, finalizerName)
+		return nil
+	}
+
+	if ctx.VM == nil {
+		conditions.MarkFalse(ctx.VMSnapshot, vmopv1alpha1.ReadyCondition,
+			vmopv1alpha1.VirtualMachineSnapshotVirtualMachineNotFoundReason, vmopv1alpha1.ConditionSeverityError,
+			"VirtualMachine %s not found", ctx.VMSnapshot.Spec.VirtualMachineName)
+		return nil
+	}
+
+	if ctx.VM.Status.UniqueID == "" {
+		// The VirtualMachine watch will requeue this snapshot once the VM has been created.
+		conditions.MarkFalse(ctx.VMSnapshot, vmopv1alpha1.ReadyCondition,
+			vmopv1alpha1.VirtualMachineSnapshotVirtualMachineNotCreatedReason, vmopv1alpha1.ConditionSeverityInfo,
+			"VirtualMachine %s has not been created", ctx.VM.Name)
+		return nil
+	}
+
+	if err := r.reconcileOwnerReference(ctx); err != nil {
+		return err
+	}
+
+	isCreate := ctx.VMSnapshot.Status.UniqueID == ""
+	if err := r.VMProvider.CreateOrUpdateVirtualMachineSnapshot(ctx, ctx.VM, ctx.VMSnapshot); err != nil {
+		ctx.Logger.Error(err, "Failed to reconcile VirtualMachineSnapshot")
+		conditions.MarkFalse(ctx.VMSnapshot, vmopv1alpha1.ReadyCondition,
+			vmopv1alpha1.VirtualMachineSnapshotCreateFailedReason, vmopv1alpha1.ConditionSeverityError, err.Error())
+		r.Recorder.EmitEvent(ctx.VMSnapshot, "CreateOrUpdate", err, false)
+		return err
+	}
+	if isCreate {
+		r.Recorder.EmitEvent(ctx.VMSnapshot, "Create", nil, false)
+	}
+	conditions.MarkTrue(ctx.VMSnapshot, vmopv1alpha1.ReadyCondition)
+
+	return r.revertIfNeeded(ctx)
+}
+
+// reconcileOwnerReference sets the VirtualMachine as the owner of the VirtualMachineSnapshot so the snapshot is
+// garbage collected when the VirtualMachine is deleted.
+func (r *Reconciler) reconcileOwnerReference(ctx *context.VirtualMachineSnapshotContext) error {
+	if err := controllerutil.SetOwnerReference(ctx.VM, ctx.VMSnapshot, r.Scheme()); err != nil {
+		return errors.Wrapf(err, "failed to set owner reference")
+	}
+	return nil
+}
+
+func (r *Reconciler) revertIfNeeded(ctx *context.VirtualMachineSnapshotContext) error {
+	revertTime, err := util.PendingTriggerTime(ctx.VMSnapshot.Spec.NextRevertTime, ctx.VMSnapshot.Status.LastRevertTime)
+	if err != nil {
+		// This is validated by the webhook so there is nothing to retry.
+		ctx.Logger.Error(err, "Ignoring invalid nextRevertTime")
+		return nil
+	}
+
+	if revertTime == nil {
+		return nil
+	}
+
+	ctx.Logger.Info("Reverting VirtualMachine to snapshot", "nextRevertTime", ctx.VMSnapshot.Spec.NextRevertTime)
+	if err := r.VMProvider.RevertVirtualMachineSnapshot(ctx, ctx.VM, ctx.VMSnapshot); err != nil {
+		ctx.Logger.Error(err, "Failed to revert VirtualMachine to snapshot")
+		r.Recorder.EmitEvent(ctx.VMSnapshot, "Revert", err, false)
+		return err
+	}
+	r.Recorder.EmitEvent(ctx.VMSnapshot, "Revert", nil, false)
+
+	ctx.VMSnapshot.Status.LastRevertTime = revertTime
+	return nil
+}
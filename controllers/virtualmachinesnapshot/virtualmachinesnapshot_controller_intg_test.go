@@ -0,0 +1,143 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinesnapshot_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	var (
+		ctx *builder.IntegrationTestContext
+
+		vm            *vmopv1alpha1.VirtualMachine
+		vmSnapshot    *vmopv1alpha1.VirtualMachineSnapshot
+		vmSnapshotKey types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+
+		vm = &vmopv1alpha1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ctx.Namespace,
+				Name:      "dummy-vm",
+			},
+			Spec: vmopv1alpha1.VirtualMachineSpec{
+				ImageName:  "dummy-image",
+				ClassName:  "dummy-class",
+				PowerState: vmopv1alpha1.VirtualMachinePoweredOn,
+			},
+		}
+
+		vmSnapshot = &vmopv1alpha1.VirtualMachineSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ctx.Namespace,
+				Name:      "dummy-snapshot",
+			},
+			Spec: vmopv1alpha1.VirtualMachineSnapshotSpec{
+				VirtualMachineName: vm.Name,
+			},
+		}
+		vmSnapshotKey = types.NamespacedName{Name: vmSnapshot.Name, Namespace: vmSnapshot.Namespace}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		intgFakeVMProvider.Reset()
+	})
+
+	getVirtualMachineSnapshot := func(ctx *builder.IntegrationTestContext, objKey types.NamespacedName) *vmopv1alpha1.VirtualMachineSnapshot {
+		vmSnapshot := &vmopv1alpha1.VirtualMachineSnapshot{}
+		if err := ctx.Client.Get(ctx, objKey, vmSnapshot); err != nil {
+			return nil
+		}
+		return vmSnapshot
+	}
+
+	Context("Reconcile", func() {
+		var deleted bool
+
+		BeforeEach(func() {
+			deleted = false
+
+			intgFakeVMProvider.Lock()
+			intgFakeVMProvider.CreateOrUpdateVirtualMachineSnapshotFn = func(_ context.Context, _ *vmopv1alpha1.VirtualMachine, vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) error {
+				vmSnapshot.Status.UniqueID = "snapshot-1"
+				return nil
+			}
+			intgFakeVMProvider.DeleteVirtualMachineSnapshotFn = func(_ context.Context, _ *vmopv1alpha1.VirtualMachine, _ *vmopv1alpha1.VirtualMachineSnapshot) error {
+				deleted = true
+				return nil
+			}
+			intgFakeVMProvider.Unlock()
+
+			Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			err := ctx.Client.Delete(ctx, vmSnapshot)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+			err = ctx.Client.Delete(ctx, vm)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Reconciles after VirtualMachineSnapshot creation and deletion", func() {
+			Expect(ctx.Client.Create(ctx, vmSnapshot)).To(Succeed())
+
+			By("VirtualMachineSnapshot should wait for the VM to be created", func() {
+				Eventually(func() string {
+					if vmSnapshot := getVirtualMachineSnapshot(ctx, vmSnapshotKey); vmSnapshot != nil {
+						return conditions.GetReason(vmSnapshot, vmopv1alpha1.ReadyCondition)
+					}
+					return ""
+				}).Should(Equal(vmopv1alpha1.VirtualMachineSnapshotVirtualMachineNotCreatedReason))
+			})
+
+			By("VirtualMachineSnapshot should be ready once the VM is created", func() {
+				vm.Status.UniqueID = "vm-1"
+				Expect(ctx.Client.Status().Update(ctx, vm)).To(Succeed())
+
+				Eventually(func() bool {
+					if vmSnapshot := getVirtualMachineSnapshot(ctx, vmSnapshotKey); vmSnapshot != nil {
+						return conditions.IsTrue(vmSnapshot, vmopv1alpha1.ReadyCondition)
+					}
+					return false
+				}).Should(BeTrue())
+
+				vmSnapshot := getVirtualMachineSnapshot(ctx, vmSnapshotKey)
+				Expect(vmSnapshot).ToNot(BeNil())
+				Expect(vmSnapshot.Status.UniqueID).To(Equal("snapshot-1"))
+				Expect(vmSnapshot.GetFinalizers()).To(ContainElement(finalizer))
+				Expect(vmSnapshot.OwnerReferences).To(HaveLen(1))
+				Expect(vmSnapshot.OwnerReferences[0].UID).To(Equal(vm.UID))
+			})
+
+			By("VirtualMachineSnapshot should be removed from the VM when deleted", func() {
+				Expect(ctx.Client.Delete(ctx, vmSnapshot)).To(Succeed())
+
+				Eventually(func() bool {
+					return getVirtualMachineSnapshot(ctx, vmSnapshotKey) == nil
+				}).Should(BeTrue())
+
+				intgFakeVMProvider.Lock()
+				defer intgFakeVMProvider.Unlock()
+				Expect(deleted).To(BeTrue())
+			})
+		})
+	})
+}
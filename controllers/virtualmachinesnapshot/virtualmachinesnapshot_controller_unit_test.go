@@ -0,0 +1,278 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachinesnapshot_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinesnapshot"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking Reconcile", unitTestsReconcile)
+}
+
+const finalizer = "virtualmachinesnapshot.vmoperator.vmware.com"
+
+func unitTestsReconcile() {
+	const (
+		providerError = "provider error"
+	)
+
+	var (
+		initObjects    []client.Object
+		ctx            *builder.UnitTestContextForController
+		reconciler     *virtualmachinesnapshot.Reconciler
+		fakeVMProvider *providerfake.VMProvider
+
+		vm            *vmopv1alpha1.VirtualMachine
+		vmSnapshot    *vmopv1alpha1.VirtualMachineSnapshot
+		vmSnapshotCtx *vmopContext.VirtualMachineSnapshotContext
+	)
+
+	BeforeEach(func() {
+		vm = &vmopv1alpha1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: "dummy-ns",
+			},
+			Status: vmopv1alpha1.VirtualMachineStatus{
+				UniqueID: "vm-42",
+			},
+		}
+
+		vmSnapshot = &vmopv1alpha1.VirtualMachineSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "dummy-snapshot",
+				Namespace:  "dummy-ns",
+				Finalizers: []string{finalizer},
+			},
+			Spec: vmopv1alpha1.VirtualMachineSnapshotSpec{
+				VirtualMachineName: vm.Name,
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachinesnapshot.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProvider,
+		)
+		fakeVMProvider = ctx.VMProvider.(*providerfake.VMProvider)
+
+		vmSnapshotCtx = &vmopContext.VirtualMachineSnapshotContext{
+			Context:    ctx,
+			Logger:     ctx.Logger.WithName(vmSnapshot.Name),
+			VMSnapshot: vmSnapshot,
+			VM:         vm,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		vmSnapshotCtx = nil
+		reconciler = nil
+		fakeVMProvider = nil
+	})
+
+	Context("ReconcileNormal", func() {
+		BeforeEach(func() {
+			initObjects = append(initObjects, vm, vmSnapshot)
+		})
+
+		When("object does not have finalizer set", func() {
+			BeforeEach(func() {
+				vmSnapshot.Finalizers = nil
+			})
+
+			It("will set finalizer", func() {
+				Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(Succeed())
+				Expect(vmSnapshot.GetFinalizers()).To(ContainElement(finalizer))
+				Expect(vmSnapshot.Status.UniqueID).To(BeEmpty())
+			})
+		})
+
+		It("will create the snapshot", func() {
+			Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(Succeed())
+			Expect(vmSnapshot.Status.UniqueID).ToNot(BeEmpty())
+			Expect(conditions.IsTrue(vmSnapshot, vmopv1alpha1.ReadyCondition)).To(BeTrue())
+			Expect(vmSnapshot.OwnerReferences).To(HaveLen(1))
+			Expect(vmSnapshot.OwnerReferences[0].Name).To(Equal(vm.Name))
+			expectEvent(ctx, "CreateSuccess")
+		})
+
+		It("will return error when provider fails to create the snapshot", func() {
+			fakeVMProvider.CreateOrUpdateVirtualMachineSnapshotFn = func(_ context.Context, _ *vmopv1alpha1.VirtualMachine, _ *vmopv1alpha1.VirtualMachineSnapshot) error {
+				return errors.New(providerError)
+			}
+
+			err := reconciler.ReconcileNormal(vmSnapshotCtx)
+			Expect(err).To(MatchError(providerError))
+			Expect(conditions.IsFalse(vmSnapshot, vmopv1alpha1.ReadyCondition)).To(BeTrue())
+			Expect(conditions.GetReason(vmSnapshot, vmopv1alpha1.ReadyCondition)).To(Equal(vmopv1alpha1.VirtualMachineSnapshotCreateFailedReason))
+			expectEvent(ctx, "CreateOrUpdateFailure")
+		})
+
+		When("the VM does not exist", func() {
+			JustBeforeEach(func() {
+				vmSnapshotCtx.VM = nil
+			})
+
+			It("will mark the snapshot not ready", func() {
+				Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(Succeed())
+				Expect(conditions.IsFalse(vmSnapshot, vmopv1alpha1.ReadyCondition)).To(BeTrue())
+				Expect(conditions.GetReason(vmSnapshot, vmopv1alpha1.ReadyCondition)).To(Equal(vmopv1alpha1.VirtualMachineSnapshotVirtualMachineNotFoundReason))
+			})
+		})
+
+		When("the VM has not been created", func() {
+			BeforeEach(func() {
+				vm.Status.UniqueID = ""
+			})
+
+			It("will mark the snapshot not ready", func() {
+				Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(Succeed())
+				Expect(vmSnapshot.Status.UniqueID).To(BeEmpty())
+				Expect(conditions.IsFalse(vmSnapshot, vmopv1alpha1.ReadyCondition)).To(BeTrue())
+				Expect(conditions.GetReason(vmSnapshot, vmopv1alpha1.ReadyCondition)).To(Equal(vmopv1alpha1.VirtualMachineSnapshotVirtualMachineNotCreatedReason))
+			})
+		})
+
+		Context("Revert", func() {
+			var reverted bool
+
+			BeforeEach(func() {
+				reverted = false
+				vmSnapshot.Status.UniqueID = "snapshot-1"
+			})
+
+			JustBeforeEach(func() {
+				fakeVMProvider.RevertVirtualMachineSnapshotFn = func(_ context.Context, _ *vmopv1alpha1.VirtualMachine, _ *vmopv1alpha1.VirtualMachineSnapshot) error {
+					reverted = true
+					return nil
+				}
+			})
+
+			It("will not revert when nextRevertTime is not set", func() {
+				Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(Succeed())
+				Expect(reverted).To(BeFalse())
+				Expect(vmSnapshot.Status.LastRevertTime).To(BeNil())
+			})
+
+			When("nextRevertTime is later than lastRevertTime", func() {
+				var nextRevertTime time.Time
+
+				BeforeEach(func() {
+					nextRevertTime = time.Now().UTC().Truncate(time.Second)
+					vmSnapshot.Spec.NextRevertTime = nextRevertTime.Format(time.RFC3339Nano)
+					lastRevertTime := metav1.NewTime(nextRevertTime.Add(-time.Hour))
+					vmSnapshot.Status.LastRevertTime = &lastRevertTime
+				})
+
+				It("will revert the VM and update lastRevertTime", func() {
+					Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(Succeed())
+					Expect(reverted).To(BeTrue())
+					Expect(vmSnapshot.Status.LastRevertTime).ToNot(BeNil())
+					Expect(vmSnapshot.Status.LastRevertTime.Time).To(BeTemporally("==", nextRevertTime))
+				})
+
+				It("will return error when provider fails to revert", func() {
+					fakeVMProvider.RevertVirtualMachineSnapshotFn = func(_ context.Context, _ *vmopv1alpha1.VirtualMachine, _ *vmopv1alpha1.VirtualMachineSnapshot) error {
+						return errors.New(providerError)
+					}
+
+					Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(MatchError(providerError))
+					Expect(vmSnapshot.Status.LastRevertTime.Time).To(BeTemporally("<", nextRevertTime))
+				})
+			})
+
+			When("nextRevertTime was already satisfied", func() {
+				BeforeEach(func() {
+					now := metav1.NewTime(time.Now().UTC().Truncate(time.Second))
+					vmSnapshot.Spec.NextRevertTime = now.Format(time.RFC3339Nano)
+					vmSnapshot.Status.LastRevertTime = &now
+				})
+
+				It("will not revert the VM again", func() {
+					Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(Succeed())
+					Expect(reverted).To(BeFalse())
+				})
+			})
+		})
+	})
+
+	Context("ReconcileDelete", func() {
+		var deleted bool
+
+		BeforeEach(func() {
+			deleted = false
+			vmSnapshot.Status.UniqueID = "snapshot-1"
+			initObjects = append(initObjects, vm, vmSnapshot)
+		})
+
+		JustBeforeEach(func() {
+			fakeVMProvider.DeleteVirtualMachineSnapshotFn = func(_ context.Context, _ *vmopv1alpha1.VirtualMachine, _ *vmopv1alpha1.VirtualMachineSnapshot) error {
+				deleted = true
+				return nil
+			}
+		})
+
+		It("will delete the snapshot and remove the finalizer", func() {
+			Expect(reconciler.ReconcileDelete(vmSnapshotCtx)).To(Succeed())
+			Expect(deleted).To(BeTrue())
+			Expect(vmSnapshot.GetFinalizers()).ToNot(ContainElement(finalizer))
+			expectEvent(ctx, "DeleteSuccess")
+		})
+
+		It("will keep the finalizer when provider fails to delete the snapshot", func() {
+			fakeVMProvider.DeleteVirtualMachineSnapshotFn = func(_ context.Context, _ *vmopv1alpha1.VirtualMachine, _ *vmopv1alpha1.VirtualMachineSnapshot) error {
+				return errors.New(providerError)
+			}
+
+			Expect(reconciler.ReconcileDelete(vmSnapshotCtx)).To(MatchError(providerError))
+			Expect(vmSnapshot.GetFinalizers()).To(ContainElement(finalizer))
+			expectEvent(ctx, "DeleteFailure")
+		})
+
+		When("the VM does not exist", func() {
+			JustBeforeEach(func() {
+				vmSnapshotCtx.VM = nil
+			})
+
+			It("will remove the finalizer", func() {
+				Expect(reconciler.ReconcileDelete(vmSnapshotCtx)).To(Succeed())
+				Expect(deleted).To(BeFalse())
+				Expect(vmSnapshot.GetFinalizers()).ToNot(ContainElement(finalizer))
+			})
+		})
+	})
+}
+
+func expectEvent(ctx *builder.UnitTestContextForController, eventStr string) {
+	var event string
+	// This does not work if we have more than one events and the first one does not match.
+	EventuallyWithOffset(1, ctx.Events).Should(Receive(&event))
+	eventComponents := strings.Split(event, " ")
+	ExpectWithOffset(1, eventComponents[1]).To(Equal(eventStr))
+}
@@ -21,6 +21,7 @@ import (
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/patch"
@@ -31,8 +32,25 @@ import (
 const (
 	DefaultExpiryTime = time.Second * 120
 	UUIDLabelKey      = "vmoperator.vmware.com/webconsolerequest-uuid"
+
+	// RequestedByAnnotationKey is the annotation that the mutation webhook sets on create to the name of the
+	// authenticated user that created the WebConsoleRequest.
+	RequestedByAnnotationKey = "vmoperator.vmware.com/webconsolerequest-requested-by"
 )
 
+// GetExpiryDuration returns the lifetime of the ticket of the WebConsoleRequest, which is the spec.ttlSeconds, or
+// the DefaultExpiryTime if that is not set, bounded by the maximum lifetime that is configured for VM Operator.
+func GetExpiryDuration(wcr *vmopv1alpha1.WebConsoleRequest) time.Duration {
+	expiry := DefaultExpiryTime
+	if ttl := wcr.Spec.TTLSeconds; ttl != nil && *ttl > 0 {
+		expiry = time.Duration(*ttl) * time.Second
+	}
+	if maxTTL := lib.GetWebConsoleRequestMaxTTL(); expiry > maxTTL {
+		expiry = maxTTL
+	}
+	return expiry
+}
+
 // AddToManager adds this package's controller to the provided manager.
 func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
 	var (
@@ -133,7 +151,7 @@ func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{Requeue: true, RequeueAfter: DefaultExpiryTime}, nil
+	return ctrl.Result{Requeue: true, RequeueAfter: GetExpiryDuration(webconsolerequest)}, nil
 }
 
 func (r *Reconciler) ReconcileEarlyNormal(ctx *context.WebConsoleRequestContext) (bool, error) {
@@ -148,6 +166,17 @@ func (r *Reconciler) ReconcileEarlyNormal(ctx *context.WebConsoleRequestContext)
 		return true, nil
 	}
 
+	// The web-console validation server deletes a one-time use request after it is used, but this makes sure
+	// the request is deleted if that failed.
+	if ctx.WebConsoleRequest.Spec.OneTimeUse && ctx.WebConsoleRequest.Status.Used {
+		err := r.Delete(ctx, ctx.WebConsoleRequest)
+		if client.IgnoreNotFound(err) != nil {
+			return false, errors.Wrapf(err, "failed to delete webconsolerequest")
+		}
+		ctx.Logger.Info("Deleted used one-time use WebConsoleRequest")
+		return true, nil
+	}
+
 	if ctx.WebConsoleRequest.Status.Response != "" {
 		// If the response is already set, no need to reconcile anymore
 		ctx.Logger.Info("Response already set, skip reconciling")
@@ -163,14 +192,29 @@ func (r *Reconciler) ReconcileNormal(ctx *context.WebConsoleRequestContext) erro
 		ctx.Logger.Info("Finished reconciling WebConsoleRequest")
 	}()
 
-	ticket, err := r.VMProvider.GetVirtualMachineWebMKSTicket(ctx, ctx.VM, ctx.WebConsoleRequest.Spec.PublicKey)
-	if err != nil {
-		return errors.Wrapf(err, "failed to get webmksticket")
+	expiryTime := metav1.NewTime(metav1.Now().Add(GetExpiryDuration(ctx.WebConsoleRequest)))
+
+	var ticket string
+	var err error
+	if ctx.WebConsoleRequest.Spec.ConsoleType == vmopv1alpha1.WebConsoleTypeSerial {
+		ticket, err = r.VMProvider.GetVirtualMachineSerialConsoleTicket(ctx, ctx.VM, ctx.WebConsoleRequest.Spec.PublicKey,
+			string(ctx.WebConsoleRequest.UID), expiryTime.Time)
+		if err != nil {
+			markTicketIssuedFalse(ctx.WebConsoleRequest, err)
+			return errors.Wrapf(err, "failed to get serial console ticket")
+		}
+	} else {
+		ticket, err = r.VMProvider.GetVirtualMachineWebMKSTicket(ctx, ctx.VM, ctx.WebConsoleRequest.Spec.PublicKey)
+		if err != nil {
+			markTicketIssuedFalse(ctx.WebConsoleRequest, err)
+			return errors.Wrapf(err, "failed to get webmksticket")
+		}
 	}
 	r.Recorder.EmitEvent(ctx.WebConsoleRequest, "Acquired Ticket", nil, false)
+	conditions.MarkTrue(ctx.WebConsoleRequest, vmopv1alpha1.WebConsoleRequestTicketIssuedCondition)
 
 	ctx.WebConsoleRequest.Status.Response = ticket
-	ctx.WebConsoleRequest.Status.ExpiryTime = metav1.NewTime(metav1.Now().Add(DefaultExpiryTime))
+	ctx.WebConsoleRequest.Status.ExpiryTime = expiryTime
 
 	// Add UUID as a Label to the current WebConsoleRequest resource after acquiring the ticket.
 	// This will be used when validating the connection request from users to the web-console URL.
@@ -179,14 +223,25 @@ func (r *Reconciler) ReconcileNormal(ctx *context.WebConsoleRequestContext) erro
 	}
 	ctx.WebConsoleRequest.Labels[UUIDLabelKey] = string(ctx.WebConsoleRequest.UID)
 
-	err = r.ReconcileOwnerReferences(ctx)
-	if err != nil {
+	if err := r.ReconcileOwnerReferences(ctx); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// markTicketIssuedFalse sets the TicketIssued condition of the WebConsoleRequest to false for the error that
+// occurred when acquiring the ticket.
+func markTicketIssuedFalse(wcr *vmopv1alpha1.WebConsoleRequest, err error) {
+	if errors.Is(err, vmprovider.ErrSerialConsolePortNotFound) {
+		conditions.MarkFalse(wcr, vmopv1alpha1.WebConsoleRequestTicketIssuedCondition,
+			vmopv1alpha1.WebConsoleRequestSerialPortNotFoundReason, vmopv1alpha1.ConditionSeverityError, "%v", err)
+		return
+	}
+	conditions.MarkFalse(wcr, vmopv1alpha1.WebConsoleRequestTicketIssuedCondition,
+		vmopv1alpha1.WebConsoleRequestTicketFailedReason, vmopv1alpha1.ConditionSeverityWarning, "%v", err)
+}
+
 func (r *Reconciler) ReconcileOwnerReferences(ctx *context.WebConsoleRequestContext) error {
 	isController := true
 	ownerRef := metav1.OwnerReference{
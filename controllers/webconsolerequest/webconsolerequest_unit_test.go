@@ -10,14 +10,18 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -113,5 +117,113 @@ func unitTestsReconcile() {
 				Expect(wcrCtx.WebConsoleRequest.Labels).To(HaveKey(webconsolerequest.UUIDLabelKey))
 			})
 		})
+
+		When("TTLSeconds is set", func() {
+			BeforeEach(func() {
+				wcr.Spec.TTLSeconds = pointer.Int32(300)
+			})
+
+			It("sets the expiry time from the TTL", func() {
+				err := reconciler.ReconcileNormal(wcrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(wcrCtx.WebConsoleRequest.Status.ExpiryTime.Time).To(BeTemporally("~", time.Now().Add(300*time.Second), 5*time.Second))
+			})
+		})
+
+		When("TTLSeconds is greater than the maximum", func() {
+			BeforeEach(func() {
+				wcr.Spec.TTLSeconds = pointer.Int32(int32((lib.DefaultWebConsoleRequestMaxTTL + time.Hour) / time.Second))
+			})
+
+			It("bounds the expiry time by the maximum", func() {
+				Expect(webconsolerequest.GetExpiryDuration(wcr)).To(Equal(lib.DefaultWebConsoleRequestMaxTTL))
+			})
+		})
+
+		When("Serial console is requested", func() {
+			var uuid string
+			var expiry time.Time
+
+			BeforeEach(func() {
+				wcr.Spec.ConsoleType = v1alpha1.WebConsoleTypeSerial
+				wcr.UID = "some-uid"
+			})
+
+			JustBeforeEach(func() {
+				fakeVMProvider.GetVirtualMachineSerialConsoleTicketFn = func(ctx context.Context, vm *v1alpha1.VirtualMachine,
+					pubKey, u string, e time.Time) (string, error) {
+					uuid, expiry = u, e
+					return "some-fake-serialconsoleticket", nil
+				}
+			})
+
+			It("returns the serial console ticket", func() {
+				err := reconciler.ReconcileNormal(wcrCtx)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(wcrCtx.WebConsoleRequest.Status.Response).To(Equal("some-fake-serialconsoleticket"))
+				Expect(wcrCtx.WebConsoleRequest.Labels).To(HaveKey(webconsolerequest.UUIDLabelKey))
+				Expect(uuid).To(Equal("some-uid"))
+				Expect(expiry).To(Equal(wcrCtx.WebConsoleRequest.Status.ExpiryTime.Time))
+				Expect(conditions.IsTrue(wcrCtx.WebConsoleRequest, v1alpha1.WebConsoleRequestTicketIssuedCondition)).To(BeTrue())
+			})
+
+			When("the VM does not have a serial port", func() {
+				JustBeforeEach(func() {
+					fakeVMProvider.GetVirtualMachineSerialConsoleTicketFn = func(ctx context.Context, vm *v1alpha1.VirtualMachine,
+						pubKey, uuid string, expiry time.Time) (string, error) {
+						return "", vmprovider.ErrSerialConsolePortNotFound
+					}
+				})
+
+				It("marks the TicketIssued condition false", func() {
+					err := reconciler.ReconcileNormal(wcrCtx)
+					Expect(err).To(HaveOccurred())
+
+					Expect(wcrCtx.WebConsoleRequest.Status.Response).To(BeEmpty())
+					Expect(conditions.IsFalse(wcrCtx.WebConsoleRequest, v1alpha1.WebConsoleRequestTicketIssuedCondition)).To(BeTrue())
+					Expect(conditions.GetReason(wcrCtx.WebConsoleRequest, v1alpha1.WebConsoleRequestTicketIssuedCondition)).
+						To(Equal(v1alpha1.WebConsoleRequestSerialPortNotFoundReason))
+				})
+			})
+		})
+	})
+
+	Context("ReconcileEarlyNormal", func() {
+		BeforeEach(func() {
+			initObjects = append(initObjects, wcr, vm)
+		})
+
+		When("a one-time use request was used", func() {
+			BeforeEach(func() {
+				wcr.Spec.OneTimeUse = true
+				wcr.Status.Response = "some-fake-webmksticket"
+				wcr.Status.Used = true
+			})
+
+			It("deletes the request", func() {
+				done, err := reconciler.ReconcileEarlyNormal(wcrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(done).To(BeTrue())
+
+				err = ctx.Client.Get(ctx, client.ObjectKeyFromObject(wcr), &v1alpha1.WebConsoleRequest{})
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		When("a one-time use request was not used", func() {
+			BeforeEach(func() {
+				wcr.Spec.OneTimeUse = true
+				wcr.Status.Response = "some-fake-webmksticket"
+			})
+
+			It("does not delete the request", func() {
+				done, err := reconciler.ReconcileEarlyNormal(wcrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(done).To(BeTrue())
+
+				Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(wcr), &v1alpha1.WebConsoleRequest{})).To(Succeed())
+			})
+		})
 	})
 }
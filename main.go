@@ -39,6 +39,7 @@ var (
 
 	defaultSyncPeriod                   = manager.DefaultSyncPeriod
 	defaultMaxConcurrentReconciles      = manager.DefaultMaxConcurrentReconciles
+	defaultMaxProbeWorkers              = manager.DefaultMaxProbeWorkers
 	defaultLeaderElectionID             = manager.DefaultLeaderElectionID
 	defaultPodNamespace                 = manager.DefaultPodNamespace
 	defaultPodName                      = manager.DefaultPodName
@@ -79,6 +80,9 @@ func init() {
 	if v, err := strconv.Atoi(os.Getenv("MAX_CONCURRENT_RECONCILES")); err == nil {
 		defaultMaxConcurrentReconciles = v
 	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_PROBE_WORKERS")); err == nil {
+		defaultMaxProbeWorkers = v
+	}
 	if v := os.Getenv("LEADER_ELECTION_ID"); v != "" {
 		defaultLeaderElectionID = v
 	}
@@ -175,6 +179,11 @@ func main() {
 		"max-concurrent-reconciles",
 		defaultMaxConcurrentReconciles,
 		"The maximum number of allowed, concurrent reconciles.")
+	flag.IntVar(
+		&managerOpts.MaxProbeWorkers,
+		"max-probe-workers",
+		defaultMaxProbeWorkers,
+		"The maximum number of workers processing each of the VM probe queues.")
 	flag.StringVar(
 		&managerOpts.PodNamespace,
 		"pod-namespace",
@@ -54,6 +54,10 @@ type ControllerManagerContext struct {
 	// controller will receive concurrently.
 	MaxConcurrentReconciles int
 
+	// MaxProbeWorkers is the maximum number of workers processing each of
+	// the VM probe queues.
+	MaxProbeWorkers int
+
 	// WebhookServiceNamespace is the namespace in which the webhook service
 	// is located.
 	WebhookServiceNamespace string
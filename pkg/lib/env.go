@@ -52,6 +52,15 @@ const (
 	// NetworkProviderType is the cluster network provider type. It can be VSPHERE_NETWORK, NSX-T or NAMED.
 	// NAMED is only used in a local test environment.
 	NetworkProviderType = "NETWORK_PROVIDER"
+
+	// SerialConsoleProxyURIEnv is the URI of the virtual serial port concentrator (vSPC), like
+	// telnets://vspc.example.com:13370, that the serial consoles of the VMs connect to.
+	SerialConsoleProxyURIEnv = "SERIAL_CONSOLE_PROXY_URI"
+
+	// WebConsoleRequestMaxTTLEnv is the maximum lifetime of a web console ticket, like 10m.
+	WebConsoleRequestMaxTTLEnv = "WEB_CONSOLE_REQUEST_MAX_TTL"
+	// DefaultWebConsoleRequestMaxTTL is the default maximum lifetime of a web console ticket.
+	DefaultWebConsoleRequestMaxTTL = 10 * time.Minute
 )
 
 // SetVMOpNamespaceEnv sets the VM Operator pod's namespace in the environment.
@@ -126,6 +135,22 @@ var MaxConcurrentCreateVMsOnProvider = func() int {
 	return val
 }
 
+// GetSerialConsoleProxyURI returns the URI of the vSPC that the serial consoles of the VMs connect to. An empty
+// string is returned if serial consoles are not configured.
+func GetSerialConsoleProxyURI() string {
+	return os.Getenv(SerialConsoleProxyURIEnv)
+}
+
+// GetWebConsoleRequestMaxTTL returns the maximum lifetime of a web console ticket.
+func GetWebConsoleRequestMaxTTL() time.Duration {
+	if s := os.Getenv(WebConsoleRequestMaxTTLEnv); len(s) > 0 {
+		if duration, err := time.ParseDuration(s); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return DefaultWebConsoleRequestMaxTTL
+}
+
 // GetInstanceStoragePVPlacementFailedTTL returns the configured wait time before declaring PV placement
 // failed after error annotation is set on PVC.
 func GetInstanceStoragePVPlacementFailedTTL() time.Duration {
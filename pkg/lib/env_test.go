@@ -6,6 +6,7 @@ package lib
 import (
 	"os"
 	"strconv"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -42,3 +43,23 @@ var _ = Describe("MaxAllowedConcurrentVMsOnProvider", func() {
 		})
 	})
 })
+
+var _ = Describe("GetWebConsoleRequestMaxTTL", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(WebConsoleRequestMaxTTLEnv)).To(Succeed())
+	})
+
+	It("returns the value from the env", func() {
+		Expect(os.Setenv(WebConsoleRequestMaxTTLEnv, "30m")).To(Succeed())
+		Expect(GetWebConsoleRequestMaxTTL()).To(Equal(30 * time.Minute))
+	})
+
+	It("returns the default value for an invalid env value", func() {
+		Expect(os.Setenv(WebConsoleRequestMaxTTLEnv, "-1m")).To(Succeed())
+		Expect(GetWebConsoleRequestMaxTTL()).To(Equal(DefaultWebConsoleRequestMaxTTL))
+	})
+
+	It("returns the default value when the env is not set", func() {
+		Expect(GetWebConsoleRequestMaxTTL()).To(Equal(DefaultWebConsoleRequestMaxTTL))
+	})
+})
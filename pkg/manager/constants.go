@@ -20,6 +20,10 @@ const (
 	// manager option.
 	DefaultMaxConcurrentReconciles = 1
 
+	// DefaultMaxProbeWorkers is the default value for the eponymous
+	// manager option.
+	DefaultMaxProbeWorkers = 50
+
 	// DefaultPodNamespace is the default value for the eponymous manager
 	// option.
 	DefaultPodNamespace = defaultPrefix + "system"
@@ -0,0 +1,65 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package manager
+
+import (
+	goctx "context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+)
+
+const (
+	// VirtualMachineClassNameIndexField is the cache index of the VirtualMachines by the name of their class.
+	VirtualMachineClassNameIndexField = "spec.className"
+
+	// VirtualMachineMetadataConfigMapIndexField is the cache index of the VirtualMachines by the name of
+	// their metadata ConfigMap.
+	VirtualMachineMetadataConfigMapIndexField = "spec.vmMetadata.configMapName"
+
+	// VirtualMachineMetadataSecretIndexField is the cache index of the VirtualMachines by the name of
+	// their metadata Secret.
+	VirtualMachineMetadataSecretIndexField = "spec.vmMetadata.secretName"
+)
+
+// addIndexes adds the cache indexes that are shared by the controllers. They are added here rather than by the
+// controllers since an index may only be added once to the manager.
+func addIndexes(ctx goctx.Context, mgr ctrlmgr.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+
+	if err := indexer.IndexField(ctx, &vmopv1.VirtualMachine{}, VirtualMachineClassNameIndexField,
+		func(obj client.Object) []string {
+			if className := obj.(*vmopv1.VirtualMachine).Spec.ClassName; className != "" {
+				return []string{className}
+			}
+			return nil
+		}); err != nil {
+		return errors.Wrapf(err, "failed to add index %s", VirtualMachineClassNameIndexField)
+	}
+
+	if err := indexer.IndexField(ctx, &vmopv1.VirtualMachine{}, VirtualMachineMetadataConfigMapIndexField,
+		func(obj client.Object) []string {
+			if md := obj.(*vmopv1.VirtualMachine).Spec.VmMetadata; md != nil && md.ConfigMapName != "" {
+				return []string{md.ConfigMapName}
+			}
+			return nil
+		}); err != nil {
+		return errors.Wrapf(err, "failed to add index %s", VirtualMachineMetadataConfigMapIndexField)
+	}
+
+	if err := indexer.IndexField(ctx, &vmopv1.VirtualMachine{}, VirtualMachineMetadataSecretIndexField,
+		func(obj client.Object) []string {
+			if md := obj.(*vmopv1.VirtualMachine).Spec.VmMetadata; md != nil && md.SecretName != "" {
+				return []string{md.SecretName}
+			}
+			return nil
+		}); err != nil {
+		return errors.Wrapf(err, "failed to add index %s", VirtualMachineMetadataSecretIndexField)
+	}
+
+	return nil
+}
@@ -9,7 +9,10 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -22,6 +25,7 @@ import (
 
 	topologyv1 "github.com/vmware-tanzu/vm-operator/external/tanzu-topology/api/v1alpha1"
 
+	vmsvcutil "github.com/vmware-tanzu/vm-operator/controllers/virtualmachineservice/utils"
 	imgregv1a1 "github.com/vmware-tanzu/vm-operator/external/image-registry/api/v1alpha1"
 	netopv1alpha1 "github.com/vmware-tanzu/vm-operator/external/net-operator/api/v1alpha1"
 	cnsv1alpha1 "github.com/vmware-tanzu/vm-operator/external/vsphere-csi-driver/pkg/syncer/cnsoperator/apis/cnsnodevmattachment/v1alpha1"
@@ -65,6 +69,19 @@ func New(opts Options) (Manager, error) {
 		cacheDisabledObjects = append(cacheDisabledObjects, &vmopv1.VirtualMachinePublishRequest{})
 	}
 
+	// The VirtualMachineService controller only needs the EndpointSlices that it manages, so restrict the cache
+	// to those instead of caching every EndpointSlice in the cluster.
+	newCache := opts.NewCache
+	if newCache == nil {
+		newCache = cache.BuilderWithOptions(cache.Options{
+			SelectorsByObject: cache.SelectorsByObject{
+				&discoveryv1.EndpointSlice{}: {
+					Label: labels.SelectorFromSet(labels.Set{discoveryv1.LabelManagedBy: vmsvcutil.EndpointSliceManagedBy}),
+				},
+			},
+		})
+	}
+
 	// Build the controller manager.
 	mgr, err := ctrlmgr.New(opts.KubeConfig, ctrlmgr.Options{
 		Scheme:                  opts.Scheme,
@@ -75,7 +92,7 @@ func New(opts Options) (Manager, error) {
 		LeaderElectionNamespace: opts.PodNamespace,
 		SyncPeriod:              &opts.SyncPeriod,
 		Namespace:               opts.WatchNamespace,
-		NewCache:                opts.NewCache,
+		NewCache:                newCache,
 		CertDir:                 opts.WebhookSecretVolumeMountPath,
 		Port:                    opts.WebhookServiceContainerPort,
 		ClientDisableCacheFor:   cacheDisabledObjects,
@@ -93,6 +110,7 @@ func New(opts Options) (Manager, error) {
 		LeaderElectionID:        opts.LeaderElectionID,
 		LeaderElectionNamespace: opts.PodNamespace,
 		MaxConcurrentReconciles: opts.MaxConcurrentReconciles,
+		MaxProbeWorkers:         opts.MaxProbeWorkers,
 		Logger:                  opts.Logger.WithName(opts.PodName),
 		Recorder:                record.New(mgr.GetEventRecorderFor(fmt.Sprintf("%s/%s", opts.PodNamespace, opts.PodName))),
 		Scheme:                  opts.Scheme,
@@ -100,6 +118,10 @@ func New(opts Options) (Manager, error) {
 		SyncPeriod:              opts.SyncPeriod,
 	}
 
+	if err := addIndexes(controllerManagerContext, mgr); err != nil {
+		return nil, err
+	}
+
 	if err := opts.InitializeProviders(controllerManagerContext, mgr); err != nil {
 		return nil, err
 	}
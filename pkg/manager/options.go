@@ -60,6 +60,12 @@ type Options struct {
 	// Defaults to the eponymous constant in this package.
 	MaxConcurrentReconciles int
 
+	// MaxProbeWorkers is the maximum number of workers processing each of
+	// the VM probe queues.
+	//
+	// Defaults to the eponymous constant in this package.
+	MaxProbeWorkers int
+
 	// MetricsAddr is the net.Addr string for the metrics server.
 	MetricsAddr string
 
@@ -175,6 +181,10 @@ func (o *Options) defaults() {
 		o.MaxConcurrentReconciles = DefaultMaxConcurrentReconciles
 	}
 
+	if o.MaxProbeWorkers == 0 {
+		o.MaxProbeWorkers = DefaultMaxProbeWorkers
+	}
+
 	if o.WebhookServiceContainerPort == 0 {
 		o.WebhookServiceContainerPort = DefaultWebhookServiceContainerPort
 	}
@@ -21,4 +21,10 @@ const (
 	imageNameLabel    = "image_name"
 	providerNameLabel = "provider_name"
 	providerKindLabel = "provider_kind"
+
+	// Prober related metrics labels.
+	probeTypeLabel   = "probe_type"
+	probeActionLabel = "probe_action"
+	probeResultLabel = "result"
+	queueLabel       = "queue"
 )
@@ -0,0 +1,103 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	proberMetricsOnce sync.Once
+	proberMetrics     *ProberMetrics
+)
+
+type ProberMetrics struct {
+	probeDuration *prometheus.HistogramVec
+	probeResults  *prometheus.CounterVec
+	queueDepth    *prometheus.GaugeVec
+	workers       *prometheus.GaugeVec
+}
+
+// NewProberMetrics initializes a singleton and registers all the defined metrics.
+func NewProberMetrics() *ProberMetrics {
+	proberMetricsOnce.Do(func() {
+		proberMetrics = &ProberMetrics{
+			probeDuration: prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Namespace: metricsNamespace,
+					Subsystem: "prober",
+					Name:      "probe_duration_seconds",
+					Help:      "Duration in seconds of VM probes",
+					Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+				},
+				[]string{probeTypeLabel, probeActionLabel},
+			),
+			probeResults: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: metricsNamespace,
+					Subsystem: "prober",
+					Name:      "probe_results_total",
+					Help:      "Number of VM probe results by probe type, action and result",
+				},
+				[]string{probeTypeLabel, probeActionLabel, probeResultLabel},
+			),
+			queueDepth: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricsNamespace,
+					Subsystem: "prober",
+					Name:      "queue_depth",
+					Help:      "Number of VMs waiting in a probe queue to be probed",
+				},
+				[]string{queueLabel},
+			),
+			workers: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricsNamespace,
+					Subsystem: "prober",
+					Name:      "workers",
+					Help:      "Number of workers processing a probe queue",
+				},
+				[]string{queueLabel},
+			),
+		}
+
+		metrics.Registry.MustRegister(
+			proberMetrics.probeDuration,
+			proberMetrics.probeResults,
+			proberMetrics.queueDepth,
+			proberMetrics.workers,
+		)
+	})
+
+	return proberMetrics
+}
+
+// RegisterProbeResult registers the duration and the result of a probe.
+func (pm *ProberMetrics) RegisterProbeResult(probeType, probeAction, result string, duration time.Duration) {
+	pm.probeDuration.With(prometheus.Labels{
+		probeTypeLabel:   probeType,
+		probeActionLabel: probeAction,
+	}).Observe(duration.Seconds())
+
+	pm.probeResults.With(prometheus.Labels{
+		probeTypeLabel:   probeType,
+		probeActionLabel: probeAction,
+		probeResultLabel: result,
+	}).Inc()
+}
+
+// RegisterQueueDepth registers the number of VMs waiting in the probe queue.
+func (pm *ProberMetrics) RegisterQueueDepth(queue string, depth int) {
+	pm.queueDepth.With(prometheus.Labels{queueLabel: queue}).Set(float64(depth))
+}
+
+// RegisterWorkers registers the number of workers processing the probe queue.
+func (pm *ProberMetrics) RegisterWorkers(queue string, workers int) {
+	pm.workers.With(prometheus.Labels{queueLabel: queue}).Set(float64(workers))
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package probe
+
+import (
+	goctx "context"
+	"fmt"
+	"time"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
+)
+
+// execProber implements the Probe interface.
+type execProber struct {
+	prober vmProviderProber
+}
+
+// NewExecProber creates a new exec prober which implements the Probe interface to execute commands inside the guest.
+func NewExecProber(vmProviderProber vmProviderProber) Probe {
+	return &execProber{
+		prober: vmProviderProber,
+	}
+}
+
+func (ep execProber) Probe(ctx *context.ProbeContext) (Result, error) {
+	p := ctx.ProbeSpec
+
+	var timeout time.Duration
+	if p.TimeoutSeconds <= 0 {
+		timeout = defaultConnectTimeout
+	} else {
+		timeout = time.Duration(p.TimeoutSeconds) * time.Second
+	}
+
+	parent := ctx.Context
+	if parent == nil {
+		parent = goctx.Background()
+	}
+	execCtx, cancel := goctx.WithTimeout(parent, timeout)
+	defer cancel()
+
+	exitCode, err := ep.prober.ExecVirtualMachineGuestCommand(execCtx, ctx.VM, p.Exec)
+	if err != nil {
+		return Unknown, err
+	}
+
+	if exitCode != 0 {
+		return Failure, fmt.Errorf("command exited with status code %d", exitCode)
+	}
+
+	return Success, nil
+}
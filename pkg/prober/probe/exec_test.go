@@ -0,0 +1,117 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package probe
+
+import (
+	goctx "context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+)
+
+var _ = Describe("Exec probe", func() {
+	var (
+		vm            *vmopv1alpha1.VirtualMachine
+		fakeProvider  *fake.VMProvider
+		testExecProbe Probe
+
+		err error
+		res Result
+	)
+
+	BeforeEach(func() {
+		vm = &vmopv1alpha1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: "dummy-ns",
+			},
+			Spec: vmopv1alpha1.VirtualMachineSpec{
+				ClassName:      "dummy-vmclass",
+				ReadinessProbe: getVirtualMachineReadinessExecProbe(),
+			},
+		}
+
+		fakeProvider = fake.NewVMProvider()
+		testExecProbe = NewExecProber(fakeProvider)
+	})
+
+	JustBeforeEach(func() {
+		probeCtx := &context.ProbeContext{
+			Logger:    ctrl.Log.WithName("Probe").WithValues("name", vm.NamespacedName()),
+			ProbeSpec: vm.Spec.ReadinessProbe,
+			VM:        vm,
+		}
+
+		res, err = testExecProbe.Probe(probeCtx)
+	})
+
+	Context("Provider returns an error", func() {
+		BeforeEach(func() {
+			fakeProvider.ExecVirtualMachineGuestCommandFn = func(_ goctx.Context, _ *vmopv1alpha1.VirtualMachine, _ *vmopv1alpha1.ExecAction) (int32, error) {
+				return 0, fmt.Errorf("fake error")
+			}
+		})
+
+		It("returns error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(Equal(Unknown))
+		})
+	})
+
+	Context("Command exits with zero", func() {
+		var (
+			action   *vmopv1alpha1.ExecAction
+			deadline time.Time
+		)
+
+		BeforeEach(func() {
+			vm.Spec.ReadinessProbe.TimeoutSeconds = 5
+			fakeProvider.ExecVirtualMachineGuestCommandFn = func(ctx goctx.Context, _ *vmopv1alpha1.VirtualMachine, a *vmopv1alpha1.ExecAction) (int32, error) {
+				action = a
+				deadline, _ = ctx.Deadline()
+				return 0, nil
+			}
+		})
+
+		It("returns success", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(Success))
+			Expect(action).To(Equal(vm.Spec.ReadinessProbe.Exec))
+			Expect(deadline).To(BeTemporally("~", time.Now().Add(5*time.Second), time.Second))
+		})
+	})
+
+	Context("Command exits with non-zero", func() {
+		BeforeEach(func() {
+			fakeProvider.ExecVirtualMachineGuestCommandFn = func(_ goctx.Context, _ *vmopv1alpha1.VirtualMachine, _ *vmopv1alpha1.ExecAction) (int32, error) {
+				return 3, nil
+			}
+		})
+
+		It("returns failure", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("status code 3"))
+			Expect(res).To(Equal(Failure))
+		})
+	})
+})
+
+func getVirtualMachineReadinessExecProbe() *vmopv1alpha1.Probe {
+	return &vmopv1alpha1.Probe{
+		Exec: &vmopv1alpha1.ExecAction{
+			Command:    []string{"/usr/bin/systemctl", "is-active", "nginx"},
+			SecretName: "guest-creds",
+		},
+	}
+}
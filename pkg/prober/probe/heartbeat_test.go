@@ -27,6 +27,10 @@ func (tp fakeVMProviderProber) GetVirtualMachineGuestHeartbeat(_ goctx.Context,
 	return tp.status, tp.err
 }
 
+func (tp fakeVMProviderProber) ExecVirtualMachineGuestCommand(_ goctx.Context, _ *vmopv1alpha1.VirtualMachine, _ *vmopv1alpha1.ExecAction) (int32, error) {
+	return 0, tp.err
+}
+
 var _ = Describe("Guest heartbeat probe", func() {
 	var (
 		vm                   *vmopv1alpha1.VirtualMachine
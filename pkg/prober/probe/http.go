@@ -0,0 +1,162 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package probe
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
+)
+
+const (
+	httpUserAgent = "vm-operator-probe"
+
+	// maxHTTPBodyLength is the maximum number of response body bytes that are read.
+	maxHTTPBodyLength = 10 * 1024
+)
+
+// defaultExpectedStatuses are the HTTP response status codes considered successful when none are specified,
+// which is the same as Kubernetes HTTP probes.
+var defaultExpectedStatuses = []vmopv1alpha1.HTTPStatusRange{
+	{Min: http.StatusOK, Max: http.StatusBadRequest - 1},
+}
+
+// httpGetProber implements the Probe interface.
+type httpGetProber struct {
+	transport *http.Transport
+}
+
+// NewHTTPGetProber creates a new HTTP GET prober which implements the Probe interface to execute HTTP GET probes.
+func NewHTTPGetProber() Probe {
+	return &httpGetProber{
+		transport: &http.Transport{
+			// Like Kubernetes, the certificate of the server is not verified since the VM is unlikely to have
+			// a certificate issued for its IP.
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+			DisableKeepAlives: true,
+			Proxy:             http.ProxyURL(nil),
+		},
+	}
+}
+
+func (pr httpGetProber) Probe(ctx *context.ProbeContext) (Result, error) {
+	vm := ctx.VM
+	p := ctx.ProbeSpec
+	action := p.HTTPGet
+
+	portNum, err := findPort(vm, action.Port, corev1.ProtocolTCP)
+	if err != nil {
+		return Failure, err
+	}
+
+	// The request is always sent to the VM so that a probe cannot be used to reach other hosts.
+	host := vm.Status.VmIp
+	if host == "" {
+		return Failure, fmt.Errorf("VM %s doesn't have an IP assigned", vm.NamespacedName())
+	}
+
+	var timeout time.Duration
+	if p.TimeoutSeconds <= 0 {
+		timeout = defaultConnectTimeout
+	} else {
+		timeout = time.Duration(p.TimeoutSeconds) * time.Second
+	}
+
+	req, err := newHTTPGetRequest(action, host, portNum)
+	if err != nil {
+		return Failure, err
+	}
+
+	client := &http.Client{
+		Transport: pr.transport,
+		Timeout:   timeout,
+		// Do not follow redirects so that the status code of the probed server is checked.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Failure, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxHTTPBodyLength))
+
+	if !isExpectedStatus(resp.StatusCode, action.ExpectedStatuses) {
+		return Failure, fmt.Errorf("HTTP probe failed with statuscode: %d", resp.StatusCode)
+	}
+
+	return Success, nil
+}
+
+func newHTTPGetRequest(
+	action *vmopv1alpha1.HTTPGetAction,
+	host string,
+	port int) (*http.Request, error) {
+
+	scheme := strings.ToLower(string(action.Scheme))
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	path := action.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	u, err := url.Parse(fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(port)), path))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Accept", "*/*")
+	for _, header := range action.HTTPHeaders {
+		if strings.EqualFold(header.Name, "Host") {
+			req.Host = header.Value
+			continue
+		}
+		req.Header.Add(header.Name, header.Value)
+	}
+
+	return req, nil
+}
+
+// isExpectedStatus returns true if the status code is within any of the expected ranges.
+func isExpectedStatus(statusCode int, expectedStatuses []vmopv1alpha1.HTTPStatusRange) bool {
+	if len(expectedStatuses) == 0 {
+		expectedStatuses = defaultExpectedStatuses
+	}
+
+	for _, r := range expectedStatuses {
+		max := r.Max
+		if max == 0 {
+			max = r.Min
+		}
+		if statusCode >= int(r.Min) && statusCode <= int(max) {
+			return true
+		}
+	}
+
+	return false
+}
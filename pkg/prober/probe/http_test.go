@@ -0,0 +1,235 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package probe
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
+)
+
+var _ = Describe("HTTPGet probe", func() {
+	var (
+		vm               *vmopv1alpha1.VirtualMachine
+		testHTTPGetProbe Probe
+
+		handler    http.HandlerFunc
+		lastReq    *http.Request
+		testServer *httptest.Server
+		testHost   string
+		testPort   int
+		useTLS     bool
+
+		probeCtx *context.ProbeContext
+	)
+
+	BeforeEach(func() {
+		vm = &vmopv1alpha1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: "dummy-ns",
+			},
+			Spec: vmopv1alpha1.VirtualMachineSpec{
+				ClassName: "dummy-vmclass",
+			},
+		}
+
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}
+		lastReq = nil
+		useTLS = false
+		testHTTPGetProbe = NewHTTPGetProber()
+	})
+
+	JustBeforeEach(func() {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lastReq = r
+			handler(w, r)
+		})
+		if useTLS {
+			testServer = httptest.NewTLSServer(h)
+		} else {
+			testServer = httptest.NewServer(h)
+		}
+
+		host, port, err := net.SplitHostPort(testServer.Listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		testHost = host
+		testPort, err = strconv.Atoi(port)
+		Expect(err).NotTo(HaveOccurred())
+
+		if vm.Status.VmIp == "" {
+			vm.Status.VmIp = testHost
+		}
+		if vm.Spec.ReadinessProbe == nil {
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPGetProbe(testPort)
+		}
+		if vm.Spec.ReadinessProbe.HTTPGet.Port.IntValue() == 0 && vm.Spec.ReadinessProbe.HTTPGet.Port.Type == intstr.Int {
+			vm.Spec.ReadinessProbe.HTTPGet.Port = intstr.FromInt(testPort)
+		}
+
+		probeCtx = &context.ProbeContext{
+			VM:        vm,
+			ProbeSpec: vm.Spec.ReadinessProbe,
+			Logger:    ctrl.Log.WithName("Probe").WithValues("name", vm.NamespacedName()),
+		}
+	})
+
+	AfterEach(func() {
+		testServer.Close()
+		vm.Spec.ReadinessProbe = nil
+	})
+
+	It("HTTPGet probe succeeds using the VM IP", func() {
+		res, err := testHTTPGetProbe.Probe(probeCtx)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(res).To(Equal(Success))
+
+		Expect(lastReq).ToNot(BeNil())
+		Expect(lastReq.URL.Path).To(Equal("/"))
+		Expect(lastReq.Header.Get("User-Agent")).To(Equal(httpUserAgent))
+	})
+
+	When("the VM does not have an IP", func() {
+		It("HTTPGet probe fails", func() {
+			vm.Status.VmIp = ""
+			res, err := testHTTPGetProbe.Probe(probeCtx)
+			Expect(err).Should(HaveOccurred())
+			Expect(res).To(Equal(Failure))
+		})
+	})
+
+	When("the port is named", func() {
+		BeforeEach(func() {
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPGetProbe(0)
+			vm.Spec.ReadinessProbe.HTTPGet.Port = intstr.FromString("http")
+		})
+
+		It("HTTPGet probe succeeds using the port from the VM spec", func() {
+			vm.Spec.Ports = []vmopv1alpha1.VirtualMachinePort{
+				{Name: "http", Port: testPort, Protocol: corev1.ProtocolTCP},
+			}
+			res, err := testHTTPGetProbe.Probe(probeCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).To(Equal(Success))
+		})
+
+		It("HTTPGet probe fails when the port is not in the VM spec", func() {
+			res, err := testHTTPGetProbe.Probe(probeCtx)
+			Expect(err).Should(HaveOccurred())
+			Expect(res).To(Equal(Failure))
+		})
+	})
+
+	When("the path and headers are set", func() {
+		BeforeEach(func() {
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPGetProbe(0)
+			vm.Spec.ReadinessProbe.HTTPGet.Path = "healthz?verbose=1"
+			vm.Spec.ReadinessProbe.HTTPGet.HTTPHeaders = []vmopv1alpha1.HTTPHeader{
+				{Name: "X-Probe", Value: "ready"},
+				{Name: "Host", Value: "my-vm.local"},
+			}
+		})
+
+		It("HTTPGet probe sends them", func() {
+			res, err := testHTTPGetProbe.Probe(probeCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).To(Equal(Success))
+
+			Expect(lastReq).ToNot(BeNil())
+			Expect(lastReq.URL.Path).To(Equal("/healthz"))
+			Expect(lastReq.URL.RawQuery).To(Equal("verbose=1"))
+			Expect(lastReq.Header.Get("X-Probe")).To(Equal("ready"))
+			Expect(lastReq.Host).To(Equal("my-vm.local"))
+		})
+	})
+
+	When("the server returns an error status", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		})
+
+		It("HTTPGet probe fails", func() {
+			res, err := testHTTPGetProbe.Probe(probeCtx)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("503"))
+			Expect(res).To(Equal(Failure))
+		})
+
+		It("HTTPGet probe succeeds when the status is expected", func() {
+			vm.Spec.ReadinessProbe.HTTPGet.ExpectedStatuses = []vmopv1alpha1.HTTPStatusRange{
+				{Min: 200},
+				{Min: 500, Max: 503},
+			}
+			res, err := testHTTPGetProbe.Probe(probeCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).To(Equal(Success))
+		})
+	})
+
+	When("the server returns a redirect", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/redirected" {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				http.Redirect(w, r, "/redirected", http.StatusFound)
+			}
+		})
+
+		It("HTTPGet probe does not follow it", func() {
+			res, err := testHTTPGetProbe.Probe(probeCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).To(Equal(Success))
+			Expect(lastReq.URL.Path).To(Equal("/"))
+		})
+	})
+
+	When("the scheme is HTTPS", func() {
+		BeforeEach(func() {
+			useTLS = true
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPGetProbe(0)
+			vm.Spec.ReadinessProbe.HTTPGet.Scheme = vmopv1alpha1.URISchemeHTTPS
+		})
+
+		It("HTTPGet probe succeeds without verifying the certificate", func() {
+			res, err := testHTTPGetProbe.Probe(probeCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).To(Equal(Success))
+		})
+	})
+
+	It("HTTPGet probe fails when the server is not listening", func() {
+		vm.Spec.ReadinessProbe.HTTPGet.Port = intstr.FromInt(10001)
+		res, err := testHTTPGetProbe.Probe(probeCtx)
+		Expect(err).Should(HaveOccurred())
+		Expect(res).To(Equal(Failure))
+	})
+})
+
+func getVirtualMachineReadinessHTTPGetProbe(port int) *vmopv1alpha1.Probe {
+	return &vmopv1alpha1.Probe{
+		HTTPGet: &vmopv1alpha1.HTTPGetAction{
+			Port: intstr.FromInt(port),
+		},
+		PeriodSeconds: 1,
+	}
+}
@@ -25,6 +25,18 @@ const (
 	defaultConnectTimeout = 10 * time.Second
 )
 
+// String returns the lowercase name of the result.
+func (r Result) String() string {
+	switch r {
+	case Failure:
+		return "failure"
+	case Success:
+		return "success"
+	default:
+		return "unknown"
+	}
+}
+
 // Probe is the interface to execute VM probes.
 type Probe interface {
 	Probe(ctx *context.ProbeContext) (Result, error)
@@ -33,12 +45,15 @@ type Probe interface {
 // Probing related provider methods.
 type vmProviderProber interface {
 	GetVirtualMachineGuestHeartbeat(ctx goctx.Context, vm *vmopv1alpha1.VirtualMachine) (vmopv1alpha1.GuestHeartbeatStatus, error)
+	ExecVirtualMachineGuestCommand(ctx goctx.Context, vm *vmopv1alpha1.VirtualMachine, action *vmopv1alpha1.ExecAction) (int32, error)
 }
 
 // Prober contains the different type of probes.
 type Prober struct {
 	TCPProbe       Probe
 	GuestHeartbeat Probe
+	HTTPGetProbe   Probe
+	ExecProbe      Probe
 }
 
 // NewProber creates a new Prober.
@@ -46,5 +61,7 @@ func NewProber(vmProviderProber vmProviderProber) *Prober {
 	return &Prober{
 		TCPProbe:       NewTCPProber(),
 		GuestHeartbeat: NewGuestHeartbeatProber(vmProviderProber),
+		HTTPGetProbe:   NewHTTPGetProber(),
+		ExecProbe:      NewExecProber(vmProviderProber),
 	}
 }
@@ -20,6 +20,7 @@ import (
 
 	vmoperatorv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
 	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/prober/probe"
 	"github.com/vmware-tanzu/vm-operator/pkg/prober/worker"
@@ -30,14 +31,18 @@ import (
 const (
 	proberManagerName       = "virtualmachine-prober-manager"
 	readinessProbeQueueName = "readinessProbeQueue"
+	livenessProbeQueueName  = "livenessProbeQueue"
+	startupProbeQueueName   = "startupProbeQueue"
 
 	// defaultPeriodSeconds represents the default value for the frequency (in seconds) to perform the probe.
 	// We use the same default value as the kubernetes container probe.
 	defaultPeriodSeconds = 10
 
-	// the number of readiness workers.
-	// TODO: find a way to calibrate it.
-	numberOfReadinessWorkers = 5
+	// minWorkersPerQueue is the minimum number of workers processing each probe queue.
+	minWorkersPerQueue = 5
+
+	// DefaultMaxWorkersPerQueue is the default maximum number of workers processing each probe queue.
+	DefaultMaxWorkersPerQueue = 50
 )
 
 // Manager represents a prober manager interface.
@@ -51,38 +56,77 @@ type Manager interface {
 type manager struct {
 	client         client.Client
 	readinessQueue workqueue.DelayingInterface
+	livenessQueue  workqueue.DelayingInterface
+	startupQueue   workqueue.DelayingInterface
 	prober         *probe.Prober
+	vmProvider     vmprovider.VirtualMachineProviderInterface
 	log            logr.Logger
 	recorder       vmoprecord.Recorder
 
-	workersWG sync.WaitGroup
+	// maxWorkers is the maximum number of workers processing each probe queue. The number of workers
+	// is scaled between minWorkersPerQueue and maxWorkers with the depth of the queue.
+	maxWorkers int
 
 	// We will use AddAfter to add an item to the queue, which will insert the item to a heap first
 	// if the time duration set in the AddAfter is not zero. vmReadinessProbeList can be used to avoid
 	// adding VMs to the readiness queue when this VM is already in the heap but not in the queue.
 	readinessMutex       sync.Mutex
 	vmReadinessProbeList map[string]*vmoperatorv1alpha1.Probe
+	// readinessResults tracks the consecutive readiness probe results of each VM across the readiness workers.
+	readinessResults *worker.ProbeResults
+
+	livenessMutex       sync.Mutex
+	vmLivenessProbeList map[string]*vmoperatorv1alpha1.Probe
+	// livenessResults tracks the consecutive liveness probe results of each VM across the liveness workers.
+	livenessResults *worker.ProbeResults
+
+	startupMutex       sync.Mutex
+	vmStartupProbeList map[string]*vmoperatorv1alpha1.Probe
+	// startupResults tracks the consecutive startup probe results of each VM across the startup workers.
+	startupResults *worker.ProbeResults
 }
 
-// NewManger initializes a prober manager.
-func NewManger(client client.Client, record vmoprecord.Recorder, vmProvider vmprovider.VirtualMachineProviderInterface) Manager {
+// NewManger initializes a prober manager. The default maximum number of workers is used when maxWorkers is zero.
+func NewManger(
+	client client.Client,
+	record vmoprecord.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterface,
+	maxWorkers int) Manager {
+
+	if maxWorkers <= 0 {
+		maxWorkers = DefaultMaxWorkersPerQueue
+	}
+
 	probeManager := &manager{
 		client:               client,
 		readinessQueue:       workqueue.NewNamedDelayingQueue(readinessProbeQueueName),
+		livenessQueue:        workqueue.NewNamedDelayingQueue(livenessProbeQueueName),
+		startupQueue:         workqueue.NewNamedDelayingQueue(startupProbeQueueName),
 		prober:               probe.NewProber(vmProvider),
+		vmProvider:           vmProvider,
 		log:                  ctrl.Log.WithName(proberManagerName),
 		recorder:             record,
+		maxWorkers:           maxWorkers,
 		vmReadinessProbeList: make(map[string]*vmoperatorv1alpha1.Probe),
+		vmLivenessProbeList:  make(map[string]*vmoperatorv1alpha1.Probe),
+		vmStartupProbeList:   make(map[string]*vmoperatorv1alpha1.Probe),
+		readinessResults:     worker.NewProbeResults(),
+		livenessResults:      worker.NewProbeResults(),
+		startupResults:       worker.NewProbeResults(),
 	}
 	return probeManager
 }
 
 // AddToManager adds the probe manager controller manager.
-func AddToManager(mgr ctrlmgr.Manager, vmProvider vmprovider.VirtualMachineProviderInterface) (Manager, error) {
+func AddToManager(
+	mgr ctrlmgr.Manager,
+	vmProvider vmprovider.VirtualMachineProviderInterface,
+	maxWorkers int) (Manager, error) {
+
 	probeRecorder := vmoprecord.New(mgr.GetEventRecorderFor(proberManagerName))
 
 	// Add the probe manager explicitly as runnable in order to receive a Start() event.
-	m := NewManger(mgr.GetClient(), probeRecorder, vmProvider)
+	m := NewManger(mgr.GetClient(), probeRecorder, vmProvider, maxWorkers)
 	if err := mgr.Add(m); err != nil {
 		return nil, err
 	}
@@ -96,22 +140,44 @@ func (m *manager) AddToProberManager(vm *vmoperatorv1alpha1.VirtualMachine) {
 	m.log.V(4).Info("Add to prober manager", "vm", vmName)
 
 	m.readinessMutex.Lock()
-	defer m.readinessMutex.Unlock()
-
-	if vm.Spec.ReadinessProbe != nil {
-		// if the VM is not in the list, or its readiness probe spec has been updated, immediately add it to the queue
-		// otherwise, ignore it.
-		newProbe := vm.Spec.ReadinessProbe
-		if oldProbe, ok := m.vmReadinessProbeList[vmName]; ok && reflect.DeepEqual(oldProbe, newProbe) {
-			m.log.V(4).Info("VM is already in the readiness probe list and its probe spec is not updated, skip it", "vm", vmName)
-			return
-		}
+	m.addToProbeList(m.readinessQueue, m.vmReadinessProbeList, vm, vm.Spec.ReadinessProbe, "readiness")
+	m.readinessMutex.Unlock()
 
-		m.readinessQueue.Add(client.ObjectKey{Name: vm.Name, Namespace: vm.Namespace})
-		m.vmReadinessProbeList[vmName] = newProbe
-	} else {
-		delete(m.vmReadinessProbeList, vmName)
+	m.livenessMutex.Lock()
+	m.addToProbeList(m.livenessQueue, m.vmLivenessProbeList, vm, vm.Spec.LivenessProbe, "liveness")
+	m.livenessMutex.Unlock()
+
+	m.startupMutex.Lock()
+	m.addToProbeList(m.startupQueue, m.vmStartupProbeList, vm, vm.Spec.StartupProbe, "startup")
+	m.startupMutex.Unlock()
+}
+
+// addToProbeList adds the VM to the probe list and queue if it has the probe. The caller must hold the
+// mutex of the probe list.
+func (m *manager) addToProbeList(
+	queue workqueue.DelayingInterface,
+	probeList map[string]*vmoperatorv1alpha1.Probe,
+	vm *vmoperatorv1alpha1.VirtualMachine,
+	newProbe *vmoperatorv1alpha1.Probe,
+	probeType string) {
+
+	vmName := vm.NamespacedName()
+
+	if newProbe == nil {
+		delete(probeList, vmName)
+		return
+	}
+
+	// if the VM is not in the list, or its probe spec has been updated, immediately add it to the queue
+	// otherwise, ignore it.
+	if oldProbe, ok := probeList[vmName]; ok && reflect.DeepEqual(oldProbe, newProbe) {
+		m.log.V(4).Info("VM is already in the probe list and its probe spec is not updated, skip it",
+			"vm", vmName, "probeType", probeType)
+		return
 	}
+
+	queue.Add(client.ObjectKey{Name: vm.Name, Namespace: vm.Namespace})
+	probeList[vmName] = newProbe
 }
 
 // RemoveFromProberManager removes a VM from the prober manager.
@@ -120,8 +186,19 @@ func (m *manager) RemoveFromProberManager(vm *vmoperatorv1alpha1.VirtualMachine)
 	m.log.V(4).Info("Remove from prober manager", "vm", vmName)
 
 	m.readinessMutex.Lock()
-	defer m.readinessMutex.Unlock()
 	delete(m.vmReadinessProbeList, vmName)
+	m.readinessMutex.Unlock()
+	m.readinessResults.Remove(vmName)
+
+	m.livenessMutex.Lock()
+	delete(m.vmLivenessProbeList, vmName)
+	m.livenessMutex.Unlock()
+	m.livenessResults.Remove(vmName)
+
+	m.startupMutex.Lock()
+	delete(m.vmStartupProbeList, vmName)
+	m.startupMutex.Unlock()
+	m.startupResults.Remove(vmName)
 }
 
 // Start starts the probe manager.
@@ -129,31 +206,56 @@ func (m *manager) Start(ctx goctx.Context) error {
 	m.log.Info("Start VirtualMachine Probe Manager")
 	defer m.log.Info("Stop VirtualMachine Probe Manager")
 
-	m.log.Info("Starting readiness workers", "count", numberOfReadinessWorkers)
-	m.workersWG.Add(numberOfReadinessWorkers)
-	for i := 0; i < numberOfReadinessWorkers; i++ {
-		readinessWorker := worker.NewReadinessWorker(m.readinessQueue, m.prober, m.client, m.recorder)
-		m.worker(readinessWorker)
+	pools := []*workerPool{
+		m.newWorkerPool(readinessProbeQueueName, m.readinessQueue, func() worker.Worker {
+			return worker.NewReadinessWorker(m.readinessQueue, m.prober, m.client, m.recorder, m.readinessResults)
+		}),
+		m.newWorkerPool(livenessProbeQueueName, m.livenessQueue, func() worker.Worker {
+			return worker.NewLivenessWorker(m.livenessQueue, m.prober, m.client, m.recorder,
+				m.livenessResults, m.vmProvider)
+		}),
+		m.newWorkerPool(startupProbeQueueName, m.startupQueue, func() worker.Worker {
+			return worker.NewStartupWorker(m.startupQueue, m.prober, m.client, m.recorder,
+				m.startupResults, m.vmProvider)
+		}),
+	}
+
+	for _, pool := range pools {
+		pool.start(ctx)
 	}
 
 	<-ctx.Done()
 
 	m.readinessQueue.ShutDown()
-	m.workersWG.Wait()
+	m.livenessQueue.ShutDown()
+	m.startupQueue.ShutDown()
+	for _, pool := range pools {
+		pool.wait()
+	}
 	return nil
 }
 
-// worker starts the probe manager workers, which are used to process items from queues.
-func (m *manager) worker(in worker.Worker) {
-	go func() {
-		defer m.workersWG.Done()
+// newWorkerPool creates a worker pool that processes items from the queue.
+func (m *manager) newWorkerPool(
+	name string,
+	queue workqueue.DelayingInterface,
+	newWorker func() worker.Worker) *workerPool {
 
-		for {
-			if quit := m.processItemFromQueue(in); quit {
-				return
-			}
-		}
-	}()
+	minWorkers := minWorkersPerQueue
+	if minWorkers > m.maxWorkers {
+		minWorkers = m.maxWorkers
+	}
+
+	return &workerPool{
+		name:       name,
+		queue:      queue,
+		newWorker:  newWorker,
+		process:    m.processItemFromQueue,
+		minWorkers: minWorkers,
+		maxWorkers: m.maxWorkers,
+		log:        m.log,
+		metrics:    metrics.NewProberMetrics(),
+	}
 }
 
 // processItemFromQueue gets a VM from a probe queue and processes the VM.
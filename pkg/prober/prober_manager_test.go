@@ -71,7 +71,7 @@ var _ = Describe("VirtualMachine probes", func() {
 		eventRecorder := clientgorecord.NewFakeRecorder(1024)
 		fakeRecorder = record.New(eventRecorder)
 		fakeVMProvider := &fake.VMProvider{}
-		testManagerIf := NewManger(fakeClient, fakeRecorder, fakeVMProvider)
+		testManagerIf := NewManger(fakeClient, fakeRecorder, fakeVMProvider, 0)
 		testManager = testManagerIf.(*manager)
 		fakeWorkerIf = fakeworker.NewFakeWorker(testManager.readinessQueue)
 		fakeWorker = fakeWorkerIf.(*fakeworker.FakeWorker)
@@ -224,6 +224,54 @@ var _ = Describe("VirtualMachine probes", func() {
 			})
 		})
 
+		When("VM has a liveness probe", func() {
+			BeforeEach(func() {
+				vm.Spec.LivenessProbe = vmProbe
+			})
+
+			It("Should add to the liveness queue and list", func() {
+				testManager.AddToProberManager(vm)
+
+				Expect(testManager.livenessQueue.Len()).To(Equal(1))
+				testManager.livenessMutex.Lock()
+				Expect(testManager.vmLivenessProbeList).Should(HaveKey(vm.NamespacedName()))
+				testManager.livenessMutex.Unlock()
+			})
+
+			It("Should remove from the liveness list when the VM is removed from the manager", func() {
+				testManager.AddToProberManager(vm)
+				testManager.RemoveFromProberManager(vm)
+
+				testManager.livenessMutex.Lock()
+				Expect(testManager.vmLivenessProbeList).ShouldNot(HaveKey(vm.NamespacedName()))
+				testManager.livenessMutex.Unlock()
+			})
+		})
+
+		When("VM has a startup probe", func() {
+			BeforeEach(func() {
+				vm.Spec.StartupProbe = vmProbe
+			})
+
+			It("Should add to the startup queue and list", func() {
+				testManager.AddToProberManager(vm)
+
+				Expect(testManager.startupQueue.Len()).To(Equal(1))
+				testManager.startupMutex.Lock()
+				Expect(testManager.vmStartupProbeList).Should(HaveKey(vm.NamespacedName()))
+				testManager.startupMutex.Unlock()
+			})
+
+			It("Should remove from the startup list when the VM is removed from the manager", func() {
+				testManager.AddToProberManager(vm)
+				testManager.RemoveFromProberManager(vm)
+
+				testManager.startupMutex.Lock()
+				Expect(testManager.vmStartupProbeList).ShouldNot(HaveKey(vm.NamespacedName()))
+				testManager.startupMutex.Unlock()
+			})
+		})
+
 		When("VM has already been added to the prober manager", func() {
 			var newVM *vmopv1alpha1.VirtualMachine
 			JustBeforeEach(func() {
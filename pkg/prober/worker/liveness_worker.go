@@ -0,0 +1,171 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	goctx "context"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/pkg/errors"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/probe"
+	vmoprecord "github.com/vmware-tanzu/vm-operator/pkg/record"
+)
+
+const (
+	// livenessProbeFailedReason and restartedReason represent reasons for liveness probe events.
+	livenessProbeFailedReason string = "LivenessProbeFailed"
+	restartedReason           string = "Restarted"
+)
+
+// vmProviderRestarter is the provider method used to restart a VM whose liveness probe fails.
+type vmProviderRestarter interface {
+	RestartVirtualMachine(ctx goctx.Context, vm *vmopv1alpha1.VirtualMachine, mode vmopv1alpha1.VirtualMachinePowerOpMode) error
+}
+
+// livenessWorker implements Worker interface.
+type livenessWorker struct {
+	queue     workqueue.DelayingInterface
+	prober    *probe.Prober
+	client    client.Client
+	recorder  vmoprecord.Recorder
+	results   *ProbeResults
+	restarter vmProviderRestarter
+}
+
+// NewLivenessWorker creates a new liveness worker to run liveness probes. The VM is restarted when its
+// liveness probe fails FailureThreshold consecutive times.
+func NewLivenessWorker(
+	queue workqueue.DelayingInterface,
+	prober *probe.Prober,
+	client client.Client,
+	recorder vmoprecord.Recorder,
+	results *ProbeResults,
+	restarter vmProviderRestarter,
+) Worker {
+	return &livenessWorker{
+		queue:     queue,
+		prober:    prober,
+		client:    client,
+		recorder:  recorder,
+		results:   results,
+		restarter: restarter,
+	}
+}
+
+func (w *livenessWorker) GetQueue() workqueue.DelayingInterface {
+	return w.queue
+}
+
+// CreateProbeContext creates a probe context for liveness probe.
+func (w *livenessWorker) CreateProbeContext(vm *vmopv1alpha1.VirtualMachine) (*context.ProbeContext, error) {
+	patchHelper, err := patch.NewHelper(vm, w.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &context.ProbeContext{
+		Context:     goctx.Background(),
+		Logger:      ctrl.Log.WithName("liveness-probe").WithValues("vmName", vm.NamespacedName()),
+		PatchHelper: patchHelper,
+		VM:          vm,
+		ProbeSpec:   vm.Spec.LivenessProbe,
+		ProbeType:   "liveness",
+	}, nil
+}
+
+func (w *livenessWorker) DoProbe(ctx *context.ProbeContext) error {
+	if reason := probesSuspended(ctx.VM); reason != "" {
+		// The initial delay starts once the probe is no longer suspended.
+		w.results.Remove(ctx.VM.NamespacedName())
+		ctx.Logger.V(4).Info("Liveness probe is suspended", "reason", reason)
+		return nil
+	}
+
+	initialDelay := time.Duration(ctx.ProbeSpec.InitialDelaySeconds) * time.Second
+	if startTime := w.results.StartTime(ctx.VM.NamespacedName()); time.Since(startTime) < initialDelay {
+		ctx.Logger.V(4).Info("Liveness probe initial delay has not elapsed", "startTime", startTime)
+		return nil
+	}
+
+	res, err := runProbe(w.prober, ctx)
+	if err != nil {
+		ctx.Logger.Error(err, "liveness probe fails", "result", res)
+	}
+	return w.ProcessProbeResult(ctx, res, err)
+}
+
+// ProcessProbeResult processes liveness probe results and restarts the VM when the probe has failed
+// FailureThreshold consecutive times. A VM that is not powered on is never restarted.
+func (w *livenessWorker) ProcessProbeResult(ctx *context.ProbeContext, res probe.Result, resErr error) error {
+	vm := ctx.VM
+	vmName := vm.NamespacedName()
+
+	if vm.Status.PowerState != vmopv1alpha1.VirtualMachinePoweredOn {
+		// Reset the results so that the initial delay applies once the VM is powered on.
+		w.results.Remove(vmName)
+		return nil
+	}
+
+	count := w.results.Record(vmName, res)
+	if res != probe.Failure {
+		return nil
+	}
+
+	failureThreshold := ctx.ProbeSpec.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = defaultFailureThreshold
+	}
+
+	msg := ""
+	if resErr != nil {
+		msg = resErr.Error()
+	}
+
+	if count < failureThreshold {
+		ctx.Logger.V(4).Info("Liveness probe failed", "failures", count, "failureThreshold", failureThreshold)
+		return nil
+	}
+
+	w.recorder.Warnf(vm, livenessProbeFailedReason, "Liveness probe failed %d times, restarting VM: %s", count, msg)
+	ctx.Logger.Info("Restarting VM because its liveness probe failed",
+		"failures", count, "restartMode", vm.Spec.RestartMode)
+
+	if err := w.restarter.RestartVirtualMachine(ctx, vm, vm.Spec.RestartMode); err != nil {
+		// Do not return the error so that the VM is not immediately re-queued: the restart is retried
+		// after the next failed probe.
+		ctx.Logger.Error(err, "Failed to restart VM")
+		w.recorder.Warnf(vm, livenessProbeFailedReason, "Failed to restart VM: %v", err)
+		return nil
+	}
+
+	// Reset the results so that the initial delay applies to the restarted VM.
+	w.results.Remove(vmName)
+	w.recorder.Eventf(vm, restartedReason, "VM restarted because its liveness probe failed")
+
+	vm.Status.RestartCount++
+	if vm.Spec.StartupProbe != nil {
+		// Suspend the liveness probe until the startup probe succeeds for the restarted VM.
+		conditions.MarkFalse(vm, vmopv1alpha1.VirtualMachineStartedCondition, vmopv1alpha1.StartupProbePendingReason,
+			vmopv1alpha1.ConditionSeverityInfo, "VM restarted because its liveness probe failed")
+	}
+
+	err := ctx.PatchHelper.Patch(ctx, vm, patch.WithOwnedConditions{
+		Conditions: []vmopv1alpha1.ConditionType{vmopv1alpha1.VirtualMachineStartedCondition},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "patched failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	goctx "context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgorecord "k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
+	fakeprobe "github.com/vmware-tanzu/vm-operator/pkg/prober/fake/probe"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/probe"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+var _ = Describe("VirtualMachine liveness probes", func() {
+	var (
+		testWorker Worker
+
+		vm    *vmopv1alpha1.VirtualMachine
+		vmKey client.ObjectKey
+
+		fakeClient     client.Client
+		fakeRecorder   record.Recorder
+		fakeEvents     chan string
+		fakeTCPProbe   *fakeprobe.FakeProbe
+		fakeVMProvider *fake.VMProvider
+		results        *ProbeResults
+
+		restartCalls int
+		restartMode  vmopv1alpha1.VirtualMachinePowerOpMode
+		restartErr   error
+	)
+
+	BeforeEach(func() {
+		vm = &vmopv1alpha1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: "dummy-ns",
+			},
+			Spec: vmopv1alpha1.VirtualMachineSpec{
+				ClassName:     "dummy-vmclass",
+				RestartMode:   vmopv1alpha1.VirtualMachinePowerOpModeHard,
+				LivenessProbe: getVirtualMachineLivenessTCPProbe(10001),
+			},
+			Status: vmopv1alpha1.VirtualMachineStatus{
+				PowerState: vmopv1alpha1.VirtualMachinePoweredOn,
+			},
+		}
+		vmKey = client.ObjectKey{Name: vm.Name, Namespace: vm.Namespace}
+
+		restartCalls = 0
+		restartMode = ""
+		restartErr = nil
+	})
+
+	JustBeforeEach(func() {
+		fakeClient = builder.NewFakeClient(vm)
+		eventRecorder := clientgorecord.NewFakeRecorder(1024)
+		fakeRecorder = record.New(eventRecorder)
+		fakeEvents = eventRecorder.Events
+
+		fakeVMProvider = fake.NewVMProvider()
+		fakeVMProvider.RestartVirtualMachineFn = func(_ goctx.Context, _ *vmopv1alpha1.VirtualMachine, mode vmopv1alpha1.VirtualMachinePowerOpMode) error {
+			restartCalls++
+			restartMode = mode
+			return restartErr
+		}
+
+		queue := workqueue.NewNamedDelayingQueue("test")
+		fakeTCPProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
+		fakeTCPProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+			return probe.Failure, fmt.Errorf("connection refused")
+		}
+		prober := &probe.Prober{
+			TCPProbe: fakeTCPProbe,
+		}
+		results = NewProbeResults()
+		testWorker = NewLivenessWorker(queue, prober, fakeClient, fakeRecorder, results, fakeVMProvider)
+	})
+
+	doProbe := func() {
+		Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+		ctx, err := testWorker.CreateProbeContext(vm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ctx.ProbeSpec).To(Equal(vm.Spec.LivenessProbe))
+		Expect(testWorker.DoProbe(ctx)).To(Succeed())
+	}
+
+	It("Should restart the VM after FailureThreshold consecutive failures", func() {
+		for i := 0; i < defaultFailureThreshold-1; i++ {
+			doProbe()
+		}
+		Expect(restartCalls).To(BeZero())
+
+		doProbe()
+		Expect(restartCalls).To(Equal(1))
+		Expect(restartMode).To(Equal(vmopv1alpha1.VirtualMachinePowerOpModeHard))
+		Expect(fakeEvents).To(Receive(ContainSubstring(livenessProbeFailedReason)))
+		Expect(fakeEvents).To(Receive(ContainSubstring(restartedReason)))
+
+		Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+		Expect(vm.Status.RestartCount).To(BeEquivalentTo(1))
+
+		By("Failures are counted again after the restart", func() {
+			doProbe()
+			Expect(restartCalls).To(Equal(1))
+		})
+	})
+
+	When("the probe succeeds between failures", func() {
+		BeforeEach(func() {
+			vm.Spec.LivenessProbe.FailureThreshold = 2
+		})
+
+		It("Should not restart the VM", func() {
+			doProbe()
+			fakeTCPProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				return probe.Success, nil
+			}
+			doProbe()
+			fakeTCPProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				return probe.Failure, nil
+			}
+			doProbe()
+			Expect(restartCalls).To(BeZero())
+
+			doProbe()
+			Expect(restartCalls).To(Equal(1))
+		})
+	})
+
+	When("the initial delay has not elapsed", func() {
+		BeforeEach(func() {
+			vm.Spec.LivenessProbe.FailureThreshold = 1
+			vm.Spec.LivenessProbe.InitialDelaySeconds = 600
+		})
+
+		It("Should not run the probe", func() {
+			probed := false
+			fakeTCPProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				probed = true
+				return probe.Failure, nil
+			}
+
+			doProbe()
+			Expect(probed).To(BeFalse())
+			Expect(restartCalls).To(BeZero())
+		})
+	})
+
+	When("the startup probe has not succeeded", func() {
+		BeforeEach(func() {
+			vm.Spec.LivenessProbe.FailureThreshold = 1
+			vm.Spec.StartupProbe = getVirtualMachineLivenessTCPProbe(10001)
+		})
+
+		It("Should not run the probe", func() {
+			probed := false
+			fakeTCPProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				probed = true
+				return probe.Failure, nil
+			}
+
+			doProbe()
+			Expect(probed).To(BeFalse())
+			Expect(restartCalls).To(BeZero())
+		})
+	})
+
+	When("the VM has a startup probe that succeeded", func() {
+		BeforeEach(func() {
+			vm.Spec.LivenessProbe.FailureThreshold = 1
+			vm.Spec.StartupProbe = getVirtualMachineLivenessTCPProbe(10001)
+			conditions.MarkTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)
+		})
+
+		It("Should suspend the liveness probe after restarting the VM", func() {
+			doProbe()
+			Expect(restartCalls).To(Equal(1))
+
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+			Expect(conditions.IsFalse(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(BeTrue())
+
+			doProbe()
+			Expect(restartCalls).To(Equal(1))
+		})
+	})
+
+	When("the VM is not powered on", func() {
+		BeforeEach(func() {
+			vm.Spec.LivenessProbe.FailureThreshold = 1
+			vm.Status.PowerState = vmopv1alpha1.VirtualMachinePoweredOff
+		})
+
+		It("Should not restart the VM", func() {
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+			ctx, err := testWorker.CreateProbeContext(vm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testWorker.ProcessProbeResult(ctx, probe.Failure, fmt.Errorf("not powered on"))).To(Succeed())
+			Expect(restartCalls).To(BeZero())
+		})
+	})
+
+	When("the restart fails", func() {
+		BeforeEach(func() {
+			vm.Spec.LivenessProbe.FailureThreshold = 1
+			restartErr = fmt.Errorf("restart error")
+		})
+
+		It("Should retry the restart after the next failure", func() {
+			doProbe()
+			Expect(restartCalls).To(Equal(1))
+			Expect(fakeEvents).To(Receive(ContainSubstring(livenessProbeFailedReason)))
+			Expect(fakeEvents).To(Receive(ContainSubstring("restart error")))
+
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+			Expect(vm.Status.RestartCount).To(BeZero())
+
+			restartErr = nil
+			doProbe()
+			Expect(restartCalls).To(Equal(2))
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+			Expect(vm.Status.RestartCount).To(BeEquivalentTo(1))
+		})
+	})
+})
+
+func getVirtualMachineLivenessTCPProbe(port int) *vmopv1alpha1.Probe {
+	return &vmopv1alpha1.Probe{
+		TCPSocket: &vmopv1alpha1.TCPSocketAction{
+			Port: intstr.FromInt(port),
+		},
+		PeriodSeconds: 1,
+	}
+}
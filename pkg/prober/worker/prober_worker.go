@@ -4,14 +4,28 @@
 package worker
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/util/workqueue"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/prober/probe"
 )
 
+const (
+	// defaultSuccessThreshold and defaultFailureThreshold are the default number of consecutive successes
+	// and failures for a probe to be considered successful or failed. We use the same default values as the
+	// kubernetes container probe.
+	defaultSuccessThreshold = 1
+	defaultFailureThreshold = 3
+
+	// defaultStartupFailureThreshold is the default number of consecutive failures for a startup probe to be
+	// considered failed. It is larger than defaultFailureThreshold since VMs may take minutes to boot and customize.
+	defaultStartupFailureThreshold = 30
+)
+
 // Worker represents a prober worker interface.
 type Worker interface {
 	GetQueue() workqueue.DelayingInterface
@@ -19,3 +33,23 @@ type Worker interface {
 	DoProbe(ctx *context.ProbeContext) error
 	ProcessProbeResult(ctx *context.ProbeContext, res probe.Result, resErr error) error
 }
+
+// guestCustomizationPending returns true if guest customization of the VM has not succeeded. VMs that do not report
+// the status of guest customization are not considered pending.
+func guestCustomizationPending(vm *vmopv1alpha1.VirtualMachine) bool {
+	c := conditions.Get(vm, vmopv1alpha1.GuestCustomizationCondition)
+	return c != nil && c.Status == corev1.ConditionFalse
+}
+
+// probesSuspended returns the reason the readiness and liveness probes of the VM are suspended, or an empty
+// string if the probes can be run. The probes are suspended until guest customization and the startup probe,
+// if any, have succeeded.
+func probesSuspended(vm *vmopv1alpha1.VirtualMachine) string {
+	if guestCustomizationPending(vm) {
+		return "guest customization has not succeeded"
+	}
+	if vm.Spec.StartupProbe != nil && !conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition) {
+		return "startup probe has not succeeded"
+	}
+	return ""
+}
@@ -6,6 +6,7 @@ package worker
 import (
 	goctx "context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/util/workqueue"
@@ -17,6 +18,7 @@ import (
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
 	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/prober/probe"
@@ -36,6 +38,7 @@ type readinessWorker struct {
 	prober   *probe.Prober
 	client   client.Client
 	recorder vmoprecord.Recorder
+	results  *ProbeResults
 }
 
 // NewReadinessWorker creates a new readiness worker to run readiness probes.
@@ -44,12 +47,14 @@ func NewReadinessWorker(
 	prober *probe.Prober,
 	client client.Client,
 	recorder vmoprecord.Recorder,
+	results *ProbeResults,
 ) Worker {
 	return &readinessWorker{
 		queue:    queue,
 		prober:   prober,
 		client:   client,
 		recorder: recorder,
+		results:  results,
 	}
 }
 
@@ -76,9 +81,24 @@ func (w *readinessWorker) CreateProbeContext(vm *vmopv1alpha1.VirtualMachine) (*
 
 // ProcessProbeResult processes probe results to get ReadyCondition and
 // sets the ReadyCondition in vm status if the new condition status is a transition.
+// A ready VM becomes not ready after FailureThreshold consecutive failures, and a VM
+// that is not ready becomes ready after SuccessThreshold consecutive successes.
 func (w *readinessWorker) ProcessProbeResult(ctx *context.ProbeContext, res probe.Result, resErr error) error {
 	vm := ctx.VM
-	condition := w.getCondition(res, resErr)
+
+	if vm.Status.PowerState != vmopv1alpha1.VirtualMachinePoweredOn {
+		// Reset the results so that the initial delay applies once the VM is powered on.
+		w.results.Remove(vm.NamespacedName())
+	} else if !w.thresholdReached(ctx, res) {
+		return nil
+	}
+
+	return w.updateCondition(ctx, w.getCondition(res, resErr))
+}
+
+// updateCondition sets the ReadyCondition in vm status and patches the VM.
+func (w *readinessWorker) updateCondition(ctx *context.ProbeContext, condition *vmopv1alpha1.Condition) error {
+	vm := ctx.VM
 
 	// We only send event when either the condition type is added or its status changes, not
 	// if either its reason, severity, or message changes.
@@ -106,33 +126,93 @@ func (w *readinessWorker) ProcessProbeResult(ctx *context.ProbeContext, res prob
 	return nil
 }
 
+// thresholdReached records the probe result and returns true if the ReadyCondition should be
+// updated with it.
+func (w *readinessWorker) thresholdReached(ctx *context.ProbeContext, res probe.Result) bool {
+	// Any result other than success counts towards the failure threshold.
+	if res != probe.Success {
+		res = probe.Failure
+	}
+	count := w.results.Record(ctx.VM.NamespacedName(), res)
+
+	isReady := conditions.IsTrue(ctx.VM, vmopv1alpha1.ReadyCondition)
+
+	var threshold int32
+	switch {
+	case res == probe.Success && !isReady:
+		if threshold = ctx.ProbeSpec.SuccessThreshold; threshold <= 0 {
+			threshold = defaultSuccessThreshold
+		}
+	case res == probe.Failure && isReady:
+		if threshold = ctx.ProbeSpec.FailureThreshold; threshold <= 0 {
+			threshold = defaultFailureThreshold
+		}
+	default:
+		// The result does not change the readiness of the VM.
+		return true
+	}
+
+	if count < threshold {
+		ctx.Logger.V(4).Info("Readiness probe threshold not reached",
+			"result", res, "count", count, "threshold", threshold)
+		return false
+	}
+
+	return true
+}
+
 func (w *readinessWorker) DoProbe(ctx *context.ProbeContext) error {
-	res, err := w.runProbe(ctx)
+	if reason := probesSuspended(ctx.VM); reason != "" {
+		// The initial delay starts once the probe is no longer suspended.
+		w.results.Remove(ctx.VM.NamespacedName())
+		ctx.Logger.V(4).Info("Readiness probe is suspended", "reason", reason)
+		return w.updateCondition(ctx, conditions.FalseCondition(vmopv1alpha1.ReadyCondition, notReadyReason,
+			vmopv1alpha1.ConditionSeverityInfo, "Readiness probe is suspended because the %s", reason))
+	}
+
+	initialDelay := time.Duration(ctx.ProbeSpec.InitialDelaySeconds) * time.Second
+	if startTime := w.results.StartTime(ctx.VM.NamespacedName()); time.Since(startTime) < initialDelay {
+		ctx.Logger.V(4).Info("Readiness probe initial delay has not elapsed", "startTime", startTime)
+		return nil
+	}
+
+	res, err := runProbe(w.prober, ctx)
 	if err != nil {
 		ctx.Logger.Error(err, "readiness probe fails", "result", res)
 	}
 	return w.ProcessProbeResult(ctx, res, err)
 }
 
-// getProbe returns a specific type of probe method.
-func (w *readinessWorker) getProbe(probeSpec *vmopv1alpha1.Probe) probe.Probe {
+// getProbe returns a specific type of probe method and the name of its action.
+func getProbe(prober *probe.Prober, probeSpec *vmopv1alpha1.Probe) (probe.Probe, string) {
 	if probeSpec.TCPSocket != nil {
-		return w.prober.TCPProbe
+		return prober.TCPProbe, "tcpSocket"
 	}
 	if probeSpec.GuestHeartbeat != nil {
-		return w.prober.GuestHeartbeat
+		return prober.GuestHeartbeat, "guestHeartbeat"
+	}
+	if probeSpec.HTTPGet != nil {
+		return prober.HTTPGetProbe, "httpGet"
+	}
+	if probeSpec.Exec != nil {
+		return prober.ExecProbe, "exec"
 	}
 
-	return nil
+	return nil, ""
 }
 
 // runProbe runs a specific type of probe based on the VM probe spec.
-func (w *readinessWorker) runProbe(ctx *context.ProbeContext) (probe.Result, error) {
-	if p := w.getProbe(ctx.ProbeSpec); p != nil {
-		return p.Probe(ctx)
+func runProbe(prober *probe.Prober, ctx *context.ProbeContext) (probe.Result, error) {
+	p, action := getProbe(prober, ctx.ProbeSpec)
+	if p == nil {
+		return probe.Unknown, fmt.Errorf("unknown action specified for VM %s %s probe", ctx.VM.NamespacedName(), ctx.ProbeType)
 	}
 
-	return probe.Unknown, fmt.Errorf("unknown action specified for VM %s readiness probe", ctx.VM.NamespacedName())
+	start := time.Now()
+	res, err := p.Probe(ctx)
+	metrics.NewProberMetrics().RegisterProbeResult(ctx.ProbeType, action, res.String(), time.Since(start))
+
+	return res, err
 }
 
 // getCondition returns condition based on VM probe results.
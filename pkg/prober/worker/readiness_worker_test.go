@@ -41,6 +41,8 @@ var _ = Describe("VirtualMachine readiness probes", func() {
 		fakeEvents         chan string
 		fakeTCPProbe       *fakeprobe.FakeProbe
 		fakeHeartbeatProbe *fakeprobe.FakeProbe
+		fakeHTTPGetProbe   *fakeprobe.FakeProbe
+		fakeExecProbe      *fakeprobe.FakeProbe
 	)
 
 	BeforeEach(func() {
@@ -64,11 +66,15 @@ var _ = Describe("VirtualMachine readiness probes", func() {
 		queue := workqueue.NewNamedDelayingQueue("test")
 		fakeTCPProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
 		fakeHeartbeatProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
+		fakeHTTPGetProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
+		fakeExecProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
 		prober := &probe.Prober{
 			TCPProbe:       fakeTCPProbe,
 			GuestHeartbeat: fakeHeartbeatProbe,
+			HTTPGetProbe:   fakeHTTPGetProbe,
+			ExecProbe:      fakeExecProbe,
 		}
-		testWorker = NewReadinessWorker(queue, prober, fakeClient, fakeRecorder)
+		testWorker = NewReadinessWorker(queue, prober, fakeClient, fakeRecorder, NewProbeResults())
 	})
 
 	checkReadyCondition := func(c client.Client, objKey client.ObjectKey, expectedCondition corev1.ConditionStatus) {
@@ -161,6 +167,133 @@ var _ = Describe("VirtualMachine readiness probes", func() {
 		})
 	})
 
+	Context("Probe thresholds and initial delay", func() {
+		var probeResult probe.Result
+
+		BeforeEach(func() {
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessTCPProbe(10001)
+			vm.Spec.ReadinessProbe.SuccessThreshold = 2
+			vm.Spec.ReadinessProbe.FailureThreshold = 2
+			vm.Status.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+			Expect(fakeClient.Create(goctx.Background(), vm)).Should(Succeed())
+
+			fakeTCPProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				return probeResult, nil
+			}
+		})
+
+		doProbe := func(res probe.Result) {
+			probeResult = res
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).Should(Succeed())
+			var err error
+			ctx, err = testWorker.CreateProbeContext(vm)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(testWorker.DoProbe(ctx)).Should(Succeed())
+		}
+
+		It("Should only update ReadyCondition when the thresholds are reached", func() {
+			By("First failure when not ready sets the condition", func() {
+				doProbe(probe.Failure)
+				checkReadyCondition(fakeClient, vmKey, corev1.ConditionFalse)
+			})
+
+			By("Successes below SuccessThreshold do not change the condition", func() {
+				doProbe(probe.Success)
+				checkReadyCondition(fakeClient, vmKey, corev1.ConditionFalse)
+			})
+
+			By("Successes reaching SuccessThreshold set the condition", func() {
+				doProbe(probe.Success)
+				checkReadyCondition(fakeClient, vmKey, corev1.ConditionTrue)
+			})
+
+			By("Failures below FailureThreshold do not change the condition", func() {
+				doProbe(probe.Failure)
+				checkReadyCondition(fakeClient, vmKey, corev1.ConditionTrue)
+				doProbe(probe.Success)
+				doProbe(probe.Unknown)
+				checkReadyCondition(fakeClient, vmKey, corev1.ConditionTrue)
+			})
+
+			By("Failures reaching FailureThreshold set the condition", func() {
+				doProbe(probe.Failure)
+				checkReadyCondition(fakeClient, vmKey, corev1.ConditionFalse)
+			})
+		})
+
+		When("the initial delay has not elapsed", func() {
+			BeforeEach(func() {
+				vm.Spec.ReadinessProbe.InitialDelaySeconds = 600
+				Expect(fakeClient.Update(goctx.Background(), vm)).Should(Succeed())
+			})
+
+			It("Should not run the probe", func() {
+				doProbe(probe.Failure)
+				Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).Should(Succeed())
+				Expect(conditions.Get(vm, vmopv1alpha1.ReadyCondition)).To(BeNil())
+			})
+		})
+	})
+
+	Context("Readiness probe is suspended", func() {
+		var probed bool
+
+		BeforeEach(func() {
+			probed = false
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessTCPProbe(10001)
+			vm.Status.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+			fakeTCPProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				probed = true
+				return probe.Success, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			Expect(fakeClient.Create(goctx.Background(), vm)).Should(Succeed())
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).Should(Succeed())
+			var err error
+			ctx, err = testWorker.CreateProbeContext(vm)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(testWorker.DoProbe(ctx)).Should(Succeed())
+		})
+
+		When("guest customization has not succeeded", func() {
+			BeforeEach(func() {
+				conditions.MarkFalse(vm, vmopv1alpha1.GuestCustomizationCondition,
+					vmopv1alpha1.GuestCustomizationRunningReason, vmopv1alpha1.ConditionSeverityInfo, "")
+			})
+
+			It("Should not run the probe", func() {
+				Expect(probed).To(BeFalse())
+				checkReadyCondition(fakeClient, vmKey, corev1.ConditionFalse)
+			})
+		})
+
+		When("the startup probe has not succeeded", func() {
+			BeforeEach(func() {
+				vm.Spec.StartupProbe = getVirtualMachineReadinessTCPProbe(10001)
+			})
+
+			It("Should not run the probe", func() {
+				Expect(probed).To(BeFalse())
+				checkReadyCondition(fakeClient, vmKey, corev1.ConditionFalse)
+			})
+		})
+
+		When("the startup probe has succeeded", func() {
+			BeforeEach(func() {
+				vm.Spec.StartupProbe = getVirtualMachineReadinessTCPProbe(10001)
+				conditions.MarkTrue(vm, vmopv1alpha1.GuestCustomizationCondition)
+				conditions.MarkTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)
+			})
+
+			It("Should run the probe", func() {
+				Expect(probed).To(BeTrue())
+				checkReadyCondition(fakeClient, vmKey, corev1.ConditionTrue)
+			})
+		})
+	})
+
 	Context("Guest heartbeat Probe", func() {
 
 		BeforeEach(func() {
@@ -185,6 +318,56 @@ var _ = Describe("VirtualMachine readiness probes", func() {
 			Expect(condition.Message).To(ContainSubstring("heartbeat error"))
 		})
 	})
+
+	Context("HTTPGet Probe", func() {
+
+		BeforeEach(func() {
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPGetProbe(80)
+			Expect(fakeClient.Create(goctx.Background(), vm)).Should(Succeed())
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).Should(Succeed())
+			var err error
+			ctx, err = testWorker.CreateProbeContext(vm)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		// Just need to test for probe selection.
+		It("Should update ReadyCondition when probe fails", func() {
+			fakeHTTPGetProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				return probe.Failure, fmt.Errorf("http error")
+			}
+
+			Expect(testWorker.DoProbe(ctx)).Should(Succeed())
+			Expect(fakeClient.Get(ctx, vmKey, vm)).Should(Succeed())
+			condition := conditions.Get(vm, vmopv1alpha1.ReadyCondition)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Message).To(ContainSubstring("http error"))
+		})
+	})
+
+	Context("Exec Probe", func() {
+
+		BeforeEach(func() {
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessExecProbe()
+			Expect(fakeClient.Create(goctx.Background(), vm)).Should(Succeed())
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).Should(Succeed())
+			var err error
+			ctx, err = testWorker.CreateProbeContext(vm)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		// Just need to test for probe selection.
+		It("Should update ReadyCondition when probe fails", func() {
+			fakeExecProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				return probe.Failure, fmt.Errorf("exec error")
+			}
+
+			Expect(testWorker.DoProbe(ctx)).Should(Succeed())
+			Expect(fakeClient.Get(ctx, vmKey, vm)).Should(Succeed())
+			condition := conditions.Get(vm, vmopv1alpha1.ReadyCondition)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Message).To(ContainSubstring("exec error"))
+		})
+	})
 })
 
 func TestReadinessProbeWorker(t *testing.T) {
@@ -207,3 +390,22 @@ func getVirtualMachineHeartbeatProbe() *vmopv1alpha1.Probe {
 		PeriodSeconds:  1,
 	}
 }
+
+func getVirtualMachineReadinessHTTPGetProbe(port int) *vmopv1alpha1.Probe {
+	return &vmopv1alpha1.Probe{
+		HTTPGet: &vmopv1alpha1.HTTPGetAction{
+			Port: intstr.FromInt(port),
+		},
+		PeriodSeconds: 1,
+	}
+}
+
+func getVirtualMachineReadinessExecProbe() *vmopv1alpha1.Probe {
+	return &vmopv1alpha1.Probe{
+		Exec: &vmopv1alpha1.ExecAction{
+			Command:    []string{"/bin/true"},
+			SecretName: "guest-creds",
+		},
+		PeriodSeconds: 1,
+	}
+}
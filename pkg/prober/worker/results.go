@@ -0,0 +1,76 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/probe"
+)
+
+// ProbeResults tracks the consecutive results of a probe for each VM. It is shared by all of the workers
+// of a probe queue since any of them may process a given VM.
+type ProbeResults struct {
+	mutex   sync.Mutex
+	results map[string]*probeResult
+}
+
+type probeResult struct {
+	// startTime is when the VM was first observed as powered on, or last restarted by the prober.
+	startTime time.Time
+	result    probe.Result
+	count     int32
+}
+
+// NewProbeResults creates a new ProbeResults.
+func NewProbeResults() *ProbeResults {
+	return &ProbeResults{
+		results: make(map[string]*probeResult),
+	}
+}
+
+// StartTime returns when the VM was first observed as powered on. The current time is recorded and returned
+// if the VM is not already tracked.
+func (r *ProbeResults) StartTime(vmName string) time.Time {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.get(vmName).startTime
+}
+
+// Record records the probe result and returns the number of consecutive times the result has been recorded.
+func (r *ProbeResults) Record(vmName string, res probe.Result) int32 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	pr := r.get(vmName)
+	if pr.result != res {
+		pr.result = res
+		pr.count = 0
+	}
+	pr.count++
+
+	return pr.count
+}
+
+// Remove stops tracking the VM so its start time and results are reset the next time it is probed.
+func (r *ProbeResults) Remove(vmName string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.results, vmName)
+}
+
+func (r *ProbeResults) get(vmName string) *probeResult {
+	pr, ok := r.results[vmName]
+	if !ok {
+		pr = &probeResult{
+			startTime: time.Now(),
+			result:    probe.Unknown,
+		}
+		r.results[vmName] = pr
+	}
+	return pr
+}
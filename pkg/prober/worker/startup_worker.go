@@ -0,0 +1,186 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	goctx "context"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/pkg/errors"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/patch"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/probe"
+	vmoprecord "github.com/vmware-tanzu/vm-operator/pkg/record"
+)
+
+const (
+	// startedReason represents the reason for the event emitted when the startup probe succeeds.
+	startedReason string = "Started"
+)
+
+// startupWorker implements Worker interface.
+type startupWorker struct {
+	queue     workqueue.DelayingInterface
+	prober    *probe.Prober
+	client    client.Client
+	recorder  vmoprecord.Recorder
+	results   *ProbeResults
+	restarter vmProviderRestarter
+}
+
+// NewStartupWorker creates a new startup worker to run startup probes. The startup probe is run until it
+// succeeds, after which the readiness and liveness probes of the VM are run. The VM is restarted when its
+// startup probe fails FailureThreshold consecutive times.
+func NewStartupWorker(
+	queue workqueue.DelayingInterface,
+	prober *probe.Prober,
+	client client.Client,
+	recorder vmoprecord.Recorder,
+	results *ProbeResults,
+	restarter vmProviderRestarter,
+) Worker {
+	return &startupWorker{
+		queue:     queue,
+		prober:    prober,
+		client:    client,
+		recorder:  recorder,
+		results:   results,
+		restarter: restarter,
+	}
+}
+
+func (w *startupWorker) GetQueue() workqueue.DelayingInterface {
+	return w.queue
+}
+
+// CreateProbeContext creates a probe context for startup probe.
+func (w *startupWorker) CreateProbeContext(vm *vmopv1alpha1.VirtualMachine) (*context.ProbeContext, error) {
+	patchHelper, err := patch.NewHelper(vm, w.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &context.ProbeContext{
+		Context:     goctx.Background(),
+		Logger:      ctrl.Log.WithName("startup-probe").WithValues("vmName", vm.NamespacedName()),
+		PatchHelper: patchHelper,
+		VM:          vm,
+		ProbeSpec:   vm.Spec.StartupProbe,
+		ProbeType:   "startup",
+	}, nil
+}
+
+func (w *startupWorker) DoProbe(ctx *context.ProbeContext) error {
+	vm := ctx.VM
+
+	if conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition) {
+		// The startup probe is not run again until the VM is powered off or restarted, which is when the
+		// condition is reset by the VM's status update.
+		return nil
+	}
+
+	if guestCustomizationPending(vm) {
+		// Do not count the time spent customizing the guest towards the initial delay or the failure threshold.
+		w.results.Remove(vm.NamespacedName())
+		return w.updateCondition(ctx, conditions.FalseCondition(vmopv1alpha1.VirtualMachineStartedCondition,
+			vmopv1alpha1.StartupProbePendingReason, vmopv1alpha1.ConditionSeverityInfo,
+			"Waiting for guest customization to succeed"))
+	}
+
+	initialDelay := time.Duration(ctx.ProbeSpec.InitialDelaySeconds) * time.Second
+	if startTime := w.results.StartTime(vm.NamespacedName()); time.Since(startTime) < initialDelay {
+		ctx.Logger.V(4).Info("Startup probe initial delay has not elapsed", "startTime", startTime)
+		return nil
+	}
+
+	res, err := runProbe(w.prober, ctx)
+	if err != nil {
+		ctx.Logger.V(4).Info("startup probe fails", "result", res, "error", err.Error())
+	}
+	return w.ProcessProbeResult(ctx, res, err)
+}
+
+// ProcessProbeResult processes startup probe results to get the VirtualMachineStartedCondition. The VM is
+// restarted when the probe has failed FailureThreshold consecutive times.
+func (w *startupWorker) ProcessProbeResult(ctx *context.ProbeContext, res probe.Result, resErr error) error {
+	vm := ctx.VM
+	vmName := vm.NamespacedName()
+
+	msg := ""
+	if resErr != nil {
+		msg = resErr.Error()
+	}
+
+	if vm.Status.PowerState != vmopv1alpha1.VirtualMachinePoweredOn {
+		// Reset the results so that the startup probe runs again once the VM is powered on.
+		w.results.Remove(vmName)
+		return w.updateCondition(ctx, conditions.FalseCondition(vmopv1alpha1.VirtualMachineStartedCondition,
+			vmopv1alpha1.StartupProbePendingReason, vmopv1alpha1.ConditionSeverityInfo, msg))
+	}
+
+	if res == probe.Success {
+		w.results.Remove(vmName)
+		w.recorder.Eventf(vm, startedReason, "Startup probe succeeded")
+		ctx.Logger.Info("VM startup probe succeeded")
+		return w.updateCondition(ctx, conditions.TrueCondition(vmopv1alpha1.VirtualMachineStartedCondition))
+	}
+
+	// Any result other than success counts towards the failure threshold.
+	count := w.results.Record(vmName, probe.Failure)
+
+	failureThreshold := ctx.ProbeSpec.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = defaultStartupFailureThreshold
+	}
+
+	if count < failureThreshold {
+		ctx.Logger.V(4).Info("Startup probe failed", "failures", count, "failureThreshold", failureThreshold)
+		return w.updateCondition(ctx, conditions.FalseCondition(vmopv1alpha1.VirtualMachineStartedCondition,
+			vmopv1alpha1.StartupProbePendingReason, vmopv1alpha1.ConditionSeverityInfo, msg))
+	}
+
+	w.recorder.Warnf(vm, vmopv1alpha1.StartupProbeFailedReason,
+		"Startup probe failed %d times, restarting VM: %s", count, msg)
+	ctx.Logger.Info("Restarting VM because its startup probe failed",
+		"failures", count, "restartMode", vm.Spec.RestartMode)
+
+	if err := w.restarter.RestartVirtualMachine(ctx, vm, vm.Spec.RestartMode); err != nil {
+		// Do not return the error so that the VM is not immediately re-queued: the restart is retried
+		// after the next failed probe.
+		ctx.Logger.Error(err, "Failed to restart VM")
+		w.recorder.Warnf(vm, vmopv1alpha1.StartupProbeFailedReason, "Failed to restart VM: %v", err)
+		return nil
+	}
+
+	// Reset the results so that the initial delay and failure threshold apply to the restarted VM.
+	w.results.Remove(vmName)
+	w.recorder.Eventf(vm, restartedReason, "VM restarted because its startup probe failed")
+
+	vm.Status.RestartCount++
+	return w.updateCondition(ctx, conditions.FalseCondition(vmopv1alpha1.VirtualMachineStartedCondition,
+		vmopv1alpha1.StartupProbeFailedReason, vmopv1alpha1.ConditionSeverityWarning,
+		"Startup probe failed %d times: %s", count, msg))
+}
+
+// updateCondition sets the VirtualMachineStartedCondition and patches the VM.
+func (w *startupWorker) updateCondition(ctx *context.ProbeContext, condition *vmopv1alpha1.Condition) error {
+	conditions.Set(ctx.VM, condition)
+
+	err := ctx.PatchHelper.Patch(ctx, ctx.VM, patch.WithOwnedConditions{
+		Conditions: []vmopv1alpha1.ConditionType{vmopv1alpha1.VirtualMachineStartedCondition},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "patched failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	goctx "context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgorecord "k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/context"
+	fakeprobe "github.com/vmware-tanzu/vm-operator/pkg/prober/fake/probe"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/probe"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+var _ = Describe("VirtualMachine startup probes", func() {
+	var (
+		testWorker Worker
+
+		vm    *vmopv1alpha1.VirtualMachine
+		vmKey client.ObjectKey
+
+		fakeClient     client.Client
+		fakeRecorder   record.Recorder
+		fakeEvents     chan string
+		fakeTCPProbe   *fakeprobe.FakeProbe
+		fakeVMProvider *fake.VMProvider
+
+		probeResult  probe.Result
+		probed       bool
+		restartCalls int
+	)
+
+	BeforeEach(func() {
+		vm = &vmopv1alpha1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: "dummy-ns",
+			},
+			Spec: vmopv1alpha1.VirtualMachineSpec{
+				ClassName:    "dummy-vmclass",
+				RestartMode:  vmopv1alpha1.VirtualMachinePowerOpModeSoft,
+				StartupProbe: getVirtualMachineLivenessTCPProbe(10001),
+			},
+			Status: vmopv1alpha1.VirtualMachineStatus{
+				PowerState: vmopv1alpha1.VirtualMachinePoweredOn,
+			},
+		}
+		vmKey = client.ObjectKey{Name: vm.Name, Namespace: vm.Namespace}
+
+		probeResult = probe.Failure
+		probed = false
+		restartCalls = 0
+	})
+
+	JustBeforeEach(func() {
+		fakeClient = builder.NewFakeClient(vm)
+		eventRecorder := clientgorecord.NewFakeRecorder(1024)
+		fakeRecorder = record.New(eventRecorder)
+		fakeEvents = eventRecorder.Events
+
+		fakeVMProvider = fake.NewVMProvider()
+		fakeVMProvider.RestartVirtualMachineFn = func(_ goctx.Context, _ *vmopv1alpha1.VirtualMachine, _ vmopv1alpha1.VirtualMachinePowerOpMode) error {
+			restartCalls++
+			return nil
+		}
+
+		queue := workqueue.NewNamedDelayingQueue("test")
+		fakeTCPProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
+		fakeTCPProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+			probed = true
+			if probeResult != probe.Success {
+				return probeResult, fmt.Errorf("connection refused")
+			}
+			return probeResult, nil
+		}
+		prober := &probe.Prober{
+			TCPProbe: fakeTCPProbe,
+		}
+		testWorker = NewStartupWorker(queue, prober, fakeClient, fakeRecorder, NewProbeResults(), fakeVMProvider)
+	})
+
+	doProbe := func() {
+		Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+		ctx, err := testWorker.CreateProbeContext(vm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ctx.ProbeSpec).To(Equal(vm.Spec.StartupProbe))
+		Expect(testWorker.DoProbe(ctx)).To(Succeed())
+		Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+	}
+
+	startedCondition := func() *vmopv1alpha1.Condition {
+		return conditions.Get(vm, vmopv1alpha1.VirtualMachineStartedCondition)
+	}
+
+	It("Should mark the VM as started when the probe succeeds", func() {
+		doProbe()
+		Expect(startedCondition()).ToNot(BeNil())
+		Expect(startedCondition().Status).To(Equal(corev1.ConditionFalse))
+		Expect(startedCondition().Reason).To(Equal(vmopv1alpha1.StartupProbePendingReason))
+
+		probeResult = probe.Success
+		doProbe()
+		Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(BeTrue())
+		Expect(fakeEvents).To(Receive(ContainSubstring(startedReason)))
+
+		By("The probe is not run once the VM has started", func() {
+			probed = false
+			doProbe()
+			Expect(probed).To(BeFalse())
+		})
+	})
+
+	It("Should use the default startup failure threshold", func() {
+		for i := 0; i < defaultStartupFailureThreshold-1; i++ {
+			doProbe()
+		}
+		Expect(restartCalls).To(BeZero())
+
+		doProbe()
+		Expect(restartCalls).To(Equal(1))
+		Expect(vm.Status.RestartCount).To(BeEquivalentTo(1))
+		Expect(startedCondition().Reason).To(Equal(vmopv1alpha1.StartupProbeFailedReason))
+		Expect(fakeEvents).To(Receive(ContainSubstring(vmopv1alpha1.StartupProbeFailedReason)))
+		Expect(fakeEvents).To(Receive(ContainSubstring(restartedReason)))
+	})
+
+	When("guest customization has not succeeded", func() {
+		BeforeEach(func() {
+			vm.Spec.StartupProbe.FailureThreshold = 1
+			conditions.MarkFalse(vm, vmopv1alpha1.GuestCustomizationCondition,
+				vmopv1alpha1.GuestCustomizationPendingReason, vmopv1alpha1.ConditionSeverityInfo, "")
+		})
+
+		It("Should not run the probe", func() {
+			doProbe()
+			Expect(probed).To(BeFalse())
+			Expect(restartCalls).To(BeZero())
+			Expect(startedCondition().Status).To(Equal(corev1.ConditionFalse))
+			Expect(startedCondition().Message).To(ContainSubstring("guest customization"))
+		})
+	})
+
+	When("the VM is not powered on", func() {
+		BeforeEach(func() {
+			vm.Status.PowerState = vmopv1alpha1.VirtualMachinePoweredOff
+			conditions.MarkTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)
+		})
+
+		It("Should reset the started condition", func() {
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+			ctx, err := testWorker.CreateProbeContext(vm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testWorker.ProcessProbeResult(ctx, probe.Failure, fmt.Errorf("not powered on"))).To(Succeed())
+
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).To(Succeed())
+			Expect(startedCondition().Status).To(Equal(corev1.ConditionFalse))
+			Expect(restartCalls).To(BeZero())
+		})
+	})
+})
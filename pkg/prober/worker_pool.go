@@ -0,0 +1,130 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package prober
+
+import (
+	goctx "context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/worker"
+)
+
+const (
+	// workerPoolScaleInterval is how often the number of workers of a pool is adjusted to the depth of its queue.
+	workerPoolScaleInterval = 5 * time.Second
+
+	// queueDepthPerWorker is the queue depth that warrants an additional worker above the minimum.
+	queueDepthPerWorker = 10
+)
+
+// workerPool runs between minWorkers and maxWorkers workers to process the items of a probe queue. Workers
+// are added as the queue depth grows and removed, once they finish their current item, as it shrinks.
+type workerPool struct {
+	name       string
+	queue      workqueue.DelayingInterface
+	newWorker  func() worker.Worker
+	process    func(worker.Worker) bool
+	minWorkers int
+	maxWorkers int
+	log        logr.Logger
+	metrics    *metrics.ProberMetrics
+
+	mutex   sync.Mutex
+	workers int
+	target  int
+	wg      sync.WaitGroup
+}
+
+// start starts the minimum number of workers and then scales the pool until the context is done.
+func (p *workerPool) start(ctx goctx.Context) {
+	p.log.Info("Starting workers", "queue", p.name, "min", p.minWorkers, "max", p.maxWorkers)
+	p.scale()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(workerPoolScaleInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.scale()
+			}
+		}
+	}()
+}
+
+// wait waits for the workers to exit after the queue has been shut down.
+func (p *workerPool) wait() {
+	p.wg.Wait()
+}
+
+// desiredWorkers returns the number of workers for the queue depth.
+func (p *workerPool) desiredWorkers(depth int) int {
+	desired := p.minWorkers + (depth+queueDepthPerWorker-1)/queueDepthPerWorker
+	if desired > p.maxWorkers {
+		desired = p.maxWorkers
+	}
+	return desired
+}
+
+// scale adjusts the number of workers to the depth of the queue.
+func (p *workerPool) scale() {
+	depth := p.queue.Len()
+
+	p.mutex.Lock()
+	p.target = p.desiredWorkers(depth)
+	if p.workers < p.target {
+		p.log.V(4).Info("Adding workers", "queue", p.name, "depth", depth, "workers", p.workers, "target", p.target)
+	}
+	for p.workers < p.target {
+		p.workers++
+		p.wg.Add(1)
+		go p.run(p.newWorker())
+	}
+	workers := p.workers
+	p.mutex.Unlock()
+
+	p.metrics.RegisterQueueDepth(p.name, depth)
+	p.metrics.RegisterWorkers(p.name, workers)
+}
+
+// run processes items from the queue until the queue is shut down or the pool has too many workers.
+func (p *workerPool) run(w worker.Worker) {
+	defer p.wg.Done()
+
+	for {
+		if p.shouldExit() {
+			return
+		}
+
+		if quit := p.process(w); quit {
+			p.mutex.Lock()
+			p.workers--
+			p.mutex.Unlock()
+			return
+		}
+	}
+}
+
+// shouldExit returns true, and removes the worker from the pool, if the pool has more workers than its target.
+func (p *workerPool) shouldExit() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.workers > p.target {
+		p.workers--
+		return true
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package prober
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/metrics"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober/worker"
+)
+
+var _ = Describe("Probe worker pool", func() {
+	var (
+		queue workqueue.DelayingInterface
+		pool  *workerPool
+	)
+
+	BeforeEach(func() {
+		queue = workqueue.NewNamedDelayingQueue("test")
+		pool = &workerPool{
+			name:      "test",
+			queue:     queue,
+			newWorker: func() worker.Worker { return nil },
+			process: func(_ worker.Worker) bool {
+				item, quit := queue.Get()
+				if quit {
+					return true
+				}
+				queue.Done(item)
+				return false
+			},
+			minWorkers: 1,
+			maxWorkers: 3,
+			log:        ctrl.Log.WithName("test"),
+			metrics:    metrics.NewProberMetrics(),
+		}
+	})
+
+	AfterEach(func() {
+		queue.ShutDown()
+		pool.wait()
+	})
+
+	numWorkers := func() int {
+		pool.mutex.Lock()
+		defer pool.mutex.Unlock()
+		return pool.workers
+	}
+
+	addItems := func(n int) {
+		for i := 0; i < n; i++ {
+			queue.Add(fmt.Sprintf("item-%d", i))
+		}
+	}
+
+	It("Should scale the desired number of workers with the queue depth", func() {
+		Expect(pool.desiredWorkers(0)).To(Equal(1))
+		Expect(pool.desiredWorkers(1)).To(Equal(2))
+		Expect(pool.desiredWorkers(queueDepthPerWorker)).To(Equal(2))
+		Expect(pool.desiredWorkers(queueDepthPerWorker + 1)).To(Equal(3))
+		Expect(pool.desiredWorkers(100 * queueDepthPerWorker)).To(Equal(3))
+	})
+
+	It("Should add workers as the queue grows and remove them as it shrinks", func() {
+		pool.scale()
+		Expect(numWorkers()).To(Equal(1))
+
+		addItems(5 * queueDepthPerWorker)
+		pool.scale()
+		Expect(numWorkers()).To(Equal(3))
+		Eventually(queue.Len).Should(BeZero())
+
+		pool.scale()
+		Expect(pool.target).To(Equal(1))
+		// Workers exit once they have processed an item.
+		addItems(10)
+		Eventually(numWorkers).Should(Equal(1))
+	})
+
+	It("Should stop the workers when the queue is shut down", func() {
+		addItems(5 * queueDepthPerWorker)
+		pool.scale()
+		Expect(numWorkers()).To(Equal(3))
+
+		queue.ShutDown()
+		pool.wait()
+		Expect(numWorkers()).To(BeZero())
+	})
+})
@@ -0,0 +1,79 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// A trigger time is a spec field, such as a VirtualMachine's spec.nextRestartTime, that requests an action by
+// being set to "now", which the mutation webhook replaces with the current time as an RFC3339 timestamp. The
+// action is done once for each timestamp that is later than the last time the action was done, which is
+// recorded in the status.
+
+const (
+	// TriggerTimeNow is the value of a trigger time that requests the action now.
+	TriggerTimeNow = "now"
+
+	invalidTriggerTimeFmt = "must be \"now\" or an RFC3339 timestamp: %s"
+	triggerTimeInFuture   = "must not be in the future"
+)
+
+// SetTriggerTimeIfNow sets the trigger time to the current time as an RFC3339Nano timestamp in UTC if its value
+// is "now" (case-insensitive).
+// Return true if the trigger time is mutated, otherwise return false.
+func SetTriggerTimeIfNow(triggerTime *string) bool {
+	if !strings.EqualFold(*triggerTime, TriggerTimeNow) {
+		return false
+	}
+
+	*triggerTime = time.Now().UTC().Format(time.RFC3339Nano)
+	return true
+}
+
+// ValidateTriggerTime validates that the trigger time is an RFC3339 timestamp that is not in the future. An
+// unchanged value is not revalidated so that it does not fail the "future" check due to clock skew.
+func ValidateTriggerTime(path *field.Path, triggerTime, oldTriggerTime string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if triggerTime == "" || triggerTime == oldTriggerTime {
+		return allErrs
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, triggerTime)
+	if err != nil {
+		return append(allErrs, field.Invalid(path, triggerTime, fmt.Sprintf(invalidTriggerTimeFmt, err.Error())))
+	}
+
+	if t.After(time.Now()) {
+		allErrs = append(allErrs, field.Invalid(path, triggerTime, triggerTimeInFuture))
+	}
+
+	return allErrs
+}
+
+// PendingTriggerTime returns the trigger time if it is set and later than the last time the action was done,
+// otherwise nil.
+func PendingTriggerTime(triggerTime string, lastTime *metav1.Time) (*metav1.Time, error) {
+	if triggerTime == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, triggerTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastTime != nil && !t.After(lastTime.Time) {
+		return nil, nil
+	}
+
+	pending := metav1.NewTime(t)
+	return &pending, nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package util_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+)
+
+var _ = Describe("SetTriggerTimeIfNow", func() {
+
+	It("Should set the current time when the value is now", func() {
+		triggerTime := "NOW"
+		before := time.Now().UTC()
+		Expect(util.SetTriggerTimeIfNow(&triggerTime)).To(BeTrue())
+		t, err := time.Parse(time.RFC3339Nano, triggerTime)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t).ToNot(BeTemporally("<", before))
+	})
+
+	It("Should not mutate a timestamp", func() {
+		triggerTime := time.Now().UTC().Format(time.RFC3339Nano)
+		value := triggerTime
+		Expect(util.SetTriggerTimeIfNow(&value)).To(BeFalse())
+		Expect(value).To(Equal(triggerTime))
+	})
+})
+
+var _ = Describe("ValidateTriggerTime", func() {
+	path := field.NewPath("spec", "nextRestartTime")
+
+	It("Should allow an empty value", func() {
+		Expect(util.ValidateTriggerTime(path, "", "")).To(BeEmpty())
+	})
+
+	It("Should allow a timestamp in the past", func() {
+		Expect(util.ValidateTriggerTime(path, time.Now().UTC().Format(time.RFC3339Nano), "")).To(BeEmpty())
+	})
+
+	It("Should deny a timestamp in the future", func() {
+		future := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+		errs := util.ValidateTriggerTime(path, future, "")
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring("must not be in the future"))
+	})
+
+	It("Should not revalidate an unchanged value", func() {
+		future := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+		Expect(util.ValidateTriggerTime(path, future, future)).To(BeEmpty())
+	})
+
+	It("Should deny a value that is not a timestamp", func() {
+		errs := util.ValidateTriggerTime(path, "tomorrow", "")
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring("must be \"now\" or an RFC3339 timestamp"))
+	})
+})
+
+var _ = Describe("PendingTriggerTime", func() {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	It("Should return nil when the value is empty", func() {
+		Expect(util.PendingTriggerTime("", nil)).To(BeNil())
+	})
+
+	It("Should return the trigger time when there is no last time", func() {
+		t, err := util.PendingTriggerTime(now.Format(time.RFC3339Nano), nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.Time).To(BeTemporally("==", now))
+	})
+
+	It("Should return the trigger time when it is later than the last time", func() {
+		last := metav1.NewTime(now.Add(-time.Minute))
+		t, err := util.PendingTriggerTime(now.Format(time.RFC3339Nano), &last)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t).ToNot(BeNil())
+	})
+
+	It("Should return nil when the action was done at the trigger time", func() {
+		last := metav1.NewTime(now)
+		Expect(util.PendingTriggerTime(now.Format(time.RFC3339Nano), &last)).To(BeNil())
+	})
+
+	It("Should return an error when the value is not a timestamp", func() {
+		_, err := util.PendingTriggerTime("tomorrow", nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
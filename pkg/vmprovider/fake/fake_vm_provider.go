@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	vimTypes "github.com/vmware/govmomi/vim25/types"
 
@@ -29,10 +30,17 @@ import (
 type funcs struct {
 	CreateOrUpdateVirtualMachineFn func(ctx context.Context, vm *v1alpha1.VirtualMachine) error
 	DeleteVirtualMachineFn         func(ctx context.Context, vm *v1alpha1.VirtualMachine) error
+	RestartVirtualMachineFn        func(ctx context.Context, vm *v1alpha1.VirtualMachine, mode v1alpha1.VirtualMachinePowerOpMode) error
 	PublishVirtualMachineFn        func(ctx context.Context, vm *v1alpha1.VirtualMachine,
 		vmPub *v1alpha1.VirtualMachinePublishRequest, cl *imgregv1a1.ContentLibrary, actID string) (string, error)
-	GetVirtualMachineGuestHeartbeatFn func(ctx context.Context, vm *v1alpha1.VirtualMachine) (v1alpha1.GuestHeartbeatStatus, error)
-	GetVirtualMachineWebMKSTicketFn   func(ctx context.Context, vm *v1alpha1.VirtualMachine, pubKey string) (string, error)
+	GetVirtualMachineGuestHeartbeatFn      func(ctx context.Context, vm *v1alpha1.VirtualMachine) (v1alpha1.GuestHeartbeatStatus, error)
+	GetVirtualMachineWebMKSTicketFn        func(ctx context.Context, vm *v1alpha1.VirtualMachine, pubKey string) (string, error)
+	GetVirtualMachineSerialConsoleTicketFn func(ctx context.Context, vm *v1alpha1.VirtualMachine, pubKey, uuid string, expiry time.Time) (string, error)
+	ExecVirtualMachineGuestCommandFn       func(ctx context.Context, vm *v1alpha1.VirtualMachine, action *v1alpha1.ExecAction) (int32, error)
+
+	CreateOrUpdateVirtualMachineSnapshotFn func(ctx context.Context, vm *v1alpha1.VirtualMachine, vmSnapshot *v1alpha1.VirtualMachineSnapshot) error
+	RevertVirtualMachineSnapshotFn         func(ctx context.Context, vm *v1alpha1.VirtualMachine, vmSnapshot *v1alpha1.VirtualMachineSnapshot) error
+	DeleteVirtualMachineSnapshotFn         func(ctx context.Context, vm *v1alpha1.VirtualMachine, vmSnapshot *v1alpha1.VirtualMachineSnapshot) error
 
 	ListItemsFromContentLibraryFn              func(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider) ([]string, error)
 	GetVirtualMachineImageFromContentLibraryFn func(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider, itemID string,
@@ -47,6 +55,7 @@ type funcs struct {
 	IsVirtualMachineSetResourcePolicyReadyFn        func(ctx context.Context, azName string, rp *v1alpha1.VirtualMachineSetResourcePolicy) (bool, error)
 	DeleteVirtualMachineSetResourcePolicyFn         func(ctx context.Context, rp *v1alpha1.VirtualMachineSetResourcePolicy) error
 	ComputeCPUMinFrequencyFn                        func(ctx context.Context) error
+	GetHostHardwareFn                               func(ctx context.Context) (*vmprovider.HostHardware, error)
 
 	GetTasksByActIDFn func(ctx context.Context, actID string) (tasksInfo []vimTypes.TaskInfo, retErr error)
 }
@@ -95,6 +104,15 @@ func (s *VMProvider) DeleteVirtualMachine(ctx context.Context, vm *v1alpha1.Virt
 	return nil
 }
 
+func (s *VMProvider) RestartVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine, mode v1alpha1.VirtualMachinePowerOpMode) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.RestartVirtualMachineFn != nil {
+		return s.RestartVirtualMachineFn(ctx, vm, mode)
+	}
+	return nil
+}
+
 func (s *VMProvider) PublishVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine,
 	vmPub *v1alpha1.VirtualMachinePublishRequest, cl *imgregv1a1.ContentLibrary, actID string) (string, error) {
 	s.Lock()
@@ -128,6 +146,55 @@ func (s *VMProvider) GetVirtualMachineWebMKSTicket(ctx context.Context, vm *v1al
 	return "", nil
 }
 
+func (s *VMProvider) GetVirtualMachineSerialConsoleTicket(
+	ctx context.Context, vm *v1alpha1.VirtualMachine, pubKey, uuid string, expiry time.Time) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.GetVirtualMachineSerialConsoleTicketFn != nil {
+		return s.GetVirtualMachineSerialConsoleTicketFn(ctx, vm, pubKey, uuid, expiry)
+	}
+	return "", nil
+}
+
+func (s *VMProvider) ExecVirtualMachineGuestCommand(ctx context.Context, vm *v1alpha1.VirtualMachine, action *v1alpha1.ExecAction) (int32, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.ExecVirtualMachineGuestCommandFn != nil {
+		return s.ExecVirtualMachineGuestCommandFn(ctx, vm, action)
+	}
+	return 0, nil
+}
+
+func (s *VMProvider) CreateOrUpdateVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, vmSnapshot *v1alpha1.VirtualMachineSnapshot) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.CreateOrUpdateVirtualMachineSnapshotFn != nil {
+		return s.CreateOrUpdateVirtualMachineSnapshotFn(ctx, vm, vmSnapshot)
+	}
+	if vmSnapshot.Status.UniqueID == "" {
+		vmSnapshot.Status.UniqueID = fmt.Sprintf("snapshot-%s", vmSnapshot.Name)
+	}
+	return nil
+}
+
+func (s *VMProvider) RevertVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, vmSnapshot *v1alpha1.VirtualMachineSnapshot) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.RevertVirtualMachineSnapshotFn != nil {
+		return s.RevertVirtualMachineSnapshotFn(ctx, vm, vmSnapshot)
+	}
+	return nil
+}
+
+func (s *VMProvider) DeleteVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, vmSnapshot *v1alpha1.VirtualMachineSnapshot) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.DeleteVirtualMachineSnapshotFn != nil {
+		return s.DeleteVirtualMachineSnapshotFn(ctx, vm, vmSnapshot)
+	}
+	return nil
+}
+
 func (s *VMProvider) CreateOrUpdateVirtualMachineSetResourcePolicy(ctx context.Context, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) error {
 	s.Lock()
 	defer s.Unlock()
@@ -178,6 +245,16 @@ func (s *VMProvider) ComputeCPUMinFrequency(ctx context.Context) error {
 	return nil
 }
 
+func (s *VMProvider) GetHostHardware(ctx context.Context) (*vmprovider.HostHardware, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.GetHostHardwareFn != nil {
+		return s.GetHostHardwareFn(ctx)
+	}
+
+	return &vmprovider.HostHardware{}, nil
+}
+
 func (s *VMProvider) UpdateVcPNID(ctx context.Context, vcPNID, vcPort string) error {
 	s.Lock()
 	defer s.Unlock()
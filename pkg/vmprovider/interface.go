@@ -5,6 +5,7 @@ package vmprovider
 
 import (
 	"context"
+	"time"
 
 	vimTypes "github.com/vmware/govmomi/vim25/types"
 
@@ -19,10 +20,17 @@ import (
 type VirtualMachineProviderInterface interface {
 	CreateOrUpdateVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine) error
 	DeleteVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine) error
+	RestartVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine, mode v1alpha1.VirtualMachinePowerOpMode) error
 	PublishVirtualMachine(ctx context.Context, vm *v1alpha1.VirtualMachine,
 		vmPub *v1alpha1.VirtualMachinePublishRequest, cl *imgregv1a1.ContentLibrary, actID string) (string, error)
 	GetVirtualMachineGuestHeartbeat(ctx context.Context, vm *v1alpha1.VirtualMachine) (v1alpha1.GuestHeartbeatStatus, error)
 	GetVirtualMachineWebMKSTicket(ctx context.Context, vm *v1alpha1.VirtualMachine, pubKey string) (string, error)
+	GetVirtualMachineSerialConsoleTicket(ctx context.Context, vm *v1alpha1.VirtualMachine, pubKey, uuid string, expiry time.Time) (string, error)
+	ExecVirtualMachineGuestCommand(ctx context.Context, vm *v1alpha1.VirtualMachine, action *v1alpha1.ExecAction) (int32, error)
+
+	CreateOrUpdateVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, vmSnapshot *v1alpha1.VirtualMachineSnapshot) error
+	RevertVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, vmSnapshot *v1alpha1.VirtualMachineSnapshot) error
+	DeleteVirtualMachineSnapshot(ctx context.Context, vm *v1alpha1.VirtualMachine, vmSnapshot *v1alpha1.VirtualMachineSnapshot) error
 
 	CreateOrUpdateVirtualMachineSetResourcePolicy(ctx context.Context, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) error
 	IsVirtualMachineSetResourcePolicyReady(ctx context.Context, availabilityZoneName string, resourcePolicy *v1alpha1.VirtualMachineSetResourcePolicy) (bool, error)
@@ -32,6 +40,7 @@ type VirtualMachineProviderInterface interface {
 	UpdateVcPNID(ctx context.Context, vcPNID, vcPort string) error
 	ResetVcClient(ctx context.Context)
 	ComputeCPUMinFrequency(ctx context.Context) error
+	GetHostHardware(ctx context.Context) (*HostHardware, error)
 
 	ListItemsFromContentLibrary(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider) ([]string, error)
 	GetVirtualMachineImageFromContentLibrary(ctx context.Context, contentLibrary *v1alpha1.ContentLibraryProvider, itemID string,
@@ -65,10 +65,43 @@ const (
 	CloudInitTypeValueCloudInitPrep = "cloudinitprep"
 	CloudInitTypeValueGuestInfo     = "guestinfo"
 
-	CloudInitGuestInfoMetadata         = "guestinfo.metadata"
-	CloudInitGuestInfoMetadataEncoding = "guestinfo.metadata.encoding"
-	CloudInitGuestInfoUserdata         = "guestinfo.userdata"
-	CloudInitGuestInfoUserdataEncoding = "guestinfo.userdata.encoding"
+	CloudInitGuestInfoMetadata           = "guestinfo.metadata"
+	CloudInitGuestInfoMetadataEncoding   = "guestinfo.metadata.encoding"
+	CloudInitGuestInfoUserdata           = "guestinfo.userdata"
+	CloudInitGuestInfoUserdataEncoding   = "guestinfo.userdata.encoding"
+	CloudInitGuestInfoVendordata         = "guestinfo.vendordata"
+	CloudInitGuestInfoVendordataEncoding = "guestinfo.vendordata.encoding"
+
+	IgnitionGuestInfoConfigData         = "guestinfo.ignition.config.data"
+	IgnitionGuestInfoConfigDataEncoding = "guestinfo.ignition.config.data.encoding"
+	AfterburnGuestInfoNetworkKargs      = "guestinfo.afterburn.initrd.network-kargs"
+
+	// IgnitionConfigKey is the VM Metadata key of the Ignition config.
+	IgnitionConfigKey = "ignition"
+
+	// CloudInitUserDataKey is the VM Metadata key of the cloud-init userdata.
+	CloudInitUserDataKey = "user-data"
+	// CloudInitUserDataPartKeyPrefix is the prefix of the VM Metadata keys of additional cloud-init userdata
+	// parts. When any are set, the userdata and the parts are merged into a MIME multipart archive.
+	CloudInitUserDataPartKeyPrefix = "user-data."
+	// CloudInitVendorDataKey is the VM Metadata key of the cloud-init vendordata.
+	CloudInitVendorDataKey = "vendor-data"
+	// CloudInitNetworkConfigKey is the VM Metadata key of a cloud-init network configuration that is used
+	// instead of the one generated from the VM's network interfaces.
+	CloudInitNetworkConfigKey = "network-config"
+
+	// SysprepUnattendKey is the VM Metadata key of a raw Sysprep unattend XML answer file. When it is set,
+	// the other Sysprep keys are ignored.
+	SysprepUnattendKey            = "unattend"
+	SysprepAdminPasswordKey       = "admin-password" //nolint:gosec
+	SysprepJoinDomainKey          = "join-domain"
+	SysprepDomainAdminKey         = "domain-admin"
+	SysprepDomainAdminPasswordKey = "domain-admin-password" //nolint:gosec
+	SysprepJoinWorkgroupKey       = "join-workgroup"
+	SysprepTimeZoneKey            = "time-zone"
+	SysprepProductKeyKey          = "product-key"
+	SysprepFullNameKey            = "full-name"
+	SysprepOrgNameKey             = "org-name"
 
 	// InstanceStoragePVCNamePrefix prefix of auto-generated PVC names.
 	InstanceStoragePVCNamePrefix = "instance-pvc-"
@@ -10,12 +10,9 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	"github.com/vmware/govmomi/object"
-	"github.com/vmware/govmomi/task"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
-
-	"github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 )
 
 type VirtualMachine struct {
@@ -138,44 +135,6 @@ func (vm *VirtualMachine) UniqueID(ctx context.Context) (string, error) {
 	return vm.ReferenceValue(), nil
 }
 
-func (vm *VirtualMachine) SetPowerState(ctx context.Context, desiredPowerState v1alpha1.VirtualMachinePowerState) error {
-	vm.logger.V(5).Info("SetPowerState", "desiredState", desiredPowerState)
-
-	var powerTask *object.Task
-	var err error
-
-	switch desiredPowerState {
-	case v1alpha1.VirtualMachinePoweredOn:
-		powerTask, err = vm.vcVirtualMachine.PowerOn(ctx)
-	case v1alpha1.VirtualMachinePoweredOff:
-		powerTask, err = vm.vcVirtualMachine.PowerOff(ctx)
-	default:
-		err = fmt.Errorf("invalid desired power state %s", desiredPowerState)
-	}
-
-	if err != nil {
-		vm.logger.Error(err, "Failed to change VM power state", "desiredState", desiredPowerState)
-		return err
-	}
-
-	if taskInfo, err := powerTask.WaitForResult(ctx, nil); err != nil {
-		if te, ok := err.(task.Error); ok {
-			// Ignore error if VM was already in desired state.
-			if ips, ok := te.Fault().(*types.InvalidPowerStateFault); ok && ips.ExistingState == ips.RequestedState {
-				return nil
-			}
-		}
-		if taskInfo != nil {
-			err = errors.Wrapf(err, "%s failed", taskInfo.Name)
-		}
-
-		vm.logger.Error(err, "VM change power state task failed", "state", desiredPowerState)
-		return err
-	}
-
-	return nil
-}
-
 // GetVirtualDevices returns the VMs VirtualDeviceList.
 func (vm *VirtualMachine) GetVirtualDevices(ctx context.Context) (object.VirtualDeviceList, error) {
 	vm.logger.V(5).Info("GetVirtualDevices")
@@ -6,6 +6,7 @@ package session
 import (
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"k8s.io/utils/pointer"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vapi/library"
 	"github.com/vmware/govmomi/vapi/vcenter"
+	"github.com/vmware/govmomi/vim25/mo"
 	vimTypes "github.com/vmware/govmomi/vim25/types"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
@@ -32,10 +34,14 @@ type VMCreateArgs struct {
 	ResourcePolicy      *vmopv1alpha1.VirtualMachineSetResourcePolicy
 	VMMetadata          VMMetadata
 	ContentLibraryUUID  string
+	SourceVMMoID        string
 	StorageClassesToIDs map[string]string
 	StorageProvisioning string
 	HasInstanceStorage  bool
 
+	// SourceVMVolumeDiskUUIDs are the disk UUIDs of the source VM's Spec.Volumes, which are not cloned.
+	SourceVMVolumeDiskUUIDs map[string]struct{}
+
 	// From the ResourcePolicy if specified
 	ChildResourcePoolName string
 	ChildFolderName       string
@@ -116,6 +122,62 @@ func (s *Session) cloneVMFromInventory(
 		return nil, errors.Wrap(err, "failed to create CloneSpec")
 	}
 
+	return s.cloneVM(vmCtx, srcVM, srcVMName, cloneSpec)
+}
+
+// cloneVMFromVirtualMachine clones the VM from the VirtualMachine in the VM's Spec.Source.
+func (s *Session) cloneVMFromVirtualMachine(
+	vmCtx context.VirtualMachineContext,
+	createArgs *VMCreateArgs) (*object.VirtualMachine, error) {
+
+	source := vmCtx.VM.Spec.Source
+	srcVMName := source.VirtualMachineName
+
+	srcVM := object.NewVirtualMachine(s.Client.VimClient(),
+		vimTypes.ManagedObjectReference{Type: "VirtualMachine", Value: createArgs.SourceVMMoID})
+
+	cloneSpec, err := s.createCloneSpec(vmCtx, createArgs, srcVM)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create CloneSpec")
+	}
+
+	var o mo.VirtualMachine
+	if err := srcVM.Properties(vmCtx, srcVM.Reference(), []string{"snapshot", "config.extraConfig"}, &o); err != nil {
+		return nil, errors.Wrapf(err, "failed to get properties of clone source VM: %s", srcVMName)
+	}
+
+	if source.LinkedClone {
+		if o.Snapshot == nil || o.Snapshot.CurrentSnapshot == nil {
+			return nil, errors.Errorf("linked clone source VM %s does not have a snapshot", srcVMName)
+		}
+
+		// A linked clone creates a delta disk backed by the source VM's current snapshot for each of its disks.
+		cloneSpec.Snapshot = o.Snapshot.CurrentSnapshot
+		for i := range cloneSpec.Location.Disk {
+			cloneSpec.Location.Disk[i].DiskMoveType = string(vimTypes.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking)
+			cloneSpec.Location.Disk[i].DiskBackingInfo = nil
+		}
+	}
+
+	if source.ResetCustomization && o.Config != nil {
+		// Setting an ExtraConfig key to the empty string removes it from the clone.
+		for _, opt := range o.Config.ExtraConfig {
+			if ov := opt.GetOptionValue(); strings.HasPrefix(ov.Key, constants.ExtraConfigGuestInfoPrefix) {
+				cloneSpec.Config.ExtraConfig = append(cloneSpec.Config.ExtraConfig,
+					&vimTypes.OptionValue{Key: ov.Key, Value: ""})
+			}
+		}
+	}
+
+	return s.cloneVM(vmCtx, srcVM, srcVMName, cloneSpec)
+}
+
+func (s *Session) cloneVM(
+	vmCtx context.VirtualMachineContext,
+	srcVM *object.VirtualMachine,
+	srcVMName string,
+	cloneSpec *vimTypes.VirtualMachineCloneSpec) (*object.VirtualMachine, error) {
+
 	// We always set cloneSpec.Location.Folder so use that to get the parent folder object.
 	folder := object.NewFolder(s.Client.VimClient(), *cloneSpec.Location.Folder)
 
@@ -154,6 +216,10 @@ func (s *Session) CreateVirtualMachine(
 	vmCtx context.VirtualMachineContext,
 	createArgs *VMCreateArgs) (*object.VirtualMachine, error) {
 
+	if vmCtx.VM.Spec.Source != nil {
+		return s.cloneVMFromVirtualMachine(vmCtx, createArgs)
+	}
+
 	// The ContentLibraryUUID can be empty when we want to clone from inventory VMs. This is
 	// not a supported workflow but we have tests that use this.
 	if createArgs.ContentLibraryUUID != "" {
@@ -183,6 +249,19 @@ func (s *Session) createCloneSpec(
 
 	virtualDisks := virtualDevices.SelectByType((*vimTypes.VirtualDisk)(nil))
 
+	if vmCtx.VM.Spec.Source != nil {
+		// The disks of the source VM's volumes are PVCs managed by CNS, so the clone must not copy them.
+		// The clone gets the volumes in its own Spec.Volumes attached after it is created.
+		var volumeDisks object.VirtualDeviceList
+		virtualDisks, volumeDisks = splitVolumeDisks(virtualDisks, createArgs.SourceVMVolumeDiskUUIDs)
+		for _, disk := range volumeDisks {
+			cloneSpec.Config.DeviceChange = append(cloneSpec.Config.DeviceChange, &vimTypes.VirtualDeviceConfigSpec{
+				Operation: vimTypes.VirtualDeviceConfigSpecOperationRemove,
+				Device:    disk,
+			})
+		}
+	}
+
 	diskDeviceChanges, err := updateVirtualDiskDeviceChanges(vmCtx, virtualDisks)
 	if err != nil {
 		return nil, err
@@ -239,6 +318,43 @@ func (s *Session) createCloneSpec(
 	return cloneSpec, nil
 }
 
+// splitVolumeDisks splits the disks into the disks that are part of the VM itself and the disks that back the
+// VM's volumes. A volume disk is either a first class disk, or a disk with its UUID in volumeDiskUUIDs.
+func splitVolumeDisks(
+	disks object.VirtualDeviceList,
+	volumeDiskUUIDs map[string]struct{}) (object.VirtualDeviceList, object.VirtualDeviceList) {
+
+	var vmDisks, volumeDisks object.VirtualDeviceList
+	for _, dev := range disks {
+		disk, ok := dev.(*vimTypes.VirtualDisk)
+		if !ok {
+			continue
+		}
+
+		isVolume := disk.VDiskId != nil && disk.VDiskId.Id != ""
+		if !isVolume {
+			var uuid string
+			switch backing := disk.Backing.(type) {
+			case *vimTypes.VirtualDiskFlatVer2BackingInfo:
+				uuid = backing.Uuid
+			case *vimTypes.VirtualDiskSeSparseBackingInfo:
+				uuid = backing.Uuid
+			}
+			if uuid != "" {
+				_, isVolume = volumeDiskUUIDs[uuid]
+			}
+		}
+
+		if isVolume {
+			volumeDisks = append(volumeDisks, disk)
+		} else {
+			vmDisks = append(vmDisks, disk)
+		}
+	}
+
+	return vmDisks, volumeDisks
+}
+
 func cloneVMDiskLocators(
 	disks object.VirtualDeviceList,
 	createArgs *VMCreateArgs,
@@ -5,9 +5,14 @@ package session
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net"
+	"net/textproto"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -63,6 +68,116 @@ func GetLinuxPrepCustSpec(vmName string, updateArgs VMUpdateArgs) *vimTypes.Cust
 	}
 }
 
+const (
+	// sysprepMaxComputerNameLen is the maximum length of a Windows computer (NetBIOS) name.
+	sysprepMaxComputerNameLen = 15
+	// sysprepDefaultTimeZone is the Microsoft time zone index of "(GMT) Greenwich Mean Time".
+	sysprepDefaultTimeZone  = 85
+	sysprepDefaultWorkgroup = "WORKGROUP"
+	sysprepDefaultFullName  = "Administrator"
+)
+
+// sysprepComputerName returns the Windows computer name for the VM name. A computer name may only contain
+// letters, digits, and hyphens, and cannot end with a hyphen, so the other characters of the VM name, like
+// periods, are removed before the name is truncated.
+func sysprepComputerName(vmName string) string {
+	computerName := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return -1
+	}, vmName)
+
+	if len(computerName) > sysprepMaxComputerNameLen {
+		computerName = computerName[:sysprepMaxComputerNameLen]
+	}
+	return strings.TrimRight(computerName, "-")
+}
+
+// GetSysprepCustSpec returns the Sysprep CustomizationSpec for a Windows guest. The raw unattend XML from
+// the VM Metadata is used as-is when present, otherwise the Sysprep answers are built from the VM Metadata.
+func GetSysprepCustSpec(vmName string, updateArgs VMUpdateArgs) (*vimTypes.CustomizationSpec, error) {
+	data := updateArgs.VMMetadata.Data
+
+	custSpec := &vimTypes.CustomizationSpec{
+		GlobalIPSettings: vimTypes.CustomizationGlobalIPSettings{
+			DnsServerList: updateArgs.DNSServers,
+		},
+		NicSettingMap: updateArgs.NetIfList.GetInterfaceCustomizations(),
+	}
+
+	if unattend := data[constants.SysprepUnattendKey]; unattend != "" {
+		custSpec.Identity = &vimTypes.CustomizationSysprepText{
+			Value: unattend,
+		}
+		return custSpec, nil
+	}
+
+	computerName := sysprepComputerName(vmName)
+
+	timeZone := int32(sysprepDefaultTimeZone)
+	if tz := data[constants.SysprepTimeZoneKey]; tz != "" {
+		v, err := strconv.ParseInt(tz, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Sysprep %s %q: %v", constants.SysprepTimeZoneKey, tz, err)
+		}
+		timeZone = int32(v)
+	}
+
+	sysprep := &vimTypes.CustomizationSysprep{
+		GuiUnattended: vimTypes.CustomizationGuiUnattended{
+			TimeZone:  timeZone,
+			AutoLogon: false,
+		},
+		UserData: vimTypes.CustomizationUserData{
+			FullName:     data[constants.SysprepFullNameKey],
+			OrgName:      data[constants.SysprepOrgNameKey],
+			ComputerName: &vimTypes.CustomizationFixedName{Name: computerName},
+			ProductId:    data[constants.SysprepProductKeyKey],
+		},
+	}
+
+	if sysprep.UserData.FullName == "" {
+		sysprep.UserData.FullName = sysprepDefaultFullName
+	}
+
+	if password := data[constants.SysprepAdminPasswordKey]; password != "" {
+		sysprep.GuiUnattended.Password = &vimTypes.CustomizationPassword{
+			Value:     password,
+			PlainText: true,
+		}
+	}
+
+	if domain := data[constants.SysprepJoinDomainKey]; domain != "" {
+		domainAdmin := data[constants.SysprepDomainAdminKey]
+		domainAdminPassword := data[constants.SysprepDomainAdminPasswordKey]
+		if domainAdmin == "" || domainAdminPassword == "" {
+			return nil, fmt.Errorf("joining Sysprep domain %s requires %s and %s",
+				domain, constants.SysprepDomainAdminKey, constants.SysprepDomainAdminPasswordKey)
+		}
+
+		sysprep.Identification = vimTypes.CustomizationIdentification{
+			JoinDomain:  domain,
+			DomainAdmin: domainAdmin,
+			DomainAdminPassword: &vimTypes.CustomizationPassword{
+				Value:     domainAdminPassword,
+				PlainText: true,
+			},
+		}
+	} else {
+		workgroup := data[constants.SysprepJoinWorkgroupKey]
+		if workgroup == "" {
+			workgroup = sysprepDefaultWorkgroup
+		}
+		sysprep.Identification = vimTypes.CustomizationIdentification{
+			JoinWorkgroup: workgroup,
+		}
+	}
+
+	custSpec.Identity = sysprep
+	return custSpec, nil
+}
+
 type CloudInitMetadata struct {
 	InstanceID    string          `yaml:"instance-id,omitempty"`
 	LocalHostname string          `yaml:"local-hostname,omitempty"`
@@ -83,27 +198,207 @@ func GetCloudInitMetadata(vm *v1alpha1.VirtualMachine,
 		PublicKeys:    data["ssh-public-keys"],
 	}
 
+	networkConfig, err := getCloudInitNetworkConfig(data)
+	if err != nil {
+		return "", err
+	}
+
 	metadataBytes, err := yaml.Marshal(metadataObj)
 	if err != nil {
 		return "", fmt.Errorf("yaml marshalling of cloud-init metadata failed %v", err)
 	}
 
+	if networkConfig != nil {
+		// The user provided network config is not restricted to what the Netplan type models, so
+		// replace the generated network in the marshalled metadata instead.
+		var metadata yaml.MapSlice
+		if err := yaml.Unmarshal(metadataBytes, &metadata); err != nil {
+			return "", fmt.Errorf("yaml unmarshalling of cloud-init metadata failed %v", err)
+		}
+
+		for i := range metadata {
+			if metadata[i].Key == "network" {
+				metadata[i].Value = networkConfig
+				break
+			}
+		}
+
+		metadataBytes, err = yaml.Marshal(metadata)
+		if err != nil {
+			return "", fmt.Errorf("yaml marshalling of cloud-init metadata failed %v", err)
+		}
+	}
+
 	return string(metadataBytes), nil
 }
 
-func GetCloudInitPrepCustSpec(
-	cloudInitMetadata string,
-	updateArgs VMUpdateArgs) (*vimTypes.CustomizationSpec, error) {
+// getCloudInitNetworkConfig returns the parsed network config from the "network-config" key, or nil if the
+// key is not set. Like cloud-init's network-config file, the config may be nested under a "network" key.
+func getCloudInitNetworkConfig(data map[string]string) (interface{}, error) {
+	rawNetworkConfig := data[constants.CloudInitNetworkConfigKey]
+	if rawNetworkConfig == "" {
+		return nil, nil
+	}
 
-	userdata := updateArgs.VMMetadata.Data["user-data"]
+	plainText, err := util.TryToDecodeBase64Gzip([]byte(rawNetworkConfig))
+	if err != nil {
+		return nil, fmt.Errorf("decoding cloud-init network config failed %v", err)
+	}
 
-	if userdata != "" {
+	var networkConfig yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(plainText), &networkConfig); err != nil {
+		return nil, fmt.Errorf("yaml unmarshalling of cloud-init network config failed %v", err)
+	}
+
+	if len(networkConfig) == 1 && networkConfig[0].Key == "network" {
+		return networkConfig[0].Value, nil
+	}
+	return networkConfig, nil
+}
+
+// cloudInitMergeType is the Merge-Type of the cloud-config parts of a MIME multipart userdata so that
+// lists are appended to and dictionaries are recursively merged instead of later parts replacing them.
+const cloudInitMergeType = "list(append)+dict(no_replace,recurse_list)+str()"
+
+// cloudInitContentTypes maps the cloud-init userdata format's starting line to its MIME content type. The
+// order matters since some of the starting lines are prefixes of others.
+var cloudInitContentTypes = []struct {
+	prefix      string
+	contentType string
+}{
+	{"#cloud-config-archive", "text/cloud-config-archive"},
+	{"#cloud-config", "text/cloud-config"},
+	{"#cloud-boothook", "text/cloud-boothook"},
+	{"#include", "text/x-include-url"},
+	{"#part-handler", "text/part-handler"},
+	{"## template: jinja", "text/jinja2"},
+	{"#!", "text/x-shellscript"},
+}
+
+func getCloudInitContentType(userdata string) string {
+	for _, ct := range cloudInitContentTypes {
+		if strings.HasPrefix(userdata, ct.prefix) {
+			return ct.contentType
+		}
+	}
+	return "text/plain"
+}
+
+// GetCloudInitUserdata returns the plain-text cloud-init userdata from the VM Metadata. The userdata is
+// the "user-data" key or, to support CAPBK's bootstrap data Secret, the "value" key. When there are keys
+// prefixed with "user-data.", the userdata and those parts - in lexical order of their keys - are merged
+// into a MIME multipart archive.
+func GetCloudInitUserdata(data map[string]string) (string, error) {
+	return getCloudInitUserdata(data, false)
+}
+
+// GetCloudInitPrepUserdata returns the plain-text cloud-init userdata for the CloudInitPrep customization,
+// which has no separate vendordata. The "vendor-data" key, if any, is merged into the userdata as the last
+// MIME part. The cloud-config parts are merged without replacing keys, so the userdata takes precedence
+// over the vendordata like it does when cloud-init gets the vendordata separately.
+func GetCloudInitPrepUserdata(data map[string]string) (string, error) {
+	return getCloudInitUserdata(data, true)
+}
+
+func getCloudInitUserdata(data map[string]string, withVendorData bool) (string, error) {
+	type part struct {
+		name string
+		data string
+	}
+	var parts []part
+
+	// Check for the 'user-data' key as per official contract and API documentation.
+	// Additionally, To support the cluster bootstrap data supplied by CAPBK's secret,
+	// we check for a 'value' key when 'user-data' is not supplied. The 'value' key
+	// lookup will eventually be deprecated.
+	if userdata := data[constants.CloudInitUserDataKey]; userdata != "" {
+		parts = append(parts, part{name: constants.CloudInitUserDataKey, data: userdata})
+	} else if value := data["value"]; value != "" {
+		parts = append(parts, part{name: "value", data: value})
+	}
+
+	partKeys := make([]string, 0)
+	for k, v := range data {
+		if strings.HasPrefix(k, constants.CloudInitUserDataPartKeyPrefix) && v != "" {
+			partKeys = append(partKeys, k)
+		}
+	}
+	sort.Strings(partKeys)
+	for _, k := range partKeys {
+		parts = append(parts, part{name: k, data: data[k]})
+	}
+
+	if vendordata := data[constants.CloudInitVendorDataKey]; withVendorData && vendordata != "" {
+		parts = append(parts, part{name: constants.CloudInitVendorDataKey, data: vendordata})
+	}
+
+	for i := range parts {
 		// Ensure the data is normalized first to plain-text.
-		plainText, err := util.TryToDecodeBase64Gzip([]byte(userdata))
+		plainText, err := util.TryToDecodeBase64Gzip([]byte(parts[i].data))
 		if err != nil {
-			return nil, fmt.Errorf("decoding cloud-init prep userdata failed %v", err)
+			return "", fmt.Errorf("decoding cloud-init userdata %q failed %v", parts[i].name, err)
 		}
-		userdata = plainText
+		parts[i].data = plainText
+	}
+
+	switch len(parts) {
+	case 0:
+		return "", nil
+	case 1:
+		return parts[0].data, nil
+	}
+
+	// Derive the boundary from the parts so the userdata is stable across reconciles.
+	hash := sha256.New()
+	for _, p := range parts {
+		if strings.HasPrefix(p.data, "Content-Type: multipart/") {
+			return "", fmt.Errorf("cloud-init userdata %q is already a MIME multipart archive and cannot be merged", p.name)
+		}
+		_, _ = hash.Write([]byte(p.name))
+		_, _ = hash.Write([]byte(p.data))
+	}
+	boundary := fmt.Sprintf("%x", hash.Sum(nil))[:32]
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\nMIME-Version: 1.0\r\n\r\n", boundary)
+
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return "", err
+	}
+
+	for _, p := range parts {
+		contentType := getCloudInitContentType(p.data)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", fmt.Sprintf("%s; charset=\"utf-8\"", contentType))
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", p.name))
+		if contentType == "text/cloud-config" {
+			header.Set("Merge-Type", cloudInitMergeType)
+		}
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return "", err
+		}
+		if _, err := w.Write([]byte(p.data)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func GetCloudInitPrepCustSpec(
+	cloudInitMetadata string,
+	updateArgs VMUpdateArgs) (*vimTypes.CustomizationSpec, error) {
+
+	userdata, err := GetCloudInitPrepUserdata(updateArgs.VMMetadata.Data)
+	if err != nil {
+		return nil, fmt.Errorf("cloud-init prep userdata failed %v", err)
 	}
 
 	return &vimTypes.CustomizationSpec{
@@ -128,31 +423,35 @@ func GetCloudInitGuestInfoCustSpec(
 	extraConfig[constants.CloudInitGuestInfoMetadata] = encodedMetadata
 	extraConfig[constants.CloudInitGuestInfoMetadataEncoding] = "gzip+base64"
 
-	var data string
-	// Check for the 'user-data' key as per official contract and API documentation.
-	// Additionally, To support the cluster bootstrap data supplied by CAPBK's secret,
-	// we check for a 'value' key when 'user-data' is not supplied. The 'value' key
-	// lookup will eventually be deprecated.
-	if userdata := updateArgs.VMMetadata.Data["user-data"]; userdata != "" {
-		data = userdata
-	} else if value := updateArgs.VMMetadata.Data["value"]; value != "" {
-		data = value
+	userdata, err := GetCloudInitUserdata(updateArgs.VMMetadata.Data)
+	if err != nil {
+		return nil, err
 	}
 
-	if data != "" {
+	if userdata != "" {
+		encodedUserdata, err := EncodeGzipBase64(userdata)
+		if err != nil {
+			return nil, fmt.Errorf("encoding cloud-init userdata failed %v", err)
+		}
+
+		extraConfig[constants.CloudInitGuestInfoUserdata] = encodedUserdata
+		extraConfig[constants.CloudInitGuestInfoUserdataEncoding] = "gzip+base64"
+	}
+
+	if vendordata := updateArgs.VMMetadata.Data[constants.CloudInitVendorDataKey]; vendordata != "" {
 		// Ensure the data is normalized first to plain-text.
-		plainText, err := util.TryToDecodeBase64Gzip([]byte(data))
+		plainText, err := util.TryToDecodeBase64Gzip([]byte(vendordata))
 		if err != nil {
-			return nil, fmt.Errorf("decoding cloud-init userdata failed %v", err)
+			return nil, fmt.Errorf("decoding cloud-init vendordata failed %v", err)
 		}
 
-		encodedUserdata, err := EncodeGzipBase64(plainText)
+		encodedVendordata, err := EncodeGzipBase64(plainText)
 		if err != nil {
-			return nil, fmt.Errorf("encoding cloud-init userdata failed %v", err)
+			return nil, fmt.Errorf("encoding cloud-init vendordata failed %v", err)
 		}
 
-		extraConfig[constants.CloudInitGuestInfoUserdata] = encodedUserdata
-		extraConfig[constants.CloudInitGuestInfoUserdataEncoding] = "gzip+base64"
+		extraConfig[constants.CloudInitGuestInfoVendordata] = encodedVendordata
+		extraConfig[constants.CloudInitGuestInfoVendordataEncoding] = "gzip+base64"
 	}
 
 	configSpec := &vimTypes.VirtualMachineConfigSpec{}
@@ -166,6 +465,108 @@ func GetCloudInitGuestInfoCustSpec(
 	return configSpec, nil
 }
 
+// GetAfterburnNetworkKargs returns the dracut network kernel arguments that Afterburn applies in the
+// initramfs of Ignition guests. Each interface keeps its netplan name, i.e. eth0, eth1, and so on, and
+// is bound to its MAC address. An interface is either configured with DHCP or with its static addresses.
+// The netplan only has an IPv4 gateway, so static IPv6 addresses are configured without a gateway.
+func GetAfterburnNetworkKargs(hostname string, netplan network.Netplan, dnsServers []string) string {
+	names := make([]string, 0, len(netplan.Ethernets))
+	for name := range netplan.Ethernets {
+		names = append(names, name)
+	}
+	// Sort the names numerically so that eth2 comes before eth10.
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	var kargs []string
+	for _, name := range names {
+		eth := netplan.Ethernets[name]
+
+		if eth.Match.MacAddress != "" {
+			kargs = append(kargs, fmt.Sprintf("ifname=%s:%s", name, eth.Match.MacAddress))
+		}
+
+		if eth.Dhcp4 || len(eth.Addresses) == 0 {
+			kargs = append(kargs, fmt.Sprintf("ip=%s:dhcp", name))
+			continue
+		}
+
+		for _, addr := range eth.Addresses {
+			ip, ipNet, err := net.ParseCIDR(addr)
+			if err != nil {
+				continue
+			}
+			prefix, _ := ipNet.Mask.Size()
+
+			var gateway string
+			if ip.To4() != nil {
+				gateway = eth.Gateway4
+			}
+
+			kargs = append(kargs, fmt.Sprintf("ip=%s::%s:%d:%s:%s:none",
+				afterburnIP(ip.String()), afterburnIP(gateway), prefix, hostname, name))
+		}
+	}
+
+	for _, dns := range dnsServers {
+		kargs = append(kargs, "nameserver="+afterburnIP(dns))
+	}
+
+	return strings.Join(kargs, " ")
+}
+
+// afterburnIP returns the IP in the dracut kernel argument format, where IPv6 addresses are bracketed.
+func afterburnIP(ip string) string {
+	if strings.Contains(ip, ":") {
+		return "[" + ip + "]"
+	}
+	return ip
+}
+
+func GetIgnitionGuestInfoCustSpec(
+	afterburnNetworkKargs string,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs VMUpdateArgs) (*vimTypes.VirtualMachineConfigSpec, error) {
+
+	extraConfig := map[string]string{}
+
+	// To support the cluster bootstrap data supplied by CAPBK's secret, we check for a
+	// 'value' key when 'ignition' is not supplied.
+	data := updateArgs.VMMetadata.Data[constants.IgnitionConfigKey]
+	if data == "" {
+		data = updateArgs.VMMetadata.Data["value"]
+	}
+
+	if data != "" {
+		// Ensure the data is normalized first to plain-text.
+		plainText, err := util.TryToDecodeBase64Gzip([]byte(data))
+		if err != nil {
+			return nil, fmt.Errorf("decoding ignition config failed %v", err)
+		}
+
+		encodedConfig, err := EncodeGzipBase64(plainText)
+		if err != nil {
+			return nil, fmt.Errorf("encoding ignition config failed %v", err)
+		}
+
+		extraConfig[constants.IgnitionGuestInfoConfigData] = encodedConfig
+		extraConfig[constants.IgnitionGuestInfoConfigDataEncoding] = "gzip+base64"
+	}
+
+	if afterburnNetworkKargs != "" {
+		extraConfig[constants.AfterburnGuestInfoNetworkKargs] = afterburnNetworkKargs
+	}
+
+	configSpec := &vimTypes.VirtualMachineConfigSpec{}
+	configSpec.ExtraConfig = MergeExtraConfig(config.ExtraConfig, extraConfig)
+
+	return configSpec, nil
+}
+
 func GetExtraConfigCustSpec(
 	config *vimTypes.VirtualMachineConfigInfo,
 	updateArgs VMUpdateArgs) *vimTypes.VirtualMachineConfigSpec {
@@ -240,11 +641,30 @@ func customizeCloudInit(
 	return configSpec, custSpec, nil
 }
 
-func (s *Session) customize(
+func customizeIgnition(
 	vmCtx context.VirtualMachineContext,
 	resVM *res.VirtualMachine,
 	config *vimTypes.VirtualMachineConfigInfo,
-	updateArgs VMUpdateArgs) error {
+	updateArgs VMUpdateArgs) (*vimTypes.VirtualMachineConfigSpec, error) {
+
+	ethCards, err := resVM.GetNetworkDevices(vmCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	netplan := updateArgs.NetIfList.GetNetplan(ethCards, updateArgs.DNSServers)
+	networkKargs := GetAfterburnNetworkKargs(vmCtx.VM.Name, netplan, updateArgs.DNSServers)
+
+	return GetIgnitionGuestInfoCustSpec(networkKargs, config, updateArgs)
+}
+
+// customizationSpecs returns the ConfigSpec and CustomizationSpec for the VM's metadata transport. Either or
+// both may be nil.
+func customizationSpecs(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs VMUpdateArgs) (*vimTypes.VirtualMachineConfigSpec, *vimTypes.CustomizationSpec, error) {
 
 	if lib.IsVMServicePublicCloudBYOIFSSEnabled() {
 		TemplateVMMetadata(vmCtx, updateArgs)
@@ -267,25 +687,83 @@ func (s *Session) customize(
 	case v1alpha1.VirtualMachineMetadataExtraConfigTransport:
 		configSpec = GetExtraConfigCustSpec(config, updateArgs)
 		custSpec = GetLinuxPrepCustSpec(vmCtx.VM.Name, updateArgs)
+	case v1alpha1.VirtualMachineMetadataSysprepTransport:
+		custSpec, err = GetSysprepCustSpec(vmCtx.VM.Name, updateArgs)
+	case v1alpha1.VirtualMachineMetadataIgnitionTransport:
+		configSpec, err = customizeIgnition(vmCtx, resVM, config, updateArgs)
 	default:
 		custSpec = GetLinuxPrepCustSpec(vmCtx.VM.Name, updateArgs)
 	}
 
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	if configSpec != nil {
-		defaultConfigSpec := &vimTypes.VirtualMachineConfigSpec{}
-		if !apiEquality.Semantic.DeepEqual(configSpec, defaultConfigSpec) {
-			vmCtx.Logger.Info("Customization Reconfigure", "configSpec", configSpec)
-			if err := resVM.Reconfigure(vmCtx, configSpec); err != nil {
-				vmCtx.Logger.Error(err, "customization reconfigure failed")
-				return err
-			}
+	return configSpec, custSpec, nil
+}
+
+// reconfigureCustomization reconfigures the VM with the customization ConfigSpec if it changes anything.
+func reconfigureCustomization(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine,
+	configSpec *vimTypes.VirtualMachineConfigSpec) error {
+
+	if configSpec == nil {
+		return nil
+	}
+
+	defaultConfigSpec := &vimTypes.VirtualMachineConfigSpec{}
+	if !apiEquality.Semantic.DeepEqual(configSpec, defaultConfigSpec) {
+		vmCtx.Logger.Info("Customization Reconfigure", "configSpec", configSpec)
+		if err := resVM.Reconfigure(vmCtx, configSpec); err != nil {
+			vmCtx.Logger.Error(err, "customization reconfigure failed")
+			return err
 		}
 	}
 
+	return nil
+}
+
+// vmMetadataChanged returns true if the data of the VM's metadata ConfigMap or Secret changed since the metadata
+// was last applied to the VM.
+func vmMetadataChanged(vm *v1alpha1.VirtualMachine, updateArgs VMUpdateArgs) bool {
+	applied := vm.Status.VmMetadataHash
+	return applied != "" && applied != updateArgs.VMMetadata.Hash()
+}
+
+// customizationConfig returns the VM config the customization is derived from. When the VM's metadata changed,
+// the existing ExtraConfig is omitted so the metadata's keys are reapplied instead of keeping the values that
+// might have been changed by the VM. Keys that were removed from the metadata are not removed from the VM's
+// ExtraConfig since the previously applied keys are not known, so they persist with their last value.
+func customizationConfig(
+	vm *v1alpha1.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs VMUpdateArgs) *vimTypes.VirtualMachineConfigInfo {
+
+	if !vmMetadataChanged(vm, updateArgs) {
+		return config
+	}
+
+	c := *config
+	c.ExtraConfig = nil
+	return &c
+}
+
+func (s *Session) customize(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs VMUpdateArgs) error {
+
+	configSpec, custSpec, err := customizationSpecs(vmCtx, resVM, customizationConfig(vmCtx.VM, config, updateArgs), updateArgs)
+	if err != nil {
+		return err
+	}
+
+	if err := reconfigureCustomization(vmCtx, resVM, configSpec); err != nil {
+		return err
+	}
+
 	if custSpec != nil {
 		if vmCtx.VM.Annotations[constants.VSphereCustomizationBypassKey] == constants.VSphereCustomizationBypassDisable {
 			vmCtx.Logger.Info("Skipping vsphere customization because of vsphere-customization bypass annotation")
@@ -7,6 +7,12 @@ import (
 	goctx "context"
 	"encoding/base64"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -23,6 +29,7 @@ import (
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/internal"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/network"
@@ -188,6 +195,137 @@ var _ = Describe("Customization via Cust Spec", func() {
 		})
 	})
 
+	Context("GetSysprepCustSpec", func() {
+		var err error
+
+		BeforeEach(func() {
+			updateArgs.VMMetadata = session.VMMetadata{
+				Data: map[string]string{},
+			}
+		})
+
+		JustBeforeEach(func() {
+			custSpec, err = session.GetSysprepCustSpec(vmName, updateArgs)
+		})
+
+		It("should return sysprep customization spec", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(custSpec).ToNot(BeNil())
+			Expect(custSpec.GlobalIPSettings.DnsServerList).To(Equal(updateArgs.DNSServers))
+			Expect(custSpec.NicSettingMap).To(Equal([]vimTypes.CustomizationAdapterMapping{*customizationAdaptorMapping}))
+			sysprep := custSpec.Identity.(*vimTypes.CustomizationSysprep)
+			computerName := sysprep.UserData.ComputerName.(*vimTypes.CustomizationFixedName).Name
+			Expect(computerName).To(Equal(vmName))
+			Expect(sysprep.UserData.FullName).To(Equal("Administrator"))
+			Expect(sysprep.GuiUnattended.Password).To(BeNil())
+			Expect(sysprep.Identification.JoinWorkgroup).To(Equal("WORKGROUP"))
+		})
+
+		When("the VM name is longer than a computer name", func() {
+			BeforeEach(func() {
+				vmName = "dummy-vm-with-a-long-name"
+			})
+
+			AfterEach(func() {
+				vmName = "dummy-vm"
+			})
+
+			It("should truncate the computer name", func() {
+				Expect(err).ToNot(HaveOccurred())
+				sysprep := custSpec.Identity.(*vimTypes.CustomizationSysprep)
+				computerName := sysprep.UserData.ComputerName.(*vimTypes.CustomizationFixedName).Name
+				Expect(computerName).To(Equal("dummy-vm-with-a"))
+			})
+		})
+
+		When("the VM name has characters that are not valid in a computer name", func() {
+			BeforeEach(func() {
+				vmName = "dummy.vm.1"
+			})
+
+			AfterEach(func() {
+				vmName = "dummy-vm"
+			})
+
+			It("should remove the characters from the computer name", func() {
+				Expect(err).ToNot(HaveOccurred())
+				sysprep := custSpec.Identity.(*vimTypes.CustomizationSysprep)
+				computerName := sysprep.UserData.ComputerName.(*vimTypes.CustomizationFixedName).Name
+				Expect(computerName).To(Equal("dummyvm1"))
+			})
+		})
+
+		When("the truncated computer name ends with a hyphen", func() {
+			BeforeEach(func() {
+				vmName = "dummy-vm-with--long-name"
+			})
+
+			AfterEach(func() {
+				vmName = "dummy-vm"
+			})
+
+			It("should remove the trailing hyphens from the computer name", func() {
+				Expect(err).ToNot(HaveOccurred())
+				sysprep := custSpec.Identity.(*vimTypes.CustomizationSysprep)
+				computerName := sysprep.UserData.ComputerName.(*vimTypes.CustomizationFixedName).Name
+				Expect(computerName).To(Equal("dummy-vm-with"))
+			})
+		})
+
+		When("the admin password, domain and time zone are specified", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data[constants.SysprepAdminPasswordKey] = "admin-password"
+				updateArgs.VMMetadata.Data[constants.SysprepJoinDomainKey] = "example.com"
+				updateArgs.VMMetadata.Data[constants.SysprepDomainAdminKey] = "domain-admin"
+				updateArgs.VMMetadata.Data[constants.SysprepDomainAdminPasswordKey] = "domain-admin-password"
+				updateArgs.VMMetadata.Data[constants.SysprepTimeZoneKey] = "4"
+			})
+
+			It("should set them in the sysprep customization spec", func() {
+				Expect(err).ToNot(HaveOccurred())
+				sysprep := custSpec.Identity.(*vimTypes.CustomizationSysprep)
+				Expect(sysprep.GuiUnattended.Password).To(Equal(&vimTypes.CustomizationPassword{Value: "admin-password", PlainText: true}))
+				Expect(sysprep.GuiUnattended.TimeZone).To(BeEquivalentTo(4))
+				Expect(sysprep.Identification.JoinDomain).To(Equal("example.com"))
+				Expect(sysprep.Identification.DomainAdmin).To(Equal("domain-admin"))
+				Expect(sysprep.Identification.DomainAdminPassword).To(Equal(&vimTypes.CustomizationPassword{Value: "domain-admin-password", PlainText: true}))
+				Expect(sysprep.Identification.JoinWorkgroup).To(BeEmpty())
+			})
+		})
+
+		When("the domain is specified without the domain admin", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data[constants.SysprepJoinDomainKey] = "example.com"
+			})
+
+			It("should return an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		When("the time zone is invalid", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data[constants.SysprepTimeZoneKey] = "GMT"
+			})
+
+			It("should return an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		When("the raw unattend XML is specified", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data[constants.SysprepUnattendKey] = "<unattend/>"
+				updateArgs.VMMetadata.Data[constants.SysprepAdminPasswordKey] = "admin-password"
+			})
+
+			It("should use the unattend XML as-is", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(custSpec.Identity).To(Equal(&vimTypes.CustomizationSysprepText{Value: "<unattend/>"}))
+				Expect(custSpec.NicSettingMap).To(Equal([]vimTypes.CustomizationAdapterMapping{*customizationAdaptorMapping}))
+			})
+		})
+	})
 })
 
 var _ = Describe("CloudInitmetadata", func() {
@@ -245,6 +383,172 @@ var _ = Describe("CloudInitmetadata", func() {
 		Expect(metadata.Network).To(Equal(netplan))
 		Expect(metadata.PublicKeys).To(Equal(publicKeys))
 	})
+
+	Context("With network-config", func() {
+		const networkConfig = `version: 2
+ethernets:
+  eth0:
+    dhcp6: true
+    routes:
+    - to: 10.0.0.0/8
+      via: 192.168.1.254
+`
+
+		expectNetworkConfig := func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			metadata := map[string]interface{}{}
+			Expect(yaml.Unmarshal([]byte(metadataString), &metadata)).To(Succeed())
+			Expect(metadata).To(HaveKeyWithValue("instance-id", string(vm.UID)))
+			Expect(metadata).To(HaveKeyWithValue("public-keys", publicKeys))
+
+			expected := map[interface{}]interface{}{}
+			Expect(yaml.Unmarshal([]byte(networkConfig), &expected)).To(Succeed())
+			Expect(metadata).To(HaveKeyWithValue("network", expected))
+		}
+
+		Context("Plain network-config", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data["network-config"] = networkConfig
+			})
+
+			It("Overrides the generated network", func() {
+				expectNetworkConfig()
+			})
+		})
+
+		Context("Base64-encoded network-config nested under a network key", func() {
+			BeforeEach(func() {
+				nested := "network:\n" + "  " + strings.ReplaceAll(strings.TrimSpace(networkConfig), "\n", "\n  ") + "\n"
+				updateArgs.VMMetadata.Data["network-config"] = base64.StdEncoding.EncodeToString([]byte(nested))
+			})
+
+			It("Overrides the generated network", func() {
+				expectNetworkConfig()
+			})
+		})
+
+		Context("Invalid network-config", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data["network-config"] = "not: [valid"
+			})
+
+			It("Returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("cloud-init network config"))
+			})
+		})
+	})
+})
+
+var _ = Describe("GetCloudInitUserdata", func() {
+	var (
+		data     map[string]string
+		userdata string
+		err      error
+	)
+
+	BeforeEach(func() {
+		data = map[string]string{}
+	})
+
+	JustBeforeEach(func() {
+		userdata, err = session.GetCloudInitUserdata(data)
+	})
+
+	type part struct {
+		filename string
+		header   textproto.MIMEHeader
+		body     string
+	}
+
+	readParts := func(userdata string) []part {
+		msg, err := mail.ReadMessage(strings.NewReader(userdata))
+		Expect(err).ToNot(HaveOccurred())
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mediaType).To(Equal("multipart/mixed"))
+
+		var parts []part
+		reader := multipart.NewReader(msg.Body, params["boundary"])
+		for {
+			p, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			body, err := io.ReadAll(p)
+			Expect(err).ToNot(HaveOccurred())
+			parts = append(parts, part{filename: p.FileName(), header: p.Header, body: string(body)})
+		}
+		return parts
+	}
+
+	Context("No userdata", func() {
+		It("Returns empty userdata", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(userdata).To(BeEmpty())
+		})
+	})
+
+	Context("Only user-data", func() {
+		BeforeEach(func() {
+			data["user-data"] = base64.StdEncoding.EncodeToString([]byte("#cloud-config\nruncmd: []\n"))
+		})
+
+		It("Returns the decoded user-data as is", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(userdata).To(Equal("#cloud-config\nruncmd: []\n"))
+		})
+	})
+
+	Context("user-data with additional parts", func() {
+		BeforeEach(func() {
+			data["user-data"] = "#cloud-config\npackages: [git]\n"
+			data["user-data.20-script"] = "#!/bin/sh\necho hello\n"
+			data["user-data.10-packages"] = base64.StdEncoding.EncodeToString([]byte("#cloud-config\npackages: [vim]\n"))
+			data["vendor-data"] = "#cloud-config\n"
+		})
+
+		It("Returns a MIME multipart archive of the parts in order", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			parts := readParts(userdata)
+			Expect(parts).To(HaveLen(3))
+
+			Expect(parts[0].filename).To(Equal("user-data"))
+			Expect(parts[0].header.Get("Content-Type")).To(HavePrefix("text/cloud-config"))
+			Expect(parts[0].header.Get("Merge-Type")).To(Equal("list(append)+dict(no_replace,recurse_list)+str()"))
+			Expect(parts[0].body).To(Equal("#cloud-config\npackages: [git]\n"))
+
+			Expect(parts[1].filename).To(Equal("user-data.10-packages"))
+			Expect(parts[1].header.Get("Content-Type")).To(HavePrefix("text/cloud-config"))
+			Expect(parts[1].body).To(Equal("#cloud-config\npackages: [vim]\n"))
+
+			Expect(parts[2].filename).To(Equal("user-data.20-script"))
+			Expect(parts[2].header.Get("Content-Type")).To(HavePrefix("text/x-shellscript"))
+			Expect(parts[2].header.Get("Merge-Type")).To(BeEmpty())
+			Expect(parts[2].body).To(Equal("#!/bin/sh\necho hello\n"))
+		})
+
+		It("Returns the same archive every time", func() {
+			Expect(err).ToNot(HaveOccurred())
+			again, err := session.GetCloudInitUserdata(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(again).To(Equal(userdata))
+		})
+	})
+
+	Context("A part that is already a MIME multipart archive", func() {
+		BeforeEach(func() {
+			data["user-data"] = "Content-Type: multipart/mixed; boundary=\"abc\"\n"
+			data["user-data.10-script"] = "#!/bin/sh\n"
+		})
+
+		It("Returns an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
 
 var _ = Describe("Cloud-Init Customization", func() {
@@ -358,6 +662,23 @@ var _ = Describe("Cloud-Init Customization", func() {
 			})
 		})
 
+		Context("With vendordata", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data["vendor-data"] = base64.StdEncoding.EncodeToString([]byte("#cloud-config\n"))
+			})
+			It("ConfigSpec.ExtraConfig to have metadata and vendordata", func() {
+				Expect(configSpec).ToNot(BeNil())
+				Expect(err).ToNot(HaveOccurred())
+				extraConfig := session.ExtraConfigToMap(configSpec.ExtraConfig)
+				Expect(extraConfig).To(HaveLen(4))
+				Expect(extraConfig).ToNot(HaveKey(constants.CloudInitGuestInfoUserdata))
+				Expect(extraConfig[constants.CloudInitGuestInfoVendordataEncoding]).To(Equal("gzip+base64"))
+				vendordata, err := util.TryToDecodeBase64Gzip([]byte(extraConfig[constants.CloudInitGuestInfoVendordata]))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vendordata).To(Equal("#cloud-config\n"))
+			})
+		})
+
 		Context("With CAPBK userdata in a 'value' key", func() {
 			BeforeEach(func() {
 				updateArgs.VMMetadata.Data["value"] = cloudInitUserdata
@@ -435,6 +756,162 @@ var _ = Describe("Cloud-Init Customization", func() {
 				Expect(cloudinitPrepSpec.Userdata).To(Equal("cloud-init-userdata"))
 			})
 		})
+
+		Context("With userdata and vendordata", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data["user-data"] = "#cloud-config\npackages: [git]\n"
+				updateArgs.VMMetadata.Data["vendor-data"] = base64.StdEncoding.EncodeToString([]byte("#cloud-config\npackages: [vim]\n"))
+			})
+			It("Cust spec to have the vendordata merged into the userdata after the userdata", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(custSpec).ToNot(BeNil())
+				cloudinitPrepSpec := custSpec.Identity.(*internal.CustomizationCloudinitPrep)
+				Expect(cloudinitPrepSpec.Metadata).To(Equal(cloudInitMetadata))
+
+				userdata := cloudinitPrepSpec.Userdata
+				Expect(userdata).To(HavePrefix("Content-Type: multipart/mixed"))
+				userdataIdx := strings.Index(userdata, `filename="user-data"`)
+				vendordataIdx := strings.Index(userdata, `filename="vendor-data"`)
+				Expect(userdataIdx).To(BeNumerically(">", 0))
+				Expect(vendordataIdx).To(BeNumerically(">", userdataIdx))
+				Expect(userdata).To(ContainSubstring("packages: [git]"))
+				Expect(userdata).To(ContainSubstring("packages: [vim]"))
+			})
+		})
+
+		Context("With only vendordata", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data["vendor-data"] = "#cloud-config\npackages: [vim]\n"
+			})
+			It("Cust spec to have the vendordata as the userdata", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(custSpec).ToNot(BeNil())
+				cloudinitPrepSpec := custSpec.Identity.(*internal.CustomizationCloudinitPrep)
+				Expect(cloudinitPrepSpec.Userdata).To(Equal("#cloud-config\npackages: [vim]\n"))
+			})
+		})
+	})
+})
+
+var _ = Describe("Ignition Customization", func() {
+
+	Context("GetAfterburnNetworkKargs", func() {
+		var (
+			netplan    network.Netplan
+			dnsServers []string
+		)
+
+		BeforeEach(func() {
+			netplan = network.Netplan{
+				Version: constants.NetPlanVersion,
+				Ethernets: map[string]network.NetplanEthernet{
+					"eth0": {
+						Match: network.NetplanEthernetMatch{
+							MacAddress: "00:50:56:9b:3a:67",
+						},
+						Addresses: []string{"192.168.1.55/24"},
+						Gateway4:  "192.168.1.1",
+					},
+					"eth1": {
+						Match: network.NetplanEthernetMatch{
+							MacAddress: "00:50:56:9b:3a:68",
+						},
+						Dhcp4: true,
+					},
+				},
+			}
+			dnsServers = []string{"8.8.8.8", "2001:4860:4860::8888"}
+		})
+
+		It("Returns the network kargs", func() {
+			kargs := session.GetAfterburnNetworkKargs("dummy-vm", netplan, dnsServers)
+			Expect(kargs).To(Equal("ifname=eth0:00:50:56:9b:3a:67 ip=192.168.1.55::192.168.1.1:24:dummy-vm:eth0:none " +
+				"ifname=eth1:00:50:56:9b:3a:68 ip=eth1:dhcp " +
+				"nameserver=8.8.8.8 nameserver=[2001:4860:4860::8888]"))
+		})
+
+		It("Orders the interfaces numerically", func() {
+			for i := 2; i <= 10; i++ {
+				netplan.Ethernets[fmt.Sprintf("eth%d", i)] = network.NetplanEthernet{Dhcp4: true}
+			}
+			kargs := session.GetAfterburnNetworkKargs("dummy-vm", netplan, nil)
+			Expect(kargs).To(HaveSuffix("ip=eth9:dhcp ip=eth10:dhcp"))
+		})
+
+		It("Configures a static IPv6 address without a gateway", func() {
+			netplan.Ethernets["eth0"] = network.NetplanEthernet{
+				Addresses: []string{"fd00::55/64"},
+				Gateway4:  "192.168.1.1",
+			}
+			kargs := session.GetAfterburnNetworkKargs("dummy-vm", netplan, nil)
+			Expect(kargs).To(HavePrefix("ip=[fd00::55]:::64:dummy-vm:eth0:none "))
+		})
+
+		It("Returns an empty string without any interfaces", func() {
+			Expect(session.GetAfterburnNetworkKargs("dummy-vm", network.Netplan{}, nil)).To(BeEmpty())
+		})
+	})
+
+	Context("GetIgnitionGuestInfoCustSpec", func() {
+		const (
+			ignitionConfig = `{"ignition":{"version":"3.3.0"}}`
+			networkKargs   = "ip=eth0:dhcp"
+		)
+
+		var (
+			updateArgs session.VMUpdateArgs
+			configInfo *vimTypes.VirtualMachineConfigInfo
+			configSpec *vimTypes.VirtualMachineConfigSpec
+			err        error
+		)
+
+		BeforeEach(func() {
+			configInfo = &vimTypes.VirtualMachineConfigInfo{}
+			updateArgs.VMMetadata.Data = map[string]string{}
+		})
+
+		JustBeforeEach(func() {
+			configSpec, err = session.GetIgnitionGuestInfoCustSpec(networkKargs, configInfo, updateArgs)
+		})
+
+		expectIgnitionConfig := func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configSpec).ToNot(BeNil())
+			extraConfig := session.ExtraConfigToMap(configSpec.ExtraConfig)
+			Expect(extraConfig).To(HaveLen(3))
+			Expect(extraConfig[constants.IgnitionGuestInfoConfigDataEncoding]).To(Equal("gzip+base64"))
+			Expect(extraConfig[constants.AfterburnGuestInfoNetworkKargs]).To(Equal(networkKargs))
+			config, err := util.TryToDecodeBase64Gzip([]byte(extraConfig[constants.IgnitionGuestInfoConfigData]))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config).To(Equal(ignitionConfig))
+		}
+
+		Context("No Ignition config", func() {
+			It("ConfigSpec.ExtraConfig to only have the network kargs", func() {
+				Expect(err).ToNot(HaveOccurred())
+				extraConfig := session.ExtraConfigToMap(configSpec.ExtraConfig)
+				Expect(extraConfig).To(HaveLen(1))
+				Expect(extraConfig[constants.AfterburnGuestInfoNetworkKargs]).To(Equal(networkKargs))
+			})
+		})
+
+		Context("With base64-encoded Ignition config", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data["ignition"] = base64.StdEncoding.EncodeToString([]byte(ignitionConfig))
+			})
+			It("ConfigSpec.ExtraConfig to have the Ignition config and network kargs", func() {
+				expectIgnitionConfig()
+			})
+		})
+
+		Context("With CAPBK Ignition config in a 'value' key", func() {
+			BeforeEach(func() {
+				updateArgs.VMMetadata.Data["value"] = ignitionConfig
+			})
+			It("ConfigSpec.ExtraConfig to have the Ignition config and network kargs", func() {
+				expectIgnitionConfig()
+			})
+		})
 	})
 })
 
@@ -4,8 +4,11 @@
 package session
 
 import (
+	"net"
 	"strconv"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8serrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/vmware/govmomi/object"
@@ -18,6 +21,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 	res "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/resources"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 )
 
 func ipCIDRNotation(ipAddress string, prefix int32) string {
@@ -39,6 +43,38 @@ func NicInfoToNetworkIfStatus(nicInfo vimTypes.GuestNicInfo) v1alpha1.NetworkInt
 	}
 }
 
+// GetPrimaryIPs returns the primary IPv4 and IPv6 addresses of the VM. The guest's primary IP is used for its
+// family, and the first global unicast address of each remaining family is taken from the network interfaces.
+func GetPrimaryIPs(vmIP string, networkIfStatuses []v1alpha1.NetworkInterfaceStatus) (string, string) {
+	var ipv4, ipv6 string
+
+	setIfUnset := func(ip net.IP) {
+		if ip == nil || !ip.IsGlobalUnicast() {
+			return
+		}
+		if ip.To4() != nil {
+			if ipv4 == "" {
+				ipv4 = ip.String()
+			}
+		} else if ipv6 == "" {
+			ipv6 = ip.String()
+		}
+	}
+
+	setIfUnset(net.ParseIP(vmIP))
+	for _, nic := range networkIfStatuses {
+		for _, ipAddress := range nic.IpAddresses {
+			ip, _, err := net.ParseCIDR(ipAddress)
+			if err != nil {
+				ip = net.ParseIP(ipAddress)
+			}
+			setIfUnset(ip)
+		}
+	}
+
+	return ipv4, ipv6
+}
+
 func MarkVMToolsRunningStatusCondition(vm *v1alpha1.VirtualMachine, guestInfo *vimTypes.GuestInfo) {
 	if guestInfo == nil || guestInfo.ToolsRunningStatus == "" {
 		conditions.MarkUnknown(vm, v1alpha1.VirtualMachineToolsCondition, "", "")
@@ -106,6 +142,7 @@ func (s *Session) updateVMStatus(
 
 	vm.Status.Phase = v1alpha1.Created
 	vm.Status.PowerState = v1alpha1.VirtualMachinePowerState(summary.Runtime.PowerState)
+	UpdateBootTime(vm, summary.Runtime.BootTime)
 	vm.Status.UniqueID = resVM.MoRef().Value
 	vm.Status.BiosUUID = summary.Config.Uuid
 	vm.Status.InstanceUUID = summary.Config.InstanceUuid
@@ -131,9 +168,12 @@ func (s *Session) updateVMStatus(
 			networkIfStatuses = append(networkIfStatuses, NicInfoToNetworkIfStatus(nicInfo))
 		}
 		vm.Status.NetworkInterfaces = networkIfStatuses
+		vm.Status.PrimaryIPv4, vm.Status.PrimaryIPv6 = GetPrimaryIPs(guestInfo.IpAddress, networkIfStatuses)
 	} else {
 		vm.Status.VmIp = ""
 		vm.Status.NetworkInterfaces = nil
+		vm.Status.PrimaryIPv4 = ""
+		vm.Status.PrimaryIPv6 = ""
 	}
 
 	MarkCustomizationInfoCondition(vm, guestInfo)
@@ -168,3 +208,22 @@ func (s *Session) updateVMStatus(
 
 	return k8serrors.NewAggregate(errs)
 }
+
+// UpdateBootTime sets the VM's boot time, and resets its VirtualMachineStartedCondition when the VM is not
+// powered on or its boot time changed, which catches the power cycles that were not done by VM Operator.
+func UpdateBootTime(vm *v1alpha1.VirtualMachine, bootTime *time.Time) {
+	lastBootTime := vm.Status.BootTime
+	if bootTime != nil {
+		// The status is serialized with a precision of a second, so truncate the boot time to compare it.
+		vm.Status.BootTime = &metav1.Time{Time: bootTime.Truncate(time.Second)}
+	} else {
+		vm.Status.BootTime = nil
+	}
+
+	switch {
+	case vm.Status.PowerState != v1alpha1.VirtualMachinePoweredOn:
+		virtualmachine.MarkNotStarted(vm, "VM is not powered on")
+	case lastBootTime != nil && vm.Status.BootTime != nil && !lastBootTime.Equal(vm.Status.BootTime):
+		virtualmachine.MarkNotStarted(vm, "VM was restarted")
+	}
+}
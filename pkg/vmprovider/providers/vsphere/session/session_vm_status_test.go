@@ -4,9 +4,12 @@
 package session_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	vimTypes "github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
@@ -47,6 +50,45 @@ var _ = Describe("Network Interfaces VM Status", func() {
 	})
 })
 
+var _ = Describe("Primary IPs VM Status", func() {
+	Context("GetPrimaryIPs", func() {
+		var networkIfStatuses []vmopv1alpha1.NetworkInterfaceStatus
+
+		BeforeEach(func() {
+			networkIfStatuses = []vmopv1alpha1.NetworkInterfaceStatus{
+				{
+					IpAddresses: []string{"fe80::250:56ff:fe8c:7b34/64", "192.168.128.5/16"},
+				},
+				{
+					IpAddresses: []string{"10.0.0.5/24", "2001:db8::5/64", "2001:db8::6/64"},
+				},
+			}
+		})
+
+		It("returns the first global address of each family", func() {
+			ipv4, ipv6 := session.GetPrimaryIPs("", networkIfStatuses)
+			Expect(ipv4).To(Equal("192.168.128.5"))
+			Expect(ipv6).To(Equal("2001:db8::5"))
+		})
+
+		It("prefers the guest's primary IP for its family", func() {
+			ipv4, ipv6 := session.GetPrimaryIPs("10.0.0.5", networkIfStatuses)
+			Expect(ipv4).To(Equal("10.0.0.5"))
+			Expect(ipv6).To(Equal("2001:db8::5"))
+
+			ipv4, ipv6 = session.GetPrimaryIPs("2001:db8::6", networkIfStatuses)
+			Expect(ipv4).To(Equal("192.168.128.5"))
+			Expect(ipv6).To(Equal("2001:db8::6"))
+		})
+
+		It("ignores link-local addresses", func() {
+			ipv4, ipv6 := session.GetPrimaryIPs("fe80::1", networkIfStatuses[:1])
+			Expect(ipv4).To(Equal("192.168.128.5"))
+			Expect(ipv6).To(BeEmpty())
+		})
+	})
+})
+
 var _ = Describe("VirtualMachineTools Status to VM Status Condition", func() {
 	Context("markVMToolsRunningStatusCondition", func() {
 		var (
@@ -241,3 +283,58 @@ var _ = Describe("VSphere Customization Status to VM Status Condition", func() {
 		})
 	})
 })
+
+var _ = Describe("Boot time VM Status", func() {
+	Context("UpdateBootTime", func() {
+		var (
+			vm       *vmopv1alpha1.VirtualMachine
+			bootTime time.Time
+		)
+
+		BeforeEach(func() {
+			bootTime = time.Now().Add(-time.Hour).Truncate(time.Second)
+			vm = &vmopv1alpha1.VirtualMachine{
+				Status: vmopv1alpha1.VirtualMachineStatus{
+					PowerState: vmopv1alpha1.VirtualMachinePoweredOn,
+					BootTime:   &metav1.Time{Time: bootTime},
+				},
+			}
+			conditions.MarkTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)
+		})
+
+		It("keeps the started condition when the VM was not restarted", func() {
+			session.UpdateBootTime(vm, &bootTime)
+			Expect(vm.Status.BootTime.Time).To(BeTemporally("==", bootTime))
+			Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(BeTrue())
+		})
+
+		It("ignores the sub-second precision of the boot time", func() {
+			subSecondBootTime := bootTime.Add(500 * time.Millisecond)
+			session.UpdateBootTime(vm, &subSecondBootTime)
+			Expect(vm.Status.BootTime.Time).To(BeTemporally("==", bootTime))
+			Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(BeTrue())
+		})
+
+		It("resets the started condition when the VM was restarted", func() {
+			newBootTime := bootTime.Add(time.Minute)
+			session.UpdateBootTime(vm, &newBootTime)
+			Expect(vm.Status.BootTime.Time).To(BeTemporally("==", newBootTime))
+			Expect(conditions.IsFalse(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(BeTrue())
+			Expect(conditions.GetReason(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(Equal(vmopv1alpha1.StartupProbePendingReason))
+		})
+
+		It("resets the started condition when the VM is not powered on", func() {
+			vm.Status.PowerState = vmopv1alpha1.VirtualMachinePoweredOff
+			session.UpdateBootTime(vm, nil)
+			Expect(vm.Status.BootTime).To(BeNil())
+			Expect(conditions.IsFalse(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(BeTrue())
+		})
+
+		It("keeps the started condition when the boot time was not recorded", func() {
+			vm.Status.BootTime = nil
+			session.UpdateBootTime(vm, &bootTime)
+			Expect(vm.Status.BootTime.Time).To(BeTemporally("==", bootTime))
+			Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(BeTrue())
+		})
+	})
+})
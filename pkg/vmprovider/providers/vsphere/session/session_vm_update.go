@@ -4,13 +4,19 @@
 package session
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
+	"github.com/pkg/errors"
 	vimTypes "github.com/vmware/govmomi/vim25/types"
 	apiEquality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/pointer"
 
 	"github.com/vmware/govmomi/object"
 
@@ -33,6 +39,25 @@ import (
 type VMMetadata struct {
 	Data      map[string]string
 	Transport v1alpha1.VirtualMachineMetadataTransport
+	// ResourceVersion is the resourceVersion of the ConfigMap or Secret the Data is from.
+	ResourceVersion string
+}
+
+// Hash returns a hash of the metadata's Data that is used to determine if the metadata changed since it
+// was last applied to the VM.
+func (md VMMetadata) Hash() string {
+	keys := make([]string, 0, len(md.Data))
+	for k := range md.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		// Length prefix the key and value so that different Data cannot produce the same input.
+		_, _ = fmt.Fprintf(h, "%d:%s%d:%s", len(k), k, len(md.Data[k]), md.Data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // VMUpdateArgs contains the arguments needed to update a VM on VC.
@@ -242,7 +267,8 @@ func UpdateConfigSpecCPUAllocation(
 
 	if !vmClassSpec.Policies.Resources.Requests.Cpu.IsZero() {
 		rsv := virtualmachine.CPUQuantityToMhz(vmClassSpec.Policies.Resources.Requests.Cpu, minCPUFeq)
-		if cpuAllocation == nil || cpuAllocation.Reservation == nil || *cpuAllocation.Reservation != rsv {
+		// An unset reservation is the same as no reservation.
+		if cpuAllocation == nil || pointer.Int64Deref(cpuAllocation.Reservation, 0) != rsv {
 			cpuReservation = &rsv
 		}
 	}
@@ -273,7 +299,8 @@ func UpdateConfigSpecMemoryAllocation(
 
 	if !vmClassSpec.Policies.Resources.Requests.Memory.IsZero() {
 		rsv := virtualmachine.MemoryQuantityToMb(vmClassSpec.Policies.Resources.Requests.Memory)
-		if memAllocation == nil || memAllocation.Reservation == nil || *memAllocation.Reservation != rsv {
+		// An unset reservation is the same as no reservation.
+		if memAllocation == nil || pointer.Int64Deref(memAllocation.Reservation, 0) != rsv {
 			memoryReservation = &rsv
 		}
 	}
@@ -407,12 +434,7 @@ func UpdateHardwareConfigSpec(
 	if config.Annotation != constants.VCVMAnnotation {
 		configSpec.Annotation = constants.VCVMAnnotation
 	}
-	if nCPUs := int32(vmClassSpec.Hardware.Cpus); config.Hardware.NumCPU != nCPUs {
-		configSpec.NumCPUs = nCPUs
-	}
-	if memMB := virtualmachine.MemoryQuantityToMb(vmClassSpec.Hardware.Memory); int64(config.Hardware.MemoryMB) != memMB {
-		configSpec.MemoryMB = memMB
-	}
+	UpdateConfigSpecCPUAndMemory(config, configSpec, vmClassSpec)
 	if config.ManagedBy == nil {
 		configSpec.ManagedBy = &vimTypes.ManagedByInfo{
 			ExtensionKey: "com.vmware.vcenter.wcp",
@@ -421,6 +443,20 @@ func UpdateHardwareConfigSpec(
 	}
 }
 
+// UpdateConfigSpecCPUAndMemory sets the desired number of CPUs and memory size of the VM from the class.
+func UpdateConfigSpecCPUAndMemory(
+	config *vimTypes.VirtualMachineConfigInfo,
+	configSpec *vimTypes.VirtualMachineConfigSpec,
+	vmClassSpec *v1alpha1.VirtualMachineClassSpec) {
+
+	if nCPUs := int32(vmClassSpec.Hardware.Cpus); config.Hardware.NumCPU != nCPUs {
+		configSpec.NumCPUs = nCPUs
+	}
+	if memMB := virtualmachine.MemoryQuantityToMb(vmClassSpec.Hardware.Memory); int64(config.Hardware.MemoryMB) != memMB {
+		configSpec.MemoryMB = memMB
+	}
+}
+
 func UpdateConfigSpecFirmware(
 	config *vimTypes.VirtualMachineConfigInfo,
 	configSpec *vimTypes.VirtualMachineConfigSpec,
@@ -446,6 +482,58 @@ func UpdateConfigSpecDeviceGroups(
 	}
 }
 
+// UpdateConfigSpecHotAdd sets whether CPU and memory hot add are enabled from the class config spec.
+// These settings can only be changed while the VM is powered off.
+func UpdateConfigSpecHotAdd(
+	config *vimTypes.VirtualMachineConfigInfo,
+	configSpec, classConfigSpec *vimTypes.VirtualMachineConfigSpec) {
+
+	if classConfigSpec == nil {
+		return
+	}
+
+	if e := classConfigSpec.CpuHotAddEnabled; e != nil && pointer.BoolDeref(config.CpuHotAddEnabled, false) != *e {
+		configSpec.CpuHotAddEnabled = e
+	}
+	if e := classConfigSpec.MemoryHotAddEnabled; e != nil && pointer.BoolDeref(config.MemoryHotAddEnabled, false) != *e {
+		configSpec.MemoryHotAddEnabled = e
+	}
+}
+
+// UpdateConfigSpecHotResize sets the changes from the resize config spec that can be applied to a powered on
+// VM. The number of CPUs and the memory size can only be increased when hot add is enabled, and the number
+// of CPUs can only be decreased when hot remove is enabled. Reservations and limits can always be changed.
+// Returns false if any of the resize changes require the VM to be powered off.
+func UpdateConfigSpecHotResize(
+	config *vimTypes.VirtualMachineConfigInfo,
+	configSpec, resizeConfigSpec *vimTypes.VirtualMachineConfigSpec) bool {
+
+	resizable := true
+
+	if nCPUs := resizeConfigSpec.NumCPUs; nCPUs != 0 {
+		hotAdd := nCPUs > config.Hardware.NumCPU && pointer.BoolDeref(config.CpuHotAddEnabled, false)
+		hotRemove := nCPUs < config.Hardware.NumCPU && pointer.BoolDeref(config.CpuHotRemoveEnabled, false)
+		if hotAdd || hotRemove {
+			configSpec.NumCPUs = nCPUs
+		} else {
+			resizable = false
+		}
+	}
+
+	if memMB := resizeConfigSpec.MemoryMB; memMB != 0 {
+		if memMB > int64(config.Hardware.MemoryMB) && pointer.BoolDeref(config.MemoryHotAddEnabled, false) {
+			configSpec.MemoryMB = memMB
+		} else {
+			resizable = false
+		}
+	}
+
+	configSpec.CpuAllocation = resizeConfigSpec.CpuAllocation
+	configSpec.MemoryAllocation = resizeConfigSpec.MemoryAllocation
+
+	return resizable
+}
+
 func updateConfigSpec(
 	vmCtx context.VirtualMachineContext,
 	config *vimTypes.VirtualMachineConfigInfo,
@@ -461,10 +549,83 @@ func updateConfigSpec(
 	UpdateConfigSpecChangeBlockTracking(config, configSpec, updateArgs.ConfigSpec, vmCtx.VM.Spec)
 	UpdateConfigSpecFirmware(config, configSpec, vmCtx.VM)
 	UpdateConfigSpecDeviceGroups(config, configSpec, updateArgs.ConfigSpec)
+	UpdateConfigSpecHotAdd(config, configSpec, updateArgs.ConfigSpec)
+
+	if classResizeDisabled(updateArgs) {
+		// The VM keeps the CPU, memory and reservations that it was last sized with.
+		configSpec.NumCPUs, configSpec.MemoryMB = 0, 0
+		configSpec.CpuAllocation, configSpec.MemoryAllocation = nil, nil
+	}
 
 	return configSpec
 }
 
+// resizeConfigSpec returns the ConfigSpec needed for the VM's CPU and memory to match its class.
+func resizeConfigSpec(
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs *VMUpdateArgs) *vimTypes.VirtualMachineConfigSpec {
+
+	configSpec := &vimTypes.VirtualMachineConfigSpec{}
+	vmClassSpec := updateArgs.VMClass.Spec
+
+	UpdateConfigSpecCPUAndMemory(config, configSpec, &vmClassSpec)
+	UpdateConfigSpecCPUAllocation(config, configSpec, &vmClassSpec, updateArgs.MinCPUFreq)
+	UpdateConfigSpecMemoryAllocation(config, configSpec, &vmClassSpec)
+
+	return configSpec
+}
+
+// markClassConfigurationSyncedCondition marks whether the VM's CPU and memory match its class. The suspended
+// argument is whether the VM is left suspended, which is not resized when it is resumed.
+func markClassConfigurationSyncedCondition(
+	vm *v1alpha1.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs *VMUpdateArgs,
+	suspended bool) {
+
+	configSpec := resizeConfigSpec(config, updateArgs)
+	if apiEquality.Semantic.DeepEqual(configSpec, &vimTypes.VirtualMachineConfigSpec{}) {
+		markClassConfigurationSynced(vm, updateArgs.VMClass)
+		return
+	}
+
+	if classResizeDisabled(updateArgs) {
+		conditions.MarkFalse(vm, v1alpha1.VirtualMachineClassConfigurationSyncedCondition,
+			v1alpha1.VirtualMachineResizeDisabledReason, v1alpha1.ConditionSeverityInfo,
+			"VM is not resized to match VirtualMachineClass %s because its updatePolicy is %s",
+			vm.Spec.ClassName, v1alpha1.VirtualMachineClassUpdatePolicyNone)
+		return
+	}
+
+	markResizePending(vm, suspended)
+}
+
+// markClassConfigurationSynced marks that the VM's CPU and memory match its class, and records the
+// generation of the class that was applied.
+func markClassConfigurationSynced(vm *v1alpha1.VirtualMachine, vmClass *v1alpha1.VirtualMachineClass) {
+	conditions.MarkTrue(vm, v1alpha1.VirtualMachineClassConfigurationSyncedCondition)
+	vm.Status.AppliedClassGeneration = vmClass.Generation
+}
+
+// classResizeDisabled returns true if the VM must not be resized to match its class.
+func classResizeDisabled(updateArgs *VMUpdateArgs) bool {
+	return updateArgs.VMClass.Spec.UpdatePolicy.Type == v1alpha1.VirtualMachineClassUpdatePolicyNone
+}
+
+func markResizePending(vm *v1alpha1.VirtualMachine, suspended bool) {
+	if suspended {
+		conditions.MarkFalse(vm, v1alpha1.VirtualMachineClassConfigurationSyncedCondition,
+			v1alpha1.VirtualMachineResizePendingReason, v1alpha1.ConditionSeverityInfo,
+			"VM will be resized to match VirtualMachineClass %s when it is powered off. A suspended VM is not resized when it is resumed",
+			vm.Spec.ClassName)
+		return
+	}
+
+	conditions.MarkFalse(vm, v1alpha1.VirtualMachineClassConfigurationSyncedCondition,
+		v1alpha1.VirtualMachineResizePendingReason, v1alpha1.ConditionSeverityInfo,
+		"VM will be resized to match VirtualMachineClass %s when it is powered off", vm.Spec.ClassName)
+}
+
 func (s *Session) prePowerOnVMConfigSpec(
 	vmCtx context.VirtualMachineContext,
 	config *vimTypes.VirtualMachineConfigInfo,
@@ -525,6 +686,16 @@ func (s *Session) prePowerOnVMReconfigure(
 		return err
 	}
 
+	// The serial port for the serial console is added here since it cannot be added to a powered on VM.
+	if proxyURI := lib.GetSerialConsoleProxyURI(); proxyURI != "" {
+		serialPortChanges, err := virtualmachine.SerialConsolePortDeviceChanges(
+			config.Hardware.Device, proxyURI, resVM.ReferenceValue())
+		if err != nil {
+			return err
+		}
+		configSpec.DeviceChange = append(configSpec.DeviceChange, serialPortChanges...)
+	}
+
 	defaultConfigSpec := &vimTypes.VirtualMachineConfigSpec{}
 	if !apiEquality.Semantic.DeepEqual(configSpec, defaultConfigSpec) {
 		vmCtx.Logger.Info("Pre PowerOn Reconfigure", "configSpec", configSpec)
@@ -628,10 +799,10 @@ func (s *Session) ensureCNSVolumes(vmCtx context.VirtualMachineContext) error {
 	return nil
 }
 
-func (s *Session) prepareVMForPowerOn(
+// ensureNetworkArgs sets the network interfaces and DNS servers in the updateArgs that the
+// customization of the VM's network is derived from.
+func (s *Session) ensureNetworkArgs(
 	vmCtx context.VirtualMachineContext,
-	resVM *res.VirtualMachine,
-	cfg *vimTypes.VirtualMachineConfigInfo,
 	updateArgs *VMUpdateArgs) error {
 
 	netIfList, err := s.ensureNetworkInterfaces(vmCtx, updateArgs.ConfigSpec)
@@ -647,6 +818,19 @@ func (s *Session) prepareVMForPowerOn(
 
 	updateArgs.NetIfList = netIfList
 	updateArgs.DNSServers = dnsServers
+	return nil
+}
+
+func (s *Session) prepareVMForPowerOn(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine,
+	cfg *vimTypes.VirtualMachineConfigInfo,
+	updateArgs *VMUpdateArgs) error {
+
+	err := s.ensureNetworkArgs(vmCtx, updateArgs)
+	if err != nil {
+		return err
+	}
 
 	err = s.prePowerOnVMReconfigure(vmCtx, resVM, cfg, updateArgs)
 	if err != nil {
@@ -657,6 +841,7 @@ func (s *Session) prepareVMForPowerOn(
 	if err != nil {
 		return err
 	}
+	setAppliedVMMetadata(vmCtx.VM, *updateArgs)
 
 	err = s.ensureCNSVolumes(vmCtx)
 	if err != nil {
@@ -666,6 +851,39 @@ func (s *Session) prepareVMForPowerOn(
 	return nil
 }
 
+// poweredOffVMReconfigure resizes the powered off VM so its CPU and memory match its class. The
+// remainder of the VM's config is reconciled when it is prepared for power on.
+func (s *Session) poweredOffVMReconfigure(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs *VMUpdateArgs) error {
+
+	resizeDisabled := classResizeDisabled(updateArgs)
+
+	configSpec := &vimTypes.VirtualMachineConfigSpec{}
+	if !resizeDisabled {
+		configSpec = resizeConfigSpec(config, updateArgs)
+	}
+	UpdateConfigSpecHotAdd(config, configSpec, updateArgs.ConfigSpec)
+
+	defaultConfigSpec := &vimTypes.VirtualMachineConfigSpec{}
+	if !apiEquality.Semantic.DeepEqual(configSpec, defaultConfigSpec) {
+		vmCtx.Logger.Info("PoweredOff Reconfigure", "configSpec", configSpec)
+		if err := resVM.Reconfigure(vmCtx, configSpec); err != nil {
+			vmCtx.Logger.Error(err, "powered off reconfigure failed")
+			return err
+		}
+	}
+
+	if resizeDisabled {
+		markClassConfigurationSyncedCondition(vmCtx.VM, config, updateArgs, false)
+	} else {
+		markClassConfigurationSynced(vmCtx.VM, updateArgs.VMClass)
+	}
+	return nil
+}
+
 func (s *Session) poweredOnVMReconfigure(
 	vmCtx context.VirtualMachineContext,
 	resVM *res.VirtualMachine,
@@ -697,6 +915,233 @@ func (s *Session) poweredOnVMReconfigure(
 	return nil
 }
 
+// poweredOnVMReapplyMetadata applies the VM's metadata to the powered on VM when its ConfigMap or Secret
+// changed since the metadata was last applied. Depending on the VM's metadata update policy, either the
+// ConfigSpec part of the customization is reapplied, or the VM is powered off, customized again, and
+// powered back on.
+func (s *Session) poweredOnVMReapplyMetadata(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs *VMUpdateArgs) error {
+
+	if vmCtx.VM.Spec.VmMetadata == nil {
+		setAppliedVMMetadata(vmCtx.VM, *updateArgs)
+		return nil
+	}
+
+	hash := updateArgs.VMMetadata.Hash()
+	appliedHash := vmCtx.VM.Status.VmMetadataHash
+
+	if !vmMetadataChanged(vmCtx.VM, *updateArgs) {
+		// The VM was powered on before the applied metadata was tracked so there is no telling
+		// if the metadata changed since. Assume it did not rather than disrupt the VM.
+		setAppliedVMMetadata(vmCtx.VM, *updateArgs)
+		return nil
+	}
+
+	if vmCtx.VM.Spec.VmMetadata.UpdatePolicy == v1alpha1.VirtualMachineMetadataUpdatePolicyReboot {
+		vmCtx.Logger.Info("Rebooting VM to reapply metadata",
+			"resourceVersion", updateArgs.VMMetadata.ResourceVersion,
+			"appliedResourceVersion", vmCtx.VM.Status.VmMetadataResourceVersion,
+			"hash", hash, "appliedHash", appliedHash)
+
+		poweredOff, err := changePowerState(vmCtx, vcVM,
+			vimTypes.VirtualMachinePowerStatePoweredOff, vmCtx.VM.Spec.PowerOffMode)
+		if err != nil || !poweredOff {
+			// Once the guest shuts down, the VM is prepared and powered on like any powered off VM.
+			return err
+		}
+
+		if err := s.prepareVMForPowerOn(vmCtx, resVM, config, updateArgs); err != nil {
+			return err
+		}
+
+		return virtualmachine.ChangePowerState(vmCtx, vcVM, vimTypes.VirtualMachinePowerStatePoweredOn, "")
+	}
+
+	vmCtx.Logger.Info("Reapplying metadata to powered on VM",
+		"resourceVersion", updateArgs.VMMetadata.ResourceVersion,
+		"appliedResourceVersion", vmCtx.VM.Status.VmMetadataResourceVersion,
+		"hash", hash, "appliedHash", appliedHash)
+
+	if err := s.ensureNetworkArgs(vmCtx, updateArgs); err != nil {
+		return err
+	}
+
+	// The CustomizationSpec can only be applied to a powered off VM.
+	configSpec, _, err := customizationSpecs(vmCtx, resVM, customizationConfig(vmCtx.VM, config, *updateArgs), *updateArgs)
+	if err != nil {
+		return err
+	}
+
+	if err := reconfigureCustomization(vmCtx, resVM, configSpec); err != nil {
+		return err
+	}
+
+	setAppliedVMMetadata(vmCtx.VM, *updateArgs)
+	return nil
+}
+
+// setAppliedVMMetadata records the VM metadata that was applied to the VM in its status.
+func setAppliedVMMetadata(vm *v1alpha1.VirtualMachine, updateArgs VMUpdateArgs) {
+	if vm.Spec.VmMetadata == nil {
+		vm.Status.VmMetadataResourceVersion = ""
+		vm.Status.VmMetadataHash = ""
+		return
+	}
+
+	vm.Status.VmMetadataResourceVersion = updateArgs.VMMetadata.ResourceVersion
+	vm.Status.VmMetadataHash = updateArgs.VMMetadata.Hash()
+}
+
+// poweredOnVMResize resizes the powered on VM so its CPU and memory match its class, using hot add
+// when it is enabled for the VM. Any change that cannot be applied while the VM is powered on is left
+// pending until the VM is powered off, and is reflected in the VM's conditions instead of failing the
+// reconcile.
+func (s *Session) poweredOnVMResize(
+	vmCtx context.VirtualMachineContext,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs *VMUpdateArgs) {
+
+	defaultConfigSpec := &vimTypes.VirtualMachineConfigSpec{}
+
+	resizeSpec := resizeConfigSpec(config, updateArgs)
+	if apiEquality.Semantic.DeepEqual(resizeSpec, defaultConfigSpec) || classResizeDisabled(updateArgs) {
+		markClassConfigurationSyncedCondition(vmCtx.VM, config, updateArgs, false)
+		return
+	}
+
+	configSpec := &vimTypes.VirtualMachineConfigSpec{}
+	resizable := UpdateConfigSpecHotResize(config, configSpec, resizeSpec)
+
+	if !apiEquality.Semantic.DeepEqual(configSpec, defaultConfigSpec) {
+		vmCtx.Logger.Info("PoweredOn Resize", "configSpec", configSpec)
+		if err := resVM.Reconfigure(vmCtx, configSpec); err != nil {
+			vmCtx.Logger.Error(err, "powered on resize failed")
+			conditions.MarkFalse(vmCtx.VM, v1alpha1.VirtualMachineClassConfigurationSyncedCondition,
+				v1alpha1.VirtualMachineResizePendingReason, v1alpha1.ConditionSeverityWarning,
+				"Failed to resize powered on VM: %v", err)
+			return
+		}
+	}
+
+	if !resizable {
+		markResizePending(vmCtx.VM, false)
+		return
+	}
+
+	markClassConfigurationSynced(vmCtx.VM, updateArgs.VMClass)
+}
+
+// powerCycleVMForClassRollout power cycles the VM so that it is resized to match its class, when the
+// VirtualMachineClass controller has selected the VM in a Rolling update of the class and the VM could
+// not be hot resized.
+func (s *Session) powerCycleVMForClassRollout(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs *VMUpdateArgs) error {
+
+	generation, ok := classRolloutGeneration(vmCtx, *updateArgs)
+	if !ok {
+		return nil
+	}
+
+	vmCtx.Logger.Info("Power cycling VM to resize it for the Rolling update of its VirtualMachineClass",
+		"classGeneration", generation)
+
+	poweredOff, err := changePowerState(vmCtx, vcVM,
+		vimTypes.VirtualMachinePowerStatePoweredOff, vmCtx.VM.Spec.PowerOffMode)
+	if err != nil || !poweredOff {
+		// Once the guest shuts down, the VM is resized and powered on like any powered off VM.
+		return err
+	}
+
+	if err := s.poweredOffVMReconfigure(vmCtx, resVM, config, updateArgs); err != nil {
+		return err
+	}
+
+	return virtualmachine.ChangePowerState(vmCtx, vcVM, vimTypes.VirtualMachinePowerStatePoweredOn, "")
+}
+
+// classRolloutGeneration returns the generation of the VM's class that the VM is power cycled for, and
+// true if the VM was selected by a Rolling update of its class and has not been resized yet.
+func classRolloutGeneration(vmCtx context.VirtualMachineContext, updateArgs VMUpdateArgs) (int64, bool) {
+	if updateArgs.VMClass.Spec.UpdatePolicy.Type != v1alpha1.VirtualMachineClassUpdatePolicyRolling ||
+		conditions.IsTrue(vmCtx.VM, v1alpha1.VirtualMachineClassConfigurationSyncedCondition) {
+		return 0, false
+	}
+
+	value, ok := vmCtx.VM.Annotations[v1alpha1.ClassRolloutGenerationAnnotation]
+	if !ok {
+		return 0, false
+	}
+	generation, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		vmCtx.Logger.Error(err, "Ignoring invalid class rollout generation annotation", "value", value)
+		return 0, false
+	}
+
+	return generation, generation > vmCtx.VM.Status.AppliedClassGeneration
+}
+
+// vmMetadataRebootNeeded returns true if the powered on VM is rebooted to reapply its changed metadata.
+func vmMetadataRebootNeeded(vm *v1alpha1.VirtualMachine, updateArgs VMUpdateArgs) bool {
+	return vm.Spec.VmMetadata != nil &&
+		vm.Spec.VmMetadata.UpdatePolicy == v1alpha1.VirtualMachineMetadataUpdatePolicyReboot &&
+		vmMetadataChanged(vm, updateArgs)
+}
+
+// clearStaleSoftPowerOp clears the pending soft power op when the VM no longer needs to realize its power
+// state, like when spec.powerState was changed back to poweredOn before the guest shut down. A pending power
+// off of a powered on VM is kept while the VM is power cycled for its class or metadata.
+func clearStaleSoftPowerOp(vmCtx context.VirtualMachineContext, updateArgs VMUpdateArgs) {
+	pending := vmCtx.VM.Status.PendingSoftPowerOp
+	if pending == nil || pending.PowerState == vmCtx.VM.Spec.PowerState {
+		return
+	}
+
+	if vmCtx.VM.Spec.PowerState == v1alpha1.VirtualMachinePoweredOn &&
+		pending.PowerState == v1alpha1.VirtualMachinePoweredOff {
+		if _, ok := classRolloutGeneration(vmCtx, updateArgs); ok || vmMetadataRebootNeeded(vmCtx.VM, updateArgs) {
+			return
+		}
+	}
+
+	vmCtx.Logger.Info("Clearing soft power op that is no longer desired",
+		"pendingPowerState", pending.PowerState, "desiredPowerState", vmCtx.VM.Spec.PowerState)
+	vmCtx.VM.Status.PendingSoftPowerOp = nil
+}
+
+func restartVMIfNeeded(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine) error {
+
+	restartTime, err := util.PendingTriggerTime(vmCtx.VM.Spec.NextRestartTime, vmCtx.VM.Status.LastRestartTime)
+	if err != nil {
+		// This is validated by the webhook so there is nothing to retry.
+		vmCtx.Logger.Error(err, "Ignoring invalid nextRestartTime")
+		return nil
+	}
+
+	if restartTime == nil {
+		return nil
+	}
+
+	vmCtx.Logger.Info("Restarting VM", "nextRestartTime", vmCtx.VM.Spec.NextRestartTime,
+		"restartMode", vmCtx.VM.Spec.RestartMode)
+	if err := virtualmachine.RestartVirtualMachine(vmCtx, vcVM, vmCtx.VM.Spec.RestartMode); err != nil {
+		return err
+	}
+
+	vmCtx.VM.Status.LastRestartTime = restartTime
+	return nil
+}
+
 func (s *Session) attachClusterModule(
 	vmCtx context.VirtualMachineContext,
 	resVM *res.VirtualMachine,
@@ -717,6 +1162,21 @@ func (s *Session) attachClusterModule(
 	return s.Client.ClusterModuleClient().AddMoRefToModule(vmCtx, moduleUUID, resVM.MoRef())
 }
 
+// changePowerState changes the power state of the VM, and returns false if the guest was asked to perform a
+// soft power op that the VM has not realized yet. The VM is checked again on a later reconcile instead.
+func changePowerState(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	ps vimTypes.VirtualMachinePowerState,
+	mode v1alpha1.VirtualMachinePowerOpMode) (bool, error) {
+
+	err := virtualmachine.ChangePowerState(vmCtx, vcVM, ps, mode)
+	if errors.Is(err, virtualmachine.ErrSoftPowerOpPending) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 func (s *Session) UpdateVirtualMachine(
 	vmCtx context.VirtualMachineContext,
 	vcVM *object.VirtualMachine,
@@ -739,44 +1199,115 @@ func (s *Session) UpdateVirtualMachine(
 		}
 	}()
 
+	config := moVM.Config
+
+	// See govmomi VirtualMachine::Device() explanation for this check.
+	if config == nil {
+		return fmt.Errorf("VM config is not available, connectionState=%s", moVM.Runtime.ConnectionState)
+	}
+
 	isOff := moVM.Runtime.PowerState == vimTypes.VirtualMachinePowerStatePoweredOff
+	isSuspended := moVM.Runtime.PowerState == vimTypes.VirtualMachinePowerStateSuspended
+
+	// A soft power op can only be pending while the guest is running.
+	if isOff || isSuspended {
+		vmCtx.VM.Status.PendingSoftPowerOp = nil
+	}
+	clearStaleSoftPowerOp(vmCtx, *updateArgs)
 
 	switch vmCtx.VM.Spec.PowerState {
 	case v1alpha1.VirtualMachinePoweredOff:
 		if !isOff {
-			err := resVM.SetPowerState(vmCtx, v1alpha1.VirtualMachinePoweredOff)
+			poweredOff, err := changePowerState(vmCtx, vcVM,
+				vimTypes.VirtualMachinePowerStatePoweredOff, vmCtx.VM.Spec.PowerOffMode)
 			if err != nil {
 				return err
 			}
+			if !poweredOff {
+				break
+			}
 		}
 
-		// BMV: We'll likely want to reconfigure a powered off VM too, but right now
-		// we'll defer that until the pre power on (and until more people complain
-		// that the UI appears wrong).
-
-	case v1alpha1.VirtualMachinePoweredOn:
-		config := moVM.Config
+		// Only the CPU and memory are reconfigured here so the VM reflects a change to
+		// its class. The rest of the config is deferred until the pre power on.
+		err := s.poweredOffVMReconfigure(vmCtx, resVM, config, updateArgs)
+		if err != nil {
+			return err
+		}
 
-		// See govmomi VirtualMachine::Device() explanation for this check.
-		if config == nil {
-			return fmt.Errorf("VM config is not available, connectionState=%s", moVM.Runtime.ConnectionState)
+	case v1alpha1.VirtualMachineSuspended:
+		// Only a powered on VM can be suspended. This is enforced by the webhook
+		// but the VM may have been powered off out of band.
+		if !isOff && !isSuspended {
+			suspended, err := changePowerState(vmCtx, vcVM,
+				vimTypes.VirtualMachinePowerStateSuspended, vmCtx.VM.Spec.SuspendMode)
+			if err != nil {
+				return err
+			}
+			if !suspended {
+				break
+			}
 		}
 
-		if isOff {
+		markClassConfigurationSyncedCondition(vmCtx.VM, config, updateArgs, true)
+
+	case v1alpha1.VirtualMachinePoweredOn:
+		switch {
+		case isOff:
 			err := s.prepareVMForPowerOn(vmCtx, resVM, config, updateArgs)
 			if err != nil {
 				return err
 			}
+			if classResizeDisabled(updateArgs) {
+				markClassConfigurationSyncedCondition(vmCtx.VM, config, updateArgs, false)
+			} else {
+				markClassConfigurationSynced(vmCtx.VM, updateArgs.VMClass)
+			}
 
-			err = resVM.SetPowerState(vmCtx, v1alpha1.VirtualMachinePoweredOn)
+			err = virtualmachine.ChangePowerState(vmCtx, vcVM, vimTypes.VirtualMachinePowerStatePoweredOn, "")
 			if err != nil {
 				return err
 			}
-		} else {
+
+			// Powering on the VM satisfies any pending restart.
+			if restartTime, err := util.PendingTriggerTime(vmCtx.VM.Spec.NextRestartTime, vmCtx.VM.Status.LastRestartTime); err != nil {
+				vmCtx.Logger.Error(err, "Ignoring invalid nextRestartTime")
+			} else if restartTime != nil {
+				vmCtx.VM.Status.LastRestartTime = restartTime
+			}
+		case isSuspended:
+			// Resume the VM. A suspended VM has already been prepared for power on.
+			err := virtualmachine.ChangePowerState(vmCtx, vcVM, vimTypes.VirtualMachinePowerStatePoweredOn, "")
+			if err != nil {
+				return err
+			}
+			markClassConfigurationSyncedCondition(vmCtx.VM, config, updateArgs, false)
+		default:
 			err := s.poweredOnVMReconfigure(vmCtx, resVM, config, updateArgs.ConfigSpec)
 			if err != nil {
 				return err
 			}
+			s.poweredOnVMResize(vmCtx, resVM, config, updateArgs)
+
+			err = s.powerCycleVMForClassRollout(vmCtx, vcVM, resVM, config, updateArgs)
+			if err != nil {
+				return err
+			}
+
+			err = s.poweredOnVMReapplyMetadata(vmCtx, vcVM, resVM, config, updateArgs)
+			if err != nil {
+				return err
+			}
+
+			if vmCtx.VM.Status.PendingSoftPowerOp != nil {
+				// The guest is shutting down so there is no point in restarting it.
+				break
+			}
+
+			err = restartVMIfNeeded(vmCtx, vcVM)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
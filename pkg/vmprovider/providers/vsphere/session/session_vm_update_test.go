@@ -714,6 +714,119 @@ var _ = Describe("Update ConfigSpec", func() {
 		})
 	})
 
+	Context("Hot Add", func() {
+		var classConfigSpec *vimTypes.VirtualMachineConfigSpec
+
+		BeforeEach(func() {
+			classConfigSpec = &vimTypes.VirtualMachineConfigSpec{}
+		})
+
+		It("Hot add not set in class config spec", func() {
+			session.UpdateConfigSpecHotAdd(config, configSpec, classConfigSpec)
+			Expect(configSpec.CpuHotAddEnabled).To(BeNil())
+			Expect(configSpec.MemoryHotAddEnabled).To(BeNil())
+		})
+
+		It("Hot add set in class config spec", func() {
+			classConfigSpec.CpuHotAddEnabled = pointer.Bool(true)
+			classConfigSpec.MemoryHotAddEnabled = pointer.Bool(true)
+
+			session.UpdateConfigSpecHotAdd(config, configSpec, classConfigSpec)
+			Expect(configSpec.CpuHotAddEnabled).To(Equal(pointer.Bool(true)))
+			Expect(configSpec.MemoryHotAddEnabled).To(Equal(pointer.Bool(true)))
+		})
+
+		It("Hot add already matches class config spec", func() {
+			classConfigSpec.CpuHotAddEnabled = pointer.Bool(true)
+			classConfigSpec.MemoryHotAddEnabled = pointer.Bool(false)
+			config.CpuHotAddEnabled = pointer.Bool(true)
+
+			session.UpdateConfigSpecHotAdd(config, configSpec, classConfigSpec)
+			Expect(configSpec.CpuHotAddEnabled).To(BeNil())
+			Expect(configSpec.MemoryHotAddEnabled).To(BeNil())
+		})
+	})
+
+	Context("Hot Resize", func() {
+		var resizeConfigSpec *vimTypes.VirtualMachineConfigSpec
+		var resizable bool
+
+		BeforeEach(func() {
+			config.Hardware.NumCPU = 2
+			config.Hardware.MemoryMB = 4096
+			resizeConfigSpec = &vimTypes.VirtualMachineConfigSpec{}
+		})
+
+		JustBeforeEach(func() {
+			resizable = session.UpdateConfigSpecHotResize(config, configSpec, resizeConfigSpec)
+		})
+
+		Context("Only allocation changes", func() {
+			BeforeEach(func() {
+				rsv := int64(1024)
+				resizeConfigSpec.MemoryAllocation = &vimTypes.ResourceAllocationInfo{Reservation: &rsv}
+			})
+
+			It("is resizable", func() {
+				Expect(resizable).To(BeTrue())
+				Expect(configSpec.MemoryAllocation).To(Equal(resizeConfigSpec.MemoryAllocation))
+			})
+		})
+
+		Context("CPU and memory increase", func() {
+			BeforeEach(func() {
+				resizeConfigSpec.NumCPUs = 4
+				resizeConfigSpec.MemoryMB = 8192
+			})
+
+			It("is not resizable when hot add is disabled", func() {
+				Expect(resizable).To(BeFalse())
+				Expect(configSpec.NumCPUs).To(BeZero())
+				Expect(configSpec.MemoryMB).To(BeZero())
+			})
+
+			When("hot add is enabled", func() {
+				BeforeEach(func() {
+					config.CpuHotAddEnabled = pointer.Bool(true)
+					config.MemoryHotAddEnabled = pointer.Bool(true)
+				})
+
+				It("is resizable", func() {
+					Expect(resizable).To(BeTrue())
+					Expect(configSpec.NumCPUs).To(BeEquivalentTo(4))
+					Expect(configSpec.MemoryMB).To(BeEquivalentTo(8192))
+				})
+			})
+		})
+
+		Context("CPU and memory decrease", func() {
+			BeforeEach(func() {
+				config.CpuHotAddEnabled = pointer.Bool(true)
+				config.MemoryHotAddEnabled = pointer.Bool(true)
+				resizeConfigSpec.NumCPUs = 1
+				resizeConfigSpec.MemoryMB = 2048
+			})
+
+			It("is not resizable", func() {
+				Expect(resizable).To(BeFalse())
+				Expect(configSpec.NumCPUs).To(BeZero())
+				Expect(configSpec.MemoryMB).To(BeZero())
+			})
+
+			When("CPU hot remove is enabled", func() {
+				BeforeEach(func() {
+					config.CpuHotRemoveEnabled = pointer.Bool(true)
+				})
+
+				It("removes CPUs but memory is not resizable", func() {
+					Expect(resizable).To(BeFalse())
+					Expect(configSpec.NumCPUs).To(BeEquivalentTo(1))
+					Expect(configSpec.MemoryMB).To(BeZero())
+				})
+			})
+		})
+	})
+
 	Context("Ethernet Card Changes", func() {
 		var expectedList object.VirtualDeviceList
 		var currentList object.VirtualDeviceList
@@ -42,3 +42,25 @@ func ClusterMinCPUFreq(ctx goctx.Context, cluster *object.ClusterComputeResource
 
 	return minFreq, nil
 }
+
+// ClusterHostHardware returns the hosts in the cluster with the properties that describe their hardware: the
+// summary.hardware, the hardware.pciDevice and the config.sharedPassthruGpuTypes.
+func ClusterHostHardware(ctx goctx.Context, cluster *object.ClusterComputeResource) ([]mo.HostSystem, error) {
+	var cr mo.ComputeResource
+	if err := cluster.Properties(ctx, cluster.Reference(), []string{"host"}, &cr); err != nil {
+		return nil, err
+	}
+
+	if len(cr.Host) == 0 {
+		return nil, nil
+	}
+
+	var hosts []mo.HostSystem
+	pc := property.DefaultCollector(cluster.Client())
+	props := []string{"summary.hardware", "hardware.pciDevice", "config.sharedPassthruGpuTypes"}
+	if err := pc.Retrieve(ctx, cr.Host, props, &hosts); err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+}
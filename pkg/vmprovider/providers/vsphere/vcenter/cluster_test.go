@@ -13,6 +13,7 @@ import (
 
 func clusterTests() {
 	Describe("ClusterMinCPUFreq", minFreq)
+	Describe("ClusterHostHardware", hostHardware)
 }
 
 func minFreq() {
@@ -45,3 +46,31 @@ func minFreq() {
 		})
 	})
 }
+
+func hostHardware() {
+	var (
+		ctx *builder.TestContextForVCSim
+	)
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{})
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	It("returns the hardware of the hosts in cluster", func() {
+		hosts, err := vcenter.ClusterHostHardware(ctx, ctx.GetSingleClusterCompute())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hosts).ToNot(BeEmpty())
+
+		for _, host := range hosts {
+			Expect(host.Summary.Hardware).ToNot(BeNil())
+			Expect(host.Summary.Hardware.MemorySize).To(BeNumerically(">", 0))
+			Expect(host.Hardware).ToNot(BeNil())
+			Expect(host.Hardware.PciDevice).ToNot(BeEmpty())
+		}
+	})
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 )
 
@@ -23,7 +25,8 @@ func DeleteVirtualMachine(
 	// Only a powered off VM can be destroyed.
 	if state != types.VirtualMachinePowerStatePoweredOff {
 		vmCtx.Logger.Info("Powering off VM prior to destroy", "currentState", state)
-		if err := ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff); err != nil {
+		// Always hard power off so the VM cannot block its own deletion.
+		if err := ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff, vmopv1alpha1.VirtualMachinePowerOpModeHard); err != nil {
 			return err
 		}
 	}
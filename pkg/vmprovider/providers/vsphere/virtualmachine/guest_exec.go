@@ -0,0 +1,164 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	goctx "context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/guest"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+const (
+	// guestProcessPollInitialInterval is how soon the guest is first polled for the exit of a started process.
+	// The interval is doubled after each poll, up to the guestProcessPollMaxInterval, so that a long running
+	// process does not cost a guest operation every interval.
+	guestProcessPollInitialInterval = 500 * time.Millisecond
+	guestProcessPollMaxInterval     = 5 * time.Second
+
+	// guestProcessTerminateTimeout bounds terminating a process whose context is done.
+	guestProcessTerminateTimeout = 5 * time.Second
+)
+
+// RunGuestCommand runs the command inside the guest with the VMware Tools guest operations and returns its exit
+// code. If the context is done before the command exits, the guest process is terminated and an error returned.
+func RunGuestCommand(
+	vmCtx context.VirtualMachineContext,
+	vm *object.VirtualMachine,
+	username, password string,
+	command []string) (int32, error) {
+
+	if len(command) == 0 {
+		return 0, errors.New("command is empty")
+	}
+
+	procMgr, err := guest.NewOperationsManager(vm.Client(), vm.Reference()).ProcessManager(vmCtx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get guest process manager")
+	}
+
+	var o mo.VirtualMachine
+	if err := vm.Properties(vmCtx, vm.Reference(), []string{"guest.guestFamily", "config.guestId"}, &o); err != nil {
+		return 0, errors.Wrap(err, "failed to get VM guest OS")
+	}
+
+	auth := &types.NamePasswordAuthentication{
+		Username: username,
+		Password: password,
+	}
+
+	spec := &types.GuestProgramSpec{
+		ProgramPath: command[0],
+		Arguments:   GuestCommandArguments(command[1:], isWindowsGuest(&o)),
+	}
+
+	pid, err := procMgr.StartProgram(vmCtx, auth, spec)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to start program %q in guest", spec.ProgramPath)
+	}
+
+	interval := guestProcessPollInitialInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		procs, err := procMgr.ListProcesses(vmCtx, auth, []int64{pid})
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to get guest process %d", pid)
+		}
+		if len(procs) == 0 {
+			return 0, errors.Errorf("guest process %d not found", pid)
+		}
+
+		if procs[0].EndTime != nil {
+			return procs[0].ExitCode, nil
+		}
+
+		select {
+		case <-vmCtx.Done():
+			// The VM context is done so use a new one to clean up the process.
+			ctx, cancel := goctx.WithTimeout(goctx.Background(), guestProcessTerminateTimeout)
+			if err := procMgr.TerminateProcess(ctx, auth, pid); err != nil {
+				vmCtx.Logger.Error(err, "Failed to terminate guest process", "pid", pid)
+			}
+			cancel()
+			return 0, errors.Wrapf(vmCtx.Err(), "guest process %d did not exit", pid)
+		case <-timer.C:
+			if interval *= 2; interval > guestProcessPollMaxInterval {
+				interval = guestProcessPollMaxInterval
+			}
+			timer.Reset(interval)
+		}
+	}
+}
+
+// GuestCommandArguments returns the command line of the arguments with each argument quoted so that the guest
+// passes it to the program as is. A Windows program splits its command line with the CommandLineToArgvW rules,
+// while the command line of any other guest is parsed by a POSIX shell.
+func GuestCommandArguments(args []string, windows bool) string {
+	quote := quotePosixArg
+	if windows {
+		quote = quoteWindowsArg
+	}
+
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, quote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+func isWindowsGuest(o *mo.VirtualMachine) bool {
+	if o.Guest != nil && o.Guest.GuestFamily != "" {
+		return o.Guest.GuestFamily == string(types.VirtualMachineGuestOsFamilyWindowsGuest)
+	}
+	// The guest family is only known while VMware Tools is running so fall back to the configured guest ID,
+	// which for all Windows versions starts with "win".
+	return o.Config != nil && strings.HasPrefix(strings.ToLower(o.Config.GuestId), "win")
+}
+
+func quotePosixArg(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-+=.,:/@%", r))
+	}) == -1 {
+		return arg
+	}
+	// Nothing is special inside single quotes, so a single quote is written by closing the quotes, escaping it,
+	// and opening the quotes again.
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func quoteWindowsArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\v\"") {
+		return arg
+	}
+
+	// Backslashes are only special before a double quote: 2n backslashes and a quote are n backslashes and the
+	// end of the quoted argument, while 2n+1 backslashes and a quote are n backslashes and a literal quote.
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for i := 0; i < len(arg); i++ {
+		switch arg[i] {
+		case '\\':
+			slashes++
+		case '"':
+			b.WriteString(strings.Repeat(`\`, slashes+1))
+			slashes = 0
+		default:
+			slashes = 0
+		}
+		b.WriteByte(arg[i])
+	}
+	b.WriteString(strings.Repeat(`\`, slashes))
+	b.WriteByte('"')
+	return b.String()
+}
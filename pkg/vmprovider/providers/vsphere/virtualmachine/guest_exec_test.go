@@ -0,0 +1,39 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
+)
+
+var _ = Describe("GuestCommandArguments", func() {
+
+	DescribeTable("POSIX guest",
+		func(args []string, expected string) {
+			Expect(virtualmachine.GuestCommandArguments(args, false)).To(Equal(expected))
+		},
+		Entry("no arguments", nil, ""),
+		Entry("plain arguments", []string{"-c", "/tmp/ready.sh"}, "-c /tmp/ready.sh"),
+		Entry("argument with spaces", []string{"-c", "test -f /tmp/ready"}, "-c 'test -f /tmp/ready'"),
+		Entry("argument with single quotes", []string{"it's"}, `'it'\''s'`),
+		Entry("argument with shell characters", []string{"$HOME;ls|wc"}, `'$HOME;ls|wc'`),
+		Entry("empty argument", []string{""}, "''"),
+	)
+
+	DescribeTable("Windows guest",
+		func(args []string, expected string) {
+			Expect(virtualmachine.GuestCommandArguments(args, true)).To(Equal(expected))
+		},
+		Entry("plain arguments", []string{"/c", `C:\ready.cmd`}, `/c C:\ready.cmd`),
+		Entry("argument with spaces", []string{"/c", `C:\Program Files\ready.cmd`}, `/c "C:\Program Files\ready.cmd"`),
+		Entry("argument with double quotes", []string{`say "hi"`}, `"say \"hi\""`),
+		Entry("argument with backslashes before a double quote", []string{`a\"b c`}, `"a\\\"b c"`),
+		Entry("argument with trailing backslashes", []string{`C:\a dir\`}, `"C:\a dir\\"`),
+		Entry("empty argument", []string{""}, `""`),
+	)
+})
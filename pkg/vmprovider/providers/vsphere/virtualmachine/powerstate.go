@@ -1,33 +1,176 @@
 package virtualmachine
 
 import (
+	goctx "context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/types"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 )
 
+// SoftPowerOpTimeout is how long the VM is given to realize the desired power
+// state after the guest is asked to perform a soft power operation. When the
+// mode is trySoft, a hard power op is issued once the timeout expires.
+var SoftPowerOpTimeout = 5 * time.Minute
+
+// ErrSoftPowerOpPending is returned when the guest was asked to change the
+// power state of the VM, but the VM has not realized the desired power state
+// yet. The caller should check again later rather than wait for the guest.
+var ErrSoftPowerOpPending = errors.New("waiting for the guest to change the power state")
+
+// ChangePowerState changes the power state of the VM to the desired state.
+// The mode is used when powering off or suspending the VM, and is ignored
+// when powering on the VM. An empty mode is treated as hard.
+//
+// A soft power op does not wait for the guest. The request is recorded in
+// the VM's status.pendingSoftPowerOp and ErrSoftPowerOpPending is returned
+// until the VM realizes the desired power state or the SoftPowerOpTimeout
+// expires.
 func ChangePowerState(
 	vmCtx context.VirtualMachineContext,
 	vcVM *object.VirtualMachine,
-	ps types.VirtualMachinePowerState) error {
+	ps types.VirtualMachinePowerState,
+	mode vmopv1alpha1.VirtualMachinePowerOpMode) error {
+
+	err := changePowerState(vmCtx, vcVM, ps, mode)
+	if !errors.Is(err, ErrSoftPowerOpPending) {
+		vmCtx.VM.Status.PendingSoftPowerOp = nil
+	}
+	if err == nil && ps != types.VirtualMachinePowerStatePoweredOn {
+		MarkNotStarted(vmCtx.VM, "VM is not powered on")
+	}
+	return err
+}
+
+// MarkNotStarted resets the VirtualMachineStartedCondition of a VM that was powered off or restarted, so
+// that its startup probe gates the readiness and liveness probes of the restarted guest.
+func MarkNotStarted(vm *vmopv1alpha1.VirtualMachine, msg string) {
+	if conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition) {
+		conditions.MarkFalse(vm, vmopv1alpha1.VirtualMachineStartedCondition,
+			vmopv1alpha1.StartupProbePendingReason, vmopv1alpha1.ConditionSeverityInfo, msg)
+	}
+}
 
-	var t *object.Task
-	var err error
+func changePowerState(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	ps types.VirtualMachinePowerState,
+	mode vmopv1alpha1.VirtualMachinePowerOpMode) error {
 
 	switch ps {
 	case types.VirtualMachinePowerStatePoweredOn:
-		t, err = vcVM.PowerOn(vmCtx)
+		return hardPowerOp(vmCtx, ps, vcVM.PowerOn)
 	case types.VirtualMachinePowerStatePoweredOff:
-		t, err = vcVM.PowerOff(vmCtx)
+		return powerOp(vmCtx, vcVM, ps, mode, vcVM.ShutdownGuest, vcVM.PowerOff)
+	case types.VirtualMachinePowerStateSuspended:
+		standbyGuest := func(ctx goctx.Context) error {
+			req := types.StandbyGuest{This: vcVM.Reference()}
+			_, err := methods.StandbyGuest(ctx, vcVM.Client(), &req)
+			return err
+		}
+		return powerOp(vmCtx, vcVM, ps, mode, standbyGuest, vcVM.Suspend)
 	default:
 		return fmt.Errorf("invalid power state %s", ps)
 	}
+}
+
+func powerOp(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	ps types.VirtualMachinePowerState,
+	mode vmopv1alpha1.VirtualMachinePowerOpMode,
+	softOpFn func(goctx.Context) error,
+	hardOpFn func(goctx.Context) (*object.Task, error)) error {
+
+	if mode == vmopv1alpha1.VirtualMachinePowerOpModeSoft || mode == vmopv1alpha1.VirtualMachinePowerOpModeTrySoft {
+		curState, err := vcVM.PowerState(vmCtx)
+		if err != nil {
+			return err
+		}
+
+		if curState == ps {
+			return nil
+		}
+
+		if curState != types.VirtualMachinePowerStatePoweredOn {
+			// The guest is not running so it cannot participate in the power op.
+			mode = vmopv1alpha1.VirtualMachinePowerOpModeHard
+		}
+	}
+
+	switch mode {
+	case vmopv1alpha1.VirtualMachinePowerOpModeSoft:
+		return softPowerOp(vmCtx, vcVM, ps, softOpFn)
+
+	case vmopv1alpha1.VirtualMachinePowerOpModeTrySoft:
+		err := softPowerOp(vmCtx, vcVM, ps, softOpFn)
+		if err == nil || errors.Is(err, ErrSoftPowerOpPending) {
+			return err
+		}
+		vmCtx.Logger.Info("Soft power op failed, falling back to hard power op",
+			"desiredState", ps, "error", err.Error())
+		return hardPowerOp(vmCtx, ps, hardOpFn)
+
+	case vmopv1alpha1.VirtualMachinePowerOpModeHard, "":
+		return hardPowerOp(vmCtx, ps, hardOpFn)
+
+	default:
+		return fmt.Errorf("invalid power op mode %s", mode)
+	}
+}
+
+// softPowerOp asks VMware Tools running in the guest to perform the power op.
+// ErrSoftPowerOpPending is returned while the VM has not realized the desired
+// power state, and an error is returned once the SoftPowerOpTimeout expires.
+func softPowerOp(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	ps types.VirtualMachinePowerState,
+	softOpFn func(goctx.Context) error) error {
+
+	desiredState := vmopv1alpha1.VirtualMachinePowerState(ps)
+
+	if pending := vmCtx.VM.Status.PendingSoftPowerOp; pending != nil && pending.PowerState == desiredState {
+		if time.Since(pending.RequestTime.Time) < SoftPowerOpTimeout {
+			return ErrSoftPowerOpPending
+		}
+		return errors.Errorf("timed out waiting for guest to change power state to %s", ps)
+	}
 
+	if err := softOpFn(vmCtx); err != nil {
+		return errors.Wrapf(err, "failed to request guest to change power state to %s", ps)
+	}
+
+	// The guest may have already realized the power state.
+	if curState, err := vcVM.PowerState(vmCtx); err == nil && curState == ps {
+		return nil
+	}
+
+	vmCtx.Logger.Info("Requested guest to change power state", "desiredState", ps)
+	vmCtx.VM.Status.PendingSoftPowerOp = &vmopv1alpha1.VirtualMachineSoftPowerOp{
+		PowerState:  desiredState,
+		RequestTime: metav1.Now(),
+	}
+	return ErrSoftPowerOpPending
+}
+
+func hardPowerOp(
+	vmCtx context.VirtualMachineContext,
+	ps types.VirtualMachinePowerState,
+	hardOpFn func(goctx.Context) (*object.Task, error)) error {
+
+	t, err := hardOpFn(vmCtx)
 	if err != nil {
 		return errors.Wrapf(err, "failed task creation to change power state to %s", ps)
 	}
@@ -49,3 +192,65 @@ func ChangePowerState(
 
 	return nil
 }
+
+// RestartVirtualMachine restarts the VM using the provided mode. A soft restart
+// asks VMware Tools to reboot the guest and does not wait for the guest to
+// come back up. An empty mode is treated as trySoft.
+func RestartVirtualMachine(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	mode vmopv1alpha1.VirtualMachinePowerOpMode) error {
+
+	err := restartVirtualMachine(vmCtx, vcVM, mode)
+	if err == nil {
+		MarkNotStarted(vmCtx.VM, "VM was restarted")
+	}
+	return err
+}
+
+func restartVirtualMachine(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	mode vmopv1alpha1.VirtualMachinePowerOpMode) error {
+
+	switch mode {
+	case vmopv1alpha1.VirtualMachinePowerOpModeSoft:
+		if err := vcVM.RebootGuest(vmCtx); err != nil {
+			return errors.Wrapf(err, "failed to request guest to reboot")
+		}
+		return nil
+
+	case vmopv1alpha1.VirtualMachinePowerOpModeTrySoft, "":
+		err := vcVM.RebootGuest(vmCtx)
+		if err == nil {
+			return nil
+		}
+		vmCtx.Logger.Info("Guest reboot failed, falling back to reset", "error", err.Error())
+		return resetVirtualMachine(vmCtx, vcVM)
+
+	case vmopv1alpha1.VirtualMachinePowerOpModeHard:
+		return resetVirtualMachine(vmCtx, vcVM)
+
+	default:
+		return fmt.Errorf("invalid restart mode %s", mode)
+	}
+}
+
+func resetVirtualMachine(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine) error {
+
+	t, err := vcVM.Reset(vmCtx)
+	if err != nil {
+		return errors.Wrapf(err, "failed task creation to reset VM")
+	}
+
+	if taskInfo, err := t.WaitForResult(vmCtx); err != nil {
+		if taskInfo != nil {
+			vmCtx.Logger.V(5).Error(err, "Reset task failed", "taskInfo", taskInfo)
+		}
+		return errors.Wrapf(err, "reset VM task failed")
+	}
+
+	return nil
+}
@@ -4,10 +4,15 @@
 package virtualmachine_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
@@ -42,7 +47,7 @@ func powerStateTests() {
 	})
 
 	It("Turns VM off", func() {
-		err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff)
+		err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff, "")
 		Expect(err).ToNot(HaveOccurred())
 
 		state, err := vcVM.PowerState(ctx)
@@ -55,7 +60,7 @@ func powerStateTests() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(t.Wait(ctx)).To(Succeed())
 
-		err = virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOn)
+		err = virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOn, "")
 		Expect(err).ToNot(HaveOccurred())
 
 		state, err := vcVM.PowerState(ctx)
@@ -68,7 +73,156 @@ func powerStateTests() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOn))
 
-		err = virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOn)
+		err = virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOn, "")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Turns VM off with soft power op mode", func() {
+		err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff, vmopv1alpha1.VirtualMachinePowerOpModeSoft)
+		Expect(err).ToNot(HaveOccurred())
+
+		state, err := vcVM.PowerState(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+	})
+
+	Context("when a soft power off is pending", func() {
+		var requestTime time.Time
+
+		JustBeforeEach(func() {
+			vmCtx.VM.Status.PendingSoftPowerOp = &vmopv1alpha1.VirtualMachineSoftPowerOp{
+				PowerState:  vmopv1alpha1.VirtualMachinePoweredOff,
+				RequestTime: metav1.NewTime(requestTime),
+			}
+		})
+
+		When("the soft power op timeout has not expired", func() {
+			BeforeEach(func() {
+				requestTime = time.Now()
+			})
+
+			It("Returns pending without waiting for the guest", func() {
+				err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff, vmopv1alpha1.VirtualMachinePowerOpModeTrySoft)
+				Expect(err).To(MatchError(virtualmachine.ErrSoftPowerOpPending))
+				Expect(vmCtx.VM.Status.PendingSoftPowerOp).ToNot(BeNil())
+
+				state, err := vcVM.PowerState(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+			})
+		})
+
+		When("the soft power op timeout has expired", func() {
+			BeforeEach(func() {
+				requestTime = time.Now().Add(-virtualmachine.SoftPowerOpTimeout)
+			})
+
+			It("Turns VM off with trySoft power op mode", func() {
+				err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff, vmopv1alpha1.VirtualMachinePowerOpModeTrySoft)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vmCtx.VM.Status.PendingSoftPowerOp).To(BeNil())
+
+				state, err := vcVM.PowerState(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+			})
+
+			It("Returns error with soft power op mode", func() {
+				err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff, vmopv1alpha1.VirtualMachinePowerOpModeSoft)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("timed out"))
+				Expect(vmCtx.VM.Status.PendingSoftPowerOp).To(BeNil())
+
+				state, err := vcVM.PowerState(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+			})
+		})
+	})
+
+	It("Suspends VM", func() {
+		err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStateSuspended, vmopv1alpha1.VirtualMachinePowerOpModeHard)
+		Expect(err).ToNot(HaveOccurred())
+
+		state, err := vcVM.PowerState(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(types.VirtualMachinePowerStateSuspended))
+	})
+
+	It("Suspends VM with trySoft power op mode when guest cannot be suspended", func() {
+		// vcsim does not support StandbyGuest so this falls back to a hard suspend.
+		err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStateSuspended, vmopv1alpha1.VirtualMachinePowerOpModeTrySoft)
+		Expect(err).ToNot(HaveOccurred())
+
+		state, err := vcVM.PowerState(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(types.VirtualMachinePowerStateSuspended))
+	})
+
+	It("Returns error when suspending VM with soft power op mode fails", func() {
+		err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStateSuspended, vmopv1alpha1.VirtualMachinePowerOpModeSoft)
+		Expect(err).To(HaveOccurred())
+
+		state, err := vcVM.PowerState(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+	})
+
+	It("Turns suspended VM off with soft power op mode", func() {
+		t, err := vcVM.Suspend(ctx)
 		Expect(err).ToNot(HaveOccurred())
+		Expect(t.Wait(ctx)).To(Succeed())
+
+		err = virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff, vmopv1alpha1.VirtualMachinePowerOpModeSoft)
+		Expect(err).ToNot(HaveOccurred())
+
+		state, err := vcVM.PowerState(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+	})
+
+	It("Resumes suspended VM", func() {
+		t, err := vcVM.Suspend(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.Wait(ctx)).To(Succeed())
+
+		err = virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOn, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		state, err := vcVM.PowerState(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+	})
+
+	It("Returns error for invalid power op mode", func() {
+		err := virtualmachine.ChangePowerState(vmCtx, vcVM, types.VirtualMachinePowerStatePoweredOff, "bogus")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Restarts VM with hard restart mode", func() {
+		err := virtualmachine.RestartVirtualMachine(vmCtx, vcVM, vmopv1alpha1.VirtualMachinePowerOpModeHard)
+		Expect(err).ToNot(HaveOccurred())
+
+		state, err := vcVM.PowerState(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+	})
+
+	It("Restarts VM with default restart mode", func() {
+		err := virtualmachine.RestartVirtualMachine(vmCtx, vcVM, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		state, err := vcVM.PowerState(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+	})
+
+	It("Returns error when restarting powered off VM", func() {
+		t, err := vcVM.PowerOff(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.Wait(ctx)).To(Succeed())
+
+		err = virtualmachine.RestartVirtualMachine(vmCtx, vcVM, vmopv1alpha1.VirtualMachinePowerOpModeSoft)
+		Expect(err).To(HaveOccurred())
 	})
 }
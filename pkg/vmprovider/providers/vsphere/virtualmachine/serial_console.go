@@ -0,0 +1,95 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+// GetSerialConsoleTicket returns the URI to connect to the serial console of the VM through the vSPC at proxyURI,
+// encrypted with the pubKey. The URI includes the uuid of the WebConsoleRequest and the expiry time of the ticket
+// so that the vSPC can validate the connection. The VM must already have a serial port that is backed by the vSPC,
+// since one cannot be added to a powered on VM.
+func GetSerialConsoleTicket(
+	vmCtx context.VirtualMachineContext,
+	vm *object.VirtualMachine,
+	proxyURI string,
+	pubKey string,
+	uuid string,
+	expiry time.Time) (string, error) {
+
+	vmCtx.Logger.V(5).Info("GetSerialConsoleTicket")
+
+	// The vSPC identifies the serial port of the VM by the service URI. The MoID is short enough that the URI
+	// still fits in the plaintext that can be encrypted with a 2048-bit RSA OAEP key.
+	serviceURI := vm.Reference().Value
+
+	devices, err := vm.Device(vmCtx)
+	if err != nil {
+		return "", err
+	}
+
+	if GetSerialConsolePort(devices, proxyURI, serviceURI) == nil {
+		return "", vmprovider.ErrSerialConsolePortNotFound
+	}
+
+	query := url.Values{}
+	query.Set("uuid", uuid)
+	query.Set("expires", strconv.FormatInt(expiry.Unix(), 10))
+
+	uri := fmt.Sprintf("%s/%s?%s", strings.TrimSuffix(proxyURI, "/"), serviceURI, query.Encode())
+	return EncryptWebMKS(pubKey, uri)
+}
+
+// SerialConsolePortDeviceChanges returns the device changes to add a serial port that is backed by the vSPC at
+// proxyURI for the serviceURI, or nil if the devices already have such a port. A serial port cannot be added to
+// a powered on VM, so the changes must be applied while the VM is powered off.
+func SerialConsolePortDeviceChanges(
+	devices object.VirtualDeviceList,
+	proxyURI, serviceURI string) ([]types.BaseVirtualDeviceConfigSpec, error) {
+
+	if GetSerialConsolePort(devices, proxyURI, serviceURI) != nil {
+		return nil, nil
+	}
+
+	port, err := devices.CreateSerialPort()
+	if err != nil {
+		return nil, err
+	}
+	devices.ConnectSerialPort(port, serviceURI, true, proxyURI)
+	port.Connectable = &types.VirtualDeviceConnectInfo{
+		StartConnected: true,
+		Connected:      true,
+	}
+
+	return []types.BaseVirtualDeviceConfigSpec{
+		&types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    port,
+		},
+	}, nil
+}
+
+// GetSerialConsolePort returns the serial port in the devices that is backed by the vSPC at proxyURI for the
+// serviceURI, or nil if there is no such port.
+func GetSerialConsolePort(devices object.VirtualDeviceList, proxyURI, serviceURI string) *types.VirtualSerialPort {
+	for _, device := range devices.SelectByType((*types.VirtualSerialPort)(nil)) {
+		port := device.(*types.VirtualSerialPort)
+		if backing, ok := port.Backing.(*types.VirtualSerialPortURIBackingInfo); ok &&
+			backing.ProxyURI == proxyURI && backing.ServiceURI == serviceURI {
+			return port
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	"crypto/rsa"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func serialConsoleTests() {
+	const proxyURI = "telnets://vspc.local:13370"
+
+	var (
+		ctx          *builder.TestContextForVCSim
+		vcVM         *object.VirtualMachine
+		vmCtx        context.VirtualMachineContext
+		privateKey   *rsa.PrivateKey
+		publicKeyPem string
+	)
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{})
+
+		var err error
+		vcVM, err = ctx.Finder.VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+		Expect(err).ToNot(HaveOccurred())
+
+		vmCtx = context.VirtualMachineContext{
+			Context: ctx,
+			Logger:  suite.GetLogger().WithValues("vmName", vcVM.Name()),
+			VM:      builder.DummyVirtualMachine(),
+		}
+
+		privateKey, publicKeyPem = builder.WebConsoleRequestKeyPair()
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	serialConsolePorts := func() []*types.VirtualSerialPort {
+		devices, err := vcVM.Device(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		var ports []*types.VirtualSerialPort
+		for _, device := range devices.SelectByType((*types.VirtualSerialPort)(nil)) {
+			port := device.(*types.VirtualSerialPort)
+			if backing, ok := port.Backing.(*types.VirtualSerialPortURIBackingInfo); ok && backing.ProxyURI == proxyURI {
+				ports = append(ports, port)
+			}
+		}
+		return ports
+	}
+
+	addSerialConsolePort := func() {
+		devices, err := vcVM.Device(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		deviceChanges, err := virtualmachine.SerialConsolePortDeviceChanges(devices, proxyURI, vcVM.Reference().Value)
+		Expect(err).ToNot(HaveOccurred())
+
+		task, err := vcVM.Reconfigure(ctx, types.VirtualMachineConfigSpec{DeviceChange: deviceChanges})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(task.Wait(ctx)).To(Succeed())
+	}
+
+	Context("SerialConsolePortDeviceChanges", func() {
+		It("returns the device change to add a serial port backed by the vSPC", func() {
+			addSerialConsolePort()
+
+			ports := serialConsolePorts()
+			Expect(ports).To(HaveLen(1))
+			backing := ports[0].Backing.(*types.VirtualSerialPortURIBackingInfo)
+			Expect(backing.ServiceURI).To(Equal(vcVM.Reference().Value))
+			Expect(backing.Direction).To(Equal(string(types.VirtualDeviceURIBackingOptionDirectionClient)))
+
+			By("returns no device change when the VM already has the serial port", func() {
+				devices, err := vcVM.Device(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				deviceChanges, err := virtualmachine.SerialConsolePortDeviceChanges(devices, proxyURI, vcVM.Reference().Value)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deviceChanges).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("GetSerialConsoleTicket", func() {
+		var expiry time.Time
+
+		BeforeEach(func() {
+			expiry = time.Unix(1700000000, 0)
+		})
+
+		It("returns the encrypted URI with the request UUID and expiry", func() {
+			addSerialConsolePort()
+
+			ticket, err := virtualmachine.GetSerialConsoleTicket(vmCtx, vcVM, proxyURI, publicKeyPem, "some-uuid", expiry)
+			Expect(err).ToNot(HaveOccurred())
+
+			uri, err := virtualmachine.DecryptWebMKS(privateKey, ticket)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uri).To(Equal(proxyURI + "/" + vcVM.Reference().Value + "?expires=1700000000&uuid=some-uuid"))
+			Expect(serialConsolePorts()).To(HaveLen(1))
+		})
+
+		It("returns an error and does not add a serial port when the VM does not have one", func() {
+			_, err := virtualmachine.GetSerialConsoleTicket(vmCtx, vcVM, proxyURI, publicKeyPem, "some-uuid", expiry)
+			Expect(err).To(MatchError(vmprovider.ErrSerialConsolePortNotFound))
+			Expect(serialConsolePorts()).To(BeEmpty())
+		})
+
+		It("returns an error for an invalid public key", func() {
+			addSerialConsolePort()
+
+			_, err := virtualmachine.GetSerialConsoleTicket(vmCtx, vcVM, proxyURI, "invalid-pub-key", "some-uuid", expiry)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
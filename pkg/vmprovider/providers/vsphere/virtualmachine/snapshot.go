@@ -0,0 +1,264 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+// snapshotUIDDescriptionPrefix prefixes the line of a snapshot's description that tags the snapshot with the
+// UID of the VirtualMachineSnapshot it was taken for.
+const snapshotUIDDescriptionPrefix = "vmoperator.vmware.com/virtualmachinesnapshot-uid: "
+
+// snapshotNode is a snapshot in the VM's snapshot tree along with its parent, if any.
+type snapshotNode struct {
+	tree   *types.VirtualMachineSnapshotTree
+	parent *types.VirtualMachineSnapshotTree
+}
+
+func findSnapshotNodes(
+	trees []types.VirtualMachineSnapshotTree,
+	parent *types.VirtualMachineSnapshotTree,
+	match func(*types.VirtualMachineSnapshotTree) bool) []snapshotNode {
+
+	var nodes []snapshotNode
+	for i := range trees {
+		tree := &trees[i]
+		if match(tree) {
+			nodes = append(nodes, snapshotNode{tree: tree, parent: parent})
+		}
+		nodes = append(nodes, findSnapshotNodes(tree.ChildSnapshotList, tree, match)...)
+	}
+	return nodes
+}
+
+// lookupSnapshot returns the snapshot of the VM with the ID that is recorded in the VirtualMachineSnapshot's
+// status.uniqueID, or nil if the VM has no such snapshot. A snapshot is never matched by its name because the
+// names of a VM's snapshots are not unique and can be changed outside of VM Operator.
+func lookupSnapshot(moVM *mo.VirtualMachine, id string) *snapshotNode {
+	if moVM.Snapshot == nil || id == "" {
+		return nil
+	}
+
+	nodes := findSnapshotNodes(moVM.Snapshot.RootSnapshotList, nil, func(t *types.VirtualMachineSnapshotTree) bool {
+		return t.Snapshot.Value == id
+	})
+	if len(nodes) == 0 {
+		return nil
+	}
+	return &nodes[0]
+}
+
+// lookupTaggedSnapshot returns the snapshot of the VM whose description is tagged with the UID of the
+// VirtualMachineSnapshot, or nil if the VM has no such snapshot. This finds a snapshot that was taken but whose
+// ID was not recorded in the VirtualMachineSnapshot's status, like when the status update failed.
+func lookupTaggedSnapshot(moVM *mo.VirtualMachine, vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) *snapshotNode {
+	if moVM.Snapshot == nil || vmSnapshot.UID == "" {
+		return nil
+	}
+
+	tag := snapshotDescriptionTag(vmSnapshot)
+	nodes := findSnapshotNodes(moVM.Snapshot.RootSnapshotList, nil, func(t *types.VirtualMachineSnapshotTree) bool {
+		for _, line := range strings.Split(t.Description, "\n") {
+			if line == tag {
+				return true
+			}
+		}
+		return false
+	})
+	if len(nodes) == 0 {
+		return nil
+	}
+	return &nodes[0]
+}
+
+func snapshotDescriptionTag(vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) string {
+	return snapshotUIDDescriptionPrefix + string(vmSnapshot.UID)
+}
+
+// snapshotDescription returns the description of the snapshot, which is tagged with the UID of the
+// VirtualMachineSnapshot so that the snapshot can be found even if its ID was not recorded.
+func snapshotDescription(vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) string {
+	if vmSnapshot.UID == "" {
+		return vmSnapshot.Spec.Description
+	}
+	if vmSnapshot.Spec.Description == "" {
+		return snapshotDescriptionTag(vmSnapshot)
+	}
+	return vmSnapshot.Spec.Description + "\n" + snapshotDescriptionTag(vmSnapshot)
+}
+
+func getSnapshotProperties(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine) (*mo.VirtualMachine, error) {
+
+	moVM := &mo.VirtualMachine{}
+	if err := vcVM.Properties(vmCtx, vcVM.Reference(), []string{"snapshot", "layoutEx"}, moVM); err != nil {
+		return nil, errors.Wrapf(err, "failed to get VM snapshot properties")
+	}
+	return moVM, nil
+}
+
+// CreateSnapshot takes the snapshot described by the VirtualMachineSnapshot if it does not already exist, and
+// updates the VirtualMachineSnapshot's status from the snapshot.
+func CreateSnapshot(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) error {
+
+	if vmSnapshot.Status.UniqueID == "" {
+		moVM, err := getSnapshotProperties(vmCtx, vcVM)
+		if err != nil {
+			return err
+		}
+
+		// The snapshot may have been taken by an earlier reconcile that failed to record its ID.
+		if node := lookupTaggedSnapshot(moVM, vmSnapshot); node != nil {
+			vmCtx.Logger.Info("Found existing snapshot", "snapshotName", vmSnapshot.Name,
+				"snapshotID", node.tree.Snapshot.Value)
+			updateSnapshotStatus(moVM, node, vmSnapshot)
+			return nil
+		}
+
+		vmCtx.Logger.Info("Creating snapshot", "snapshotName", vmSnapshot.Name,
+			"memory", vmSnapshot.Spec.Memory, "quiesce", vmSnapshot.Spec.Quiesce)
+
+		t, err := vcVM.CreateSnapshot(vmCtx, vmSnapshot.Name, snapshotDescription(vmSnapshot),
+			vmSnapshot.Spec.Memory, vmSnapshot.Spec.Quiesce)
+		if err != nil {
+			return errors.Wrapf(err, "failed task creation to create snapshot")
+		}
+
+		taskInfo, err := t.WaitForResult(vmCtx)
+		if err != nil {
+			if taskInfo != nil {
+				vmCtx.Logger.V(5).Error(err, "create snapshot task failed", "taskInfo", taskInfo)
+			}
+			return errors.Wrapf(err, "create snapshot task failed")
+		}
+
+		ref, ok := taskInfo.Result.(types.ManagedObjectReference)
+		if !ok {
+			return errors.Errorf("create snapshot task result is %T instead of the snapshot", taskInfo.Result)
+		}
+
+		vmSnapshot.Status.UniqueID = ref.Value
+	}
+
+	moVM, err := getSnapshotProperties(vmCtx, vcVM)
+	if err != nil {
+		return err
+	}
+
+	node := lookupSnapshot(moVM, vmSnapshot.Status.UniqueID)
+	if node == nil {
+		return errors.Errorf("snapshot %s no longer exists", vmSnapshot.Status.UniqueID)
+	}
+
+	updateSnapshotStatus(moVM, node, vmSnapshot)
+	return nil
+}
+
+func updateSnapshotStatus(
+	moVM *mo.VirtualMachine,
+	node *snapshotNode,
+	vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) {
+
+	status := &vmSnapshot.Status
+	status.UniqueID = node.tree.Snapshot.Value
+	creationTime := metav1.NewTime(node.tree.CreateTime)
+	status.CreationTime = &creationTime
+	status.Quiesced = node.tree.Quiesced
+
+	var parentRef *types.ManagedObjectReference
+	if node.parent != nil {
+		status.Parent = node.parent.Name
+		parentRef = &node.parent.Snapshot
+	} else {
+		status.Parent = ""
+	}
+
+	if moVM.LayoutEx != nil {
+		isCurrent := moVM.Snapshot.CurrentSnapshot != nil && moVM.Snapshot.CurrentSnapshot.Value == node.tree.Snapshot.Value
+		size := object.SnapshotSize(node.tree.Snapshot, parentRef, moVM.LayoutEx, isCurrent)
+		status.Size = resource.NewQuantity(int64(size), resource.BinarySI)
+	}
+}
+
+// RevertToSnapshot reverts the VM to the snapshot that backs the VirtualMachineSnapshot.
+func RevertToSnapshot(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) error {
+
+	if vmSnapshot.Status.UniqueID == "" {
+		return errors.New("snapshot has not been created")
+	}
+
+	// The VM controller reconciles the power state afterwards so do not suppress the power on of a snapshot
+	// that was taken while the VM was powered on.
+	t, err := vcVM.RevertToSnapshot(vmCtx, vmSnapshot.Status.UniqueID, false)
+	if err != nil {
+		return errors.Wrapf(err, "failed task creation to revert to snapshot")
+	}
+
+	if taskInfo, err := t.WaitForResult(vmCtx); err != nil {
+		if taskInfo != nil {
+			vmCtx.Logger.V(5).Error(err, "revert to snapshot task failed", "taskInfo", taskInfo)
+		}
+		return errors.Wrapf(err, "revert to snapshot task failed")
+	}
+
+	return nil
+}
+
+// DeleteSnapshot removes the snapshot that backs the VirtualMachineSnapshot. The children of the snapshot are
+// not removed.
+func DeleteSnapshot(
+	vmCtx context.VirtualMachineContext,
+	vcVM *object.VirtualMachine,
+	vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) error {
+
+	moVM, err := getSnapshotProperties(vmCtx, vcVM)
+	if err != nil {
+		return err
+	}
+
+	var node *snapshotNode
+	if vmSnapshot.Status.UniqueID != "" {
+		node = lookupSnapshot(moVM, vmSnapshot.Status.UniqueID)
+	} else {
+		node = lookupTaggedSnapshot(moVM, vmSnapshot)
+	}
+	if node == nil {
+		// Snapshot was never created or does not exist.
+		return nil
+	}
+
+	consolidate := true
+	t, err := vcVM.RemoveSnapshot(vmCtx, node.tree.Snapshot.Value, false, &consolidate)
+	if err != nil {
+		return errors.Wrapf(err, "failed task creation to remove snapshot")
+	}
+
+	if taskInfo, err := t.WaitForResult(vmCtx); err != nil {
+		if taskInfo != nil {
+			vmCtx.Logger.V(5).Error(err, "remove snapshot task failed", "taskInfo", taskInfo)
+		}
+		return errors.Wrapf(err, "remove snapshot task failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func snapshotTests() {
+
+	var (
+		ctx        *builder.TestContextForVCSim
+		vcVM       *object.VirtualMachine
+		vmCtx      context.VirtualMachineContext
+		vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot
+	)
+
+	snapshotCount := func() int {
+		var o mo.VirtualMachine
+		Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"snapshot"}, &o)).To(Succeed())
+		if o.Snapshot == nil {
+			return 0
+		}
+		count := 0
+		var walk func(trees []types.VirtualMachineSnapshotTree)
+		walk = func(trees []types.VirtualMachineSnapshotTree) {
+			for _, t := range trees {
+				count++
+				walk(t.ChildSnapshotList)
+			}
+		}
+		walk(o.Snapshot.RootSnapshotList)
+		return count
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{})
+
+		var err error
+		vcVM, err = ctx.Finder.VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+		Expect(err).ToNot(HaveOccurred())
+
+		vm := builder.DummyVirtualMachine()
+		vmCtx = context.VirtualMachineContext{
+			Context: ctx,
+			Logger:  suite.GetLogger().WithValues("vmName", vcVM.Name()),
+			VM:      vm,
+		}
+
+		vmSnapshot = builder.DummyVirtualMachineSnapshot(vm.Namespace, "snapshot-1", vm.Name)
+		vmSnapshot.UID = "snapshot-1-uid"
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	It("Creates the snapshot and updates the status", func() {
+		vmSnapshot.Spec.Memory = true
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+
+		Expect(snapshotCount()).To(Equal(1))
+		Expect(vmSnapshot.Status.UniqueID).ToNot(BeEmpty())
+		Expect(vmSnapshot.Status.CreationTime).ToNot(BeNil())
+		Expect(vmSnapshot.Status.Parent).To(BeEmpty())
+		Expect(vmSnapshot.Status.Size).ToNot(BeNil())
+
+		ref, err := vcVM.FindSnapshot(ctx, vmSnapshot.Status.UniqueID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ref.Value).To(Equal(vmSnapshot.Status.UniqueID))
+	})
+
+	It("Can be called multiple times", func() {
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		uniqueID := vmSnapshot.Status.UniqueID
+
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		Expect(vmSnapshot.Status.UniqueID).To(Equal(uniqueID))
+		Expect(snapshotCount()).To(Equal(1))
+	})
+
+	It("Tags the snapshot description with the VirtualMachineSnapshot UID", func() {
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+
+		var o mo.VirtualMachine
+		Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"snapshot"}, &o)).To(Succeed())
+		Expect(o.Snapshot).ToNot(BeNil())
+		Expect(o.Snapshot.RootSnapshotList).To(HaveLen(1))
+		Expect(o.Snapshot.RootSnapshotList[0].Description).To(Equal(
+			"dummy-description\nvmoperator.vmware.com/virtualmachinesnapshot-uid: snapshot-1-uid"))
+	})
+
+	It("Does not take another snapshot when the ID of the snapshot was not recorded", func() {
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		uniqueID := vmSnapshot.Status.UniqueID
+
+		vmSnapshot.Status = vmopv1alpha1.VirtualMachineSnapshotStatus{}
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		Expect(vmSnapshot.Status.UniqueID).To(Equal(uniqueID))
+		Expect(vmSnapshot.Status.CreationTime).ToNot(BeNil())
+		Expect(snapshotCount()).To(Equal(1))
+	})
+
+	It("Deletes the snapshot when the ID of the snapshot was not recorded", func() {
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+
+		vmSnapshot.Status = vmopv1alpha1.VirtualMachineSnapshotStatus{}
+		Expect(virtualmachine.DeleteSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		Expect(snapshotCount()).To(BeZero())
+	})
+
+	It("Does not match a snapshot by its name", func() {
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		uniqueID := vmSnapshot.Status.UniqueID
+
+		sameName := builder.DummyVirtualMachineSnapshot(vmSnapshot.Namespace, vmSnapshot.Name, vmSnapshot.Spec.VirtualMachineName)
+		sameName.UID = "other-uid"
+
+		By("Deleting a snapshot that was not taken is a no-op", func() {
+			Expect(virtualmachine.DeleteSnapshot(vmCtx, vcVM, sameName)).To(Succeed())
+			Expect(snapshotCount()).To(Equal(1))
+		})
+
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, sameName)).To(Succeed())
+		Expect(sameName.Status.UniqueID).ToNot(Equal(uniqueID))
+		Expect(snapshotCount()).To(Equal(2))
+	})
+
+	It("Reports the parent snapshot", func() {
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+
+		child := builder.DummyVirtualMachineSnapshot(vmSnapshot.Namespace, "snapshot-2", vmSnapshot.Spec.VirtualMachineName)
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, child)).To(Succeed())
+		Expect(child.Status.Parent).To(Equal(vmSnapshot.Name))
+		Expect(snapshotCount()).To(Equal(2))
+	})
+
+	It("Reverts to the snapshot", func() {
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+
+		child := builder.DummyVirtualMachineSnapshot(vmSnapshot.Namespace, "snapshot-2", vmSnapshot.Spec.VirtualMachineName)
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, child)).To(Succeed())
+
+		Expect(virtualmachine.RevertToSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+
+		var o mo.VirtualMachine
+		Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"snapshot"}, &o)).To(Succeed())
+		Expect(o.Snapshot.CurrentSnapshot).ToNot(BeNil())
+		Expect(o.Snapshot.CurrentSnapshot.Value).To(Equal(vmSnapshot.Status.UniqueID))
+	})
+
+	It("Returns error when reverting to a snapshot that was not taken", func() {
+		Expect(virtualmachine.RevertToSnapshot(vmCtx, vcVM, vmSnapshot)).ToNot(Succeed())
+	})
+
+	It("Deletes the snapshot", func() {
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		Expect(virtualmachine.DeleteSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		Expect(snapshotCount()).To(BeZero())
+
+		By("Deleting the snapshot again is a no-op", func() {
+			Expect(virtualmachine.DeleteSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		})
+	})
+
+	It("Returns error when the snapshot no longer exists", func() {
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		Expect(virtualmachine.DeleteSnapshot(vmCtx, vcVM, vmSnapshot)).To(Succeed())
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)).ToNot(Succeed())
+	})
+}
@@ -16,6 +16,8 @@ func vcSimTests() {
 	Describe("Delete", deleteTests)
 	Describe("Power State", powerStateTests)
 	Describe("Publish", publishTests)
+	Describe("Serial Console", serialConsoleTests)
+	Describe("Snapshot", snapshotTests)
 }
 
 var suite = builder.NewTestSuite()
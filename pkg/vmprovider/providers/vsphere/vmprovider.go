@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -55,6 +56,9 @@ type vSphereVMProvider struct {
 
 	vcClientLock sync.Mutex
 	vcClient     *vcclient.Client
+
+	guestCredentialsLock sync.Mutex
+	guestCredentials     map[string]guestCredentials
 }
 
 func NewVSphereVMProviderFromClient(
@@ -275,22 +279,42 @@ func (vs *vSphereVMProvider) ComputeCPUMinFrequency(ctx goctx.Context) error {
 }
 
 func (vs *vSphereVMProvider) computeCPUMinFrequency(ctx goctx.Context) (uint64, error) {
-	// Get all the availability zones in order to calculate the minimum
-	// CPU frequencies for each of the zones' vSphere clusters.
-	availabilityZones, err := topology.GetAvailabilityZones(ctx, vs.k8sClient)
+	clusters, err := vs.getAvailabilityZoneClusters(ctx)
 	if err != nil {
 		return 0, err
 	}
 
+	var errs []error
+
+	var minFreq uint64
+	for _, ccr := range clusters {
+		freq, err := vcenter.ClusterMinCPUFreq(ctx, ccr)
+		if err != nil {
+			errs = append(errs, err)
+		} else if minFreq == 0 || freq < minFreq {
+			minFreq = freq
+		}
+	}
+
+	return minFreq, k8serrors.NewAggregate(errs)
+}
+
+// getAvailabilityZoneClusters returns the vSphere clusters of all the availability zones.
+func (vs *vSphereVMProvider) getAvailabilityZoneClusters(ctx goctx.Context) ([]*object.ClusterComputeResource, error) {
+	availabilityZones, err := topology.GetAvailabilityZones(ctx, vs.k8sClient)
+	if err != nil {
+		return nil, err
+	}
+
 	client, err := vs.getVcClient(ctx)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	if !lib.IsWcpFaultDomainsFSSEnabled() {
 		ccr, err := vcenter.GetResourcePoolOwnerMoRef(ctx, client.VimClient(), client.Config().ResourcePool)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 
 		// Only expect 1 AZ in this case.
@@ -299,9 +323,7 @@ func (vs *vSphereVMProvider) computeCPUMinFrequency(ctx goctx.Context) (uint64,
 		}
 	}
 
-	var errs []error
-
-	var minFreq uint64
+	var clusters []*object.ClusterComputeResource
 	for _, az := range availabilityZones {
 		moIDs := az.Spec.ClusterComputeResourceMoIDs
 		if len(moIDs) == 0 {
@@ -309,19 +331,81 @@ func (vs *vSphereVMProvider) computeCPUMinFrequency(ctx goctx.Context) (uint64,
 		}
 
 		for _, moID := range moIDs {
-			ccr := object.NewClusterComputeResource(client.VimClient(),
-				types.ManagedObjectReference{Type: "ClusterComputeResource", Value: moID})
-
-			freq, err := vcenter.ClusterMinCPUFreq(ctx, ccr)
-			if err != nil {
-				errs = append(errs, err)
-			} else if minFreq == 0 || freq < minFreq {
-				minFreq = freq
+			clusters = append(clusters, object.NewClusterComputeResource(client.VimClient(),
+				types.ManagedObjectReference{Type: "ClusterComputeResource", Value: moID}))
+		}
+	}
+
+	return clusters, nil
+}
+
+// GetHostHardware returns the hardware of the hosts in the vSphere clusters of all the availability zones.
+func (vs *vSphereVMProvider) GetHostHardware(ctx goctx.Context) (*vmprovider.HostHardware, error) {
+	clusters, err := vs.getAvailabilityZoneClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hostHardware := &vmprovider.HostHardware{}
+	vgpuProfiles := map[string]struct{}{}
+	pciDevices := map[vmprovider.PCIDevice]struct{}{}
+
+	for _, ccr := range clusters {
+		hosts, err := vcenter.ClusterHostHardware(ctx, ccr)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, host := range hosts {
+			if hw := host.Summary.Hardware; hw != nil {
+				cpuMHz := int64(hw.CpuMhz) * int64(hw.NumCpuCores)
+				if cpuMHz > hostHardware.MaxCPUMHz {
+					hostHardware.MaxCPUMHz = cpuMHz
+				}
+				memoryMB := hw.MemorySize / (1024 * 1024)
+				if memoryMB > hostHardware.MaxMemoryMB {
+					hostHardware.MaxMemoryMB = memoryMB
+				}
+				if freq := uint64(hw.CpuMhz); hostHardware.MinCPUFreqMHz == 0 || freq < hostHardware.MinCPUFreqMHz {
+					hostHardware.MinCPUFreqMHz = freq
+				}
+			}
+
+			if host.Config != nil {
+				for _, profile := range host.Config.SharedPassthruGpuTypes {
+					vgpuProfiles[profile] = struct{}{}
+				}
+			}
+
+			if host.Hardware != nil {
+				for _, dev := range host.Hardware.PciDevice {
+					// The IDs are unsigned 16-bit values that the API returns as signed.
+					pciDevices[vmprovider.PCIDevice{
+						VendorID: int(uint16(dev.VendorId)),
+						DeviceID: int(uint16(dev.DeviceId)),
+					}] = struct{}{}
+				}
 			}
 		}
 	}
 
-	return minFreq, k8serrors.NewAggregate(errs)
+	for profile := range vgpuProfiles {
+		hostHardware.VGPUProfiles = append(hostHardware.VGPUProfiles, profile)
+	}
+	sort.Strings(hostHardware.VGPUProfiles)
+
+	for dev := range pciDevices {
+		hostHardware.PCIDevices = append(hostHardware.PCIDevices, dev)
+	}
+	sort.Slice(hostHardware.PCIDevices, func(i, j int) bool {
+		a, b := hostHardware.PCIDevices[i], hostHardware.PCIDevices[j]
+		if a.VendorID != b.VendorID {
+			return a.VendorID < b.VendorID
+		}
+		return a.DeviceID < b.DeviceID
+	})
+
+	return hostHardware, nil
 }
 
 // ResVMToVirtualMachineImage isn't currently used.
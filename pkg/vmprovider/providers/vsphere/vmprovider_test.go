@@ -40,4 +40,16 @@ func cpuFreqTests() {
 			Expect(vmProvider.ComputeCPUMinFrequency(ctx)).To(Succeed())
 		})
 	})
+
+	Context("GetHostHardware", func() {
+		It("returns the hardware of the hosts", func() {
+			hostHardware, err := vmProvider.GetHostHardware(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hostHardware.MinCPUFreqMHz).To(BeNumerically(">", 0))
+			Expect(hostHardware.MaxCPUMHz).To(BeNumerically(">=", hostHardware.MinCPUFreqMHz))
+			Expect(hostHardware.MaxMemoryMB).To(BeNumerically(">", 0))
+			// The vcsim hosts have Intel Corporation (0x8086) PCI devices.
+			Expect(hostHardware.PCIDevices).To(ContainElement(HaveField("VendorID", 0x8086)))
+		})
+	})
 }
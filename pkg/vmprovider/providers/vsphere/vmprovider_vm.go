@@ -7,10 +7,14 @@ import (
 	goctx "context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
 	"github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	ctrlruntime "sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
@@ -37,6 +41,9 @@ type vmCreateArgs = session.VMCreateArgs
 type vmUpdateArgs = session.VMUpdateArgs
 type vmMetadata = session.VMMetadata
 
+// guestCredentialsTTL is how long the credentials in the Secret of an ExecAction are cached.
+const guestCredentialsTTL = 5 * time.Minute
+
 var (
 	createCountLock          sync.Mutex
 	concurrentCreateCount    int
@@ -105,6 +112,30 @@ func (vs *vSphereVMProvider) DeleteVirtualMachine(
 	return virtualmachine.DeleteVirtualMachine(vmCtx, vcVM)
 }
 
+func (vs *vSphereVMProvider) RestartVirtualMachine(
+	ctx goctx.Context,
+	vm *vmopv1alpha1.VirtualMachine,
+	mode vmopv1alpha1.VirtualMachinePowerOpMode) error {
+
+	vmCtx := context.VirtualMachineContext{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "restartVM")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName()),
+		VM:      vm,
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, true)
+	if err != nil {
+		return err
+	}
+
+	return virtualmachine.RestartVirtualMachine(vmCtx, vcVM, mode)
+}
+
 func (vs *vSphereVMProvider) PublishVirtualMachine(ctx goctx.Context, vm *vmopv1alpha1.VirtualMachine,
 	vmPub *vmopv1alpha1.VirtualMachinePublishRequest, cl *imgregv1a1.ContentLibrary, actID string) (string, error) {
 	vmCtx := context.VirtualMachineContext{
@@ -185,6 +216,207 @@ func (vs *vSphereVMProvider) GetVirtualMachineWebMKSTicket(
 	return ticket, nil
 }
 
+func (vs *vSphereVMProvider) GetVirtualMachineSerialConsoleTicket(
+	ctx goctx.Context,
+	vm *vmopv1alpha1.VirtualMachine,
+	pubKey, uuid string,
+	expiry time.Time) (string, error) {
+
+	proxyURI := lib.GetSerialConsoleProxyURI()
+	if proxyURI == "" {
+		return "", fmt.Errorf("serial console is not configured: %s is not set", lib.SerialConsoleProxyURIEnv)
+	}
+
+	vmCtx := context.VirtualMachineContext{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "serialconsole")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName()),
+		VM:      vm,
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return "", err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, true)
+	if err != nil {
+		return "", err
+	}
+
+	ticket, err := virtualmachine.GetSerialConsoleTicket(vmCtx, vcVM, proxyURI, pubKey, uuid, expiry)
+	if err != nil {
+		return "", err
+	}
+
+	return ticket, nil
+}
+
+func (vs *vSphereVMProvider) ExecVirtualMachineGuestCommand(
+	ctx goctx.Context,
+	vm *vmopv1alpha1.VirtualMachine,
+	action *vmopv1alpha1.ExecAction) (int32, error) {
+
+	vmCtx := context.VirtualMachineContext{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "exec")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName()),
+		VM:      vm,
+	}
+
+	secretKey := ctrlruntime.ObjectKey{Name: action.SecretName, Namespace: vm.Namespace}
+	creds, err := vs.getGuestCredentials(vmCtx, secretKey)
+	if err != nil {
+		return 0, err
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return 0, err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, true)
+	if err != nil {
+		return 0, err
+	}
+
+	exitCode, err := virtualmachine.RunGuestCommand(vmCtx, vcVM, creds.username, creds.password, action.Command)
+	if err != nil && isInvalidGuestLogin(err) {
+		// The credentials may have been changed in the Secret, so read it again on the next exec.
+		vs.forgetGuestCredentials(secretKey)
+	}
+	return exitCode, err
+}
+
+// guestCredentials are the credentials in the Secret of an ExecAction.
+type guestCredentials struct {
+	username string
+	password string
+	expiry   time.Time
+}
+
+// getGuestCredentials returns the credentials in the Secret. Secrets are not cached by the client, so the
+// credentials are cached for the guestCredentialsTTL instead of reading the Secret from the API server every
+// time a probe runs.
+func (vs *vSphereVMProvider) getGuestCredentials(
+	ctx goctx.Context,
+	secretKey ctrlruntime.ObjectKey) (guestCredentials, error) {
+
+	vs.guestCredentialsLock.Lock()
+	defer vs.guestCredentialsLock.Unlock()
+
+	if creds, ok := vs.guestCredentials[secretKey.String()]; ok && time.Now().Before(creds.expiry) {
+		return creds, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := vs.k8sClient.Get(ctx, secretKey, secret); err != nil {
+		return guestCredentials{}, errors.Wrapf(err, "failed to get guest credentials Secret %s", secretKey)
+	}
+
+	creds := guestCredentials{
+		username: string(secret.Data[corev1.BasicAuthUsernameKey]),
+		password: string(secret.Data[corev1.BasicAuthPasswordKey]),
+		expiry:   time.Now().Add(guestCredentialsTTL),
+	}
+	if creds.username == "" {
+		return guestCredentials{}, errors.Errorf("guest credentials Secret %s does not have the %q key",
+			secretKey, corev1.BasicAuthUsernameKey)
+	}
+
+	if vs.guestCredentials == nil {
+		vs.guestCredentials = map[string]guestCredentials{}
+	}
+	vs.guestCredentials[secretKey.String()] = creds
+	return creds, nil
+}
+
+func (vs *vSphereVMProvider) forgetGuestCredentials(secretKey ctrlruntime.ObjectKey) {
+	vs.guestCredentialsLock.Lock()
+	defer vs.guestCredentialsLock.Unlock()
+	delete(vs.guestCredentials, secretKey.String())
+}
+
+// isInvalidGuestLogin returns true if the error is because the guest rejected the credentials.
+func isInvalidGuestLogin(err error) bool {
+	err = errors.Cause(err)
+	if !soap.IsSoapFault(err) {
+		return false
+	}
+	switch soap.ToSoapFault(err).VimFault().(type) {
+	case types.InvalidGuestLogin, *types.InvalidGuestLogin:
+		return true
+	}
+	return false
+}
+
+func (vs *vSphereVMProvider) CreateOrUpdateVirtualMachineSnapshot(
+	ctx goctx.Context,
+	vm *vmopv1alpha1.VirtualMachine,
+	vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) error {
+
+	return vs.doVirtualMachineSnapshotOp(ctx, vm, vmSnapshot, "createSnapshot", virtualmachine.CreateSnapshot)
+}
+
+func (vs *vSphereVMProvider) RevertVirtualMachineSnapshot(
+	ctx goctx.Context,
+	vm *vmopv1alpha1.VirtualMachine,
+	vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) error {
+
+	return vs.doVirtualMachineSnapshotOp(ctx, vm, vmSnapshot, "revertSnapshot", virtualmachine.RevertToSnapshot)
+}
+
+func (vs *vSphereVMProvider) DeleteVirtualMachineSnapshot(
+	ctx goctx.Context,
+	vm *vmopv1alpha1.VirtualMachine,
+	vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot) error {
+
+	vmCtx := context.VirtualMachineContext{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "deleteSnapshot")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName(), "snapshotName", vmSnapshot.Name),
+		VM:      vm,
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, false)
+	if err != nil {
+		return err
+	} else if vcVM == nil {
+		// VM does not exist so neither does the snapshot.
+		return nil
+	}
+
+	return virtualmachine.DeleteSnapshot(vmCtx, vcVM, vmSnapshot)
+}
+
+func (vs *vSphereVMProvider) doVirtualMachineSnapshotOp(
+	ctx goctx.Context,
+	vm *vmopv1alpha1.VirtualMachine,
+	vmSnapshot *vmopv1alpha1.VirtualMachineSnapshot,
+	op string,
+	snapshotOpFn func(context.VirtualMachineContext, *object.VirtualMachine, *vmopv1alpha1.VirtualMachineSnapshot) error) error {
+
+	vmCtx := context.VirtualMachineContext{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, op)),
+		Logger:  log.WithValues("vmName", vm.NamespacedName(), "snapshotName", vmSnapshot.Name),
+		VM:      vm,
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, true)
+	if err != nil {
+		return err
+	}
+
+	return snapshotOpFn(vmCtx, vcVM, vmSnapshot)
+}
+
 func (vs *vSphereVMProvider) createVirtualMachine(
 	vmCtx context.VirtualMachineContext,
 	vcClient *vcclient.Client) (*object.VirtualMachine, error) {
@@ -494,6 +726,11 @@ func (vs *vSphereVMProvider) vmCreateGetPrereqs(
 		return nil, err
 	}
 
+	srcVM, err := GetVirtualMachineSource(vmCtx, vs.k8sClient)
+	if err != nil {
+		return nil, err
+	}
+
 	resourcePolicy, err := GetVMSetResourcePolicy(vmCtx, vs.k8sClient)
 	if err != nil {
 		return nil, err
@@ -509,6 +746,15 @@ func (vs *vSphereVMProvider) vmCreateGetPrereqs(
 	createArgs.VMImageStatus = vmImageStatus
 	createArgs.ContentLibraryUUID = clUUID
 	createArgs.VMMetadata = vmMD
+	if srcVM != nil {
+		createArgs.SourceVMMoID = srcVM.Status.UniqueID
+		createArgs.SourceVMVolumeDiskUUIDs = make(map[string]struct{}, len(srcVM.Status.Volumes))
+		for _, volume := range srcVM.Status.Volumes {
+			if volume.DiskUuid != "" {
+				createArgs.SourceVMVolumeDiskUUIDs[volume.DiskUuid] = struct{}{}
+			}
+		}
+	}
 
 	// TODO: Perhaps a condition type for each resource is better so all missing one(s)
 	// 	     can be reported at once (and will help for the best-effort update changes).
@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"os"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -26,12 +28,15 @@ import (
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/conditions"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/instancestorage"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/session"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/virtualmachine"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
@@ -903,6 +908,390 @@ func vmTests() {
 				Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOff))
 			})
 
+			It("Does not wait for the guest when a soft power off is pending", func() {
+				vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+				Expect(err).ToNot(HaveOccurred())
+
+				vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOff
+				vm.Spec.PowerOffMode = vmopv1alpha1.VirtualMachinePowerOpModeTrySoft
+				vm.Status.PendingSoftPowerOp = &vmopv1alpha1.VirtualMachineSoftPowerOp{
+					PowerState:  vmopv1alpha1.VirtualMachinePoweredOff,
+					RequestTime: metav1.Now(),
+				}
+				Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+				Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachinePoweredOn))
+				Expect(vm.Status.PendingSoftPowerOp).ToNot(BeNil())
+
+				By("Powers VM off once the soft power op timed out", func() {
+					vm.Status.PendingSoftPowerOp.RequestTime = metav1.NewTime(time.Now().Add(-virtualmachine.SoftPowerOpTimeout))
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachinePoweredOff))
+					Expect(vm.Status.PendingSoftPowerOp).To(BeNil())
+					state, err := vcVM.PowerState(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+				})
+			})
+
+			It("Clears a pending soft power off when the VM is set back to powered on", func() {
+				_, err := createOrUpdateAndGetVcVM(ctx, vm)
+				Expect(err).ToNot(HaveOccurred())
+
+				vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+				vm.Spec.NextRestartTime = time.Now().UTC().Add(-time.Minute).Format(time.RFC3339Nano)
+				vm.Spec.RestartMode = vmopv1alpha1.VirtualMachinePowerOpModeHard
+				vm.Status.PendingSoftPowerOp = &vmopv1alpha1.VirtualMachineSoftPowerOp{
+					PowerState:  vmopv1alpha1.VirtualMachinePoweredOff,
+					RequestTime: metav1.Now(),
+				}
+				Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+				Expect(vm.Status.PendingSoftPowerOp).To(BeNil())
+				Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachinePoweredOn))
+				Expect(vm.Status.LastRestartTime).ToNot(BeNil())
+			})
+
+			It("Suspends and resumes VM", func() {
+				vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachinePoweredOn))
+				vm.Spec.PowerState = vmopv1alpha1.VirtualMachineSuspended
+				vm.Spec.SuspendMode = vmopv1alpha1.VirtualMachinePowerOpModeTrySoft
+				Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+				Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachineSuspended))
+				state, err := vcVM.PowerState(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(state).To(Equal(types.VirtualMachinePowerStateSuspended))
+
+				vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+				Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+				Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachinePoweredOn))
+				state, err = vcVM.PowerState(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+			})
+
+			Context("Restart", func() {
+				var restartTime string
+
+				BeforeEach(func() {
+					restartTime = time.Now().UTC().Add(-time.Minute).Format(time.RFC3339Nano)
+				})
+
+				It("Restarts VM when nextRestartTime is later than lastRestartTime", func() {
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vm.Status.LastRestartTime).To(BeNil())
+					Expect(vm.Status.BootTime).ToNot(BeNil())
+					conditions.MarkTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)
+
+					vm.Spec.NextRestartTime = restartTime
+					vm.Spec.RestartMode = vmopv1alpha1.VirtualMachinePowerOpModeHard
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(vm.Status.LastRestartTime).ToNot(BeNil())
+					Expect(vm.Status.LastRestartTime.UTC().Format(time.RFC3339Nano)).To(Equal(restartTime))
+					Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachinePoweredOn))
+					Expect(conditions.IsFalse(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(BeTrue())
+
+					By("Does not restart VM again for the same nextRestartTime", func() {
+						lastRestartTime := vm.Status.LastRestartTime.DeepCopy()
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.LastRestartTime).To(Equal(lastRestartTime))
+					})
+				})
+
+				It("Powering on VM satisfies nextRestartTime", func() {
+					vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOff
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					vm.Spec.NextRestartTime = restartTime
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(vm.Status.LastRestartTime).To(BeNil())
+
+					vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(vm.Status.LastRestartTime).ToNot(BeNil())
+					Expect(vm.Status.LastRestartTime.UTC().Format(time.RFC3339Nano)).To(Equal(restartTime))
+				})
+			})
+
+			Context("Snapshot", func() {
+				It("Creates, reverts to, and deletes a snapshot of the VM", func() {
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					vmSnapshot := builder.DummyVirtualMachineSnapshot(vm.Namespace, "snapshot-1", vm.Name)
+					Expect(vmProvider.CreateOrUpdateVirtualMachineSnapshot(ctx, vm, vmSnapshot)).To(Succeed())
+					Expect(vmSnapshot.Status.UniqueID).ToNot(BeEmpty())
+					Expect(vmSnapshot.Status.CreationTime).ToNot(BeNil())
+
+					Expect(vmProvider.RevertVirtualMachineSnapshot(ctx, vm, vmSnapshot)).To(Succeed())
+
+					Expect(vmProvider.DeleteVirtualMachineSnapshot(ctx, vm, vmSnapshot)).To(Succeed())
+					Expect(vmProvider.CreateOrUpdateVirtualMachineSnapshot(ctx, vm, vmSnapshot)).ToNot(Succeed())
+				})
+
+				It("Deleting a snapshot of a VM that does not exist is a no-op", func() {
+					vmSnapshot := builder.DummyVirtualMachineSnapshot(vm.Namespace, "snapshot-1", vm.Name)
+					Expect(vmProvider.DeleteVirtualMachineSnapshot(ctx, vm, vmSnapshot)).To(Succeed())
+				})
+			})
+
+			Context("Clone from VirtualMachine", func() {
+				var cloneVM *vmopv1alpha1.VirtualMachine
+
+				JustBeforeEach(func() {
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ctx.Client.Create(ctx, vm.DeepCopy())).To(Succeed())
+
+					cloneVM = builder.DummyBasicVirtualMachine("test-vm-clone", vm.Namespace)
+					cloneVM.Spec.ClassName = vm.Spec.ClassName
+					cloneVM.Spec.ImageName = vm.Spec.ImageName
+					cloneVM.Spec.StorageClass = vm.Spec.StorageClass
+					cloneVM.Spec.Source = &vmopv1alpha1.VirtualMachineSource{VirtualMachineName: vm.Name}
+				})
+
+				It("Clones the source VM", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, cloneVM)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(cloneVM.Status.UniqueID).ToNot(Equal(vm.Status.UniqueID))
+					Expect(vcVM.Name()).To(Equal(cloneVM.Name))
+				})
+
+				It("Does not clone the disks of the source VM's volumes", func() {
+					srcVcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					devices, err := srcVcVM.Device(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					srcDisks := devices.SelectByType((*types.VirtualDisk)(nil))
+					Expect(srcDisks).To(HaveLen(1))
+					backing := srcDisks[0].(*types.VirtualDisk).Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+
+					srcVM := &vmopv1alpha1.VirtualMachine{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vm), srcVM)).To(Succeed())
+					srcVM.Status.Volumes = []vmopv1alpha1.VirtualMachineVolumeStatus{
+						{Name: "pvc-volume", Attached: true, DiskUuid: backing.Uuid},
+					}
+					Expect(ctx.Client.Status().Update(ctx, srcVM)).To(Succeed())
+
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, cloneVM)
+					Expect(err).ToNot(HaveOccurred())
+					devices, err = vcVM.Device(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(devices.SelectByType((*types.VirtualDisk)(nil))).To(BeEmpty())
+				})
+
+				It("Linked clones the source VM from its current snapshot", func() {
+					cloneVM.Spec.Source.LinkedClone = true
+
+					By("requires the source VM to have a snapshot", func() {
+						err := vmProvider.CreateOrUpdateVirtualMachine(ctx, cloneVM)
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("does not have a snapshot"))
+					})
+
+					vmSnapshot := builder.DummyVirtualMachineSnapshot(vm.Namespace, "snapshot-1", vm.Name)
+					Expect(vmProvider.CreateOrUpdateVirtualMachineSnapshot(ctx, vm, vmSnapshot)).To(Succeed())
+
+					_, err := createOrUpdateAndGetVcVM(ctx, cloneVM)
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("Returns error when the source VM does not exist", func() {
+					cloneVM.Spec.Source.VirtualMachineName = "does-not-exist"
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, cloneVM)).ToNot(Succeed())
+					Expect(conditions.GetReason(cloneVM, vmopv1alpha1.VirtualMachinePrereqReadyCondition)).To(
+						Equal(vmopv1alpha1.VirtualMachineSourceNotFoundReason))
+				})
+			})
+
+			Context("Resize", func() {
+				var newVMClass *vmopv1alpha1.VirtualMachineClass
+
+				JustBeforeEach(func() {
+					newVMClass = builder.DummyVirtualMachineClass()
+					newVMClass.Generation = 2
+					newVMClass.Spec.Hardware.Cpus = 4
+					newVMClass.Spec.Hardware.Memory = resource.MustParse("8Gi")
+					Expect(ctx.Client.Create(ctx, newVMClass)).To(Succeed())
+
+					vmClassBinding := builder.DummyVirtualMachineClassBinding(newVMClass.Name, nsInfo.Namespace)
+					Expect(ctx.Client.Create(ctx, vmClassBinding)).To(Succeed())
+				})
+
+				It("Resizes powered off VM when its class changes", func() {
+					vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOff
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(BeTrue())
+
+					vm.Spec.ClassName = newVMClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(BeTrue())
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					Expect(o.Config.Hardware.NumCPU).To(BeEquivalentTo(4))
+					Expect(o.Config.Hardware.MemoryMB).To(BeEquivalentTo(8 * 1024))
+					Expect(o.Summary.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+					Expect(vm.Status.AppliedClassGeneration).To(BeEquivalentTo(2))
+				})
+
+				It("Does not resize VM when the updatePolicy of its class is None", func() {
+					vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOff
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					newVMClass.Spec.UpdatePolicy.Type = vmopv1alpha1.VirtualMachineClassUpdatePolicyNone
+					Expect(ctx.Client.Update(ctx, newVMClass)).To(Succeed())
+
+					vm.Spec.ClassName = newVMClass.Name
+					vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(conditions.IsFalse(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(BeTrue())
+					Expect(conditions.GetReason(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(Equal(vmopv1alpha1.VirtualMachineResizeDisabledReason))
+					Expect(vm.Status.AppliedClassGeneration).ToNot(BeEquivalentTo(2))
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					Expect(o.Config.Hardware.NumCPU).ToNot(BeEquivalentTo(4))
+					Expect(o.Summary.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+				})
+
+				It("Power cycles powered on VM that is selected by the Rolling update of its class", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					newVMClass.Spec.UpdatePolicy.Type = vmopv1alpha1.VirtualMachineClassUpdatePolicyRolling
+					Expect(ctx.Client.Update(ctx, newVMClass)).To(Succeed())
+
+					vm.Spec.ClassName = newVMClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(conditions.GetReason(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(Equal(vmopv1alpha1.VirtualMachineResizePendingReason))
+
+					vm.Annotations = map[string]string{vmopv1alpha1.ClassRolloutGenerationAnnotation: "2"}
+					conditions.MarkTrue(vm, vmopv1alpha1.VirtualMachineStartedCondition)
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(BeTrue())
+					Expect(vm.Status.AppliedClassGeneration).To(BeEquivalentTo(2))
+					Expect(conditions.IsFalse(vm, vmopv1alpha1.VirtualMachineStartedCondition)).To(BeTrue())
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					Expect(o.Config.Hardware.NumCPU).To(BeEquivalentTo(4))
+					Expect(o.Config.Hardware.MemoryMB).To(BeEquivalentTo(8 * 1024))
+					Expect(o.Summary.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+				})
+
+				It("Does not fail the reconcile while the soft power off of the Rolling update of its class is pending", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					newVMClass.Spec.UpdatePolicy.Type = vmopv1alpha1.VirtualMachineClassUpdatePolicyRolling
+					Expect(ctx.Client.Update(ctx, newVMClass)).To(Succeed())
+
+					vm.Spec.ClassName = newVMClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(conditions.GetReason(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(Equal(vmopv1alpha1.VirtualMachineResizePendingReason))
+
+					vm.Spec.PowerOffMode = vmopv1alpha1.VirtualMachinePowerOpModeTrySoft
+					vm.Annotations = map[string]string{vmopv1alpha1.ClassRolloutGenerationAnnotation: "2"}
+					vm.Status.PendingSoftPowerOp = &vmopv1alpha1.VirtualMachineSoftPowerOp{
+						PowerState:  vmopv1alpha1.VirtualMachinePoweredOff,
+						RequestTime: metav1.Now(),
+					}
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachinePoweredOn))
+					Expect(vm.Status.PendingSoftPowerOp).ToNot(BeNil())
+					Expect(conditions.GetReason(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(Equal(vmopv1alpha1.VirtualMachineResizePendingReason))
+
+					By("Power cycles VM once the soft power off timed out", func() {
+						vm.Status.PendingSoftPowerOp.RequestTime = metav1.NewTime(time.Now().Add(-virtualmachine.SoftPowerOpTimeout))
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.PendingSoftPowerOp).To(BeNil())
+						Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(BeTrue())
+						Expect(vm.Status.AppliedClassGeneration).To(BeEquivalentTo(2))
+
+						var o mo.VirtualMachine
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+						Expect(o.Config.Hardware.NumCPU).To(BeEquivalentTo(4))
+						Expect(o.Summary.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+					})
+				})
+
+				It("Marks powered on VM as pending resize when its class changes", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(BeTrue())
+
+					vm.Spec.ClassName = newVMClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(conditions.IsFalse(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(BeTrue())
+					Expect(conditions.GetReason(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(Equal(vmopv1alpha1.VirtualMachineResizePendingReason))
+
+					By("Resizes VM when it is powered off", func() {
+						vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOff
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(BeTrue())
+
+						var o mo.VirtualMachine
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+						Expect(o.Config.Hardware.NumCPU).To(BeEquivalentTo(4))
+					})
+				})
+
+				It("Marks suspended VM as pending resize until it is powered off", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					vm.Spec.PowerState = vmopv1alpha1.VirtualMachineSuspended
+					vm.Spec.ClassName = newVMClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachineSuspended))
+					Expect(conditions.GetReason(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(Equal(vmopv1alpha1.VirtualMachineResizePendingReason))
+					Expect(conditions.GetMessage(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(ContainSubstring("not resized when it is resumed"))
+
+					By("Does not resize VM when it is resumed", func() {
+						vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(conditions.GetReason(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(Equal(vmopv1alpha1.VirtualMachineResizePendingReason))
+
+						var o mo.VirtualMachine
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+						Expect(o.Config.Hardware.NumCPU).ToNot(BeEquivalentTo(4))
+					})
+				})
+
+				It("Hot adds CPU and memory to powered on VM when its class changes", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					task, err := vcVM.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+						CpuHotAddEnabled:    pointer.Bool(true),
+						MemoryHotAddEnabled: pointer.Bool(true),
+					})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(task.Wait(ctx)).To(Succeed())
+
+					vm.Spec.ClassName = newVMClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(conditions.IsTrue(vm, vmopv1alpha1.VirtualMachineClassConfigurationSyncedCondition)).To(BeTrue())
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					Expect(o.Config.Hardware.NumCPU).To(BeEquivalentTo(4))
+					Expect(o.Config.Hardware.MemoryMB).To(BeEquivalentTo(8 * 1024))
+					Expect(o.Summary.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+				})
+			})
+
 			It("returns error when StorageClass is required but none specified", func() {
 				vm.Spec.StorageClass = ""
 				err := vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
@@ -996,6 +1385,114 @@ func vmTests() {
 						})
 					})
 				})
+
+				Context("Reapply", func() {
+					var configMap *corev1.ConfigMap
+
+					getExtraConfig := func(vcVM *object.VirtualMachine) map[string]string {
+						var o mo.VirtualMachine
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+
+						ec := map[string]string{}
+						for _, option := range o.Config.ExtraConfig {
+							if val := option.GetOptionValue(); val != nil {
+								ec[val.Key] = val.Value.(string)
+							}
+						}
+						return ec
+					}
+
+					updateConfigMap := func() {
+						configMap.Data["guestinfo.Foo"] = "bar"
+						Expect(ctx.Client.Update(ctx, configMap)).To(Succeed())
+					}
+
+					metadataHash := func() string {
+						return session.VMMetadata{Data: configMap.Data}.Hash()
+					}
+
+					JustBeforeEach(func() {
+						configMap = &corev1.ConfigMap{
+							ObjectMeta: metav1.ObjectMeta{
+								GenerateName: "md-configmap-",
+								Namespace:    vm.Namespace,
+							},
+							Data: map[string]string{
+								"guestinfo.Foo": "foo",
+							},
+						}
+						Expect(ctx.Client.Create(ctx, configMap)).To(Succeed())
+
+						vm.Spec.VmMetadata = &vmopv1alpha1.VirtualMachineMetadata{
+							ConfigMapName: configMap.Name,
+							Transport:     vmopv1alpha1.VirtualMachineMetadataExtraConfigTransport,
+						}
+					})
+
+					It("Reapplies changed metadata to powered on VM", func() {
+						vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(vm.Status.VmMetadataHash).To(Equal(metadataHash()))
+						Expect(vm.Status.VmMetadataResourceVersion).To(Equal(configMap.ResourceVersion))
+						Expect(getExtraConfig(vcVM)).To(HaveKeyWithValue("guestinfo.Foo", "foo"))
+
+						updateConfigMap()
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.VmMetadataHash).To(Equal(metadataHash()))
+						Expect(vm.Status.VmMetadataResourceVersion).To(Equal(configMap.ResourceVersion))
+						Expect(getExtraConfig(vcVM)).To(HaveKeyWithValue("guestinfo.Foo", "bar"))
+						Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachinePoweredOn))
+					})
+
+					It("Reboots powered on VM to reapply changed metadata when UpdatePolicy is Reboot", func() {
+						vm.Spec.VmMetadata.UpdatePolicy = vmopv1alpha1.VirtualMachineMetadataUpdatePolicyReboot
+						vm.Spec.PowerOffMode = vmopv1alpha1.VirtualMachinePowerOpModeHard
+						vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+
+						updateConfigMap()
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.VmMetadataHash).To(Equal(metadataHash()))
+						Expect(getExtraConfig(vcVM)).To(HaveKeyWithValue("guestinfo.Foo", "bar"))
+						Expect(vm.Status.PowerState).To(Equal(vmopv1alpha1.VirtualMachinePoweredOn))
+					})
+
+					It("Does not reapply metadata to powered on VM that has not recorded the applied metadata", func() {
+						vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+
+						vm.Status.VmMetadataHash = ""
+						updateConfigMap()
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.VmMetadataHash).To(Equal(metadataHash()))
+						Expect(getExtraConfig(vcVM)).To(HaveKeyWithValue("guestinfo.Foo", "foo"))
+					})
+
+					It("Does not reapply metadata when only the ConfigMap's resourceVersion changed", func() {
+						vm.Spec.VmMetadata.UpdatePolicy = vmopv1alpha1.VirtualMachineMetadataUpdatePolicyReboot
+						vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+						hash := vm.Status.VmMetadataHash
+
+						configMap.Labels = map[string]string{"foo": "bar"}
+						Expect(ctx.Client.Update(ctx, configMap)).To(Succeed())
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.VmMetadataHash).To(Equal(hash))
+						Expect(vm.Status.VmMetadataResourceVersion).To(Equal(configMap.ResourceVersion))
+						Expect(getExtraConfig(vcVM)).To(HaveKeyWithValue("guestinfo.Foo", "foo"))
+					})
+
+					It("Clears the applied metadata when the VM's metadata is removed", func() {
+						_, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(vm.Status.VmMetadataHash).ToNot(BeEmpty())
+
+						vm.Spec.VmMetadata = nil
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.VmMetadataHash).To(BeEmpty())
+						Expect(vm.Status.VmMetadataResourceVersion).To(BeEmpty())
+					})
+				})
 			})
 
 			Context("Network", func() {
@@ -1384,6 +1881,158 @@ func vmTests() {
 			})
 		})
 
+		Context("Serial console ticket", func() {
+			const proxyURI = "telnets://vspc.local:13370"
+
+			var expiry time.Time
+
+			BeforeEach(func() {
+				vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+				expiry = time.Unix(1700000000, 0)
+			})
+
+			JustBeforeEach(func() {
+				Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+			})
+
+			It("returns error when the vSPC is not configured", func() {
+				Expect(os.Unsetenv(lib.SerialConsoleProxyURIEnv)).To(Succeed())
+				_, err := vmProvider.GetVirtualMachineSerialConsoleTicket(ctx, vm, "foo", "some-uuid", expiry)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("serial console is not configured"))
+			})
+
+			It("returns error when the VM was powered on before the vSPC was configured", func() {
+				Expect(os.Setenv(lib.SerialConsoleProxyURIEnv, proxyURI)).To(Succeed())
+				defer func() {
+					Expect(os.Unsetenv(lib.SerialConsoleProxyURIEnv)).To(Succeed())
+				}()
+
+				_, publicKeyPem := builder.WebConsoleRequestKeyPair()
+				_, err := vmProvider.GetVirtualMachineSerialConsoleTicket(ctx, vm, publicKeyPem, "some-uuid", expiry)
+				Expect(err).To(MatchError(vmprovider.ErrSerialConsolePortNotFound))
+			})
+
+			When("the vSPC is configured", func() {
+				BeforeEach(func() {
+					Expect(os.Setenv(lib.SerialConsoleProxyURIEnv, proxyURI)).To(Succeed())
+				})
+
+				AfterEach(func() {
+					Expect(os.Unsetenv(lib.SerialConsoleProxyURIEnv)).To(Succeed())
+				})
+
+				It("adds the serial port when the VM is powered on and returns ticket", func() {
+					privateKey, publicKeyPem := builder.WebConsoleRequestKeyPair()
+					ticket, err := vmProvider.GetVirtualMachineSerialConsoleTicket(ctx, vm, publicKeyPem, "some-uuid", expiry)
+					Expect(err).ToNot(HaveOccurred())
+
+					uri, err := virtualmachine.DecryptWebMKS(privateKey, ticket)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(uri).To(HavePrefix(proxyURI + "/"))
+					Expect(uri).To(HaveSuffix("?expires=1700000000&uuid=some-uuid"))
+				})
+			})
+		})
+
+		Context("Restart", func() {
+			JustBeforeEach(func() {
+				vm.Spec.PowerState = vmopv1alpha1.VirtualMachinePoweredOn
+				Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+			})
+
+			It("restarts the VM", func() {
+				Expect(vmProvider.RestartVirtualMachine(ctx, vm, vmopv1alpha1.VirtualMachinePowerOpModeHard)).To(Succeed())
+			})
+
+			It("returns error for an invalid mode", func() {
+				err := vmProvider.RestartVirtualMachine(ctx, vm, "bogus")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid restart mode"))
+			})
+		})
+
+		Context("Guest command", func() {
+			var action *vmopv1alpha1.ExecAction
+
+			JustBeforeEach(func() {
+				Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+				action = &vmopv1alpha1.ExecAction{
+					Command:    []string{"/bin/true"},
+					SecretName: "guest-creds",
+				}
+			})
+
+			It("returns error when the Secret does not exist", func() {
+				_, err := vmProvider.ExecVirtualMachineGuestCommand(ctx, vm, action)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("failed to get guest credentials Secret"))
+			})
+
+			It("returns error when the Secret does not have a username", func() {
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      action.SecretName,
+						Namespace: vm.Namespace,
+					},
+					Data: map[string][]byte{
+						corev1.BasicAuthPasswordKey: []byte("password"),
+					},
+				}
+				Expect(ctx.Client.Create(ctx, secret)).To(Succeed())
+
+				_, err := vmProvider.ExecVirtualMachineGuestCommand(ctx, vm, action)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("does not have the \"username\" key"))
+			})
+
+			It("runs the command in the guest", func() {
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      action.SecretName,
+						Namespace: vm.Namespace,
+					},
+					Data: map[string][]byte{
+						corev1.BasicAuthUsernameKey: []byte("user"),
+						corev1.BasicAuthPasswordKey: []byte("password"),
+					},
+				}
+				Expect(ctx.Client.Create(ctx, secret)).To(Succeed())
+
+				// vcsim only supports guest operations for container backed VMs so expect an error.
+				_, err := vmProvider.ExecVirtualMachineGuestCommand(ctx, vm, action)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("failed to start program"))
+			})
+
+			It("caches the credentials in the Secret", func() {
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      action.SecretName,
+						Namespace: vm.Namespace,
+					},
+					Data: map[string][]byte{
+						corev1.BasicAuthUsernameKey: []byte("user"),
+						corev1.BasicAuthPasswordKey: []byte("password"),
+					},
+				}
+				Expect(ctx.Client.Create(ctx, secret)).To(Succeed())
+
+				// vcsim does not handle a second guest operation on the VM after the first one fails, so read
+				// the Secret with a VM that does not exist in vCenter.
+				otherVM := builder.DummyBasicVirtualMachine("does-not-exist", vm.Namespace)
+				_, err := vmProvider.ExecVirtualMachineGuestCommand(ctx, otherVM, action)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).ToNot(ContainSubstring("failed to get guest credentials Secret"))
+
+				Expect(ctx.Client.Delete(ctx, secret)).To(Succeed())
+
+				_, err = vmProvider.ExecVirtualMachineGuestCommand(ctx, vm, action)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("failed to start program"))
+			})
+		})
+
 		Context("ResVMToVirtualMachineImage", func() {
 			JustBeforeEach(func() {
 				Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
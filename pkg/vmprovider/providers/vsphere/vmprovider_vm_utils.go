@@ -84,6 +84,43 @@ func GetVirtualMachineClass(
 	return vmClass, nil
 }
 
+// GetVirtualMachineSource returns the VirtualMachine to clone when the VM has a Source, otherwise nil.
+// The source VirtualMachine is always looked up in the VM's namespace.
+func GetVirtualMachineSource(
+	vmCtx context.VirtualMachineContext,
+	k8sClient ctrlclient.Client) (*vmopv1alpha1.VirtualMachine, error) {
+
+	source := vmCtx.VM.Spec.Source
+	if source == nil {
+		return nil, nil
+	}
+
+	srcVMName := source.VirtualMachineName
+
+	srcVM := &vmopv1alpha1.VirtualMachine{}
+	if err := k8sClient.Get(vmCtx, ctrlclient.ObjectKey{Name: srcVMName, Namespace: vmCtx.VM.Namespace}, srcVM); err != nil {
+		msg := fmt.Sprintf("Failed to get source VirtualMachine: %s", srcVMName)
+		conditions.MarkFalse(vmCtx.VM,
+			vmopv1alpha1.VirtualMachinePrereqReadyCondition,
+			vmopv1alpha1.VirtualMachineSourceNotFoundReason,
+			vmopv1alpha1.ConditionSeverityError,
+			msg)
+		return nil, errors.Wrap(err, msg)
+	}
+
+	if srcVM.Status.UniqueID == "" {
+		msg := fmt.Sprintf("Source VirtualMachine %s has not been created", srcVMName)
+		conditions.MarkFalse(vmCtx.VM,
+			vmopv1alpha1.VirtualMachinePrereqReadyCondition,
+			vmopv1alpha1.VirtualMachineSourceNotReadyReason,
+			vmopv1alpha1.ConditionSeverityInfo,
+			msg)
+		return nil, errors.New(msg)
+	}
+
+	return srcVM, nil
+}
+
 func GetVMImageStatusAndContentLibraryUUID(
 	vmCtx context.VirtualMachineContext,
 	k8sClient ctrlclient.Client) (*vmopv1alpha1.VirtualMachineImageStatus, string, error) {
@@ -228,6 +265,7 @@ func GetVMMetadata(
 
 		vmMD.Transport = metadata.Transport
 		vmMD.Data = cm.Data
+		vmMD.ResourceVersion = cm.ResourceVersion
 	} else if metadata.SecretName != "" {
 		secret := &corev1.Secret{}
 		err := k8sClient.Get(vmCtx, ctrlclient.ObjectKey{Name: metadata.SecretName, Namespace: vmCtx.VM.Namespace}, secret)
@@ -237,6 +275,7 @@ func GetVMMetadata(
 		}
 
 		vmMD.Transport = metadata.Transport
+		vmMD.ResourceVersion = secret.ResourceVersion
 		vmMD.Data = make(map[string]string)
 		for k, v := range secret.Data {
 			vmMD.Data[k] = string(v)
@@ -0,0 +1,33 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vmprovider
+
+import (
+	"errors"
+)
+
+// ErrSerialConsolePortNotFound is returned when the serial console of a VM is requested but the VM does not have a
+// serial port that is backed by the vSPC, and one cannot be added because the VM is powered on.
+var ErrSerialConsolePortNotFound = errors.New("the VM does not have a serial port backed by the vSPC; " +
+	"a serial port is added when the VM is powered on, so the VM must be power cycled")
+
+// HostHardware describes the hardware of the hosts that VMs can be placed on.
+type HostHardware struct {
+	// VGPUProfiles are the vGPU profiles that are supported by at least one host.
+	VGPUProfiles []string
+	// PCIDevices are the PCI devices of the hosts, which may be used as DynamicDirectPathIO devices.
+	PCIDevices []PCIDevice
+	// MaxCPUMHz is the CPU capacity, in MHz, of the host with the largest CPU capacity.
+	MaxCPUMHz int64
+	// MaxMemoryMB is the memory capacity, in MB, of the host with the largest memory capacity.
+	MaxMemoryMB int64
+	// MinCPUFreqMHz is the minimum CPU frequency of the hosts, which is used to convert CPU cores to MHz.
+	MinCPUFreqMHz uint64
+}
+
+// PCIDevice identifies a PCI device by its vendor and device IDs.
+type PCIDevice struct {
+	VendorID int
+	DeviceID int
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webconsolevalidation
+
+import (
+	"net/http"
+	"time"
+
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+)
+
+// AuditRecord is the audit record of a web-console validation request.
+type AuditRecord struct {
+	// Time is when the validation request was received.
+	Time time.Time
+	// RemoteAddr is the address of the client that sent the validation request.
+	RemoteAddr string
+	// ForwardedFor is the X-Forwarded-For header of the validation request, which is the address of the user when
+	// the request is sent by a proxy.
+	ForwardedFor string
+	// UserAgent is the User-Agent header of the validation request.
+	UserAgent string
+
+	Namespace   string
+	UUID        string
+	ConsoleType vmopv1alpha1.WebConsoleType
+
+	// WebConsoleRequest is the name of the WebConsoleRequest that matched the validation request, if any.
+	WebConsoleRequest string
+	// VirtualMachine is the name of the VM that the web console is requested for, if any.
+	VirtualMachine string
+	// RequestedBy is the authenticated Kubernetes user that created the matched WebConsoleRequest, if any. The
+	// validation request itself is not authenticated, so this is the owner of the ticket, not necessarily the
+	// client that presented it.
+	RequestedBy string
+
+	Allowed    bool
+	StatusCode int
+	Reason     string
+}
+
+var auditLogger = ctrllog.Log.WithName("audit")
+
+// Audit emits the audit record. This is a variable so that tests can capture the records.
+var Audit = func(record AuditRecord) {
+	auditLogger.Info("Web console validation",
+		"time", record.Time.UTC().Format(time.RFC3339Nano),
+		"remoteAddr", record.RemoteAddr,
+		"forwardedFor", record.ForwardedFor,
+		"userAgent", record.UserAgent,
+		"namespace", record.Namespace,
+		"uuid", record.UUID,
+		"consoleType", record.ConsoleType,
+		"webConsoleRequest", record.WebConsoleRequest,
+		"virtualMachine", record.VirtualMachine,
+		"requestedBy", record.RequestedBy,
+		"allowed", record.Allowed,
+		"statusCode", record.StatusCode,
+		"reason", record.Reason)
+}
+
+func newAuditRecord(r *http.Request) *AuditRecord {
+	query := r.URL.Query()
+	return &AuditRecord{
+		Time:         time.Now(),
+		RemoteAddr:   r.RemoteAddr,
+		ForwardedFor: r.Header.Get("X-Forwarded-For"),
+		UserAgent:    r.UserAgent(),
+		Namespace:    query.Get("namespace"),
+		UUID:         query.Get("uuid"),
+		ConsoleType:  vmopv1alpha1.WebConsoleType(query.Get("type")),
+	}
+}
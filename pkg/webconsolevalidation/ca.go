@@ -0,0 +1,58 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webconsolevalidation
+
+import (
+	"context"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrlruntime "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// CAConfigMapKey is the key of the CA certificate in the ConfigMap that the CA is published in.
+	CAConfigMapKey = "ca.crt"
+
+	// caPublishInterval is how often the CA file is published again, so that a renewed CA is published.
+	caPublishInterval = time.Minute
+)
+
+// PublishCA publishes the CA certificate in the file to the ConfigMap, so that the clients of the server can
+// verify its serving certificate without reading the Secret of the certificate.
+func PublishCA(ctx context.Context, c ctrlruntime.Client, namespace, name, caFile string) error {
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, c, cm, func() error {
+		cm.Data = map[string]string{CAConfigMapKey: string(ca)}
+		return nil
+	})
+	return err
+}
+
+// runCAPublisher publishes the CA until the context is done.
+func runCAPublisher(ctx context.Context, c ctrlruntime.Client, opts ServerOptions) {
+	logger := ctrllog.Log.WithName("webconsolevalidation").WithValues(
+		"namespace", opts.CAConfigMapNamespace, "name", opts.CAConfigMapName)
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := PublishCA(ctx, c, opts.CAConfigMapNamespace, opts.CAConfigMapName, opts.TLSCAFile); err != nil {
+			logger.Error(err, "Failed to publish the CA")
+		}
+	}, caPublishInterval)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webconsolevalidation
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+)
+
+const (
+	// DefaultRateLimitQPS is the default number of validation requests per second that are allowed per namespace.
+	DefaultRateLimitQPS = 10.0
+	// DefaultRateLimitBurst is the default burst of validation requests that are allowed per namespace.
+	DefaultRateLimitBurst = 20
+
+	// The namespace param is client provided, so the limiters are kept in a bounded cache instead of growing a
+	// map with every namespace that was ever requested. An evicted limiter is recreated with a full bucket.
+	maxRateLimitedNamespaces = 4096
+	rateLimiterTTL           = 10 * time.Minute
+)
+
+// NamespaceRateLimiter limits the rate of the validation requests for each namespace.
+type NamespaceRateLimiter struct {
+	qps   rate.Limit
+	burst int
+
+	mu       sync.Mutex
+	limiters *utilcache.LRUExpireCache
+}
+
+// NewNamespaceRateLimiter returns a NamespaceRateLimiter that allows qps requests per second, with bursts of up to
+// burst requests, for each namespace.
+func NewNamespaceRateLimiter(qps float64, burst int) *NamespaceRateLimiter {
+	return &NamespaceRateLimiter{
+		qps:      rate.Limit(qps),
+		burst:    burst,
+		limiters: utilcache.NewLRUExpireCache(maxRateLimitedNamespaces),
+	}
+}
+
+// Allow returns whether a validation request for the namespace is allowed now.
+func (l *NamespaceRateLimiter) Allow(namespace string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var limiter *rate.Limiter
+	if obj, ok := l.limiters.Get(namespace); ok {
+		limiter = obj.(*rate.Limiter)
+	} else {
+		limiter = rate.NewLimiter(l.qps, l.burst)
+	}
+	// Re-add the limiter to extend its TTL while the namespace is in use.
+	l.limiters.Add(namespace, limiter, rateLimiterTTL)
+
+	return limiter.Allow()
+}
@@ -5,10 +5,17 @@ package webconsolevalidation
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"net/http"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	ctrlruntime "sigs.k8s.io/controller-runtime/pkg/client"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -16,11 +23,46 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
 )
 
+const (
+	// UUIDIndexField is the cache index of the WebConsoleRequests by the value of the UUID label.
+	UUIDIndexField = "webconsolerequest.uuid"
+
+	readHeaderTimeout = 10 * time.Second
+)
+
 // K8sClient is used to get the webconsolerequest resource from UUID and namespace.
 var K8sClient ctrlruntime.Client
 
-// InitServer initializes a K8sClient used by the web-console validation server.
-func InitServer() error {
+// RateLimiter limits the rate of the validation requests per namespace. Rate limiting is disabled when this is nil.
+var RateLimiter *NamespaceRateLimiter
+
+// ServerOptions are the options of the web-console validation server.
+type ServerOptions struct {
+	// Addr is the address that the server serves plain HTTP on. Plain HTTP is not served when it is empty.
+	Addr string
+	// TLSAddr is the address that the server serves HTTPS on when TLSCertFile and TLSKeyFile are set.
+	TLSAddr string
+	// Path is the path that the server handles the validation requests on.
+	Path string
+	// TLSCertFile and TLSKeyFile are the serving certificate and key of the server. The files are reloaded
+	// when they change.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSCAFile is the CA certificate of the serving certificate. When CAConfigMapName is also set, the CA
+	// is published in that ConfigMap for the clients of the server.
+	TLSCAFile            string
+	CAConfigMapNamespace string
+	CAConfigMapName      string
+	// RateLimitQPS and RateLimitBurst limit the rate of the validation requests per namespace. Rate limiting
+	// is disabled when RateLimitQPS is not positive.
+	RateLimitQPS   float64
+	RateLimitBurst int
+}
+
+// InitServer initializes a K8sClient used by the web-console validation server. The WebConsoleRequests are read
+// from a shared informer cache that is indexed by UUID, which is started with the given context. The CA of the
+// serving certificate is published with the given context when it is configured.
+func InitServer(ctx context.Context, opts ServerOptions) error {
 	restConfig, err := rest.InClusterConfig()
 	if err != nil {
 		return err
@@ -30,66 +72,262 @@ func InitServer() error {
 	if err = vmopv1alpha1.AddToScheme(scheme); err != nil {
 		return err
 	}
+	if err = corev1.AddToScheme(scheme); err != nil {
+		return err
+	}
+
+	informerCache, err := cache.New(restConfig, cache.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	if err := informerCache.IndexField(ctx, &vmopv1alpha1.WebConsoleRequest{}, UUIDIndexField, uuidIndexFunc); err != nil {
+		return err
+	}
 
 	ctrlruntimeClient, err := ctrlruntime.New(restConfig, ctrlruntime.Options{Scheme: scheme})
 	if err != nil {
 		return err
 	}
 
-	K8sClient = ctrlruntimeClient
+	go func() {
+		if err := informerCache.Start(ctx); err != nil {
+			ctrllog.Log.WithName("webconsolevalidation").Error(err, "Error occurred in running the informer cache")
+		}
+	}()
+	if !informerCache.WaitForCacheSync(ctx) {
+		return errors.New("failed to sync the informer cache")
+	}
+
+	delegatingClient, err := ctrlruntime.NewDelegatingClient(ctrlruntime.NewDelegatingClientInput{
+		CacheReader: informerCache,
+		Client:      ctrlruntimeClient,
+	})
+	if err != nil {
+		return err
+	}
+
+	K8sClient = delegatingClient
+	if opts.TLSCAFile != "" && opts.CAConfigMapName != "" {
+		// The ConfigMap is not read from the cache since that would cache all the ConfigMaps.
+		go runCAPublisher(ctx, ctrlruntimeClient, opts)
+	}
+	if opts.RateLimitQPS > 0 {
+		RateLimiter = NewNamespaceRateLimiter(opts.RateLimitQPS, opts.RateLimitBurst)
+	}
 	return nil
 }
 
-// RunServer runs the web-console validation server at the given addr and path.
-func RunServer(addr, path string) error {
+// RunServer runs the web-console validation server until the context is done. The server serves plain HTTP and,
+// when a serving certificate is configured, HTTPS, so that the clients can move to HTTPS while plain HTTP is
+// still served.
+func RunServer(ctx context.Context, opts ServerOptions) error {
 	mux := http.NewServeMux()
-	mux.HandleFunc(path, HandleWebConsoleValidation)
+	mux.HandleFunc(opts.Path, HandleWebConsoleValidation)
+
+	newServer := func(addr string) *http.Server {
+		return &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: readHeaderTimeout,
+		}
+	}
+
+	var servers []*http.Server
+	errCh := make(chan error, 2)
+
+	if opts.Addr != "" {
+		server := newServer(opts.Addr)
+		servers = append(servers, server)
+		go func() {
+			errCh <- server.ListenAndServe()
+		}()
+	}
+
+	if opts.TLSCertFile != "" && opts.TLSKeyFile != "" {
+		certWatcher, err := certwatcher.New(opts.TLSCertFile, opts.TLSKeyFile)
+		if err != nil {
+			closeServers(servers)
+			return err
+		}
+		go func() {
+			if err := certWatcher.Start(ctx); err != nil {
+				ctrllog.Log.WithName("webconsolevalidation").Error(err, "Error occurred in watching the certificate")
+			}
+		}()
+
+		server := newServer(opts.TLSAddr)
+		server.TLSConfig = &tls.Config{
+			GetCertificate: certWatcher.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		}
+		servers = append(servers, server)
+		go func() {
+			// The certificate is provided by the TLSConfig so that it is reloaded when the files change.
+			errCh <- server.ListenAndServeTLS("", "")
+		}()
+	}
+
+	if len(servers) == 0 {
+		return errors.New("neither plain HTTP nor HTTPS is configured")
+	}
 
-	return http.ListenAndServe(addr, mux)
+	go func() {
+		<-ctx.Done()
+		closeServers(servers)
+	}()
+
+	// Stop serving altogether when either server stops.
+	err := <-errCh
+	closeServers(servers)
+	return err
 }
 
-// HandleWebConsoleValidation handles the web-console validation server requests.
+func closeServers(servers []*http.Server) {
+	for _, server := range servers {
+		_ = server.Close()
+	}
+}
+
+// HandleWebConsoleValidation handles the web-console validation server requests. An audit record is emitted for
+// every request.
 func HandleWebConsoleValidation(w http.ResponseWriter, r *http.Request) {
-	uuid := r.URL.Query().Get("uuid")
+	record := newAuditRecord(r)
+	defer func() {
+		Audit(*record)
+	}()
+
+	respond := func(statusCode int, reason string) {
+		record.StatusCode = statusCode
+		record.Allowed = statusCode == http.StatusOK
+		record.Reason = reason
+		if statusCode == http.StatusOK || statusCode == http.StatusForbidden {
+			w.WriteHeader(statusCode)
+		} else {
+			http.Error(w, reason, statusCode)
+		}
+	}
+
+	uuid := record.UUID
 	if uuid == "" {
-		http.Error(w, "'uuid' param is empty", http.StatusBadRequest)
+		respond(http.StatusBadRequest, "'uuid' param is empty")
 		return
 	}
 
-	namespace := r.URL.Query().Get("namespace")
+	namespace := record.Namespace
 	if namespace == "" {
-		http.Error(w, "'namespace' param is empty", http.StatusBadRequest)
+		respond(http.StatusBadRequest, "'namespace' param is empty")
+		return
+	}
+
+	if RateLimiter != nil && !RateLimiter.Allow(namespace) {
+		respond(http.StatusTooManyRequests, "rate limit exceeded for the namespace")
 		return
 	}
 
 	logger := ctrllog.Log.WithName(r.URL.Path).WithValues("uuid", uuid).WithValues("namespace", namespace)
 
-	found, err := isResourceFound(r.Context(), uuid, namespace)
+	// The optional 'type' param restricts the connection to the requested console type, so that the ticket of a
+	// WebMKS request cannot be used to connect to the serial console, and vice versa.
+	wcr, err := findResource(r.Context(), uuid, namespace, record.ConsoleType)
 	if err != nil {
 		logger.Error(err, "Error occurred in finding a webconsolerequest resource with the given params.")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respond(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	if found {
-		logger.Info("Found a webconsolerequest resource with the given params. Returning 200.")
-		w.WriteHeader(http.StatusOK)
-	} else {
+	if wcr == nil {
 		logger.Info("Didn't find a webconsolerequest resource with the given params. Returning 403.")
-		w.WriteHeader(http.StatusForbidden)
+		respond(http.StatusForbidden, "no matching webconsolerequest")
+		return
+	}
+
+	record.WebConsoleRequest = wcr.Name
+	record.VirtualMachine = wcr.Spec.VirtualMachineName
+	record.RequestedBy = wcr.Annotations[webconsolerequest.RequestedByAnnotationKey]
+	record.ConsoleType = getConsoleType(wcr)
+
+	if wcr.Spec.OneTimeUse {
+		consumed, err := consumeResource(r.Context(), wcr)
+		if err != nil {
+			logger.Error(err, "Error occurred in consuming the one-time use webconsolerequest resource.")
+			respond(http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !consumed {
+			logger.Info("The one-time use webconsolerequest resource was already used. Returning 403.")
+			respond(http.StatusForbidden, "one-time use webconsolerequest was already used")
+			return
+		}
 	}
+
+	logger.Info("Found a webconsolerequest resource with the given params. Returning 200.")
+	respond(http.StatusOK, "found a matching webconsolerequest")
 }
 
-func isResourceFound(goCtx context.Context, uuid, namespace string) (bool, error) {
-	labelSelector := ctrlruntime.MatchingLabels{
-		webconsolerequest.UUIDLabelKey: uuid,
+// consumeResource marks the one-time use WebConsoleRequest as used and deletes it. Returns false if the request
+// was already used.
+func consumeResource(goCtx context.Context, wcr *vmopv1alpha1.WebConsoleRequest) (bool, error) {
+	if wcr.Status.Used {
+		return false, nil
 	}
 
-	// TODO: Use an Informer to avoid hitting the API server for every request.
-	wcrObjectList := &vmopv1alpha1.WebConsoleRequestList{}
-	if err := K8sClient.List(goCtx, wcrObjectList, ctrlruntime.InNamespace(namespace), labelSelector); err != nil {
+	// The WebConsoleRequest is from the cache, so the update fails with a conflict if the request has been used
+	// concurrently, or since the cache was updated, because its resource version has then changed.
+	wcr = wcr.DeepCopy()
+	wcr.Status.Used = true
+	if err := K8sClient.Status().Update(goCtx, wcr); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			return false, nil
+		}
 		return false, err
 	}
 
-	return len(wcrObjectList.Items) > 0, nil
+	// The WebConsoleRequest controller deletes the used request as well, so the connection is allowed even if
+	// this fails.
+	if err := K8sClient.Delete(goCtx, wcr); ctrlruntime.IgnoreNotFound(err) != nil {
+		ctrllog.Log.WithName("webconsolevalidation").Error(err, "Failed to delete the used webconsolerequest",
+			"name", wcr.NamespacedName())
+	}
+
+	return true, nil
+}
+
+func findResource(
+	goCtx context.Context,
+	uuid, namespace string,
+	consoleType vmopv1alpha1.WebConsoleType) (*vmopv1alpha1.WebConsoleRequest, error) {
+
+	wcrObjectList := &vmopv1alpha1.WebConsoleRequestList{}
+	if err := K8sClient.List(goCtx, wcrObjectList,
+		ctrlruntime.InNamespace(namespace), ctrlruntime.MatchingFields{UUIDIndexField: uuid}); err != nil {
+		return nil, err
+	}
+
+	for i := range wcrObjectList.Items {
+		wcr := &wcrObjectList.Items[i]
+		// Check the label as well so that the lookup is correct with a client that does not support the index.
+		if wcr.Labels[webconsolerequest.UUIDLabelKey] != uuid {
+			continue
+		}
+		if consoleType == "" || getConsoleType(wcr) == consoleType {
+			return wcr, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func uuidIndexFunc(obj ctrlruntime.Object) []string {
+	if uuid := obj.GetLabels()[webconsolerequest.UUIDLabelKey]; uuid != "" {
+		return []string{uuid}
+	}
+	return nil
+}
+
+func getConsoleType(wcr *vmopv1alpha1.WebConsoleRequest) vmopv1alpha1.WebConsoleType {
+	if wcr.Spec.ConsoleType == "" {
+		return vmopv1alpha1.WebConsoleTypeWebMKS
+	}
+	return wcr.Spec.ConsoleType
 }
@@ -4,12 +4,17 @@
 package webconsolevalidation_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1alpha1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
@@ -23,9 +28,19 @@ func serverUnitTests() {
 	Describe("web-console validation server unit tests", func() {
 
 		var (
-			initObjects []client.Object
+			initObjects  []client.Object
+			auditRecords []webconsolevalidation.AuditRecord
+			oldAudit     func(webconsolevalidation.AuditRecord)
 		)
 
+		BeforeEach(func() {
+			auditRecords = nil
+			oldAudit = webconsolevalidation.Audit
+			webconsolevalidation.Audit = func(record webconsolevalidation.AuditRecord) {
+				auditRecords = append(auditRecords, record)
+			}
+		})
+
 		JustBeforeEach(func() {
 			webconsolevalidation.K8sClient = builder.NewFakeClient(initObjects...)
 		})
@@ -33,6 +48,8 @@ func serverUnitTests() {
 		AfterEach(func() {
 			initObjects = nil
 			webconsolevalidation.K8sClient = nil
+			webconsolevalidation.RateLimiter = nil
+			webconsolevalidation.Audit = oldAudit
 		})
 
 		Context("requests with missing params", func() {
@@ -48,6 +65,12 @@ func serverUnitTests() {
 
 				responseCode = fakeValidationRequest("/?namespace=dummy")
 				Expect(responseCode).To(Equal(http.StatusBadRequest))
+
+				Expect(auditRecords).To(HaveLen(3))
+				for _, record := range auditRecords {
+					Expect(record.Allowed).To(BeFalse())
+					Expect(record.StatusCode).To(Equal(http.StatusBadRequest))
+				}
 			})
 
 		})
@@ -56,13 +79,57 @@ func serverUnitTests() {
 
 			BeforeEach(func() {
 				wcr := &vmopv1alpha1.WebConsoleRequest{}
+				wcr.Name = "dummy-wcr"
 				wcr.Namespace = "dummy-namespace"
+				wcr.Spec.VirtualMachineName = "dummy-vm"
 				wcr.Labels = map[string]string{
 					webconsolerequest.UUIDLabelKey: "dummy-uuid-1234",
 				}
+				wcr.Annotations = map[string]string{
+					webconsolerequest.RequestedByAnnotationKey: "dummy-user",
+				}
 				initObjects = append(initObjects, wcr)
 			})
 
+			When("the WebConsoleRequest is one-time use", func() {
+
+				BeforeEach(func() {
+					initObjects[0].(*vmopv1alpha1.WebConsoleRequest).Spec.OneTimeUse = true
+				})
+
+				It("should allow the first request and then delete the WebConsoleRequest", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusOK))
+
+					wcr := &vmopv1alpha1.WebConsoleRequest{}
+					err := webconsolevalidation.K8sClient.Get(context.Background(),
+						client.ObjectKey{Namespace: "dummy-namespace", Name: "dummy-wcr"}, wcr)
+					Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+				})
+
+			})
+
+			When("the one-time use WebConsoleRequest was already used", func() {
+
+				BeforeEach(func() {
+					wcr := initObjects[0].(*vmopv1alpha1.WebConsoleRequest)
+					wcr.Spec.OneTimeUse = true
+					wcr.Status.Used = true
+				})
+
+				It("should return http.StatusForbidden (403)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+
+					Expect(auditRecords).To(HaveLen(1))
+					Expect(auditRecords[0].Allowed).To(BeFalse())
+					Expect(auditRecords[0].VirtualMachine).To(Equal("dummy-vm"))
+				})
+
+			})
+
 			When("UUID matches an existing WebConsoleRequest resource", func() {
 
 				It("should return http.StatusOK (200)", func() {
@@ -71,6 +138,68 @@ func serverUnitTests() {
 					Expect(responseCode).To(Equal(http.StatusOK))
 				})
 
+				It("should emit an audit record of the allowed request", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusOK))
+
+					Expect(auditRecords).To(HaveLen(1))
+					record := auditRecords[0]
+					Expect(record.Allowed).To(BeTrue())
+					Expect(record.StatusCode).To(Equal(http.StatusOK))
+					Expect(record.Namespace).To(Equal("dummy-namespace"))
+					Expect(record.UUID).To(Equal("dummy-uuid-1234"))
+					Expect(record.WebConsoleRequest).To(Equal("dummy-wcr"))
+					Expect(record.VirtualMachine).To(Equal("dummy-vm"))
+					Expect(record.RequestedBy).To(Equal("dummy-user"))
+					Expect(record.ConsoleType).To(Equal(vmopv1alpha1.WebConsoleTypeWebMKS))
+					Expect(record.ForwardedFor).To(Equal("10.0.0.1"))
+					Expect(record.Time).ToNot(BeZero())
+				})
+
+			})
+
+			When("the namespace exceeds the rate limit", func() {
+
+				BeforeEach(func() {
+					webconsolevalidation.RateLimiter = webconsolevalidation.NewNamespaceRateLimiter(0.001, 2)
+				})
+
+				It("should return http.StatusTooManyRequests (429)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusOK))
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusOK))
+					Expect(fakeValidationRequest(url)).To(Equal(http.StatusTooManyRequests))
+
+					Expect(auditRecords).To(HaveLen(3))
+					Expect(auditRecords[2].Allowed).To(BeFalse())
+					Expect(auditRecords[2].StatusCode).To(Equal(http.StatusTooManyRequests))
+
+					By("other namespaces are rate limited separately", func() {
+						url := "/?uuid=dummy-uuid-1234&namespace=other-namespace"
+						Expect(fakeValidationRequest(url)).To(Equal(http.StatusForbidden))
+					})
+				})
+
+			})
+
+			When("Console type matches the WebConsoleRequest resource", func() {
+
+				It("should return http.StatusOK (200)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace&type=WebMKS"
+					responseCode := fakeValidationRequest(url)
+					Expect(responseCode).To(Equal(http.StatusOK))
+				})
+
+			})
+
+			When("Console type doesn't match the WebConsoleRequest resource", func() {
+
+				It("should return http.StatusForbidden (403)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace&type=Serial"
+					responseCode := fakeValidationRequest(url)
+					Expect(responseCode).To(Equal(http.StatusForbidden))
+				})
+
 			})
 
 			When("Namespace doesn't match any WebConsoleRequest resource", func() {
@@ -89,11 +218,49 @@ func serverUnitTests() {
 					url := "/?uuid=non-existent-uuid&namespace=dummy-namespace"
 					responseCode := fakeValidationRequest(url)
 					Expect(responseCode).To(Equal(http.StatusForbidden))
+
+					Expect(auditRecords).To(HaveLen(1))
+					Expect(auditRecords[0].Allowed).To(BeFalse())
+					Expect(auditRecords[0].VirtualMachine).To(BeEmpty())
 				})
 
 			})
 		})
 	})
+
+	Describe("PublishCA", func() {
+
+		var (
+			k8sClient client.Client
+			caFile    string
+		)
+
+		BeforeEach(func() {
+			k8sClient = builder.NewFakeClient()
+			caFile = filepath.Join(GinkgoT().TempDir(), "ca.crt")
+			Expect(os.WriteFile(caFile, []byte("ca-1"), 0600)).To(Succeed())
+		})
+
+		It("publishes the CA in the ConfigMap and updates it when the CA changes", func() {
+			key := client.ObjectKey{Namespace: "dummy-namespace", Name: "dummy-ca"}
+			Expect(webconsolevalidation.PublishCA(context.Background(), k8sClient, key.Namespace, key.Name, caFile)).To(Succeed())
+
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(context.Background(), key, cm)).To(Succeed())
+			Expect(cm.Data).To(HaveKeyWithValue(webconsolevalidation.CAConfigMapKey, "ca-1"))
+
+			Expect(os.WriteFile(caFile, []byte("ca-2"), 0600)).To(Succeed())
+			Expect(webconsolevalidation.PublishCA(context.Background(), k8sClient, key.Namespace, key.Name, caFile)).To(Succeed())
+
+			Expect(k8sClient.Get(context.Background(), key, cm)).To(Succeed())
+			Expect(cm.Data).To(HaveKeyWithValue(webconsolevalidation.CAConfigMapKey, "ca-2"))
+		})
+
+		It("returns an error when the CA file does not exist", func() {
+			Expect(webconsolevalidation.PublishCA(context.Background(), k8sClient, "dummy-namespace", "dummy-ca",
+				filepath.Join(filepath.Dir(caFile), "missing.crt"))).ToNot(Succeed())
+		})
+	})
 }
 
 // fakeValidationRequest is a helper function to make a fake validation request.
@@ -102,6 +269,7 @@ func fakeValidationRequest(url string) int {
 	responseRecorder := httptest.NewRecorder()
 	handler := http.HandlerFunc(webconsolevalidation.HandleWebConsoleValidation)
 	testRequest, _ := http.NewRequest("GET", url, nil)
+	testRequest.Header.Set("X-Forwarded-For", "10.0.0.1")
 	handler.ServeHTTP(responseRecorder, testRequest)
 	response := responseRecorder.Result()
 	_ = response.Body.Close()
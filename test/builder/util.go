@@ -429,6 +429,19 @@ func DummyWebConsoleRequest(namespace, wcrName, vmName, pubKey string) *vmopv1.W
 	}
 }
 
+func DummyVirtualMachineSnapshot(namespace, name, vmName string) *vmopv1.VirtualMachineSnapshot {
+	return &vmopv1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineSnapshotSpec{
+			VirtualMachineName: vmName,
+			Description:        "dummy-description",
+		},
+	}
+}
+
 func WebConsoleRequestKeyPair() (privateKey *rsa.PrivateKey, publicKeyPem string) {
 	privateKey, _ = rsa.GenerateKey(rand.Reader, 2048)
 	publicKey := privateKey.PublicKey
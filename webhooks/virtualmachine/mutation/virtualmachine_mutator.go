@@ -23,6 +23,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/builder"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/network"
 )
@@ -78,11 +79,18 @@ func (m mutator) Mutate(ctx *context.WebhookRequestContext) admission.Response {
 	original := vm
 	modified := original.DeepCopy()
 
+	if SetNextRestartTime(ctx, modified) {
+		wasMutated = true
+	}
+
 	switch ctx.Op {
 	case admissionv1.Create:
 		if AddDefaultNetworkInterface(ctx, m.client, modified) {
 			wasMutated = true
 		}
+		if SetImageNameFromSource(ctx, m.client, modified) {
+			wasMutated = true
+		}
 	case admissionv1.Update:
 		// Prevent someone from setting the Spec.VmMetadata.SecretName
 		// field to an empty string if the field is already set to a
@@ -169,6 +177,31 @@ func AddDefaultNetworkInterface(ctx *context.WebhookRequestContext, client clien
 	return true
 }
 
+// SetNextRestartTime sets spec.nextRestartTime to the current time as an RFC3339Nano timestamp in UTC
+// if the field's value is "now" (case-insensitive).
+// Return true if spec.nextRestartTime is mutated, otherwise return false.
+func SetNextRestartTime(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) bool {
+	return util.SetTriggerTimeIfNow(&vm.Spec.NextRestartTime)
+}
+
+// SetImageNameFromSource sets spec.imageName to the ImageName of the source VirtualMachine if the VM is cloned
+// from a VirtualMachine and spec.imageName is not set. The source VirtualMachine is looked up in the VM's
+// namespace; the validation webhook reports the error if it does not exist.
+// Return true if spec.imageName is mutated, otherwise return false.
+func SetImageNameFromSource(ctx *context.WebhookRequestContext, c client.Client, vm *vmopv1.VirtualMachine) bool {
+	if vm.Spec.Source == nil || vm.Spec.Source.VirtualMachineName == "" || vm.Spec.ImageName != "" {
+		return false
+	}
+
+	srcVM := &vmopv1.VirtualMachine{}
+	if err := c.Get(ctx, client.ObjectKey{Name: vm.Spec.Source.VirtualMachineName, Namespace: vm.Namespace}, srcVM); err != nil {
+		return false
+	}
+
+	vm.Spec.ImageName = srcVM.Spec.ImageName
+	return vm.Spec.ImageName != ""
+}
+
 // Only used in gce2e tests.
 func getVSphereProviderConfigMap(ctx *context.WebhookRequestContext, c client.Client) (string, error) {
 	configMapKey := client.ObjectKey{Name: config.ProviderConfigMapName, Namespace: ctx.Namespace}
@@ -5,6 +5,7 @@ package mutation_test
 
 import (
 	"os"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -215,4 +216,59 @@ func unitTestsMutating() {
 			})
 		})
 	})
+
+	Describe("SetNextRestartTime", func() {
+		It("Should set nextRestartTime to the current time when it is now", func() {
+			ctx.vm.Spec.NextRestartTime = "Now"
+			before := time.Now().UTC()
+			Expect(mutation.SetNextRestartTime(&ctx.WebhookRequestContext, ctx.vm)).To(BeTrue())
+			t, err := time.Parse(time.RFC3339Nano, ctx.vm.Spec.NextRestartTime)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t).ToNot(BeTemporally("<", before))
+		})
+
+		It("Should not mutate nextRestartTime when it is a timestamp", func() {
+			nextRestartTime := time.Now().UTC().Format(time.RFC3339Nano)
+			ctx.vm.Spec.NextRestartTime = nextRestartTime
+			Expect(mutation.SetNextRestartTime(&ctx.WebhookRequestContext, ctx.vm)).To(BeFalse())
+			Expect(ctx.vm.Spec.NextRestartTime).To(Equal(nextRestartTime))
+		})
+
+		It("Should not mutate nextRestartTime when it is empty", func() {
+			Expect(mutation.SetNextRestartTime(&ctx.WebhookRequestContext, ctx.vm)).To(BeFalse())
+			Expect(ctx.vm.Spec.NextRestartTime).To(BeEmpty())
+		})
+	})
+
+	Describe("SetImageNameFromSource", func() {
+		var srcVM *vmopv1.VirtualMachine
+
+		BeforeEach(func() {
+			srcVM = builder.DummyVirtualMachine()
+			srcVM.Name = "dummy-source-vm"
+			srcVM.Namespace = ctx.vm.Namespace
+			srcVM.Spec.ImageName = "dummy-source-image"
+			Expect(ctx.Client.Create(ctx, srcVM)).To(Succeed())
+
+			ctx.vm.Spec.ImageName = ""
+			ctx.vm.Spec.Source = &vmopv1.VirtualMachineSource{VirtualMachineName: srcVM.Name}
+		})
+
+		It("Should set imageName to the imageName of the source VM", func() {
+			Expect(mutation.SetImageNameFromSource(&ctx.WebhookRequestContext, ctx.Client, ctx.vm)).To(BeTrue())
+			Expect(ctx.vm.Spec.ImageName).To(Equal(srcVM.Spec.ImageName))
+		})
+
+		It("Should not mutate imageName when it is set", func() {
+			ctx.vm.Spec.ImageName = "dummy-image"
+			Expect(mutation.SetImageNameFromSource(&ctx.WebhookRequestContext, ctx.Client, ctx.vm)).To(BeFalse())
+			Expect(ctx.vm.Spec.ImageName).To(Equal("dummy-image"))
+		})
+
+		It("Should not mutate imageName when the source VM is in another namespace", func() {
+			ctx.vm.Namespace += "-other"
+			Expect(mutation.SetImageNameFromSource(&ctx.WebhookRequestContext, ctx.Client, ctx.vm)).To(BeFalse())
+			Expect(ctx.vm.Spec.ImageName).To(BeEmpty())
+		})
+	})
 }
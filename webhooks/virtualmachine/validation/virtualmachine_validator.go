@@ -4,19 +4,24 @@
 package validation
 
 import (
+	goctx "context"
 	"fmt"
 	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,6 +40,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/instancestorage"
@@ -50,6 +56,7 @@ const (
 
 	readinessProbeNoActions                   = "must specify an action"
 	readinessProbeOnlyOneAction               = "only one action can be specified"
+	probeSuccessThresholdMustBeOne            = "must be 1"
 	updatesNotAllowedWhenPowerOn              = "updates to this field is not allowed when VM power is on"
 	virtualMachineImageNotSupported           = "VirtualMachineImage is not compatible with v1alpha1 or is not a TKG Image"
 	storageClassNotAssignedFmt                = "Storage policy is not associated with the namespace %s"
@@ -60,11 +67,26 @@ const (
 	eagerZeroedAndThinProvisionedNotSupported = "Volume provisioning cannot have EagerZeroed and ThinProvisioning set. Eager zeroing requires thick provisioning"
 	addingModifyingInstanceVolumesNotAllowed  = "adding or modifying instance storage volume claim(s) is not allowed"
 	metadataTransportResourcesInvalid         = "%s and %s cannot be specified simultaneously"
+	invalidPowerStateOnCreateFmt              = "cannot set a new VM's power state to %s"
+	invalidPowerStateOnUpdateFmt              = "cannot %s a VM that is %s"
+	classNotBoundToNamespaceFmt               = "VirtualMachineClass is not bound to the namespace %s"
+	sourceIsSelf                              = "a VirtualMachine cannot be cloned from itself"
+	sourceAccessDeniedFmt                     = "user %q cannot get VirtualMachine %s"
+	sysprepRequiresSecret                     = "the Sysprep transport requires a Secret"
+	ignitionRequiresSecret                    = "the Ignition transport requires a Secret"
+	httpStatusRangeMaxLessThanMinFmt          = "must be greater than or equal to min %d"
 )
 
 // +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-virtualmachine,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachines,versions=v1alpha1,name=default.validating.virtualmachine.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines/status,verbs=get
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// CreateSubjectAccessReview creates the SubjectAccessReview and fills in its status. This is a variable so that unit
+// tests can fake the authorization decision.
+var CreateSubjectAccessReview = func(ctx goctx.Context, c client.Client, sar *authorizationv1.SubjectAccessReview) error {
+	return c.Create(ctx, sar)
+}
 
 // AddToManager adds the webhook to the provided manager.
 func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
@@ -104,14 +126,19 @@ func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.
 	var fieldErrs field.ErrorList
 
 	fieldErrs = append(fieldErrs, v.validateMetadata(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validatePowerStateOnCreate(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateNextRestartTime(ctx, vm, nil)...)
 	fieldErrs = append(fieldErrs, v.validateAvailabilityZone(ctx, vm, nil)...)
 	fieldErrs = append(fieldErrs, v.validateImage(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateSource(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateClass(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateStorageClass(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateNetwork(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateVolumes(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateVMVolumeProvisioningOptions(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateReadinessProbe(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateLivenessProbe(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateStartupProbe(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateInstanceStorageVolumes(ctx, vm, nil)...)
 
 	validationErrs := make([]string, 0, len(fieldErrs))
@@ -129,7 +156,7 @@ func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Resp
 // ValidateUpdate validates if the given VirtualMachineSpec update is valid.
 // Updates to following fields are not allowed:
 //   - ImageName
-//   - ClassName
+//   - Source
 //   - StorageClass
 //   - ResourcePolicyName
 
@@ -141,6 +168,9 @@ func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Resp
 //   - AdvancedOptions
 //     - DefaultVolumeProvisioningOptions
 
+// ClassName can be updated when the VM is powered on. The VM's CPU and memory are hot added when enabled
+// for the VM, otherwise the resize is pending until the VM is powered off.
+
 // All other updates are allowed.
 func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
 	vm, err := v.vmFromUnstructured(ctx.Obj)
@@ -164,8 +194,10 @@ func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.
 	// If a VM is powered off, all config changes are allowed.
 	// If a VM is requesting a power off, we can Reconfigure the VM _after_ we power it off - all changes are allowed.
 	// If a VM is requesting a power on, we can Reconfigure the VM _before_ we power it on - all changes are allowed.
-	// So, we only run these validations when the VM is powered on, and is not requesting a power state change.
-	if currentPowerState == vmopv1.VirtualMachinePoweredOn && desiredPowerState == vmopv1.VirtualMachinePoweredOn {
+	// A suspended VM cannot be reconfigured, and resuming a suspended VM does not Reconfigure it, so a suspended VM
+	// is treated the same as a powered on VM.
+	// So, we only run these validations when the VM is powered on or suspended, and is not requesting a power off.
+	if isPoweredOnOrSuspended(currentPowerState) && isPoweredOnOrSuspended(desiredPowerState) {
 		invalidFields := v.validateUpdatesWhenPoweredOn(ctx, vm, oldVM)
 		fieldErrs = append(fieldErrs, invalidFields...)
 	}
@@ -173,11 +205,16 @@ func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.
 	// Validations for allowed updates. Return validation responses here for conditional updates regardless
 	// of whether the update is allowed or not.
 	fieldErrs = append(fieldErrs, v.validateMetadata(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateClassOnUpdate(ctx, vm, oldVM)...)
+	fieldErrs = append(fieldErrs, v.validatePowerStateOnUpdate(ctx, vm, oldVM)...)
+	fieldErrs = append(fieldErrs, v.validateNextRestartTime(ctx, vm, oldVM)...)
 	fieldErrs = append(fieldErrs, v.validateAvailabilityZone(ctx, vm, oldVM)...)
 	fieldErrs = append(fieldErrs, v.validateNetwork(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateVolumes(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateVMVolumeProvisioningOptions(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateReadinessProbe(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateLivenessProbe(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateStartupProbe(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateInstanceStorageVolumes(ctx, vm, oldVM)...)
 
 	validationErrs := make([]string, 0, len(fieldErrs))
@@ -202,9 +239,63 @@ func (v validator) validateMetadata(ctx *context.WebhookRequestContext, vm *vmop
 			fmt.Sprintf(metadataTransportResourcesInvalid, mdPath.Child("configMapName"), mdPath.Child("secretName"))))
 	}
 
+	// The Sysprep answers include passwords so they may only be provided by a Secret.
+	if vm.Spec.VmMetadata.Transport == vmopv1.VirtualMachineMetadataSysprepTransport && vm.Spec.VmMetadata.ConfigMapName != "" {
+		allErrs = append(allErrs, field.Forbidden(mdPath.Child("configMapName"), sysprepRequiresSecret))
+	}
+
+	// The Ignition config may include credentials so it may only be provided by a Secret.
+	if vm.Spec.VmMetadata.Transport == vmopv1.VirtualMachineMetadataIgnitionTransport && vm.Spec.VmMetadata.ConfigMapName != "" {
+		allErrs = append(allErrs, field.Forbidden(mdPath.Child("configMapName"), ignitionRequiresSecret))
+	}
+
+	return allErrs
+}
+
+func (v validator) validatePowerStateOnCreate(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// A new VM cannot be suspended since it has never been powered on.
+	if vm.Spec.PowerState == vmopv1.VirtualMachineSuspended {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "powerState"), vm.Spec.PowerState,
+			fmt.Sprintf(invalidPowerStateOnCreateFmt, vm.Spec.PowerState)))
+	}
+
+	return allErrs
+}
+
+func (v validator) validatePowerStateOnUpdate(ctx *context.WebhookRequestContext, vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Only a powered on VM can be suspended.
+	if vm.Spec.PowerState == vmopv1.VirtualMachineSuspended && oldVM.Spec.PowerState == vmopv1.VirtualMachinePoweredOff {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "powerState"), vm.Spec.PowerState,
+			fmt.Sprintf(invalidPowerStateOnUpdateFmt, "suspend", "powered off")))
+	}
+
+	return allErrs
+}
+
+// validateNextRestartTime validates spec.nextRestartTime. The mutation webhook has already
+// replaced the value "now" with the current time.
+func (v validator) validateNextRestartTime(ctx *context.WebhookRequestContext, vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
+	var allErrs field.ErrorList
+
+	var oldNextRestartTime string
+	if oldVM != nil {
+		oldNextRestartTime = oldVM.Spec.NextRestartTime
+	}
+
+	nextRestartTimePath := field.NewPath("spec", "nextRestartTime")
+	allErrs = append(allErrs, util.ValidateTriggerTime(nextRestartTimePath, vm.Spec.NextRestartTime, oldNextRestartTime)...)
+
 	return allErrs
 }
 
+func isPoweredOnOrSuspended(powerState vmopv1.VirtualMachinePowerState) bool {
+	return powerState == vmopv1.VirtualMachinePoweredOn || powerState == vmopv1.VirtualMachineSuspended
+}
+
 func (v validator) validateImage(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -220,8 +311,12 @@ func (v validator) validateImage(ctx *context.WebhookRequestContext, vm *vmopv1.
 		return allErrs
 	}
 
-	if vm.Spec.VmMetadata != nil && vm.Spec.VmMetadata.Transport == vmopv1.VirtualMachineMetadataCloudInitTransport {
-		return allErrs
+	if vm.Spec.VmMetadata != nil {
+		switch vm.Spec.VmMetadata.Transport {
+		case vmopv1.VirtualMachineMetadataCloudInitTransport, vmopv1.VirtualMachineMetadataSysprepTransport,
+			vmopv1.VirtualMachineMetadataIgnitionTransport:
+			return allErrs
+		}
 	}
 
 	// With the effort of UnifiedTKG, image-support-check isn't needed when UnifiedTKG FSS enabled.
@@ -253,6 +348,82 @@ func (v validator) validateImage(ctx *context.WebhookRequestContext, vm *vmopv1.
 	return allErrs
 }
 
+// validateSource validates that the source VirtualMachine to clone exists and that the user creating the VirtualMachine
+// can get it. The source VirtualMachine is always looked up in the namespace of the VirtualMachine so a VirtualMachine
+// in another namespace cannot be cloned.
+func (v validator) validateSource(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if vm.Spec.Source == nil {
+		return allErrs
+	}
+
+	srcVMNamePath := field.NewPath("spec", "source", "virtualMachineName")
+	srcVMName := vm.Spec.Source.VirtualMachineName
+
+	if srcVMName == "" {
+		return append(allErrs, field.Required(srcVMNamePath, ""))
+	}
+
+	if srcVMName == vm.Name {
+		return append(allErrs, field.Invalid(srcVMNamePath, srcVMName, sourceIsSelf))
+	}
+
+	srcVM := &vmopv1.VirtualMachine{}
+	if err := v.client.Get(ctx, client.ObjectKey{Name: srcVMName, Namespace: vm.Namespace}, srcVM); err != nil {
+		if apierrors.IsNotFound(err) {
+			return append(allErrs, field.NotFound(srcVMNamePath, srcVMName))
+		}
+		return append(allErrs, field.Invalid(srcVMNamePath, srcVMName, err.Error()))
+	}
+
+	// The webhook reads the source VirtualMachine with its own service account, so check that the user is
+	// allowed to read it too. Otherwise, anyone who can create a VirtualMachine could clone any VirtualMachine
+	// in the namespace.
+	if !ctx.IsPrivilegedAccount {
+		allowed, err := v.canGetVirtualMachine(ctx, srcVM)
+		if err != nil {
+			return append(allErrs, field.Invalid(srcVMNamePath, srcVMName, err.Error()))
+		}
+		if !allowed {
+			return append(allErrs, field.Forbidden(srcVMNamePath,
+				fmt.Sprintf(sourceAccessDeniedFmt, ctx.UserInfo.Username, srcVMName)))
+		}
+	}
+
+	return allErrs
+}
+
+// canGetVirtualMachine returns true if the user of the request is authorized to get the VirtualMachine.
+func (v validator) canGetVirtualMachine(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(ctx.UserInfo.Extra))
+	for k, val := range ctx.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(val)
+	}
+
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: vm.Namespace,
+				Verb:      "get",
+				Group:     vmopv1.SchemeGroupVersion.Group,
+				Resource:  "virtualmachines",
+				Name:      vm.Name,
+			},
+			User:   ctx.UserInfo.Username,
+			Groups: ctx.UserInfo.Groups,
+			UID:    ctx.UserInfo.UID,
+			Extra:  extra,
+		},
+	}
+
+	if err := CreateSubjectAccessReview(ctx, v.client, sar); err != nil {
+		return false, errors.Wrap(err, "failed to create SubjectAccessReview")
+	}
+
+	return sar.Status.Allowed, nil
+}
+
 func (v validator) validateClass(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -263,6 +434,43 @@ func (v validator) validateClass(ctx *context.WebhookRequestContext, vm *vmopv1.
 	return allErrs
 }
 
+// validateClassOnUpdate validates a change to the VM's class. The new class must exist and be bound
+// to the VM's namespace so the VM can be resized to it.
+func (v validator) validateClassOnUpdate(ctx *context.WebhookRequestContext, vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if vm.Spec.ClassName == oldVM.Spec.ClassName {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, v.validateClass(ctx, vm)...)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	classPath := field.NewPath("spec", "className")
+	className := vm.Spec.ClassName
+
+	vmClass := &vmopv1.VirtualMachineClass{}
+	if err := v.client.Get(ctx, client.ObjectKey{Name: className}, vmClass); err != nil {
+		return append(allErrs, field.Invalid(classPath, className, err.Error()))
+	}
+
+	classBindings := &vmopv1.VirtualMachineClassBindingList{}
+	if err := v.client.List(ctx, classBindings, client.InNamespace(vm.Namespace)); err != nil {
+		return append(allErrs, field.Invalid(classPath, className, err.Error()))
+	}
+
+	for _, binding := range classBindings.Items {
+		if binding.ClassRef.Kind == "VirtualMachineClass" && binding.ClassRef.Name == className {
+			return allErrs
+		}
+	}
+
+	return append(allErrs, field.Invalid(classPath, className,
+		fmt.Sprintf(classNotBoundToNamespaceFmt, vm.Namespace)))
+}
+
 func (v validator) validateStorageClass(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -497,36 +705,151 @@ func (v validator) validateVMVolumeProvisioningOptions(ctx *context.WebhookReque
 }
 
 func (v validator) validateReadinessProbe(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
-	var allErrs field.ErrorList
-
 	probe := vm.Spec.ReadinessProbe
 	if probe == nil {
-		return allErrs
+		return nil
+	}
+
+	return v.validateProbe(ctx, probe, field.NewPath("spec", "readinessProbe"))
+}
+
+func (v validator) validateLivenessProbe(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+	probe := vm.Spec.LivenessProbe
+	if probe == nil {
+		return nil
 	}
 
-	readinessProbePath := field.NewPath("spec", "readinessProbe")
+	livenessProbePath := field.NewPath("spec", "livenessProbe")
+	allErrs := v.validateProbe(ctx, probe, livenessProbePath)
+	allErrs = append(allErrs, validateProbeSuccessThresholdIsOne(probe, livenessProbePath)...)
+
+	return allErrs
+}
+
+func (v validator) validateStartupProbe(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+	probe := vm.Spec.StartupProbe
+	if probe == nil {
+		return nil
+	}
+
+	startupProbePath := field.NewPath("spec", "startupProbe")
+	allErrs := v.validateProbe(ctx, probe, startupProbePath)
+	allErrs = append(allErrs, validateProbeSuccessThresholdIsOne(probe, startupProbePath)...)
+
+	return allErrs
+}
+
+// validateProbeSuccessThresholdIsOne validates the success threshold of the liveness and startup probes, for which
+// a single success is enough to be considered successful.
+func validateProbeSuccessThresholdIsOne(probe *vmopv1.Probe, probePath *field.Path) field.ErrorList {
+	if probe.SuccessThreshold != 0 && probe.SuccessThreshold != 1 {
+		return field.ErrorList{field.Invalid(probePath.Child("successThreshold"), probe.SuccessThreshold,
+			probeSuccessThresholdMustBeOne)}
+	}
+	return nil
+}
+
+func (v validator) validateProbe(ctx *context.WebhookRequestContext, probe *vmopv1.Probe, probePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	numActions := 0
+	if probe.TCPSocket != nil {
+		numActions++
+	}
+	if probe.GuestHeartbeat != nil {
+		numActions++
+	}
+	if probe.HTTPGet != nil {
+		numActions++
+	}
+	if probe.Exec != nil {
+		numActions++
+	}
 
-	if probe.TCPSocket == nil && probe.GuestHeartbeat == nil {
-		allErrs = append(allErrs, field.Forbidden(readinessProbePath, readinessProbeNoActions))
-	} else if probe.TCPSocket != nil && probe.GuestHeartbeat != nil {
-		allErrs = append(allErrs, field.Forbidden(readinessProbePath, readinessProbeOnlyOneAction))
+	if numActions == 0 {
+		allErrs = append(allErrs, field.Forbidden(probePath, readinessProbeNoActions))
+	} else if numActions > 1 {
+		allErrs = append(allErrs, field.Forbidden(probePath, readinessProbeOnlyOneAction))
 	}
 
 	// Validate the TCP probe if set and environment is a restricted network environment between CP VMs and Workload VMs e.g. VMC
 	if probe.TCPSocket != nil {
-		tcpSocketPath := readinessProbePath.Child("tcpSocket")
-		isRestrictedEnv, err := v.isNetworkRestrictedForReadinessProbe(ctx)
-		if err != nil {
-			allErrs = append(allErrs, field.Forbidden(tcpSocketPath, err.Error()))
-		} else if isRestrictedEnv && probe.TCPSocket.Port.IntValue() != allowedRestrictedNetworkTCPProbePort {
-			allErrs = append(allErrs, field.NotSupported(tcpSocketPath.Child("port"), probe.TCPSocket.Port.IntValue(),
-				[]string{strconv.Itoa(allowedRestrictedNetworkTCPProbePort)}))
+		allErrs = append(allErrs, v.validateProbePortInRestrictedNetwork(ctx, probe.TCPSocket.Port, probePath.Child("tcpSocket"))...)
+	}
+
+	if probe.HTTPGet != nil {
+		httpGetPath := probePath.Child("httpGet")
+		allErrs = append(allErrs, validateHTTPGetAction(probe.HTTPGet, httpGetPath)...)
+		allErrs = append(allErrs, v.validateProbePortInRestrictedNetwork(ctx, probe.HTTPGet.Port, httpGetPath)...)
+	}
+
+	// The Exec probe runs inside the guest so it is not subject to the restricted network port check.
+	if probe.Exec != nil {
+		execPath := probePath.Child("exec")
+		if len(probe.Exec.Command) == 0 || probe.Exec.Command[0] == "" {
+			allErrs = append(allErrs, field.Required(execPath.Child("command"), ""))
+		}
+		if probe.Exec.SecretName == "" {
+			allErrs = append(allErrs, field.Required(execPath.Child("secretName"), ""))
+		}
+	}
+
+	return allErrs
+}
+
+// validateProbePortInRestrictedNetwork validates that the probe's port is allowed when the environment is a
+// restricted network environment between CP VMs and Workload VMs e.g. VMC.
+func (v validator) validateProbePortInRestrictedNetwork(
+	ctx *context.WebhookRequestContext,
+	port intstr.IntOrString,
+	actionPath *field.Path) field.ErrorList {
+
+	var allErrs field.ErrorList
+
+	isRestrictedEnv, err := v.isNetworkRestrictedForReadinessProbe(ctx)
+	if err != nil {
+		allErrs = append(allErrs, field.Forbidden(actionPath, err.Error()))
+	} else if isRestrictedEnv && port.IntValue() != allowedRestrictedNetworkTCPProbePort {
+		allErrs = append(allErrs, field.NotSupported(actionPath.Child("port"), port.IntValue(),
+			[]string{strconv.Itoa(allowedRestrictedNetworkTCPProbePort)}))
+	}
+
+	return allErrs
+}
+
+func validateHTTPGetAction(action *vmopv1.HTTPGetAction, httpGetPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	portPath := httpGetPath.Child("port")
+	switch action.Port.Type {
+	case intstr.Int:
+		for _, msg := range utilvalidation.IsValidPortNum(action.Port.IntValue()) {
+			allErrs = append(allErrs, field.Invalid(portPath, action.Port.IntValue(), msg))
+		}
+	case intstr.String:
+		for _, msg := range utilvalidation.IsValidPortName(action.Port.StrVal) {
+			allErrs = append(allErrs, field.Invalid(portPath, action.Port.StrVal, msg))
+		}
+	}
+
+	for i, header := range action.HTTPHeaders {
+		for _, msg := range utilvalidation.IsHTTPHeaderName(header.Name) {
+			allErrs = append(allErrs, field.Invalid(httpGetPath.Child("httpHeaders").Index(i).Child("name"), header.Name, msg))
+		}
+	}
+
+	for i, r := range action.ExpectedStatuses {
+		if r.Max != 0 && r.Max < r.Min {
+			allErrs = append(allErrs, field.Invalid(httpGetPath.Child("expectedStatuses").Index(i).Child("max"), r.Max,
+				fmt.Sprintf(httpStatusRangeMaxLessThanMinFmt, r.Min)))
 		}
 	}
 
 	return allErrs
 }
 
+// validateUpdatesWhenPoweredOn validates the updates to a powered on VM. Changes to spec.className are not
+// validated here since a powered on VM is resized to its new class using hot add, or when it is next powered off.
 func (v validator) validateUpdatesWhenPoweredOn(ctx *context.WebhookRequestContext, vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -611,7 +934,7 @@ func (v validator) validateImmutableFields(ctx *context.WebhookRequestContext, v
 	specPath := field.NewPath("spec")
 
 	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.ImageName, oldVM.Spec.ImageName, specPath.Child("imageName"))...)
-	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.ClassName, oldVM.Spec.ClassName, specPath.Child("className"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.Source, oldVM.Spec.Source, specPath.Child("source"))...)
 	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.StorageClass, oldVM.Spec.StorageClass, specPath.Child("storageClass"))...)
 	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.ResourcePolicyName, oldVM.Spec.ResourcePolicyName, specPath.Child("resourcePolicyName"))...)
 
@@ -658,7 +981,7 @@ func (v validator) isNetworkRestrictedForReadinessProbe(ctx *context.WebhookRequ
 	configMap := &corev1.ConfigMap{}
 	configMapKey := client.ObjectKey{Name: config.ProviderConfigMapName, Namespace: ctx.Namespace}
 	if err := v.client.Get(ctx, configMapKey, configMap); err != nil {
-		return false, fmt.Errorf("error fetching config map: %s while validating TCP readiness probe port: %v", configMapKey, err)
+		return false, fmt.Errorf("error fetching config map: %s while validating readiness probe port: %v", configMapKey, err)
 	}
 
 	return configMap.Data[isRestrictedNetworkKey] == "true", nil
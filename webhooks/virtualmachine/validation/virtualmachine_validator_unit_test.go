@@ -4,7 +4,10 @@
 package validation_test
 
 import (
+	goctx "context"
 	"fmt"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 
@@ -12,6 +15,7 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -29,12 +33,15 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere/network"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
+	vmvalidation "github.com/vmware-tanzu/vm-operator/webhooks/virtualmachine/validation"
 )
 
 const (
 	updateSuffix            = "-updated"
+	unboundSuffix           = "-unbound"
 	dummyNamespaceImageName = "dummy-namespace-image"
 	dummyClusterImageName   = "dummy-cluster-image"
+	dummySourceVMName       = "dummy-source-vm"
 )
 
 func unitTests() {
@@ -73,7 +80,11 @@ func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhoo
 	nsVMImage.Namespace = vm.Namespace
 	clusterVMImage := builder.DummyClusterVirtualMachineImage(dummyClusterImageName)
 
-	initObjects := []client.Object{vmImage, vmImage1, zone, nsVMImage, clusterVMImage}
+	vmClass1, vmClassBinding1 := builder.DummyVirtualMachineClassAndBinding(vm.Spec.ClassName+updateSuffix, vm.Namespace)
+	unboundVMClass := builder.DummyVirtualMachineClass()
+	unboundVMClass.Name = vm.Spec.ClassName + unboundSuffix
+
+	initObjects := []client.Object{vmImage, vmImage1, zone, nsVMImage, clusterVMImage, vmClass1, vmClassBinding1, unboundVMClass}
 
 	return &unitValidatingWebhookContext{
 		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj, initObjects...),
@@ -116,6 +127,7 @@ func unitTestsValidateCreate() {
 		oldFaultDomainsFunc  func() bool
 		oldUnifiedTKGFunc    func() bool
 		oldImageRegistryFunc func() bool
+		oldCreateSARFunc     func(goctx.Context, client.Client, *authorizationv1.SubjectAccessReview) error
 	)
 
 	const (
@@ -149,8 +161,21 @@ func unitTestsValidateCreate() {
 		imageNonCompatible                   bool
 		imageSupportCheckSkipAnnotation      bool
 		imageNonCompatibleCloudInitTransport bool
+		imageNonCompatibleSysprepTransport   bool
+		imageNonCompatibleIgnitionTransport  bool
+		sysprepTransportWithConfigMap        bool
+		ignitionTransportWithConfigMap       bool
 		invalidReadinessNoProbe              bool
 		invalidReadinessProbe                bool
+		validHTTPGetReadinessProbe           bool
+		invalidHTTPGetReadinessProbe         bool
+		multipleWithHTTPGetReadinessProbe    bool
+		validExecReadinessProbe              bool
+		invalidExecReadinessProbe            bool
+		validLivenessProbe                   bool
+		invalidLivenessProbe                 bool
+		validStartupProbe                    bool
+		invalidStartupProbe                  bool
 		isRestrictedNetworkEnv               bool
 		isRestrictedNetworkValidProbePort    bool
 		isNonRestrictedNetworkEnv            bool
@@ -162,6 +187,12 @@ func unitTestsValidateCreate() {
 		isServiceUser                        bool
 		addInstanceStorageVolumes            bool
 		isWCPVMImageRegistryEnabled          bool
+		isSuspendedPowerState                bool
+		sourceVM                             bool
+		sourceVMNotFound                     bool
+		sourceVMInOtherNamespace             bool
+		sourceVMIsSelf                       bool
+		sourceVMAccessDenied                 bool
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -196,6 +227,22 @@ func unitTestsValidateCreate() {
 		if args.imageNonCompatibleCloudInitTransport {
 			ctx.vm.Spec.VmMetadata.Transport = vmopv1.VirtualMachineMetadataCloudInitTransport
 		}
+		if args.imageNonCompatibleSysprepTransport {
+			ctx.vm.Spec.VmMetadata.Transport = vmopv1.VirtualMachineMetadataSysprepTransport
+			ctx.vm.Spec.VmMetadata.SecretName = ctx.vm.Spec.VmMetadata.ConfigMapName
+			ctx.vm.Spec.VmMetadata.ConfigMapName = ""
+		}
+		if args.imageNonCompatibleIgnitionTransport {
+			ctx.vm.Spec.VmMetadata.Transport = vmopv1.VirtualMachineMetadataIgnitionTransport
+			ctx.vm.Spec.VmMetadata.SecretName = ctx.vm.Spec.VmMetadata.ConfigMapName
+			ctx.vm.Spec.VmMetadata.ConfigMapName = ""
+		}
+		if args.ignitionTransportWithConfigMap {
+			ctx.vm.Spec.VmMetadata.Transport = vmopv1.VirtualMachineMetadataIgnitionTransport
+		}
+		if args.sysprepTransportWithConfigMap {
+			ctx.vm.Spec.VmMetadata.Transport = vmopv1.VirtualMachineMetadataSysprepTransport
+		}
 		if args.invalidNetworkType {
 			ctx.vm.Spec.NetworkInterfaces[0].NetworkName = bogusNetworkName
 			ctx.vm.Spec.NetworkInterfaces[0].NetworkType = "bogusNetworkType"
@@ -295,6 +342,73 @@ func unitTestsValidateCreate() {
 				GuestHeartbeat: &vmopv1.GuestHeartbeatAction{},
 			}
 		}
+		if args.validHTTPGetReadinessProbe {
+			ctx.vm.Spec.ReadinessProbe = &vmopv1.Probe{
+				HTTPGet: &vmopv1.HTTPGetAction{
+					Path:             "/healthz",
+					Port:             intstr.FromString("http"),
+					Scheme:           vmopv1.URISchemeHTTPS,
+					HTTPHeaders:      []vmopv1.HTTPHeader{{Name: "X-Probe", Value: "true"}},
+					ExpectedStatuses: []vmopv1.HTTPStatusRange{{Min: 200}, {Min: 300, Max: 302}},
+				},
+			}
+		}
+		if args.invalidHTTPGetReadinessProbe {
+			ctx.vm.Spec.ReadinessProbe = &vmopv1.Probe{
+				HTTPGet: &vmopv1.HTTPGetAction{
+					Port:             intstr.FromInt(0),
+					HTTPHeaders:      []vmopv1.HTTPHeader{{Name: "X Probe"}},
+					ExpectedStatuses: []vmopv1.HTTPStatusRange{{Min: 300, Max: 200}},
+				},
+			}
+		}
+		if args.multipleWithHTTPGetReadinessProbe {
+			ctx.vm.Spec.ReadinessProbe = &vmopv1.Probe{
+				HTTPGet:        &vmopv1.HTTPGetAction{Port: intstr.FromInt(80)},
+				GuestHeartbeat: &vmopv1.GuestHeartbeatAction{},
+			}
+		}
+		if args.validHTTPGetReadinessProbe || args.invalidHTTPGetReadinessProbe || args.multipleWithHTTPGetReadinessProbe {
+			Expect(ctx.Client.Create(ctx, setConfigMap(ctx.Namespace, false))).To(Succeed())
+		}
+		if args.validExecReadinessProbe {
+			ctx.vm.Spec.ReadinessProbe = &vmopv1.Probe{
+				Exec: &vmopv1.ExecAction{
+					Command:    []string{"/usr/bin/systemctl", "is-active", "nginx"},
+					SecretName: "guest-creds",
+				},
+			}
+		}
+		if args.invalidExecReadinessProbe {
+			ctx.vm.Spec.ReadinessProbe = &vmopv1.Probe{
+				Exec: &vmopv1.ExecAction{},
+			}
+		}
+		if args.validLivenessProbe {
+			ctx.vm.Spec.LivenessProbe = &vmopv1.Probe{
+				GuestHeartbeat:      &vmopv1.GuestHeartbeatAction{},
+				InitialDelaySeconds: 60,
+				SuccessThreshold:    1,
+				FailureThreshold:    5,
+			}
+		}
+		if args.invalidLivenessProbe {
+			ctx.vm.Spec.LivenessProbe = &vmopv1.Probe{
+				SuccessThreshold: 2,
+			}
+		}
+		if args.validStartupProbe {
+			ctx.vm.Spec.StartupProbe = &vmopv1.Probe{
+				GuestHeartbeat:   &vmopv1.GuestHeartbeatAction{},
+				PeriodSeconds:    10,
+				FailureThreshold: 60,
+			}
+		}
+		if args.invalidStartupProbe {
+			ctx.vm.Spec.StartupProbe = &vmopv1.Probe{
+				SuccessThreshold: 3,
+			}
+		}
 		if args.isRestrictedNetworkEnv || args.isNonRestrictedNetworkEnv {
 			configMapIn := setConfigMap(ctx.Namespace, args.isRestrictedNetworkEnv)
 			ctx.vm.Spec.ReadinessProbe = setReadinessProbe(args.isRestrictedNetworkValidProbePort)
@@ -303,10 +417,37 @@ func unitTestsValidateCreate() {
 		if args.isServiceUser {
 			ctx.IsPrivilegedAccount = true
 		}
+		if args.isSuspendedPowerState {
+			ctx.vm.Spec.PowerState = vmopv1.VirtualMachineSuspended
+		}
 		if args.addInstanceStorageVolumes {
 			instanceStorageVolume := builder.DummyInstanceStorageVirtualMachineVolumes()
 			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, instanceStorageVolume...)
 		}
+		if args.sourceVM || args.sourceVMNotFound || args.sourceVMInOtherNamespace || args.sourceVMIsSelf {
+			srcVM := builder.DummyVirtualMachine()
+			srcVM.Name = dummySourceVMName
+			srcVM.Namespace = ctx.vm.Namespace
+			if args.sourceVMInOtherNamespace {
+				srcVM.Namespace += updateSuffix
+			}
+			if !args.sourceVMNotFound {
+				Expect(ctx.Client.Create(ctx, srcVM)).To(Succeed())
+			}
+			ctx.vm.Spec.Source = &vmopv1.VirtualMachineSource{VirtualMachineName: srcVM.Name}
+			if args.sourceVMIsSelf {
+				ctx.vm.Spec.Source.VirtualMachineName = ctx.vm.Name
+			}
+		}
+		ctx.UserInfo.Username = "dummy-user"
+		vmvalidation.CreateSubjectAccessReview = func(_ goctx.Context, _ client.Client, sar *authorizationv1.SubjectAccessReview) error {
+			Expect(sar.Spec.User).To(Equal("dummy-user"))
+			Expect(sar.Spec.ResourceAttributes.Verb).To(Equal("get"))
+			Expect(sar.Spec.ResourceAttributes.Resource).To(Equal("virtualmachines"))
+			Expect(sar.Spec.ResourceAttributes.Name).To(Equal(dummySourceVMName))
+			sar.Status.Allowed = !args.sourceVMAccessDenied
+			return nil
+		}
 		// Please note this prevents the unit tests from running safely in parallel.
 		lib.IsWcpFaultDomainsFSSEnabled = func() bool {
 			return args.isWCPFaultDomainsFSSEnabled
@@ -352,18 +493,21 @@ func unitTestsValidateCreate() {
 		oldFaultDomainsFunc = lib.IsWcpFaultDomainsFSSEnabled
 		oldUnifiedTKGFunc = lib.IsUnifiedTKGFSSEnabled
 		oldImageRegistryFunc = lib.IsWCPVMImageRegistryEnabled
+		oldCreateSARFunc = vmvalidation.CreateSubjectAccessReview
 	})
 
 	AfterEach(func() {
 		lib.IsWcpFaultDomainsFSSEnabled = oldFaultDomainsFunc
 		lib.IsUnifiedTKGFSSEnabled = oldUnifiedTKGFunc
 		lib.IsWCPVMImageRegistryEnabled = oldImageRegistryFunc
+		vmvalidation.CreateSubjectAccessReview = oldCreateSARFunc
 		ctx = nil
 	})
 
 	specPath := field.NewPath("spec")
 	netIntPath := specPath.Child("networkInterfaces")
 	volPath := specPath.Child("volumes")
+	srcVMNamePath := specPath.Child("source", "virtualMachineName")
 
 	DescribeTable("create table", validateCreate,
 		Entry("should allow valid", createArgs{}, true, nil, nil),
@@ -381,6 +525,33 @@ func unitTestsValidateCreate() {
 			field.Forbidden(specPath.Child("readinessProbe"), "only one action can be specified").Error(), nil),
 		Entry("should fail when Readiness probe has no actions", createArgs{invalidReadinessNoProbe: true}, false,
 			field.Forbidden(specPath.Child("readinessProbe"), "must specify an action").Error(), nil),
+		Entry("should allow valid HTTPGet Readiness probe", createArgs{validHTTPGetReadinessProbe: true}, true, nil, nil),
+		Entry("should fail when HTTPGet Readiness probe is invalid", createArgs{invalidHTTPGetReadinessProbe: true}, false,
+			strings.Join([]string{
+				field.Invalid(specPath.Child("readinessProbe", "httpGet", "port"), 0, "must be between 1 and 65535, inclusive").Error(),
+				field.Invalid(specPath.Child("readinessProbe", "httpGet", "httpHeaders").Index(0).Child("name"), "X Probe", strings.Join(validation.IsHTTPHeaderName("X Probe"), ", ")).Error(),
+				field.Invalid(specPath.Child("readinessProbe", "httpGet", "expectedStatuses").Index(0).Child("max"), 200, "must be greater than or equal to min 300").Error(),
+			}, ", "), nil),
+		Entry("should fail when Readiness probe has HTTPGet and another action", createArgs{multipleWithHTTPGetReadinessProbe: true}, false,
+			field.Forbidden(specPath.Child("readinessProbe"), "only one action can be specified").Error(), nil),
+		Entry("should allow valid Liveness probe", createArgs{validLivenessProbe: true}, true, nil, nil),
+		Entry("should fail when Liveness probe is invalid", createArgs{invalidLivenessProbe: true}, false,
+			strings.Join([]string{
+				field.Forbidden(specPath.Child("livenessProbe"), "must specify an action").Error(),
+				field.Invalid(specPath.Child("livenessProbe", "successThreshold"), 2, "must be 1").Error(),
+			}, ", "), nil),
+		Entry("should allow valid Startup probe", createArgs{validStartupProbe: true}, true, nil, nil),
+		Entry("should fail when Startup probe is invalid", createArgs{invalidStartupProbe: true}, false,
+			strings.Join([]string{
+				field.Forbidden(specPath.Child("startupProbe"), "must specify an action").Error(),
+				field.Invalid(specPath.Child("startupProbe", "successThreshold"), 3, "must be 1").Error(),
+			}, ", "), nil),
+		Entry("should allow valid Exec Readiness probe", createArgs{validExecReadinessProbe: true}, true, nil, nil),
+		Entry("should fail when Exec Readiness probe is invalid", createArgs{invalidExecReadinessProbe: true}, false,
+			strings.Join([]string{
+				field.Required(specPath.Child("readinessProbe", "exec", "command"), "").Error(),
+				field.Required(specPath.Child("readinessProbe", "exec", "secretName"), "").Error(),
+			}, ", "), nil),
 
 		Entry("should deny invalid network type", createArgs{invalidNetworkType: true}, false,
 			field.NotSupported(netIntPath.Index(0).Child("networkType"), "bogusNetworkType", []string{network.NsxtNetworkType, network.VdsNetworkType}).Error(), nil),
@@ -432,6 +603,12 @@ func unitTestsValidateCreate() {
 		Entry("should allow despite incompatible image when UnifiedTKG FSS disabled and VMOperatorImageSupportedCheckKey is enabled", createArgs{imageSupportCheckSkipAnnotation: true, imageNonCompatible: true}, true, nil, nil),
 		Entry("should allow despite incompatible image when UnifiedTKG FSS enabled", createArgs{isUnifiedTKGFSSEnabled: true, imageNonCompatible: true}, true, nil, nil),
 		Entry("should allow despite incompatible image when VirtualMachineMetadataTransport is CloudInit", createArgs{imageNonCompatibleCloudInitTransport: true}, true, nil, nil),
+		Entry("should allow despite incompatible image when VirtualMachineMetadataTransport is Sysprep", createArgs{imageNonCompatibleSysprepTransport: true, imageNonCompatible: true}, true, nil, nil),
+		Entry("should allow despite incompatible image when VirtualMachineMetadataTransport is Ignition", createArgs{imageNonCompatibleIgnitionTransport: true, imageNonCompatible: true}, true, nil, nil),
+		Entry("should deny when VirtualMachineMetadataTransport is Sysprep and a ConfigMap is specified", createArgs{sysprepTransportWithConfigMap: true}, false,
+			field.Forbidden(specPath.Child("vmMetadata", "configMapName"), "the Sysprep transport requires a Secret").Error(), nil),
+		Entry("should deny when VirtualMachineMetadataTransport is Ignition and a ConfigMap is specified", createArgs{ignitionTransportWithConfigMap: true}, false,
+			field.Forbidden(specPath.Child("vmMetadata", "configMapName"), "the Ignition transport requires a Secret").Error(), nil),
 
 		Entry("should deny when restricted network env is set in provider config map and TCP port in readiness probe is not 6443", createArgs{isRestrictedNetworkEnv: true, isRestrictedNetworkValidProbePort: false}, false,
 			field.NotSupported(specPath.Child("readinessProbe", "tcpSocket", "port"), 443, []string{"6443"}).Error(), nil),
@@ -455,6 +632,20 @@ func unitTestsValidateCreate() {
 		Entry("should deny when there are instance storage volumes and user is SSO user", createArgs{addInstanceStorageVolumes: true}, false,
 			field.Forbidden(volPath, "adding or modifying instance storage volume claim(s) is not allowed").Error(), nil),
 		Entry("should allow when there are instance storage volumes and user is service user", createArgs{addInstanceStorageVolumes: true, isServiceUser: true}, true, nil, nil),
+
+		Entry("should deny when power state is suspended", createArgs{isSuspendedPowerState: true}, false,
+			field.Invalid(field.NewPath("spec", "powerState"), vmopv1.VirtualMachineSuspended, "cannot set a new VM's power state to suspended").Error(), nil),
+
+		Entry("should allow source VM in the same namespace", createArgs{sourceVM: true}, true, nil, nil),
+		Entry("should deny source VM that does not exist", createArgs{sourceVMNotFound: true}, false,
+			field.NotFound(srcVMNamePath, dummySourceVMName).Error(), nil),
+		Entry("should deny source VM in another namespace", createArgs{sourceVMInOtherNamespace: true}, false,
+			field.NotFound(srcVMNamePath, dummySourceVMName).Error(), nil),
+		Entry("should deny source VM that is the VM itself", createArgs{sourceVMIsSelf: true}, false,
+			field.Invalid(srcVMNamePath, "dummy-vm-for-webhook-validation", "a VirtualMachine cannot be cloned from itself").Error(), nil),
+		Entry("should deny source VM that the user cannot get", createArgs{sourceVM: true, sourceVMAccessDenied: true}, false,
+			field.Forbidden(srcVMNamePath, `user "dummy-user" cannot get VirtualMachine dummy-source-vm`).Error(), nil),
+		Entry("should allow source VM for service user without checking access", createArgs{sourceVM: true, sourceVMAccessDenied: true, isServiceUser: true}, true, nil, nil),
 	)
 }
 
@@ -465,7 +656,10 @@ func unitTestsValidateUpdate() {
 
 	type updateArgs struct {
 		changeClassName                 bool
+		changeClassNameNotFound         bool
+		changeClassNameUnbound          bool
 		changeImageName                 bool
+		changeSource                    bool
 		changeStorageClass              bool
 		changeResourcePolicy            bool
 		assignZoneName                  bool
@@ -473,6 +667,10 @@ func unitTestsValidateUpdate() {
 		changeInstanceStorageVolumeName bool
 		isServiceUser                   bool
 		addInstanceStorageVolume        bool
+		oldPowerState                   vmopv1.VirtualMachinePowerState
+		newPowerState                   vmopv1.VirtualMachinePowerState
+		changeVMMetadata                bool
+		nextRestartTime                 string
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -481,6 +679,12 @@ func unitTestsValidateUpdate() {
 		if args.changeClassName {
 			ctx.vm.Spec.ClassName += updateSuffix
 		}
+		if args.changeClassNameNotFound {
+			ctx.vm.Spec.ClassName += "-not-found"
+		}
+		if args.changeClassNameUnbound {
+			ctx.vm.Spec.ClassName += unboundSuffix
+		}
 		if args.changeImageName {
 			ctx.vm.Spec.ImageName += updateSuffix
 		}
@@ -490,6 +694,9 @@ func unitTestsValidateUpdate() {
 		if args.changeResourcePolicy {
 			ctx.vm.Spec.ResourcePolicyName = updateSuffix
 		}
+		if args.changeSource {
+			ctx.vm.Spec.Source = &vmopv1.VirtualMachineSource{VirtualMachineName: dummySourceVMName}
+		}
 		if args.assignZoneName {
 			ctx.vm.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName
 		}
@@ -511,6 +718,18 @@ func unitTestsValidateUpdate() {
 			instanceStorageVolumes[0].Name += updateSuffix
 			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, instanceStorageVolumes...)
 		}
+		if args.oldPowerState != "" {
+			ctx.oldVM.Spec.PowerState = args.oldPowerState
+		}
+		if args.newPowerState != "" {
+			ctx.vm.Spec.PowerState = args.newPowerState
+		}
+		if args.changeVMMetadata {
+			ctx.vm.Spec.VmMetadata.ConfigMapName += updateSuffix
+		}
+		if args.nextRestartTime != "" {
+			ctx.vm.Spec.NextRestartTime = args.nextRestartTime
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vm)
 		Expect(err).ToNot(HaveOccurred())
@@ -537,12 +756,18 @@ func unitTestsValidateUpdate() {
 
 	msg := "field is immutable"
 	volumesPath := field.NewPath("spec", "volumes")
+	powerStatePath := field.NewPath("spec", "powerState")
+	vmMetadataPath := field.NewPath("spec", "vmMetadata")
+	nextRestartTimePath := field.NewPath("spec", "nextRestartTime")
+	pastRestartTime := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+	futureRestartTime := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+	_, invalidRestartTimeErr := time.Parse(time.RFC3339Nano, "tomorrow")
 
 	DescribeTable("update table", validateUpdate,
 		// Immutable Fields
 		Entry("should allow", updateArgs{}, true, nil, nil),
-		Entry("should deny class name change", updateArgs{changeClassName: true}, false, msg, nil),
 		Entry("should deny image name change", updateArgs{changeImageName: true}, false, msg, nil),
+		Entry("should deny source change", updateArgs{changeSource: true}, false, msg, nil),
 		Entry("should deny storageClass change", updateArgs{changeStorageClass: true}, false, msg, nil),
 		Entry("should deny resourcePolicy change", updateArgs{changeResourcePolicy: true}, false, msg, nil),
 		Entry("should allow initial zone assignment", updateArgs{assignZoneName: true}, true, nil, nil),
@@ -553,6 +778,32 @@ func unitTestsValidateUpdate() {
 			field.Forbidden(volumesPath, "adding or modifying instance storage volume claim(s) is not allowed").Error(), nil),
 		Entry("should allow adding new instance storage volume, when user type is service user", updateArgs{addInstanceStorageVolume: true, isServiceUser: true}, true, nil, nil),
 		Entry("should allow instance storage volume name change, when user type is service user", updateArgs{changeInstanceStorageVolumeName: true, isServiceUser: true}, true, nil, nil),
+
+		// Class
+		Entry("should allow class name change to a class bound to the namespace", updateArgs{changeClassName: true}, true, nil, nil),
+		Entry("should allow class name change when VM is powered on", updateArgs{changeClassName: true, oldPowerState: vmopv1.VirtualMachinePoweredOn, newPowerState: vmopv1.VirtualMachinePoweredOn}, true, nil, nil),
+		Entry("should allow class name change when VM is suspended", updateArgs{changeClassName: true, oldPowerState: vmopv1.VirtualMachineSuspended, newPowerState: vmopv1.VirtualMachineSuspended}, true, nil, nil),
+		Entry("should allow class name change when VM is powered off", updateArgs{changeClassName: true, oldPowerState: vmopv1.VirtualMachinePoweredOff, newPowerState: vmopv1.VirtualMachinePoweredOff}, true, nil, nil),
+		Entry("should deny class name change to a class that does not exist", updateArgs{changeClassNameNotFound: true}, false, "not found", nil),
+		Entry("should deny class name change to a class not bound to the namespace", updateArgs{changeClassNameUnbound: true}, false,
+			"VirtualMachineClass is not bound to the namespace dummy-vm-namespace-for-webhook-validation", nil),
+
+		// Power State
+		Entry("should allow suspending a powered on VM", updateArgs{oldPowerState: vmopv1.VirtualMachinePoweredOn, newPowerState: vmopv1.VirtualMachineSuspended}, true, nil, nil),
+		Entry("should allow resuming a suspended VM", updateArgs{oldPowerState: vmopv1.VirtualMachineSuspended, newPowerState: vmopv1.VirtualMachinePoweredOn}, true, nil, nil),
+		Entry("should allow powering off a suspended VM", updateArgs{oldPowerState: vmopv1.VirtualMachineSuspended, newPowerState: vmopv1.VirtualMachinePoweredOff}, true, nil, nil),
+		Entry("should deny suspending a powered off VM", updateArgs{oldPowerState: vmopv1.VirtualMachinePoweredOff, newPowerState: vmopv1.VirtualMachineSuspended}, false,
+			field.Invalid(powerStatePath, vmopv1.VirtualMachineSuspended, "cannot suspend a VM that is powered off").Error(), nil),
+		Entry("should deny vmMetadata change when VM is suspended", updateArgs{oldPowerState: vmopv1.VirtualMachineSuspended, newPowerState: vmopv1.VirtualMachineSuspended, changeVMMetadata: true}, false,
+			field.Forbidden(vmMetadataPath, "updates to this field is not allowed when VM power is on").Error(), nil),
+		Entry("should allow vmMetadata change when suspended VM is powered off", updateArgs{oldPowerState: vmopv1.VirtualMachineSuspended, newPowerState: vmopv1.VirtualMachinePoweredOff, changeVMMetadata: true}, true, nil, nil),
+
+		// Restart
+		Entry("should allow nextRestartTime in the past", updateArgs{nextRestartTime: pastRestartTime}, true, nil, nil),
+		Entry("should deny nextRestartTime in the future", updateArgs{nextRestartTime: futureRestartTime}, false,
+			field.Invalid(nextRestartTimePath, futureRestartTime, "must not be in the future").Error(), nil),
+		Entry("should deny nextRestartTime that is not a timestamp", updateArgs{nextRestartTime: "tomorrow"}, false,
+			field.Invalid(nextRestartTimePath, "tomorrow", fmt.Sprintf("must be \"now\" or an RFC3339 timestamp: %s", invalidRestartTimeErr)).Error(), nil),
 	)
 
 	When("the update is performed while object deletion", func() {
@@ -1,4 +1,4 @@
-// Copyright (c) 2019-2021 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2022 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package validation
@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,8 +30,9 @@ import (
 const (
 	webHookName = "default"
 
-	invalidCPUReqMsg    = "CPU request must not be larger than the CPU limit"
-	invalidMemoryReqMsg = "memory request must not be larger than the memory limit"
+	invalidCPUReqMsg      = "CPU request must not be larger than the CPU limit"
+	invalidMemoryReqMsg   = "memory request must not be larger than the memory limit"
+	invalidMaxUnavailable = "must be a positive number or percentage"
 )
 
 // +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-virtualmachineclass,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachineclasses,versions=v1alpha1,name=default.validating.virtualmachineclass.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
@@ -72,6 +74,7 @@ func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.
 	var fieldErrs field.ErrorList
 
 	fieldErrs = append(fieldErrs, v.validatePolicies(ctx, vmClass, field.NewPath("spec", "policies"))...)
+	fieldErrs = append(fieldErrs, v.validateUpdatePolicy(vmClass, field.NewPath("spec", "updatePolicy"))...)
 
 	validationErrs := make([]string, 0, len(fieldErrs))
 	for _, fieldErr := range fieldErrs {
@@ -86,7 +89,15 @@ func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Resp
 }
 
 func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	vmClass, err := v.vmClassFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
 	var fieldErrs field.ErrorList
+
+	fieldErrs = append(fieldErrs, v.validateUpdatePolicy(vmClass, field.NewPath("spec", "updatePolicy"))...)
+
 	validationErrs := make([]string, 0, len(fieldErrs))
 	for _, fieldErr := range fieldErrs {
 		validationErrs = append(validationErrs, fieldErr.Error())
@@ -117,6 +128,21 @@ func (v validator) validatePolicies(ctx *context.WebhookRequestContext, vmClass
 	return allErrs
 }
 
+func (v validator) validateUpdatePolicy(vmClass *vmopv1.VirtualMachineClass, updatePolicyPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if maxUnavailable := vmClass.Spec.UpdatePolicy.MaxUnavailable; maxUnavailable != nil {
+		// Scale the value to 100 VMs so that a percentage is validated as a number.
+		value, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, 100, true)
+		if err != nil || value <= 0 {
+			allErrs = append(allErrs, field.Invalid(updatePolicyPath.Child("maxUnavailable"),
+				maxUnavailable.String(), invalidMaxUnavailable))
+		}
+	}
+
+	return allErrs
+}
+
 // vmClassFromUnstructured returns the VirtualMachineClass from the unstructured object.
 func (v validator) vmClassFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineClass, error) {
 	vmClass := &vmopv1.VirtualMachineClass{}
@@ -1,4 +1,4 @@
-// Copyright (c) 2019 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2019-2022 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package validation_test
@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -62,6 +63,7 @@ func unitTestsValidateCreate() {
 		invalidMemoryRequest bool
 		noCPULimit           bool
 		noMemoryLimit        bool
+		maxUnavailable       *intstr.IntOrString
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -81,6 +83,12 @@ func unitTestsValidateCreate() {
 		if args.noMemoryLimit {
 			ctx.vmClass.Spec.Policies.Resources.Limits.Memory = resource.MustParse("0")
 		}
+		if args.maxUnavailable != nil {
+			ctx.vmClass.Spec.UpdatePolicy = vmopv1.VirtualMachineClassUpdatePolicy{
+				Type:           vmopv1.VirtualMachineClassUpdatePolicyRolling,
+				MaxUnavailable: args.maxUnavailable,
+			}
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmClass)
 		Expect(err).ToNot(HaveOccurred())
@@ -105,12 +113,20 @@ func unitTestsValidateCreate() {
 	reqPath := field.NewPath("spec", "policies", "resources", "requests")
 	invalidCPUField := field.Invalid(reqPath.Child("cpu"), "2Gi", "CPU request must not be larger than the CPU limit")
 	invalidMemField := field.Invalid(reqPath.Child("memory"), "2Gi", "memory request must not be larger than the memory limit")
+	maxUnavailablePath := field.NewPath("spec", "updatePolicy", "maxUnavailable")
+	maxUnavailable := func(v intstr.IntOrString) *intstr.IntOrString { return &v }
 	DescribeTable("create table", validateCreate,
 		Entry("should allow valid", createArgs{}, true, nil, nil),
 		Entry("should allow no cpu limit", createArgs{noCPULimit: true}, true, nil, nil),
 		Entry("should allow no memory limit", createArgs{noMemoryLimit: true}, true, nil, nil),
 		Entry("should deny invalid cpu request", createArgs{invalidCPURequest: true}, false, invalidCPUField.Error(), nil),
 		Entry("should deny invalid memory request", createArgs{invalidMemoryRequest: true}, false, invalidMemField.Error(), nil),
+		Entry("should allow maxUnavailable number", createArgs{maxUnavailable: maxUnavailable(intstr.FromInt(2))}, true, nil, nil),
+		Entry("should allow maxUnavailable percentage", createArgs{maxUnavailable: maxUnavailable(intstr.FromString("25%"))}, true, nil, nil),
+		Entry("should deny zero maxUnavailable", createArgs{maxUnavailable: maxUnavailable(intstr.FromInt(0))}, false,
+			field.Invalid(maxUnavailablePath, "0", "must be a positive number or percentage").Error(), nil),
+		Entry("should deny invalid maxUnavailable", createArgs{maxUnavailable: maxUnavailable(intstr.FromString("two"))}, false,
+			field.Invalid(maxUnavailablePath, "two", "must be a positive number or percentage").Error(), nil),
 	)
 }
 
@@ -125,6 +141,8 @@ func unitTestsValidateUpdate() {
 		changeHwMemory bool
 		changeCPU      bool
 		changeMemory   bool
+		changePolicy   bool
+		invalidPolicy  bool
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -144,6 +162,17 @@ func unitTestsValidateUpdate() {
 			ctx.vmClass.Spec.Policies.Resources.Requests.Memory = resource.MustParse("5Gi")
 			ctx.vmClass.Spec.Policies.Resources.Limits.Memory = resource.MustParse("10Gi")
 		}
+		if args.changePolicy {
+			maxUnavailable := intstr.FromString("50%")
+			ctx.vmClass.Spec.UpdatePolicy = vmopv1.VirtualMachineClassUpdatePolicy{
+				Type:           vmopv1.VirtualMachineClassUpdatePolicyRolling,
+				MaxUnavailable: &maxUnavailable,
+			}
+		}
+		if args.invalidPolicy {
+			maxUnavailable := intstr.FromString("0%")
+			ctx.vmClass.Spec.UpdatePolicy.MaxUnavailable = &maxUnavailable
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmClass)
 		Expect(err).ToNot(HaveOccurred())
@@ -183,6 +212,8 @@ func unitTestsValidateUpdate() {
 		Entry("should deny hw memory change", updateArgs{changeHwMemory: true}, true, nil, nil),
 		Entry("should deny policy cpu change", updateArgs{changeCPU: true}, true, nil, nil),
 		Entry("should deny policy memory change", updateArgs{changeMemory: true}, true, nil, nil),
+		Entry("should allow update policy change", updateArgs{changePolicy: true}, true, nil, nil),
+		Entry("should deny invalid maxUnavailable", updateArgs{invalidPolicy: true}, false, nil, nil),
 	)
 
 	When("the update is performed while object deletion", func() {
@@ -33,6 +33,9 @@ import (
 
 const (
 	webHookName = "default"
+
+	// maxClientIPAffinitySeconds is the maximum timeout of the ClientIP session affinity, the same as for a Service.
+	maxClientIPAffinitySeconds = 86400
 )
 
 var (
@@ -47,6 +50,27 @@ var (
 		string(corev1.ProtocolUDP),
 		string(corev1.ProtocolSCTP),
 	)
+
+	supportedSessionAffinity = sets.NewString(
+		string(vmopv1.VirtualMachineServiceSessionAffinityClientIP),
+		string(vmopv1.VirtualMachineServiceSessionAffinityNone),
+	)
+
+	supportedTrafficPolicy = sets.NewString(
+		string(vmopv1.VirtualMachineServiceTrafficPolicyCluster),
+		string(vmopv1.VirtualMachineServiceTrafficPolicyLocal),
+	)
+
+	supportedIPFamilies = sets.NewString(
+		string(vmopv1.IPv4Protocol),
+		string(vmopv1.IPv6Protocol),
+	)
+
+	supportedIPFamilyPolicy = sets.NewString(
+		string(vmopv1.IPFamilyPolicySingleStack),
+		string(vmopv1.IPFamilyPolicyPreferDualStack),
+		string(vmopv1.IPFamilyPolicyRequireDualStack),
+	)
 )
 
 // +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-virtualmachineservice,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachineservices,versions=v1alpha1,name=default.validating.virtualmachineservice.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
@@ -196,6 +220,136 @@ func (v validator) validateSpec(ctx *context.WebhookRequestContext, vmService *v
 		}
 	}
 
+	allErrs = append(allErrs, validateSessionAffinity(vmService, specPath)...)
+	allErrs = append(allErrs, validateTrafficPolicies(vmService, specPath)...)
+	allErrs = append(allErrs, validateIPFamilies(vmService, specPath)...)
+
+	return allErrs
+}
+
+func validateSessionAffinity(vmService *vmopv1.VirtualMachineService, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	affinity := vmService.Spec.SessionAffinity
+	affinityPath := specPath.Child("sessionAffinity")
+
+	if affinity != "" && !supportedSessionAffinity.Has(string(affinity)) {
+		allErrs = append(allErrs, field.NotSupported(affinityPath, affinity, supportedSessionAffinity.List()))
+	}
+
+	if affinity == vmopv1.VirtualMachineServiceSessionAffinityClientIP &&
+		vmService.Spec.Type == vmopv1.VirtualMachineServiceTypeExternalName {
+		allErrs = append(allErrs, field.Forbidden(affinityPath, "may not be 'ClientIP' for ExternalName services"))
+	}
+
+	config := vmService.Spec.SessionAffinityConfig
+	if config == nil {
+		return allErrs
+	}
+	configPath := specPath.Child("sessionAffinityConfig")
+
+	if affinity != vmopv1.VirtualMachineServiceSessionAffinityClientIP {
+		allErrs = append(allErrs, field.Forbidden(configPath, "may only be set when `sessionAffinity` is 'ClientIP'"))
+	} else if config.ClientIP != nil && config.ClientIP.TimeoutSeconds != nil {
+		if timeout := *config.ClientIP.TimeoutSeconds; timeout <= 0 || timeout > maxClientIPAffinitySeconds {
+			allErrs = append(allErrs, field.Invalid(configPath.Child("clientIP", "timeoutSeconds"), timeout,
+				fmt.Sprintf("must be greater than 0 and less than or equal to %d", maxClientIPAffinitySeconds)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateTrafficPolicies(vmService *vmopv1.VirtualMachineService, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if etp := vmService.Spec.ExternalTrafficPolicy; etp != "" {
+		etpPath := specPath.Child("externalTrafficPolicy")
+		if !supportedTrafficPolicy.Has(string(etp)) {
+			allErrs = append(allErrs, field.NotSupported(etpPath, etp, supportedTrafficPolicy.List()))
+		}
+		if vmService.Spec.Type != vmopv1.VirtualMachineServiceTypeLoadBalancer {
+			allErrs = append(allErrs, field.Forbidden(etpPath, "may only be set when `type` is 'LoadBalancer'"))
+		}
+	}
+
+	if itp := vmService.Spec.InternalTrafficPolicy; itp != "" {
+		itpPath := specPath.Child("internalTrafficPolicy")
+		if !supportedTrafficPolicy.Has(string(itp)) {
+			allErrs = append(allErrs, field.NotSupported(itpPath, itp, supportedTrafficPolicy.List()))
+		}
+		if vmService.Spec.Type == vmopv1.VirtualMachineServiceTypeExternalName {
+			allErrs = append(allErrs, field.Forbidden(itpPath, "may not be set for ExternalName services"))
+		}
+	}
+
+	if vmService.Spec.TopologyMode == vmopv1.VirtualMachineServiceTopologyModeAuto &&
+		vmService.Spec.Type == vmopv1.VirtualMachineServiceTypeExternalName {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("topologyMode"), "may not be 'Auto' for ExternalName services"))
+	}
+
+	if port := vmService.Spec.HealthCheckNodePort; port != 0 {
+		hcPath := specPath.Child("healthCheckNodePort")
+		if vmService.Spec.Type != vmopv1.VirtualMachineServiceTypeLoadBalancer ||
+			vmService.Spec.ExternalTrafficPolicy != vmopv1.VirtualMachineServiceTrafficPolicyLocal {
+			allErrs = append(allErrs, field.Forbidden(hcPath,
+				"may only be set when `type` is 'LoadBalancer' and `externalTrafficPolicy` is 'Local'"))
+		}
+		for _, msg := range validation.IsValidPortNum(int(port)) {
+			allErrs = append(allErrs, field.Invalid(hcPath, port, msg))
+		}
+	}
+
+	return allErrs
+}
+
+func validateIPFamilies(vmService *vmopv1.VirtualMachineService, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	ipFamilies := vmService.Spec.IPFamilies
+	ipFamiliesPath := specPath.Child("ipFamilies")
+	policy := vmService.Spec.IPFamilyPolicy
+	policyPath := specPath.Child("ipFamilyPolicy")
+
+	if vmService.Spec.Type == vmopv1.VirtualMachineServiceTypeExternalName {
+		if len(ipFamilies) > 0 {
+			allErrs = append(allErrs, field.Forbidden(ipFamiliesPath, "may not be set for ExternalName services"))
+		}
+		if policy != "" {
+			allErrs = append(allErrs, field.Forbidden(policyPath, "may not be set for ExternalName services"))
+		}
+		return allErrs
+	}
+
+	if policy != "" && !supportedIPFamilyPolicy.Has(string(policy)) {
+		allErrs = append(allErrs, field.NotSupported(policyPath, policy, supportedIPFamilyPolicy.List()))
+	}
+
+	if len(ipFamilies) > 2 {
+		allErrs = append(allErrs, field.Invalid(ipFamiliesPath, ipFamilies, "may specify no more than two IP families"))
+	}
+
+	seen := sets.NewString()
+	for i, ipFamily := range ipFamilies {
+		if !supportedIPFamilies.Has(string(ipFamily)) {
+			allErrs = append(allErrs, field.NotSupported(ipFamiliesPath.Index(i), ipFamily, supportedIPFamilies.List()))
+		} else if seen.Has(string(ipFamily)) {
+			allErrs = append(allErrs, field.Duplicate(ipFamiliesPath.Index(i), ipFamily))
+		}
+		seen.Insert(string(ipFamily))
+	}
+
+	if len(ipFamilies) > 1 && policy == vmopv1.IPFamilyPolicySingleStack {
+		allErrs = append(allErrs, field.Invalid(policyPath, policy,
+			"must be 'RequireDualStack' or 'PreferDualStack' when multiple `ipFamilies` are specified"))
+	}
+
+	// The primary family must match the family of the ClusterIP.
+	if clusterIP := vmService.Spec.ClusterIP; len(ipFamilies) > 0 && clusterIP != "" && clusterIP != corev1.ClusterIPNone {
+		if ip := utilnet.ParseIPSloppy(clusterIP); ip != nil && utilnet.IsIPv6(ip) != (ipFamilies[0] == vmopv1.IPv6Protocol) {
+			allErrs = append(allErrs, field.Invalid(ipFamiliesPath.Index(0), ipFamilies[0],
+				"must match the IP family of `clusterIP`"))
+		}
+	}
+
 	return allErrs
 }
 
@@ -274,6 +428,12 @@ func (v validator) validateAllowedChanges(ctx *context.WebhookRequestContext, vm
 		allErrs = append(allErrs, field.Forbidden(specPath.Child("clusterIP"), "field is immutable"))
 	}
 
+	// Like the Service's, the primary IP family may not be changed, but the secondary family may be added or removed.
+	if old := oldVMService.Spec.IPFamilies; len(old) > 0 && len(vmService.Spec.IPFamilies) > 0 &&
+		old[0] != vmService.Spec.IPFamilies[0] {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("ipFamilies").Index(0), "primary IP family is immutable"))
+	}
+
 	return allErrs
 }
 
@@ -56,14 +56,29 @@ func unitTestsValidateCreate() {
 	)
 
 	type createArgs struct {
-		invalidDNSName        bool
-		emptyType             bool
-		invalidType           bool
-		invalidPorts          bool
-		invalidSelector       bool
-		invalidClusterIP      bool
-		invalidLBSourceRanges bool
-		invalidExternalName   bool
+		invalidDNSName         bool
+		emptyType              bool
+		invalidType            bool
+		invalidPorts           bool
+		invalidSelector        bool
+		invalidClusterIP       bool
+		invalidLBSourceRanges  bool
+		invalidExternalName    bool
+		clientIPAffinity       bool
+		invalidAffinity        bool
+		invalidAffinityConfig  bool
+		invalidAffinityTimeout bool
+		localTrafficPolicies   bool
+		invalidETPType         bool
+		invalidHCNodePort      bool
+		dualStack              bool
+		invalidIPFamily        bool
+		duplicateIPFamily      bool
+		singleStackDualFamily  bool
+		ipFamilyClusterIP      bool
+		externalNameIPFamily   bool
+		topologyModeAuto       bool
+		externalNameTopology   bool
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -96,6 +111,64 @@ func unitTestsValidateCreate() {
 			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeExternalName
 			ctx.vmService.Spec.ExternalName = "InValid!"
 		}
+		if args.clientIPAffinity || args.invalidAffinityTimeout {
+			ctx.vmService.Spec.SessionAffinity = vmopv1.VirtualMachineServiceSessionAffinityClientIP
+			timeout := int32(3600)
+			if args.invalidAffinityTimeout {
+				timeout = 86401
+			}
+			ctx.vmService.Spec.SessionAffinityConfig = &vmopv1.SessionAffinityConfig{
+				ClientIP: &vmopv1.ClientIPConfig{TimeoutSeconds: &timeout},
+			}
+		}
+		if args.invalidAffinity {
+			ctx.vmService.Spec.SessionAffinity = "Sticky"
+		}
+		if args.invalidAffinityConfig {
+			ctx.vmService.Spec.SessionAffinityConfig = &vmopv1.SessionAffinityConfig{}
+		}
+		if args.localTrafficPolicies {
+			ctx.vmService.Spec.ExternalTrafficPolicy = vmopv1.VirtualMachineServiceTrafficPolicyLocal
+			ctx.vmService.Spec.InternalTrafficPolicy = vmopv1.VirtualMachineServiceTrafficPolicyLocal
+			ctx.vmService.Spec.HealthCheckNodePort = 30100
+		}
+		if args.invalidETPType {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeClusterIP
+			ctx.vmService.Spec.ExternalTrafficPolicy = vmopv1.VirtualMachineServiceTrafficPolicyLocal
+		}
+		if args.invalidHCNodePort {
+			ctx.vmService.Spec.HealthCheckNodePort = 30100
+		}
+		if args.dualStack {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv6Protocol, vmopv1.IPv4Protocol}
+			ctx.vmService.Spec.IPFamilyPolicy = vmopv1.IPFamilyPolicyPreferDualStack
+		}
+		if args.invalidIPFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{"IPv5"}
+		}
+		if args.duplicateIPFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol, vmopv1.IPv4Protocol}
+		}
+		if args.singleStackDualFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol, vmopv1.IPv6Protocol}
+			ctx.vmService.Spec.IPFamilyPolicy = vmopv1.IPFamilyPolicySingleStack
+		}
+		if args.ipFamilyClusterIP {
+			ctx.vmService.Spec.ClusterIP = "10.0.0.10"
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv6Protocol}
+		}
+		if args.externalNameIPFamily {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeExternalName
+			ctx.vmService.Spec.ExternalName = "my.service.com"
+			ctx.vmService.Spec.IPFamilyPolicy = vmopv1.IPFamilyPolicySingleStack
+		}
+		if args.topologyModeAuto || args.externalNameTopology {
+			ctx.vmService.Spec.TopologyMode = vmopv1.VirtualMachineServiceTopologyModeAuto
+		}
+		if args.externalNameTopology {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeExternalName
+			ctx.vmService.Spec.ExternalName = "my.service.com"
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmService)
 		Expect(err).ToNot(HaveOccurred())
@@ -127,6 +200,21 @@ func unitTestsValidateCreate() {
 		Entry("should deny invalid ClusterIP", createArgs{invalidClusterIP: true}, false, "spec.clusterIP: Invalid value: \"100.1000.1.1\": must be a valid IP address", nil),
 		Entry("should deny invalid LoadBalancerSourceRanges", createArgs{invalidLBSourceRanges: true}, false, "spec.loadBalancerSourceRanges: Invalid value: \"[10.1.1.1/42]", nil),
 		Entry("should deny invalid ExternalName", createArgs{invalidExternalName: true}, false, "spec.externalName: Invalid value: \"InValid!\": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters", nil),
+		Entry("should allow ClientIP session affinity", createArgs{clientIPAffinity: true}, true, nil, nil),
+		Entry("should deny invalid session affinity", createArgs{invalidAffinity: true}, false, "spec.sessionAffinity: Unsupported value: \"Sticky\"", nil),
+		Entry("should deny session affinity config without ClientIP", createArgs{invalidAffinityConfig: true}, false, "spec.sessionAffinityConfig: Forbidden: may only be set when `sessionAffinity` is 'ClientIP'", nil),
+		Entry("should deny invalid session affinity timeout", createArgs{invalidAffinityTimeout: true}, false, "spec.sessionAffinityConfig.clientIP.timeoutSeconds: Invalid value: 86401", nil),
+		Entry("should allow Local traffic policies", createArgs{localTrafficPolicies: true}, true, nil, nil),
+		Entry("should deny externalTrafficPolicy when not LoadBalancer", createArgs{invalidETPType: true}, false, "spec.externalTrafficPolicy: Forbidden: may only be set when `type` is 'LoadBalancer'", nil),
+		Entry("should deny healthCheckNodePort when externalTrafficPolicy is not Local", createArgs{invalidHCNodePort: true}, false, "spec.healthCheckNodePort: Forbidden", nil),
+		Entry("should allow dual-stack IP families", createArgs{dualStack: true}, true, nil, nil),
+		Entry("should deny invalid IP family", createArgs{invalidIPFamily: true}, false, "spec.ipFamilies[0]: Unsupported value: \"IPv5\"", nil),
+		Entry("should deny duplicate IP families", createArgs{duplicateIPFamily: true}, false, "spec.ipFamilies[1]: Duplicate value: \"IPv4\"", nil),
+		Entry("should deny SingleStack with two IP families", createArgs{singleStackDualFamily: true}, false, "spec.ipFamilyPolicy: Invalid value: \"SingleStack\": must be 'RequireDualStack' or 'PreferDualStack'", nil),
+		Entry("should deny IP family that does not match ClusterIP", createArgs{ipFamilyClusterIP: true}, false, "spec.ipFamilies[0]: Invalid value: \"IPv6\": must match the IP family of `clusterIP`", nil),
+		Entry("should deny IP family policy for ExternalName", createArgs{externalNameIPFamily: true}, false, "spec.ipFamilyPolicy: Forbidden: may not be set for ExternalName services", nil),
+		Entry("should allow Auto topology mode", createArgs{topologyModeAuto: true}, true, nil, nil),
+		Entry("should deny Auto topology mode for ExternalName", createArgs{externalNameTopology: true}, false, "spec.topologyMode: Forbidden: may not be 'Auto' for ExternalName services", nil),
 	)
 
 	validatePortCreate := func(expectedReason string, ports []vmopv1.VirtualMachineServicePort) {
@@ -227,8 +315,10 @@ func unitTestsValidateUpdate() {
 	)
 
 	type updateArgs struct {
-		updateType      bool
-		updateClusterIP bool
+		updateType            bool
+		updateClusterIP       bool
+		addSecondaryIPFamily  bool
+		updatePrimaryIPFamily bool
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -240,6 +330,18 @@ func unitTestsValidateUpdate() {
 		if args.updateClusterIP {
 			ctx.vmService.Spec.ClusterIP = "9.9.9.9"
 		}
+		if args.addSecondaryIPFamily || args.updatePrimaryIPFamily {
+			ctx.oldVMService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol}
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(ctx.oldVMService)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		if args.addSecondaryIPFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol, vmopv1.IPv6Protocol}
+			ctx.vmService.Spec.IPFamilyPolicy = vmopv1.IPFamilyPolicyRequireDualStack
+		}
+		if args.updatePrimaryIPFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv6Protocol}
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmService)
 		Expect(err).ToNot(HaveOccurred())
@@ -265,6 +367,8 @@ func unitTestsValidateUpdate() {
 		Entry("should allow", updateArgs{}, true, nil, nil),
 		Entry("should deny Type change", updateArgs{updateType: true}, false, "spec.type: Forbidden: field is immutable", nil),
 		Entry("should deny ClusterIP change", updateArgs{updateClusterIP: true}, false, "spec.clusterIP: Forbidden: field is immutable", nil),
+		Entry("should allow adding secondary IP family", updateArgs{addSecondaryIPFamily: true}, true, nil, nil),
+		Entry("should deny primary IP family change", updateArgs{updatePrimaryIPFamily: true}, false, "spec.ipFamilies[0]: Forbidden: primary IP family is immutable", nil),
 	)
 
 	When("the update is performed while object deletion", func() {
@@ -0,0 +1,104 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package mutation
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+)
+
+const (
+	webHookName = "default"
+)
+
+// +kubebuilder:webhook:path=/default-mutate-vmoperator-vmware-com-v1alpha1-virtualmachinesnapshot,mutating=true,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachinesnapshots,verbs=create;update,versions=v1alpha1,name=default.mutating.virtualmachinesnapshot.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesnapshots,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesnapshots/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewMutatingWebhook(ctx, mgr, webHookName, NewMutator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create mutation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+
+	return nil
+}
+
+// NewMutator returns the package's Mutator.
+func NewMutator(client client.Client) builder.Mutator {
+	return mutator{
+		client:    client,
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type mutator struct {
+	client    client.Client
+	converter runtime.UnstructuredConverter
+}
+
+func (m mutator) Mutate(ctx *context.WebhookRequestContext) admission.Response {
+	if ctx.Op == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	vmSnapshot, err := m.vmSnapshotFromUnstructured(ctx.Obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	original := vmSnapshot
+	modified := original.DeepCopy()
+
+	if !SetNextRevertTime(ctx, modified) {
+		return admission.Allowed("")
+	}
+
+	rawOriginal, err := json.Marshal(original)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	rawModified, err := json.Marshal(modified)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(rawOriginal, rawModified)
+}
+
+func (m mutator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineSnapshot{}).Name())
+}
+
+// vmSnapshotFromUnstructured returns the VirtualMachineSnapshot from the unstructured object.
+func (m mutator) vmSnapshotFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineSnapshot, error) {
+	vmSnapshot := &vmopv1.VirtualMachineSnapshot{}
+	if err := m.converter.FromUnstructured(obj.UnstructuredContent(), vmSnapshot); err != nil {
+		return nil, err
+	}
+	return vmSnapshot, nil
+}
+
+// SetNextRevertTime sets spec.nextRevertTime to the current time as an RFC3339Nano timestamp in UTC
+// if the field's value is "now" (case-insensitive).
+// Return true if spec.nextRevertTime is mutated, otherwise return false.
+func SetNextRevertTime(ctx *context.WebhookRequestContext, vmSnapshot *vmopv1.VirtualMachineSnapshot) bool {
+	return util.SetTriggerTimeIfNow(&vmSnapshot.Spec.NextRevertTime)
+}
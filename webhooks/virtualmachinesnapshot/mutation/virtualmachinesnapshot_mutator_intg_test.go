@@ -0,0 +1,59 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package mutation_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking Mutation", intgTestsMutating)
+}
+
+func intgTestsMutating() {
+	var (
+		ctx        *builder.IntegrationTestContext
+		vmSnapshot *vmopv1.VirtualMachineSnapshot
+	)
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+		vmSnapshot = builder.DummyVirtualMachineSnapshot(ctx.Namespace, "dummy-snapshot", "dummy-vm")
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	Describe("mutate", func() {
+		When("nextRevertTime is set to now on update", func() {
+			BeforeEach(func() {
+				Expect(ctx.Client.Create(ctx, vmSnapshot)).To(Succeed())
+			})
+			AfterEach(func() {
+				Expect(ctx.Client.Delete(ctx, vmSnapshot)).To(Succeed())
+			})
+
+			It("should set nextRevertTime to the current time", func() {
+				before := time.Now().UTC().Truncate(time.Second)
+				vmSnapshot.Spec.NextRevertTime = "now"
+				Expect(ctx.Client.Update(ctx, vmSnapshot)).To(Succeed())
+
+				modified := &vmopv1.VirtualMachineSnapshot{}
+				Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(vmSnapshot), modified)).To(Succeed())
+				t, err := time.Parse(time.RFC3339Nano, modified.Spec.NextRevertTime)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(t).ToNot(BeTemporally("<", before))
+			})
+		})
+	})
+}
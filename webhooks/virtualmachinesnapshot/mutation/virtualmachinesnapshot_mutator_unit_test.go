@@ -0,0 +1,91 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package mutation_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinesnapshot/mutation"
+)
+
+func unitTests() {
+	Describe("Invoking Mutate", unitTestsMutating)
+}
+
+type unitMutationWebhookContext struct {
+	builder.UnitTestContextForMutatingWebhook
+	vmSnapshot *vmopv1.VirtualMachineSnapshot
+}
+
+func newUnitTestContextForMutatingWebhook() *unitMutationWebhookContext {
+	vmSnapshot := builder.DummyVirtualMachineSnapshot("dummy-ns", "dummy-snapshot", "dummy-vm")
+	obj, err := builder.ToUnstructured(vmSnapshot)
+	Expect(err).ToNot(HaveOccurred())
+
+	return &unitMutationWebhookContext{
+		UnitTestContextForMutatingWebhook: *suite.NewUnitTestContextForMutatingWebhook(obj),
+		vmSnapshot:                        vmSnapshot,
+	}
+}
+
+func unitTestsMutating() {
+	var (
+		ctx *unitMutationWebhookContext
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForMutatingWebhook()
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	Describe("Mutate", func() {
+		It("Should patch nextRevertTime when it is now", func() {
+			ctx.vmSnapshot.Spec.NextRevertTime = "now"
+			obj, err := builder.ToUnstructured(ctx.vmSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			ctx.WebhookRequestContext.Obj = obj
+			ctx.WebhookRequestContext.Op = admissionv1.Update
+
+			response := ctx.Mutate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(HaveLen(1))
+			Expect(response.Patches[0].Path).To(Equal("/spec/nextRevertTime"))
+		})
+
+		It("Should not patch when nextRevertTime is empty", func() {
+			response := ctx.Mutate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(BeEmpty())
+		})
+	})
+
+	Describe("SetNextRevertTime", func() {
+		It("Should set nextRevertTime to the current time when it is now", func() {
+			ctx.vmSnapshot.Spec.NextRevertTime = "NOW"
+			before := time.Now().UTC()
+			Expect(mutation.SetNextRevertTime(&ctx.WebhookRequestContext, ctx.vmSnapshot)).To(BeTrue())
+			t, err := time.Parse(time.RFC3339Nano, ctx.vmSnapshot.Spec.NextRevertTime)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t).ToNot(BeTemporally("<", before))
+		})
+
+		It("Should not mutate nextRevertTime when it is a timestamp", func() {
+			nextRevertTime := time.Now().UTC().Format(time.RFC3339Nano)
+			ctx.vmSnapshot.Spec.NextRevertTime = nextRevertTime
+			Expect(mutation.SetNextRevertTime(&ctx.WebhookRequestContext, ctx.vmSnapshot)).To(BeFalse())
+			Expect(ctx.vmSnapshot.Spec.NextRevertTime).To(Equal(nextRevertTime))
+		})
+	})
+}
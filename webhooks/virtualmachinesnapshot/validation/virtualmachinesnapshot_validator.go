@@ -0,0 +1,172 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+
+	nextRevertTimeOnCreate = "cannot revert to a snapshot that has not been taken"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha1-virtualmachinesnapshot,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachinesnapshots,versions=v1alpha1,name=default.validating.virtualmachinesnapshot.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesnapshots,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesnapshots/status,verbs=get
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create VirtualMachineSnapshot validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(client client.Client) builder.Validator {
+	return validator{
+		client:    client,
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	client    client.Client
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineSnapshot{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	vmSnapshot, err := v.vmSnapshotFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+
+	fieldErrs = append(fieldErrs, v.validateVirtualMachine(ctx, vmSnapshot)...)
+
+	if vmSnapshot.Spec.NextRevertTime != "" {
+		fieldErrs = append(fieldErrs, field.Forbidden(field.NewPath("spec", "nextRevertTime"), nextRevertTimeOnCreate))
+	}
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+// ValidateUpdate validates if the given VirtualMachineSnapshotSpec update is valid.
+// Only the NextRevertTime field may be updated.
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	vmSnapshot, err := v.vmSnapshotFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldVMSnapshot, err := v.vmSnapshotFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+
+	fieldErrs = append(fieldErrs, v.validateImmutableFields(vmSnapshot, oldVMSnapshot)...)
+	fieldErrs = append(fieldErrs, v.validateNextRevertTime(vmSnapshot, oldVMSnapshot)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, validationErrs, nil)
+}
+
+// validateVirtualMachine validates that the VirtualMachine exists. The VirtualMachine is always looked up in the
+// namespace of the VirtualMachineSnapshot so a VirtualMachine in another namespace cannot be snapshot.
+func (v validator) validateVirtualMachine(ctx *context.WebhookRequestContext, vmSnapshot *vmopv1.VirtualMachineSnapshot) field.ErrorList {
+	var allErrs field.ErrorList
+
+	vmNamePath := field.NewPath("spec", "virtualMachineName")
+	vmName := vmSnapshot.Spec.VirtualMachineName
+
+	if vmName == "" {
+		return append(allErrs, field.Required(vmNamePath, ""))
+	}
+
+	vm := &vmopv1.VirtualMachine{}
+	if err := v.client.Get(ctx, client.ObjectKey{Name: vmName, Namespace: vmSnapshot.Namespace}, vm); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return append(allErrs, field.NotFound(vmNamePath, vmName))
+		}
+		return append(allErrs, field.Invalid(vmNamePath, vmName, err.Error()))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateImmutableFields(vmSnapshot, oldVMSnapshot *vmopv1.VirtualMachineSnapshot) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validation.ValidateImmutableField(vmSnapshot.Spec.VirtualMachineName, oldVMSnapshot.Spec.VirtualMachineName, specPath.Child("virtualMachineName"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(vmSnapshot.Spec.Description, oldVMSnapshot.Spec.Description, specPath.Child("description"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(vmSnapshot.Spec.Memory, oldVMSnapshot.Spec.Memory, specPath.Child("memory"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(vmSnapshot.Spec.Quiesce, oldVMSnapshot.Spec.Quiesce, specPath.Child("quiesce"))...)
+
+	return allErrs
+}
+
+func (v validator) validateNextRevertTime(vmSnapshot, oldVMSnapshot *vmopv1.VirtualMachineSnapshot) field.ErrorList {
+	var allErrs field.ErrorList
+
+	nextRevertTimePath := field.NewPath("spec", "nextRevertTime")
+	allErrs = append(allErrs, util.ValidateTriggerTime(nextRevertTimePath,
+		vmSnapshot.Spec.NextRevertTime, oldVMSnapshot.Spec.NextRevertTime)...)
+
+	return allErrs
+}
+
+// vmSnapshotFromUnstructured returns the VirtualMachineSnapshot from the unstructured object.
+func (v validator) vmSnapshotFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineSnapshot, error) {
+	vmSnapshot := &vmopv1.VirtualMachineSnapshot{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), vmSnapshot); err != nil {
+		return nil, err
+	}
+	return vmSnapshot, nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking Create", intgTestsValidateCreate)
+	Describe("Invoking Update", intgTestsValidateUpdate)
+}
+
+type intgValidatingWebhookContext struct {
+	builder.IntegrationTestContext
+	vm         *vmopv1.VirtualMachine
+	vmSnapshot *vmopv1.VirtualMachineSnapshot
+}
+
+func newIntgValidatingWebhookContext() *intgValidatingWebhookContext {
+	ctx := &intgValidatingWebhookContext{
+		IntegrationTestContext: *suite.NewIntegrationTestContext(),
+	}
+
+	ctx.vm = builder.DummyVirtualMachine()
+	ctx.vm.Name = "dummy-vm"
+	ctx.vm.Namespace = ctx.Namespace
+	ctx.vmSnapshot = builder.DummyVirtualMachineSnapshot(ctx.Namespace, "dummy-snapshot", ctx.vm.Name)
+
+	return ctx
+}
+
+func intgTestsValidateCreate() {
+	var (
+		err error
+		ctx *intgValidatingWebhookContext
+	)
+
+	BeforeEach(func() {
+		ctx = newIntgValidatingWebhookContext()
+	})
+	AfterEach(func() {
+		err = nil
+		ctx = nil
+	})
+
+	When("create is performed for a VM that exists", func() {
+		BeforeEach(func() {
+			Expect(ctx.Client.Create(ctx, ctx.vm)).To(Succeed())
+			err = ctx.Client.Create(ctx, ctx.vmSnapshot)
+		})
+		It("should allow the request", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("create is performed for a VM that does not exist", func() {
+		BeforeEach(func() {
+			err = ctx.Client.Create(ctx, ctx.vmSnapshot)
+		})
+		It("should deny the request", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func intgTestsValidateUpdate() {
+	var (
+		err error
+		ctx *intgValidatingWebhookContext
+	)
+
+	BeforeEach(func() {
+		ctx = newIntgValidatingWebhookContext()
+		Expect(ctx.Client.Create(ctx, ctx.vm)).To(Succeed())
+		Expect(ctx.Client.Create(ctx, ctx.vmSnapshot)).To(Succeed())
+	})
+	JustBeforeEach(func() {
+		err = ctx.Client.Update(suite, ctx.vmSnapshot)
+	})
+	AfterEach(func() {
+		err = nil
+		ctx = nil
+	})
+
+	When("update is performed with changed vm name", func() {
+		BeforeEach(func() {
+			ctx.vmSnapshot.Spec.VirtualMachineName = "alternate-vm-name"
+		})
+		It("should deny the request", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	vm            *vmopv1.VirtualMachine
+	vmSnapshot    *vmopv1.VirtualMachineSnapshot
+	oldVMSnapshot *vmopv1.VirtualMachineSnapshot
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	vm := builder.DummyVirtualMachine()
+	vm.Name = "dummy-vm"
+	vm.Namespace = "dummy-ns"
+
+	vmSnapshot := builder.DummyVirtualMachineSnapshot(vm.Namespace, "dummy-snapshot", vm.Name)
+	obj, err := builder.ToUnstructured(vmSnapshot)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldVMSnapshot *vmopv1.VirtualMachineSnapshot
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldVMSnapshot = vmSnapshot.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldVMSnapshot)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj, vm),
+		vm:                                  vm,
+		vmSnapshot:                          vmSnapshot,
+		oldVMSnapshot:                       oldVMSnapshot,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		emptyVirtualMachineName bool
+		vmInOtherNamespace      bool
+		vmNotFound              bool
+		setNextRevertTime       bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.emptyVirtualMachineName {
+			ctx.vmSnapshot.Spec.VirtualMachineName = ""
+		}
+		if args.vmInOtherNamespace {
+			ctx.vmSnapshot.Namespace = "other-ns"
+		}
+		if args.vmNotFound {
+			ctx.vmSnapshot.Spec.VirtualMachineName = "missing-vm"
+		}
+		if args.setNextRevertTime {
+			ctx.vmSnapshot.Spec.NextRevertTime = time.Now().UTC().Format(time.RFC3339Nano)
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmSnapshot)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(Equal(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, nil, nil),
+		Entry("should deny empty virtualMachineName", createArgs{emptyVirtualMachineName: true}, false, "spec.virtualMachineName: Required value", nil),
+		Entry("should deny VM that does not exist", createArgs{vmNotFound: true}, false, "spec.virtualMachineName: Not found: \"missing-vm\"", nil),
+		Entry("should deny VM in another namespace", createArgs{vmInOtherNamespace: true}, false, "spec.virtualMachineName: Not found: \"dummy-vm\"", nil),
+		Entry("should deny nextRevertTime", createArgs{setNextRevertTime: true}, false, "spec.nextRevertTime: Forbidden: cannot revert to a snapshot that has not been taken", nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	type updateArgs struct {
+		updateVirtualMachineName bool
+		updateDescription        bool
+		updateMemory             bool
+		updateQuiesce            bool
+		nextRevertTime           string
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.updateVirtualMachineName {
+			ctx.vmSnapshot.Spec.VirtualMachineName = "new-vm-name"
+		}
+		if args.updateDescription {
+			ctx.vmSnapshot.Spec.Description = "new-description"
+		}
+		if args.updateMemory {
+			ctx.vmSnapshot.Spec.Memory = true
+		}
+		if args.updateQuiesce {
+			ctx.vmSnapshot.Spec.Quiesce = true
+		}
+		ctx.vmSnapshot.Spec.NextRevertTime = args.nextRevertTime
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmSnapshot)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(Equal(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	pastTime := time.Now().UTC().Add(-time.Minute).Format(time.RFC3339Nano)
+	futureTime := time.Now().UTC().Add(time.Hour).Format(time.RFC3339Nano)
+	_, parseErr := time.Parse(time.RFC3339Nano, "tomorrow")
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, nil, nil),
+		Entry("should deny virtualMachineName change", updateArgs{updateVirtualMachineName: true}, false, "spec.virtualMachineName: Invalid value: \"new-vm-name\": field is immutable", nil),
+		Entry("should deny description change", updateArgs{updateDescription: true}, false, "spec.description: Invalid value: \"new-description\": field is immutable", nil),
+		Entry("should deny memory change", updateArgs{updateMemory: true}, false, "spec.memory: Invalid value: true: field is immutable", nil),
+		Entry("should deny quiesce change", updateArgs{updateQuiesce: true}, false, "spec.quiesce: Invalid value: true: field is immutable", nil),
+		Entry("should allow nextRevertTime in the past", updateArgs{nextRevertTime: pastTime}, true, nil, nil),
+		Entry("should deny nextRevertTime in the future", updateArgs{nextRevertTime: futureTime}, false,
+			fmt.Sprintf("spec.nextRevertTime: Invalid value: %q: must not be in the future", futureTime), nil),
+		Entry("should deny invalid nextRevertTime", updateArgs{nextRevertTime: "tomorrow"}, false,
+			fmt.Sprintf("spec.nextRevertTime: Invalid value: \"tomorrow\": must be \"now\" or an RFC3339 timestamp: %s", parseErr), nil),
+	)
+
+	When("the update is performed while object deletion", func() {
+		JustBeforeEach(func() {
+			t := metav1.Now()
+			ctx.WebhookRequestContext.Obj.SetDeletionTimestamp(&t)
+			response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
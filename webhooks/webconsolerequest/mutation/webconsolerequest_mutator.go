@@ -0,0 +1,111 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package mutation
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+const (
+	webHookName = "default"
+)
+
+// +kubebuilder:webhook:path=/default-mutate-vmoperator-vmware-com-v1alpha1-webconsolerequest,mutating=true,failurePolicy=fail,groups=vmoperator.vmware.com,resources=webconsolerequests,verbs=create,versions=v1alpha1,name=default.mutating.webconsolerequest.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=webconsolerequests,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=webconsolerequests/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewMutatingWebhook(ctx, mgr, webHookName, NewMutator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create mutation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+
+	return nil
+}
+
+// NewMutator returns the package's Mutator.
+func NewMutator(_ client.Client) builder.Mutator {
+	return mutator{
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type mutator struct {
+	converter runtime.UnstructuredConverter
+}
+
+func (m mutator) Mutate(ctx *context.WebhookRequestContext) admission.Response {
+	if ctx.Op != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	wcr, err := m.webConsoleRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	original := wcr
+	modified := original.DeepCopy()
+
+	if !SetRequestedBy(ctx, modified) {
+		return admission.Allowed("")
+	}
+
+	rawOriginal, err := json.Marshal(original)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	rawModified, err := json.Marshal(modified)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(rawOriginal, rawModified)
+}
+
+func (m mutator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.WebConsoleRequest{}).Name())
+}
+
+// webConsoleRequestFromUnstructured returns the WebConsoleRequest from the unstructured object.
+func (m mutator) webConsoleRequestFromUnstructured(obj runtime.Unstructured) (*vmopv1.WebConsoleRequest, error) {
+	wcr := &vmopv1.WebConsoleRequest{}
+	if err := m.converter.FromUnstructured(obj.UnstructuredContent(), wcr); err != nil {
+		return nil, err
+	}
+	return wcr, nil
+}
+
+// SetRequestedBy sets the requested-by annotation to the authenticated user of the admission request, overwriting
+// any value supplied by the client.
+// Return true if the annotation is mutated, otherwise return false.
+func SetRequestedBy(ctx *context.WebhookRequestContext, wcr *vmopv1.WebConsoleRequest) bool {
+	username := ctx.UserInfo.Username
+	if val, ok := wcr.Annotations[webconsolerequest.RequestedByAnnotationKey]; ok && val == username {
+		return false
+	}
+
+	if wcr.Annotations == nil {
+		wcr.Annotations = map[string]string{}
+	}
+	wcr.Annotations[webconsolerequest.RequestedByAnnotationKey] = username
+	return true
+}
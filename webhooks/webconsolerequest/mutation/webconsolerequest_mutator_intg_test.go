@@ -0,0 +1,55 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package mutation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking Mutation", intgTestsMutating)
+}
+
+func intgTestsMutating() {
+	var (
+		ctx *builder.IntegrationTestContext
+		wcr *vmopv1.WebConsoleRequest
+	)
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+		_, publicKeyPem := builder.WebConsoleRequestKeyPair()
+		wcr = builder.DummyWebConsoleRequest(ctx.Namespace, "dummy-wcr", "dummy-vm", publicKeyPem)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	Describe("mutate", func() {
+		When("a client supplies its own requested-by annotation on create", func() {
+			BeforeEach(func() {
+				wcr.Annotations = map[string]string{webconsolerequest.RequestedByAnnotationKey: "someone-else"}
+				Expect(ctx.Client.Create(ctx, wcr)).To(Succeed())
+			})
+			AfterEach(func() {
+				Expect(ctx.Client.Delete(ctx, wcr)).To(Succeed())
+			})
+
+			It("should overwrite it with the authenticated user", func() {
+				modified := &vmopv1.WebConsoleRequest{}
+				Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(wcr), modified)).To(Succeed())
+				Expect(modified.Annotations).To(HaveKey(webconsolerequest.RequestedByAnnotationKey))
+				Expect(modified.Annotations[webconsolerequest.RequestedByAnnotationKey]).ToNot(Equal("someone-else"))
+			})
+		})
+	})
+}
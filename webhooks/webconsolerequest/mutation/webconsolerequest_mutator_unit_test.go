@@ -0,0 +1,89 @@
+// Copyright (c) 2022 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package mutation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+	"github.com/vmware-tanzu/vm-operator/webhooks/webconsolerequest/mutation"
+)
+
+func unitTests() {
+	Describe("Invoking Mutate", unitTestsMutating)
+}
+
+type unitMutationWebhookContext struct {
+	builder.UnitTestContextForMutatingWebhook
+	wcr *vmopv1.WebConsoleRequest
+}
+
+func newUnitTestContextForMutatingWebhook() *unitMutationWebhookContext {
+	_, publicKeyPem := builder.WebConsoleRequestKeyPair()
+	wcr := builder.DummyWebConsoleRequest("dummy-ns", "dummy-wcr", "dummy-vm", publicKeyPem)
+	obj, err := builder.ToUnstructured(wcr)
+	Expect(err).ToNot(HaveOccurred())
+
+	ctx := &unitMutationWebhookContext{
+		UnitTestContextForMutatingWebhook: *suite.NewUnitTestContextForMutatingWebhook(obj),
+		wcr:                               wcr,
+	}
+	ctx.WebhookRequestContext.Op = admissionv1.Create
+	ctx.WebhookRequestContext.UserInfo.Username = "dummy-user"
+	return ctx
+}
+
+func unitTestsMutating() {
+	var (
+		ctx *unitMutationWebhookContext
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForMutatingWebhook()
+	})
+
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	Describe("Mutate", func() {
+		It("Should patch the requested-by annotation on create", func() {
+			response := ctx.Mutate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(HaveLen(1))
+			Expect(response.Patches[0].Path).To(Equal("/metadata/annotations"))
+		})
+
+		It("Should not patch on update", func() {
+			ctx.WebhookRequestContext.Op = admissionv1.Update
+			response := ctx.Mutate(&ctx.WebhookRequestContext)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(BeEmpty())
+		})
+	})
+
+	Describe("SetRequestedBy", func() {
+		It("Should set the annotation to the requesting user", func() {
+			Expect(mutation.SetRequestedBy(&ctx.WebhookRequestContext, ctx.wcr)).To(BeTrue())
+			Expect(ctx.wcr.Annotations).To(HaveKeyWithValue(webconsolerequest.RequestedByAnnotationKey, "dummy-user"))
+		})
+
+		It("Should overwrite a value supplied by the client", func() {
+			ctx.wcr.Annotations = map[string]string{webconsolerequest.RequestedByAnnotationKey: "someone-else"}
+			Expect(mutation.SetRequestedBy(&ctx.WebhookRequestContext, ctx.wcr)).To(BeTrue())
+			Expect(ctx.wcr.Annotations).To(HaveKeyWithValue(webconsolerequest.RequestedByAnnotationKey, "dummy-user"))
+		})
+
+		It("Should not mutate when the annotation already matches", func() {
+			ctx.wcr.Annotations = map[string]string{webconsolerequest.RequestedByAnnotationKey: "dummy-user"}
+			Expect(mutation.SetRequestedBy(&ctx.WebhookRequestContext, ctx.wcr)).To(BeFalse())
+		})
+	})
+}
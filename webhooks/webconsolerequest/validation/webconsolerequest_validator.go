@@ -6,8 +6,10 @@ package validation
 import (
 	"crypto/x509"
 	"encoding/pem"
+	"fmt"
 	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/validation"
@@ -101,6 +103,7 @@ func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.
 	var fieldErrs field.ErrorList
 	fieldErrs = append(fieldErrs, v.validateImmutableFields(wcr, oldwcr)...)
 	fieldErrs = append(fieldErrs, v.validateUUIDLabel(wcr, oldwcr)...)
+	fieldErrs = append(fieldErrs, v.validateRequestedByAnnotation(wcr, oldwcr)...)
 
 	validationErrs := make([]string, 0, len(fieldErrs))
 	for _, fieldErr := range fieldErrs {
@@ -120,10 +123,28 @@ func (v validator) validateSpec(ctx *context.WebhookRequestContext, wcr *vmopv1.
 
 	fieldErrs = append(fieldErrs, v.validateVirtualMachineName(specPath.Child("virtualMachineName"), wcr)...)
 	fieldErrs = append(fieldErrs, v.validatePublicKey(specPath.Child("publicKey"), wcr.Spec.PublicKey)...)
+	fieldErrs = append(fieldErrs, v.validateTTLSeconds(specPath.Child("ttlSeconds"), wcr.Spec.TTLSeconds)...)
 
 	return fieldErrs
 }
 
+func (v validator) validateTTLSeconds(path *field.Path, ttlSeconds *int32) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if ttlSeconds == nil {
+		return allErrs
+	}
+
+	if *ttlSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(path, *ttlSeconds, "must be greater than 0"))
+	} else if maxTTL := lib.GetWebConsoleRequestMaxTTL(); time.Duration(*ttlSeconds)*time.Second > maxTTL {
+		allErrs = append(allErrs, field.Invalid(path, *ttlSeconds,
+			fmt.Sprintf("must not be greater than the maximum of %d seconds", int64(maxTTL.Seconds()))))
+	}
+
+	return allErrs
+}
+
 func (v validator) validateVirtualMachineName(path *field.Path, wcr *vmopv1.WebConsoleRequest) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -165,6 +186,9 @@ func (v validator) validateImmutableFields(wcr, oldwcr *vmopv1.WebConsoleRequest
 
 	allErrs = append(allErrs, validation.ValidateImmutableField(wcr.Spec.VirtualMachineName, oldwcr.Spec.VirtualMachineName, specPath.Child("virtualMachineName"))...)
 	allErrs = append(allErrs, validation.ValidateImmutableField(wcr.Spec.PublicKey, oldwcr.Spec.PublicKey, specPath.Child("publicKey"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(wcr.Spec.ConsoleType, oldwcr.Spec.ConsoleType, specPath.Child("consoleType"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(wcr.Spec.TTLSeconds, oldwcr.Spec.TTLSeconds, specPath.Child("ttlSeconds"))...)
+	allErrs = append(allErrs, validation.ValidateImmutableField(wcr.Spec.OneTimeUse, oldwcr.Spec.OneTimeUse, specPath.Child("oneTimeUse"))...)
 
 	return allErrs
 }
@@ -192,3 +216,9 @@ func (v validator) validateUUIDLabel(wcr, oldwcr *vmopv1.WebConsoleRequest) fiel
 
 	return allErrs
 }
+
+func (v validator) validateRequestedByAnnotation(wcr, oldwcr *vmopv1.WebConsoleRequest) field.ErrorList {
+	key := webconsolerequest.RequestedByAnnotationKey
+	annotationsPath := field.NewPath("metadata", "annotations")
+	return validation.ValidateImmutableField(wcr.Annotations[key], oldwcr.Annotations[key], annotationsPath.Key(key))
+}
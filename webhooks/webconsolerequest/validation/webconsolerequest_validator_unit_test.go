@@ -14,6 +14,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/vmware-tanzu/vm-operator/controllers/webconsolerequest"
@@ -41,6 +42,9 @@ func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhoo
 	wcr.Labels = map[string]string{
 		webconsolerequest.UUIDLabelKey: "some-uuid",
 	}
+	wcr.Annotations = map[string]string{
+		webconsolerequest.RequestedByAnnotationKey: "some-user",
+	}
 	obj, err := builder.ToUnstructured(wcr)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -72,6 +76,7 @@ func unitTestsValidateCreate() {
 		emptyVirtualMachineName    bool
 		emptyPublicKey             bool
 		invalidPublicKey           bool
+		ttlSeconds                 *int32
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -90,6 +95,7 @@ func unitTestsValidateCreate() {
 		if args.invalidPublicKey {
 			ctx.wcr.Spec.PublicKey = "invalid-public-key"
 		}
+		ctx.wcr.Spec.TTLSeconds = args.ttlSeconds
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.wcr)
 		Expect(err).ToNot(HaveOccurred())
@@ -119,6 +125,9 @@ func unitTestsValidateCreate() {
 		Entry("should deny empty virtualmachinename", createArgs{emptyVirtualMachineName: true}, false, "spec.virtualMachineName: Required value", nil),
 		Entry("should deny empty publickey", createArgs{emptyPublicKey: true}, false, "spec.publicKey: Required value", nil),
 		Entry("should deny invalid publickey", createArgs{invalidPublicKey: true}, false, "spec.publicKey: Invalid value: \"\": invalid public key format", nil),
+		Entry("should allow ttlSeconds within the maximum", createArgs{ttlSeconds: pointer.Int32(300)}, true, nil, nil),
+		Entry("should deny zero ttlSeconds", createArgs{ttlSeconds: pointer.Int32(0)}, false, "spec.ttlSeconds: Invalid value: 0: must be greater than 0", nil),
+		Entry("should deny ttlSeconds greater than the maximum", createArgs{ttlSeconds: pointer.Int32(3600)}, false, "spec.ttlSeconds: Invalid value: 3600: must not be greater than the maximum of 600 seconds", nil),
 	)
 }
 
@@ -131,7 +140,11 @@ func unitTestsValidateUpdate() {
 	type updateArgs struct {
 		updateVirtualMachineName bool
 		updatePublicKey          bool
+		updateConsoleType        bool
+		updateTTLSeconds         bool
+		updateOneTimeUse         bool
 		updateUUIDLabel          bool
+		updateRequestedBy        bool
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -145,10 +158,26 @@ func unitTestsValidateUpdate() {
 			ctx.wcr.Spec.PublicKey = "new-public-key"
 		}
 
+		if args.updateConsoleType {
+			ctx.wcr.Spec.ConsoleType = vmopv1.WebConsoleTypeSerial
+		}
+
+		if args.updateTTLSeconds {
+			ctx.wcr.Spec.TTLSeconds = pointer.Int32(60)
+		}
+
+		if args.updateOneTimeUse {
+			ctx.wcr.Spec.OneTimeUse = true
+		}
+
 		if args.updateUUIDLabel {
 			ctx.wcr.Labels[webconsolerequest.UUIDLabelKey] = "new-uuid"
 		}
 
+		if args.updateRequestedBy {
+			ctx.wcr.Annotations[webconsolerequest.RequestedByAnnotationKey] = "new-user"
+		}
+
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured((ctx.wcr))
 		Expect(err).ToNot(HaveOccurred())
 
@@ -173,7 +202,11 @@ func unitTestsValidateUpdate() {
 		Entry("should allow", updateArgs{}, true, nil, nil),
 		Entry("should deny VirtualmachineName change", updateArgs{updateVirtualMachineName: true}, false, "spec.virtualMachineName: Invalid value: \"new-vm-name\": field is immutable", nil),
 		Entry("should deny PublicKey change", updateArgs{updatePublicKey: true}, false, "spec.publicKey: Invalid value: \"new-public-key\": field is immutable", nil),
+		Entry("should deny ConsoleType change", updateArgs{updateConsoleType: true}, false, "spec.consoleType: Invalid value: \"Serial\": field is immutable", nil),
+		Entry("should deny TTLSeconds change", updateArgs{updateTTLSeconds: true}, false, "spec.ttlSeconds: Invalid value: 60: field is immutable", nil),
+		Entry("should deny OneTimeUse change", updateArgs{updateOneTimeUse: true}, false, "spec.oneTimeUse: Invalid value: true: field is immutable", nil),
 		Entry("should deny UUID label change", updateArgs{updateUUIDLabel: true}, false, "metadata.labels[vmoperator.vmware.com/webconsolerequest-uuid]: Invalid value: \"new-uuid\": field is immutable", nil),
+		Entry("should deny requested-by annotation change", updateArgs{updateRequestedBy: true}, false, "metadata.annotations[vmoperator.vmware.com/webconsolerequest-requested-by]: Invalid value: \"new-user\": field is immutable", nil),
 	)
 
 	When("the update is performed while object deletion", func() {
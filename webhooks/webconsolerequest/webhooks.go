@@ -9,6 +9,7 @@ import (
 	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/webhooks/webconsolerequest/mutation"
 	"github.com/vmware-tanzu/vm-operator/webhooks/webconsolerequest/validation"
 )
 
@@ -16,5 +17,8 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) er
 	if err := validation.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize validation webhook")
 	}
+	if err := mutation.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize mutation webhook")
+	}
 	return nil
 }